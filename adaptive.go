@@ -0,0 +1,89 @@
+package slru
+
+import (
+	"cmp"
+	"time"
+
+	"github.com/hey-kong/slru/list"
+)
+
+// DefaultAdaptiveMinTTL is the shortest TTL of AdaptiveTTL unless set.
+const DefaultAdaptiveMinTTL = time.Second
+
+// AdaptiveTTL configures WithAdaptiveTTL.
+type AdaptiveTTL struct {
+	// MinTTL and MaxTTL bound the TTLs given. Keys without a history get
+	// MinTTL, DefaultAdaptiveMinTTL if zero. A zero MaxTTL is unbounded.
+	MinTTL, MaxTTL time.Duration
+
+	// History is the number of keys whose changes are remembered, beyond
+	// the lifetime of their entries, the size of the cache if zero.
+	History int
+}
+
+// WithAdaptiveTTL gives the entries written without an explicit TTL one
+// following how often their value changes, in place of WithTTL,
+// WithSegmentTTLs and WithTTLFunc: keys whose value changes rapidly get
+// short TTLs and those whose value stays the same get longer ones.
+//
+// The cache remembers the last value of recently written keys, and when it
+// changed. A write of a different value, compared as by CompareAndSwap,
+// averages the time since the last change into the interval between
+// changes of the key. The TTL given is that interval, or twice the time
+// since the last change if longer, so a stable key reloaded when it
+// expires sees its TTL double each time, within the bounds of cfg.
+func WithAdaptiveTTL[K comparable, V any](cfg AdaptiveTTL) Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		cfg.MinTTL = cmp.Or(cfg.MinTTL, DefaultAdaptiveMinTTL)
+		s.adaptive = &cfg
+	}
+}
+
+// changeHistory is the last value of a key and when it changed.
+type changeHistory[K comparable, V any] struct {
+	key     K
+	value   V
+	changed time.Time
+	// interval is the average time between changes, zero until one.
+	interval time.Duration
+}
+
+// startAdaptiveTTL starts the history of changes, if enabled.
+func (s *SLRU[K, V]) startAdaptiveTTL() {
+	if s.adaptive == nil {
+		return
+	}
+	s.changes = make(map[K]*list.Element)
+	s.changeKeys = list.NewBounded(cmp.Or(s.adaptive.History, s.size), func(v any) {
+		delete(s.changes, v.(*changeHistory[K, V]).key)
+	})
+}
+
+// adaptTTL records the write of value to key at now in its history and
+// returns the TTL the history calls for.
+func (s *SLRU[K, V]) adaptTTL(key K, value V, now time.Time) time.Duration {
+	cfg := s.adaptive
+	e, ok := s.changes[key]
+	if !ok {
+		if e := s.changeKeys.PushFront(&changeHistory[K, V]{key: key, value: value, changed: now}); e != nil {
+			s.changes[key] = e
+		}
+		return cfg.MinTTL
+	}
+	s.changeKeys.MoveToFront(e)
+	h := e.Value.(*changeHistory[K, V])
+	if !s.equals(h.value, value) {
+		observed := now.Sub(h.changed)
+		if h.interval == 0 {
+			h.interval = observed
+		} else {
+			h.interval = (h.interval + observed) / 2
+		}
+		h.value, h.changed = value, now
+	}
+	ttl := max(h.interval, 2*now.Sub(h.changed), cfg.MinTTL)
+	if cfg.MaxTTL > 0 {
+		ttl = min(ttl, cfg.MaxTTL)
+	}
+	return ttl
+}
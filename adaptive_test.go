@@ -0,0 +1,47 @@
+package slru
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdaptiveTTL(t *testing.T) {
+	now := time.Unix(0, 0)
+	cache := newSLRU(100,
+		WithClock[string, int](func() time.Time { return now }),
+		WithAdaptiveTTL[string, int](AdaptiveTTL{MinTTL: time.Second, MaxTTL: time.Minute}),
+	)
+	ttl := func(key string) time.Duration {
+		ttl, ok := cache.TTL(key)
+		require.True(t, ok)
+		return ttl
+	}
+
+	// a stable key reloaded on expiry doubles its TTL up to the maximum
+	cache.Set("stable", 1)
+	require.Equal(t, time.Second, ttl("stable"))
+	for _, want := range []time.Duration{2 * time.Second, 6 * time.Second, 18 * time.Second, 54 * time.Second, time.Minute} {
+		now = now.Add(ttl("stable"))
+		_, ok := cache.Get("stable")
+		require.False(t, ok)
+		cache.Set("stable", 1)
+		require.Equal(t, want, ttl("stable"))
+	}
+
+	// a key changing every 3s gets about that, down to the minimum
+	cache.Set("volatile", 0)
+	for i := range 4 {
+		now = now.Add(3 * time.Second)
+		cache.Set("volatile", i+1)
+	}
+	require.Equal(t, 3*time.Second, ttl("volatile"))
+	now = now.Add(100 * time.Millisecond)
+	cache.Set("volatile", 5)
+	require.Equal(t, 1550*time.Millisecond, ttl("volatile"))
+
+	// explicit TTLs are kept
+	cache.SetWithTTL("volatile", 6, time.Hour)
+	require.Equal(t, time.Hour, ttl("volatile"))
+}
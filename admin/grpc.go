@@ -0,0 +1,180 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/hey-kong/slru"
+)
+
+// AdminServer is the server of the Admin service of admin.proto.
+type AdminServer interface {
+	Stats(ctx context.Context, in *emptypb.Empty) (*structpb.Struct, error)
+	Lookup(ctx context.Context, in *wrapperspb.StringValue) (*structpb.Struct, error)
+	Invalidate(ctx context.Context, in *wrapperspb.StringValue) (*wrapperspb.BoolValue, error)
+	InvalidateAll(ctx context.Context, in *emptypb.Empty) (*emptypb.Empty, error)
+	Snapshot(ctx context.Context, in *emptypb.Empty) (*emptypb.Empty, error)
+}
+
+// RegisterAdminServer registers srv as the Admin service of s.
+func RegisterAdminServer(s grpc.ServiceRegistrar, srv AdminServer) {
+	s.RegisterService(&adminServiceDesc, srv)
+}
+
+// unary returns the handler of a unary method of AdminServer.
+func unary[In any](method string, call func(srv AdminServer, ctx context.Context, in *In) (any, error)) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: method,
+		Handler: func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+			in := new(In)
+			if err := dec(in); err != nil {
+				return nil, err
+			}
+			if interceptor == nil {
+				return call(srv.(AdminServer), ctx, in)
+			}
+			info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/slru.admin.v1.Admin/" + method}
+			return interceptor(ctx, in, info, func(ctx context.Context, req any) (any, error) {
+				return call(srv.(AdminServer), ctx, req.(*In))
+			})
+		},
+	}
+}
+
+var adminServiceDesc = grpc.ServiceDesc{
+	ServiceName: "slru.admin.v1.Admin",
+	HandlerType: (*AdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		unary("Stats", func(srv AdminServer, ctx context.Context, in *emptypb.Empty) (any, error) {
+			return srv.Stats(ctx, in)
+		}),
+		unary("Lookup", func(srv AdminServer, ctx context.Context, in *wrapperspb.StringValue) (any, error) {
+			return srv.Lookup(ctx, in)
+		}),
+		unary("Invalidate", func(srv AdminServer, ctx context.Context, in *wrapperspb.StringValue) (any, error) {
+			return srv.Invalidate(ctx, in)
+		}),
+		unary("InvalidateAll", func(srv AdminServer, ctx context.Context, in *emptypb.Empty) (any, error) {
+			return srv.InvalidateAll(ctx, in)
+		}),
+		unary("Snapshot", func(srv AdminServer, ctx context.Context, in *emptypb.Empty) (any, error) {
+			return srv.Snapshot(ctx, in)
+		}),
+	},
+	Metadata: "admin.proto",
+}
+
+// GRPCServer is an AdminServer over a cache.
+type GRPCServer[K comparable, V any] struct {
+	// Authorize is called before each call, which fails with
+	// PERMISSION_DENIED and the error it returns. If nil, every call is
+	// allowed.
+	Authorize func(ctx context.Context) error
+
+	// TakeSnapshot is called by Snapshot. If nil, Snapshot fails with
+	// UNIMPLEMENTED.
+	TakeSnapshot func(ctx context.Context) error
+
+	// FormatKey formats the keys of entries. If nil, keys are formatted
+	// with fmt.
+	FormatKey func(key K) string
+
+	cache    slru.Cache[K, V]
+	parseKey func(s string) (K, error)
+}
+
+// NewGRPCServer returns a GRPCServer for cache, parsing the keys of calls
+// with parseKey, e.g. StringKey.
+func NewGRPCServer[K comparable, V any](cache slru.Cache[K, V], parseKey func(s string) (K, error)) *GRPCServer[K, V] {
+	return &GRPCServer[K, V]{cache: cache, parseKey: parseKey}
+}
+
+func (s *GRPCServer[K, V]) Stats(ctx context.Context, in *emptypb.Empty) (*structpb.Struct, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	return toStruct(statsOf(s.cache))
+}
+
+func (s *GRPCServer[K, V]) Lookup(ctx context.Context, in *wrapperspb.StringValue) (*structpb.Struct, error) {
+	key, err := s.key(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	ent, ok := entryOf(s.cache, key, func(key K) string { return formatKey(s.FormatKey, key) })
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "key %q not found", in.GetValue())
+	}
+	return toStruct(ent)
+}
+
+func (s *GRPCServer[K, V]) Invalidate(ctx context.Context, in *wrapperspb.StringValue) (*wrapperspb.BoolValue, error) {
+	key, err := s.key(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return wrapperspb.Bool(s.cache.Remove(key)), nil
+}
+
+func (s *GRPCServer[K, V]) InvalidateAll(ctx context.Context, in *emptypb.Empty) (*emptypb.Empty, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	s.cache.InvalidateBefore(s.cache.NewGeneration())
+	return &emptypb.Empty{}, nil
+}
+
+func (s *GRPCServer[K, V]) Snapshot(ctx context.Context, in *emptypb.Empty) (*emptypb.Empty, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	if s.TakeSnapshot == nil {
+		return nil, status.Error(codes.Unimplemented, "no snapshot configured")
+	}
+	if err := s.TakeSnapshot(ctx); err != nil {
+		return nil, status.Errorf(codes.Internal, "snapshot: %v", err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *GRPCServer[K, V]) authorize(ctx context.Context) error {
+	if s.Authorize == nil {
+		return nil
+	}
+	if err := s.Authorize(ctx); err != nil {
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+	return nil
+}
+
+// key authorizes the call and parses its key.
+func (s *GRPCServer[K, V]) key(ctx context.Context, in *wrapperspb.StringValue) (key K, err error) {
+	if err := s.authorize(ctx); err != nil {
+		return key, err
+	}
+	key, err = s.parseKey(in.GetValue())
+	if err != nil {
+		return key, status.Errorf(codes.InvalidArgument, "bad key: %v", err)
+	}
+	return key, nil
+}
+
+// toStruct converts v to a Struct through its JSON encoding.
+func toStruct(v any) (*structpb.Struct, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return structpb.NewStruct(m)
+}
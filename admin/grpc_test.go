@@ -0,0 +1,106 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/hey-kong/slru"
+)
+
+// dialAdmin serves srv in memory and returns a connection to it.
+func dialAdmin(t *testing.T, srv AdminServer) *grpc.ClientConn {
+	ln := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	RegisterAdminServer(s, srv)
+	go s.Serve(ln)
+	t.Cleanup(s.Stop)
+
+	cc, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return ln.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { cc.Close() })
+	return cc
+}
+
+func TestGRPCServer(t *testing.T) {
+	cache := slru.New[string, string](100)
+	cache.SetWithTTL("k", "v", time.Hour)
+	cache.Get("k")
+	cache.Set("other", "v")
+	srv := NewGRPCServer(cache, StringKey)
+	snapshots := 0
+	srv.TakeSnapshot = func(ctx context.Context) error {
+		snapshots++
+		return nil
+	}
+	cc := dialAdmin(t, srv)
+	ctx := context.Background()
+	const prefix = "/slru.admin.v1.Admin/"
+
+	stats := &structpb.Struct{}
+	require.NoError(t, cc.Invoke(ctx, prefix+"Stats", &emptypb.Empty{}, stats))
+	require.Equal(t, 1.0, stats.Fields["Hits"].GetNumberValue())
+	require.Equal(t, 1.0, stats.Fields["HitRatio"].GetNumberValue())
+
+	ent := &structpb.Struct{}
+	require.NoError(t, cc.Invoke(ctx, prefix+"Lookup", wrapperspb.String("k"), ent))
+	require.Equal(t, "v", ent.Fields["Value"].GetStringValue())
+	require.Greater(t, ent.Fields["TTL"].GetNumberValue(), float64(time.Minute))
+	err := cc.Invoke(ctx, prefix+"Lookup", wrapperspb.String("missing"), ent)
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	present := &wrapperspb.BoolValue{}
+	require.NoError(t, cc.Invoke(ctx, prefix+"Invalidate", wrapperspb.String("k"), present))
+	require.True(t, present.Value)
+	require.False(t, cache.Contains("k"))
+
+	require.NoError(t, cc.Invoke(ctx, prefix+"InvalidateAll", &emptypb.Empty{}, &emptypb.Empty{}))
+	require.False(t, cache.Contains("other"))
+
+	require.NoError(t, cc.Invoke(ctx, prefix+"Snapshot", &emptypb.Empty{}, &emptypb.Empty{}))
+	require.Equal(t, 1, snapshots)
+	srv.TakeSnapshot = nil
+	err = cc.Invoke(ctx, prefix+"Snapshot", &emptypb.Empty{}, &emptypb.Empty{})
+	require.Equal(t, codes.Unimplemented, status.Code(err))
+}
+
+func TestGRPCServerAuthorize(t *testing.T) {
+	cache := slru.New[int, int](10)
+	cache.Set(1, 1)
+	srv := NewGRPCServer(cache, IntKey)
+	srv.Authorize = func(ctx context.Context) error {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if len(md.Get("token")) == 0 || md.Get("token")[0] != "secret" {
+			return errors.New("bad token")
+		}
+		return nil
+	}
+	cc := dialAdmin(t, srv)
+	const method = "/slru.admin.v1.Admin/Invalidate"
+
+	err := cc.Invoke(context.Background(), method, wrapperspb.String("1"), &wrapperspb.BoolValue{})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	require.True(t, cache.Contains(1))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "token", "secret")
+	err = cc.Invoke(ctx, method, wrapperspb.String("x"), &wrapperspb.BoolValue{})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	require.NoError(t, cc.Invoke(ctx, method, wrapperspb.String("1"), &wrapperspb.BoolValue{}))
+	require.False(t, cache.Contains(1))
+}
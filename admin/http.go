@@ -0,0 +1,250 @@
+// Package admin exposes the state of a live cache to operators, over HTTP
+// or gRPC: stats, configuration, keys and entries, and actions to remove,
+// purge or invalidate them.
+//
+//	h := admin.NewHandler[string, []byte](cache, admin.StringKey)
+//	h.Authorize = func(r *http.Request) error { ... }
+//	http.Handle("/debug/cache/", http.StripPrefix("/debug/cache", h))
+//
+//	admin.RegisterAdminServer(grpcServer, admin.NewGRPCServer[string, []byte](cache, admin.StringKey))
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hey-kong/slru"
+)
+
+// DefaultPageSize is the number of keys listed per page by default.
+const DefaultPageSize = 100
+
+// maxPageSize bounds the keys listed per page.
+const maxPageSize = 10000
+
+// StringKey parses string keys.
+func StringKey(s string) (string, error) {
+	return s, nil
+}
+
+// IntKey parses int keys.
+func IntKey(s string) (int, error) {
+	return strconv.Atoi(s)
+}
+
+// Handler serves the admin endpoints of a cache:
+//
+//	GET    /stats              Stats, as JSON
+//	GET    /config             Config, or that of the cache, or its length
+//	GET    /keys?cursor=&limit= a page of keys, from the next victim
+//	GET    /keys/{key}         the value and TTL of an entry
+//	DELETE /keys/{key}         removes an entry
+//	POST   /purge              removes every entry
+//	POST   /invalidate         invalidates every entry written so far
+type Handler[K comparable, V any] struct {
+	// Authorize is called before each request, which is rejected as
+	// forbidden with the error it returns. If nil, every request is
+	// allowed.
+	Authorize func(r *http.Request) error
+
+	// Config is shown by /config. If nil, /config shows the effective
+	// configuration of caches reporting one, such as an SLRU.
+	Config any
+
+	// FormatKey formats listed keys. If nil, keys are formatted with fmt.
+	FormatKey func(key K) string
+
+	cache    slru.Cache[K, V]
+	parseKey func(s string) (K, error)
+	mux      *http.ServeMux
+}
+
+// NewHandler returns a Handler for cache, parsing the keys of requests
+// with parseKey, e.g. StringKey.
+func NewHandler[K comparable, V any](cache slru.Cache[K, V], parseKey func(s string) (K, error)) *Handler[K, V] {
+	h := &Handler[K, V]{cache: cache, parseKey: parseKey, mux: http.NewServeMux()}
+	h.mux.HandleFunc("GET /stats", h.stats)
+	h.mux.HandleFunc("GET /config", h.config)
+	h.mux.HandleFunc("GET /keys", h.keys)
+	h.mux.HandleFunc("GET /keys/{key}", h.entry)
+	h.mux.HandleFunc("DELETE /keys/{key}", h.remove)
+	h.mux.HandleFunc("POST /purge", h.purge)
+	h.mux.HandleFunc("POST /invalidate", h.invalidate)
+	return h
+}
+
+func (h *Handler[K, V]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.Authorize != nil {
+		if err := h.Authorize(r); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+	}
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *Handler[K, V]) stats(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, statsOf(h.cache))
+}
+
+// statsView is what the admin endpoints show of Stats.
+type statsView struct {
+	slru.Stats
+	HitRatio float64
+}
+
+func statsOf[K comparable, V any](cache slru.Cache[K, V]) statsView {
+	stats := cache.Stats()
+	return statsView{stats, stats.HitRatio()}
+}
+
+func (h *Handler[K, V]) config(w http.ResponseWriter, r *http.Request) {
+	if h.Config != nil {
+		writeJSON(w, h.Config)
+		return
+	}
+	if c, ok := h.cache.(interface{ Config() slru.EffectiveConfig }); ok {
+		writeJSON(w, c.Config())
+		return
+	}
+	writeJSON(w, struct{ Len int }{h.cache.Len()})
+}
+
+// Page is a page of keys listed by /keys. Next is the cursor of the next
+// page, or zero after the last one.
+type Page struct {
+	Keys  []string
+	Next  int `json:",omitempty"`
+	Total int
+}
+
+func (h *Handler[K, V]) keys(w http.ResponseWriter, r *http.Request) {
+	cursor, err := queryInt(r, "cursor", 0)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit, err := queryInt(r, "limit", DefaultPageSize)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit = min(max(limit, 1), maxPageSize)
+
+	keys := h.cache.Keys()
+	page := Page{Keys: []string{}, Total: len(keys)}
+	start := min(cursor, len(keys))
+	end := min(start+limit, len(keys))
+	for _, key := range keys[start:end] {
+		page.Keys = append(page.Keys, h.formatKey(key))
+	}
+	if end < len(keys) {
+		page.Next = end
+	}
+	writeJSON(w, page)
+}
+
+// Entry is an entry shown by /keys/{key}. TTL is zero if it never expires.
+type Entry struct {
+	Key   string
+	Value any
+	TTL   time.Duration
+}
+
+func (h *Handler[K, V]) entry(w http.ResponseWriter, r *http.Request) {
+	key, ok := h.key(w, r)
+	if !ok {
+		return
+	}
+	ent, ok := entryOf(h.cache, key, h.formatKey)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, ent)
+}
+
+// entryOf returns the entry of key without promoting it. Values that don't
+// encode to JSON are formatted with fmt.
+func entryOf[K comparable, V any](cache slru.Cache[K, V], key K, formatKey func(key K) string) (Entry, bool) {
+	value, ok := cache.Peek(key)
+	if !ok {
+		return Entry{}, false
+	}
+	ttl, _ := cache.TTL(key)
+	ent := Entry{Key: formatKey(key), Value: value, TTL: ttl}
+	if _, err := json.Marshal(value); err != nil {
+		ent.Value = fmt.Sprint(value)
+	}
+	return ent, true
+}
+
+func (h *Handler[K, V]) remove(w http.ResponseWriter, r *http.Request) {
+	key, ok := h.key(w, r)
+	if !ok {
+		return
+	}
+	if !h.cache.Remove(key) {
+		http.NotFound(w, r)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler[K, V]) purge(w http.ResponseWriter, r *http.Request) {
+	h.cache.Purge()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler[K, V]) invalidate(w http.ResponseWriter, r *http.Request) {
+	h.cache.InvalidateBefore(h.cache.NewGeneration())
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// key parses the key of r, replying with an error if it's malformed.
+func (h *Handler[K, V]) key(w http.ResponseWriter, r *http.Request) (key K, ok bool) {
+	key, err := h.parseKey(r.PathValue("key"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("bad key: %v", err), http.StatusBadRequest)
+		return key, false
+	}
+	return key, true
+}
+
+func (h *Handler[K, V]) formatKey(key K) string {
+	return formatKey(h.FormatKey, key)
+}
+
+// formatKey formats key with format, or with fmt if format is nil.
+func formatKey[K any](format func(key K) string, key K) string {
+	if format != nil {
+		return format(key)
+	}
+	return fmt.Sprint(key)
+}
+
+func queryInt(r *http.Request, name string, def int) (int, error) {
+	s := r.URL.Query().Get(name)
+	if s == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0, errors.New("bad " + name + ": " + s)
+	}
+	return n, nil
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
@@ -0,0 +1,101 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hey-kong/slru"
+)
+
+func TestHandler(t *testing.T) {
+	cache := slru.New[int, string](100)
+	for i := 0; i < 5; i++ {
+		cache.Set(i, "v")
+	}
+	cache.SetWithTTL(7, "seven", time.Hour)
+	cache.Get(7)
+	h := NewHandler(cache, IntKey)
+
+	serve := func(method, target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+		return rec
+	}
+	decode := func(rec *httptest.ResponseRecorder, v any) {
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), v))
+	}
+
+	var stats struct {
+		Hits     uint64
+		HitRatio float64
+	}
+	decode(serve(http.MethodGet, "/stats"), &stats)
+	require.Equal(t, uint64(1), stats.Hits)
+	require.Equal(t, 1.0, stats.HitRatio)
+
+	var page Page
+	decode(serve(http.MethodGet, "/keys?limit=4"), &page)
+	require.Equal(t, Page{Keys: []string{"0", "1", "2", "3"}, Next: 4, Total: 6}, page)
+	page = Page{}
+	decode(serve(http.MethodGet, "/keys?cursor=4&limit=4"), &page)
+	require.Equal(t, Page{Keys: []string{"4", "7"}, Total: 6}, page)
+	require.Equal(t, http.StatusBadRequest, serve(http.MethodGet, "/keys?limit=x").Code)
+
+	var ent Entry
+	decode(serve(http.MethodGet, "/keys/7"), &ent)
+	require.Equal(t, "7", ent.Key)
+	require.Equal(t, "seven", ent.Value)
+	require.Greater(t, ent.TTL, time.Minute)
+	require.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/keys/8").Code)
+	require.Equal(t, http.StatusBadRequest, serve(http.MethodGet, "/keys/x").Code)
+
+	require.Equal(t, http.StatusNoContent, serve(http.MethodDelete, "/keys/7").Code)
+	require.False(t, cache.Contains(7))
+	require.Equal(t, http.StatusNotFound, serve(http.MethodDelete, "/keys/7").Code)
+
+	var cfg slru.EffectiveConfig
+	decode(serve(http.MethodGet, "/config"), &cfg)
+	require.Equal(t, cache.(*slru.SLRU[int, string]).Config(), cfg)
+	h.Config = slru.Config{Size: 200}
+	var shown slru.Config
+	decode(serve(http.MethodGet, "/config"), &shown)
+	require.Equal(t, 200, shown.Size)
+
+	require.Equal(t, http.StatusNoContent, serve(http.MethodPost, "/invalidate").Code)
+	require.False(t, cache.Contains(0))
+	cache.Set(9, "v")
+	require.Equal(t, http.StatusNoContent, serve(http.MethodPost, "/purge").Code)
+	require.Zero(t, cache.Len())
+	require.Equal(t, http.StatusMethodNotAllowed, serve(http.MethodGet, "/purge").Code)
+}
+
+func TestHandlerAuthorize(t *testing.T) {
+	cache := slru.New[string, int](10)
+	cache.Set("k", 1)
+	h := NewHandler(cache, StringKey)
+	h.Authorize = func(r *http.Request) error {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			return errors.New("bad token")
+		}
+		return nil
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/purge", nil))
+	require.Equal(t, http.StatusForbidden, rec.Code)
+	require.Equal(t, 1, cache.Len())
+
+	req := httptest.NewRequest(http.MethodPost, "/purge", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	require.Zero(t, cache.Len())
+}
@@ -0,0 +1,88 @@
+package slru
+
+import (
+	"hash/maphash"
+	"sync"
+)
+
+// WithAdmissionProbability admits a write of a new key only with
+// probability p in (0, 1], counting the others as Rejections, for caches
+// under scans across so many keys that even churning probation costs too
+// much: keys that keep coming back get in after a few tries, one-off keys
+// mostly don't. Updates of cached keys are always applied.
+func WithAdmissionProbability[K comparable, V any](p float64) Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		s.admitProbability = min(max(p, 0), 1)
+	}
+}
+
+// WithAdmissionFilter admits a write of a new key only if filter returns
+// true for it, counting the others as Rejections, such as a Doorkeeper.
+// filter runs under the cache lock; shared by the shards of a Sharded or
+// across caches, it must be safe for concurrent use. It is applied after
+// the admission probability, if any.
+func WithAdmissionFilter[K comparable, V any](filter func(key K) bool) Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		s.admitFilter = filter
+	}
+}
+
+// admit reports whether a new key passes admission.
+func (s *SLRU[K, V]) admit(key K) bool {
+	if s.admitProbability > 0 && s.admitProbability < 1 && s.randFloat64() >= s.admitProbability {
+		return false
+	}
+	return s.admitFilter == nil || s.admitFilter(key)
+}
+
+// Doorkeeper returns an admission filter for WithAdmissionFilter admitting
+// keys on their second sighting among about the last n distinct keys: it
+// records each key it rejects in a bitset of 8n bits, cleared once n keys
+// are recorded. Keys seen once, such as those of a scan, never get in.
+// False positives admit about one in eight keys on their first sighting.
+// It is safe for concurrent use.
+func Doorkeeper[K comparable](n int) func(key K) bool {
+	return newDoorkeeper(n, defaultHash[K](maphash.MakeSeed()))
+}
+
+// SeededDoorkeeper is Doorkeeper hashing keys with SeededHash(seed), so its
+// false positives are the same from run to run, as for WithSeed.
+func SeededDoorkeeper[K comparable](n int, seed uint64) func(key K) bool {
+	return newDoorkeeper(n, SeededHash[K](seed))
+}
+
+func newDoorkeeper[K comparable](n int, hash func(key K) uint64) func(key K) bool {
+	d := &doorkeeper[K]{
+		hash: hash,
+		bits: make([]uint64, max(n, 8)/8),
+		n:    max(n, 1),
+	}
+	return d.admit
+}
+
+type doorkeeper[K comparable] struct {
+	hash func(key K) uint64
+	lock sync.Mutex
+	bits []uint64
+	// seen counts the keys recorded since the bits were cleared, up to n.
+	seen int
+	n    int
+}
+
+func (d *doorkeeper[K]) admit(key K) bool {
+	h := d.hash(key) % uint64(len(d.bits)*64)
+	word, bit := h/64, uint64(1)<<(h%64)
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if d.bits[word]&bit != 0 {
+		return true
+	}
+	if d.seen++; d.seen > d.n {
+		clear(d.bits)
+		d.seen = 1
+	}
+	d.bits[word] |= bit
+	return false
+}
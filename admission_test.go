@@ -0,0 +1,38 @@
+package slru
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdmissionProbability(t *testing.T) {
+	cache := newSLRU[int, int](10000, WithAdmissionProbability[int, int](0.25))
+	for i := range 1000 {
+		cache.Set(i, i)
+	}
+	require.InDelta(t, 250, cache.Len(), 100)
+	require.Equal(t, uint64(1000-cache.Len()), cache.Stats().Rejections)
+
+	// updates always apply
+	key := cache.Keys()[0]
+	cache.Set(key, -1)
+	v, _ := cache.Get(key)
+	require.Equal(t, -1, v)
+}
+
+func TestDoorkeeper(t *testing.T) {
+	cache := newSLRU[int, int](100, WithAdmissionFilter[int, int](Doorkeeper[int](1000)))
+	cache.Set(1, 1)
+	require.False(t, cache.Contains(1))
+	cache.Set(1, 1)
+	require.True(t, cache.Contains(1))
+	cache.Get(1)
+
+	// a scan of one-off keys mostly stays out
+	for i := 1000; i < 1500; i++ {
+		cache.Set(i, i)
+	}
+	require.True(t, cache.Contains(1))
+	require.Greater(t, cache.Stats().Rejections, uint64(400))
+}
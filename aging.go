@@ -0,0 +1,66 @@
+package slru
+
+import (
+	"time"
+
+	"github.com/hey-kong/slru/list"
+)
+
+// WithProtectedAging demotes protected entries neither hit nor written for
+// idle back to the head of probation, so formerly hot entries don't hold
+// protected until it overflows. Stale entries are found at the protected
+// tail whenever the cache is written or promotes an entry.
+func WithProtectedAging[K comparable, V any](idle time.Duration) Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		s.agingWindow = int64(idle)
+		s.agingOps = false
+	}
+}
+
+// WithProtectedAgingOps is WithProtectedAging with the window counted in
+// accesses to the cache, hits and writes, rather than in time.
+func WithProtectedAgingOps[K comparable, V any](ops int) Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		s.agingWindow = int64(ops)
+		s.agingOps = true
+	}
+}
+
+// touch stamps an accessed entry for aging. Readers holding the shared lock
+// call it under the segment lock.
+func (s *SLRU[K, V]) touch(ent *entry[K, V], now time.Time) {
+	if s.agingWindow <= 0 {
+		return
+	}
+	if s.agingOps {
+		ent.touched = s.ops.Add(1)
+	} else {
+		ent.touched = now.UnixNano()
+	}
+}
+
+// age demotes the stale entries at the protected tail, returning how many.
+func (s *SLRU[K, V]) age() (demoted int) {
+	if s.agingWindow <= 0 {
+		return 0
+	}
+	now := s.ops.Load()
+	if !s.agingOps {
+		now = s.now().UnixNano()
+	}
+	for e := s.protected.Back(); e != nil; e = s.protected.Back() {
+		if now-e.Value.(*entry[K, V]).touched < s.agingWindow {
+			break
+		}
+		s.demote(e)
+		demoted++
+	}
+	return demoted
+}
+
+// demote moves protected element e to the front of probation.
+func (s *SLRU[K, V]) demote(e *list.Element) {
+	s.unlink(e)
+	s.push(s.probation, e)
+	s.stats.demotions.inc()
+}
@@ -0,0 +1,53 @@
+package slru
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func debugKeys(entries []DebugEntry[int]) []int {
+	keys := make([]int, len(entries))
+	for i, e := range entries {
+		keys[i] = e.Key
+	}
+	return keys
+}
+
+func TestProtectedAgingOps(t *testing.T) {
+	cache := newSLRU[int, int](10, WithProtectedAgingOps[int, int](4))
+	for i := 0; i < 3; i++ {
+		cache.Set(i, i)
+		cache.Get(i)
+	}
+	// 0 was last touched 4 accesses before 2 was hit
+	state := cache.DebugState()
+	require.Equal(t, []int{0}, debugKeys(state.Probation))
+	require.Equal(t, []int{2, 1}, debugKeys(state.Protected))
+	require.Equal(t, uint64(1), cache.Stats().Demotions)
+
+	// hits under the shared lock keep protected entries fresh
+	cache.Get(1)
+	cache.Get(2)
+	cache.Get(1)
+	cache.Set(3, 3)
+	state = cache.DebugState()
+	require.Equal(t, []int{1, 2}, debugKeys(state.Protected))
+	require.NoError(t, cache.Verify())
+}
+
+func TestProtectedAging(t *testing.T) {
+	cache := newSLRU[int, int](10, WithProtectedAging[int, int](time.Millisecond))
+	cache.Set(0, 0)
+	cache.Get(0)
+	cache.Set(1, 1)
+	require.Equal(t, []int{0}, debugKeys(cache.DebugState().Protected))
+
+	time.Sleep(2 * time.Millisecond)
+	cache.Set(1, 1)
+	state := cache.DebugState()
+	require.Equal(t, []int{1}, debugKeys(state.Protected))
+	require.Equal(t, []int{0}, debugKeys(state.Probation))
+	require.Equal(t, uint64(1), cache.Stats().Demotions)
+}
@@ -0,0 +1,151 @@
+package slru
+
+import "github.com/hey-kong/slru/list"
+
+// NewARC creates a cache of up to size entries replaced by ARC, the
+// Adaptive Replacement Cache of Megiddo and Modha. It keeps recently and
+// frequently used entries in two lists, remembers the keys recently evicted
+// from each, and moves the split between them towards the list whose
+// evicted keys come back.
+func NewARC[K comparable, V any](size int, opts ...PolicyOption[K, V]) *Policy[K, V] {
+	return newPolicy[K, V](newARC[K](size), opts...)
+}
+
+// arc is the ARC replacer. t1 and t2 hold the resident keys seen once and
+// at least twice recently, b1 and b2 the keys evicted from them, all with
+// the most recent at the front. p is the target length of t1.
+type arc[K comparable] struct {
+	size, p        int
+	t1, t2, b1, b2 *list.List
+	keyed          map[K]*list.Element
+}
+
+func newARC[K comparable](size int) *arc[K] {
+	a := &arc[K]{size: size}
+	a.reset()
+	return a
+}
+
+func (a *arc[K]) reset() {
+	a.p = 0
+	a.t1, a.t2, a.b1, a.b2 = list.New(), list.New(), list.New(), list.New()
+	a.keyed = make(map[K]*list.Element)
+}
+
+func (a *arc[K]) add(key K, evict func(key K)) {
+	if a.size < 1 {
+		evict(key)
+		return
+	}
+	if e, ok := a.keyed[key]; ok {
+		// a ghost hit adapts p to favour the list that evicted it
+		ghost := e.List()
+		if ghost == a.b1 {
+			a.p = min(a.size, a.p+max(a.b2.Len()/a.b1.Len(), 1))
+		} else {
+			a.p = max(0, a.p-max(a.b1.Len()/a.b2.Len(), 1))
+		}
+		if a.resident() >= a.size {
+			a.replace(ghost == a.b2, evict)
+		}
+		ghost.Remove(e)
+		a.keyed[key] = a.t2.PushFront(key)
+		return
+	}
+	if l1 := a.t1.Len() + a.b1.Len(); l1 >= a.size {
+		if a.t1.Len() < a.size {
+			a.forget(a.b1)
+			if a.resident() >= a.size {
+				a.replace(false, evict)
+			}
+		} else {
+			evict(a.drop(a.t1))
+		}
+	} else if total := l1 + a.t2.Len() + a.b2.Len(); total >= a.size {
+		if total >= 2*a.size {
+			a.forget(a.b2)
+		}
+		if a.resident() >= a.size {
+			a.replace(false, evict)
+		}
+	}
+	a.keyed[key] = a.t1.PushFront(key)
+}
+
+// replace evicts the tail of t1 or t2 into its ghost list, preferring t1
+// while it is over its target.
+func (a *arc[K]) replace(inB2 bool, evict func(key K)) {
+	if n := a.t1.Len(); n > 0 && (n > a.p || (inB2 && n == a.p) || a.t2.Len() == 0) {
+		evict(a.demote(a.t1, a.b1))
+	} else if a.t2.Len() > 0 {
+		evict(a.demote(a.t2, a.b2))
+	}
+}
+
+// demote moves the tail of resident list l to the front of ghost list.
+func (a *arc[K]) demote(l, ghost *list.List) K {
+	key := l.Remove(l.Back()).(K)
+	a.keyed[key] = ghost.PushFront(key)
+	return key
+}
+
+// drop removes the tail of l without remembering it.
+func (a *arc[K]) drop(l *list.List) K {
+	key := l.Remove(l.Back()).(K)
+	delete(a.keyed, key)
+	return key
+}
+
+// forget drops the tail of ghost list l, if any.
+func (a *arc[K]) forget(l *list.List) {
+	if l.Len() > 0 {
+		a.drop(l)
+	}
+}
+
+func (a *arc[K]) resident() int {
+	return a.t1.Len() + a.t2.Len()
+}
+
+func (a *arc[K]) hit(key K) {
+	if e, ok := a.keyed[key]; ok {
+		if l := e.List(); l == a.t1 {
+			l.Remove(e)
+			a.keyed[key] = a.t2.PushFront(key)
+		} else if l == a.t2 {
+			l.MoveToFront(e)
+		}
+	}
+}
+
+func (a *arc[K]) remove(key K) {
+	if e, ok := a.keyed[key]; ok {
+		e.List().Remove(e)
+		delete(a.keyed, key)
+	}
+}
+
+func (a *arc[K]) resize(size int, evict func(key K)) {
+	a.size = max(size, 0)
+	a.p = min(a.p, a.size)
+	for a.resident() > a.size {
+		a.replace(false, evict)
+	}
+	for a.t1.Len()+a.b1.Len() > a.size && a.b1.Len() > 0 {
+		a.forget(a.b1)
+	}
+	for a.resident()+a.b1.Len()+a.b2.Len() > 2*a.size && a.b2.Len() > 0 {
+		a.forget(a.b2)
+	}
+}
+
+// keys returns the keys of t1 then t2, each from its tail.
+func (a *arc[K]) keys() []K {
+	keys := make([]K, 0, a.resident())
+	for _, l := range []*list.List{a.t1, a.t2} {
+		for e := l.Back(); e != nil; e = e.Prev() {
+			keys = append(keys, e.Value.(K))
+		}
+	}
+	return keys
+}
@@ -0,0 +1,60 @@
+package slru
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestARC(t *testing.T) {
+	testPolicy(t, NewARC[int, int])
+}
+
+func TestARCResistsScans(t *testing.T) {
+	cache := NewARC[int, int](10)
+	for i := 0; i < 5; i++ {
+		cache.Set(i, i)
+		cache.Get(i)
+	}
+	for i := 100; i < 200; i++ {
+		cache.Set(i, i)
+	}
+	for i := 0; i < 5; i++ {
+		require.True(t, cache.Contains(i))
+	}
+}
+
+func TestARCAdapts(t *testing.T) {
+	a := newARC[int](4)
+	var evicted []int
+	evict := func(key int) { evicted = append(evicted, key) }
+	for i := 0; i < 4; i++ {
+		a.add(i, evict)
+	}
+	a.hit(0)
+	a.add(4, evict)
+	require.Equal(t, []int{1}, evicted)
+
+	// a hit on a key recently evicted from t1 grows its target
+	a.add(1, evict)
+	require.Equal(t, 1, a.p)
+	require.Equal(t, []int{1, 2}, evicted)
+	require.Equal(t, []int{3, 4, 0, 1}, a.keys())
+}
+
+func TestARCBoundsGhosts(t *testing.T) {
+	a := newARC[int](16)
+	evict := func(int) {}
+	for i := 0; i < 5000; i++ {
+		key := (i * 7919) % 97
+		if e, ok := a.keyed[key]; ok && (e.List() == a.t1 || e.List() == a.t2) {
+			a.hit(key)
+		} else {
+			a.add(key, evict)
+		}
+		require.LessOrEqual(t, a.resident(), 16)
+		require.LessOrEqual(t, a.t1.Len()+a.b1.Len(), 16)
+		require.LessOrEqual(t, a.resident()+a.b1.Len()+a.b2.Len(), 32)
+		require.Len(t, a.keyed, a.resident()+a.b1.Len()+a.b2.Len())
+	}
+}
@@ -0,0 +1,89 @@
+package arena
+
+import "math/bits"
+
+const (
+	// ChunkSize is the size of the chunks the arena allocates, which is
+	// also the largest value it stores.
+	ChunkSize = 1 << 20
+
+	minClassShift = 6
+	// classes are the size classes from 64 bytes to ChunkSize.
+	classes = 20 - minClassShift + 1
+)
+
+// span locates a value in the arena. It holds no pointers, so entries
+// referencing values add nothing for the garbage collector to scan.
+type span struct {
+	chunk uint32
+	off   uint32
+	len   uint32
+	class uint8
+}
+
+// arena stores values in large byte chunks, which the garbage collector
+// never scans, and recycles the slots of freed values by size class.
+type arena struct {
+	chunks [][]byte
+	// carve is the chunk each class carves new slots from and next the
+	// offset of the next one, with chunk -1 if it has none yet.
+	carve [classes]struct {
+		chunk int
+		next  uint32
+	}
+	free [classes][]span
+}
+
+func newArena() *arena {
+	a := &arena{}
+	for i := range a.carve {
+		a.carve[i].chunk = -1
+	}
+	return a
+}
+
+// classOf returns the size class holding n bytes.
+func classOf(n int) uint8 {
+	if n <= 1<<minClassShift {
+		return 0
+	}
+	return uint8(bits.Len(uint(n-1)) - minClassShift)
+}
+
+// classSize returns the slot size of class c.
+func classSize(c uint8) int {
+	return 1 << (int(c) + minClassShift)
+}
+
+// alloc copies value into a free slot and returns its span. value must be
+// at most ChunkSize long.
+func (a *arena) alloc(value []byte) span {
+	c := classOf(len(value))
+	var s span
+	if free := a.free[c]; len(free) > 0 {
+		s = free[len(free)-1]
+		a.free[c] = free[:len(free)-1]
+	} else {
+		carve := &a.carve[c]
+		if carve.chunk < 0 || int(carve.next)+classSize(c) > ChunkSize {
+			a.chunks = append(a.chunks, make([]byte, ChunkSize))
+			carve.chunk = len(a.chunks) - 1
+			carve.next = 0
+		}
+		s = span{chunk: uint32(carve.chunk), off: carve.next, class: c}
+		carve.next += uint32(classSize(c))
+	}
+	s.len = uint32(len(value))
+	copy(a.bytes(s), value)
+	return s
+}
+
+// release returns the slot of s for reuse.
+func (a *arena) release(s span) {
+	a.free[s.class] = append(a.free[s.class], s)
+}
+
+// bytes returns the value at s, which aliases the arena.
+func (a *arena) bytes(s span) []byte {
+	return a.chunks[s.chunk][s.off : s.off+s.len : s.off+s.len]
+}
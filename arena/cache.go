@@ -0,0 +1,113 @@
+// Package arena caches byte-slice values in large, manually managed byte
+// chunks referenced by offset. The garbage collector never scans the
+// chunks, and the cache entries hold offsets rather than slices, so cached
+// payloads add next to nothing to GC mark time however large they are.
+//
+// Slots are recycled by power-of-two size class, so a value takes up to
+// twice its length, which is what the capacity is accounted in. Chunks,
+// once allocated, are kept for the lifetime of the cache.
+package arena
+
+import (
+	"sync"
+
+	"github.com/hey-kong/slru"
+)
+
+// Cache is an SLRU of byte-slice values stored in an arena.
+type Cache[K comparable] struct {
+	lock     sync.Mutex
+	capacity int
+	cache    slru.Cache[K, span]
+	arena    *arena
+}
+
+// New returns a Cache holding up to capacity bytes of values.
+func New[K comparable](capacity int) *Cache[K] {
+	c := &Cache[K]{capacity: capacity}
+	c.reset()
+	return c
+}
+
+func (c *Cache[K]) reset() {
+	c.arena = newArena()
+	c.cache = slru.New(c.capacity,
+		slru.WithWeigher(func(key K, s span) int { return classSize(s.class) }),
+		// evictions happen within our own operations, under c.lock
+		slru.WithEvictCallback(func(key K, s span) { c.arena.release(s) }),
+	)
+}
+
+// Set copies value into the cache, reporting whether it was stored. Values
+// longer than ChunkSize are not.
+func (c *Cache[K]) Set(key K, value []byte) (ok bool) {
+	if len(value) > ChunkSize {
+		return false
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	s := c.arena.alloc(value)
+	old, existed := c.cache.Swap(key, s)
+	if existed {
+		c.arena.release(old)
+		// if the update evicted key, s went back with it
+		return c.cache.Contains(key)
+	}
+	if !c.cache.Contains(key) {
+		// not admitted, too heavy for probation
+		c.arena.release(s)
+		return false
+	}
+	return true
+}
+
+// Get returns a copy of the value of key.
+func (c *Cache[K]) Get(key K) (value []byte, ok bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	s, ok := c.cache.Get(key)
+	if !ok {
+		return nil, false
+	}
+	return append([]byte(nil), c.arena.bytes(s)...), true
+}
+
+// Remove removes key, reporting whether it was present.
+func (c *Cache[K]) Remove(key K) (present bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	s, ok := c.cache.Peek(key)
+	if !ok {
+		return false
+	}
+	c.cache.Remove(key)
+	c.arena.release(s)
+	return true
+}
+
+// Len returns the number of cached values.
+func (c *Cache[K]) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.cache.Len()
+}
+
+// Purge removes every value and releases the arena.
+func (c *Cache[K]) Purge() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.reset()
+}
+
+// Stats returns the statistics of the underlying cache.
+func (c *Cache[K]) Stats() slru.Stats {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.cache.Stats()
+}
@@ -0,0 +1,65 @@
+package arena
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClasses(t *testing.T) {
+	require.Equal(t, uint8(0), classOf(0))
+	require.Equal(t, uint8(0), classOf(64))
+	require.Equal(t, uint8(1), classOf(65))
+	require.Equal(t, uint8(classes-1), classOf(ChunkSize))
+	require.Equal(t, ChunkSize, classSize(classes-1))
+}
+
+func TestArenaRecyclesSlots(t *testing.T) {
+	a := newArena()
+	s1 := a.alloc([]byte("hello"))
+	s2 := a.alloc(bytes.Repeat([]byte("x"), 100))
+	require.Equal(t, "hello", string(a.bytes(s1)))
+	require.Len(t, a.bytes(s2), 100)
+	require.Len(t, a.chunks, 2)
+
+	a.release(s1)
+	s3 := a.alloc([]byte("bye"))
+	require.Equal(t, s1.off, s3.off)
+	require.Equal(t, "bye", string(a.bytes(s3)))
+}
+
+func TestCache(t *testing.T) {
+	// probation holds two 64 byte slots
+	c := New[string](640)
+	require.True(t, c.Set("a", []byte("1")))
+	require.True(t, c.Set("a", []byte("2")))
+	v, ok := c.Get("a")
+	require.True(t, ok)
+	require.Equal(t, "2", string(v))
+	require.Equal(t, 1, c.Len())
+
+	// the returned value is a copy
+	v[0] = 'x'
+	v, _ = c.Get("a")
+	require.Equal(t, "2", string(v))
+
+	// evicted slots are reused
+	for _, k := range []string{"b", "c", "d", "e"} {
+		require.True(t, c.Set(k, []byte(k)))
+	}
+	next := c.arena.carve[0].next
+	require.True(t, c.Set("f", []byte("f")))
+	require.Equal(t, next, c.arena.carve[0].next)
+	require.Len(t, c.arena.free[0], 1)
+
+	require.False(t, c.Set("big", make([]byte, 200)))
+	require.False(t, c.Set("huge", make([]byte, ChunkSize+1)))
+
+	require.True(t, c.Remove("a"))
+	require.False(t, c.Remove("a"))
+	c.Purge()
+	require.Zero(t, c.Len())
+	_, ok = c.Get("f")
+	require.False(t, ok)
+}
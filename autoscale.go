@@ -0,0 +1,154 @@
+package slru
+
+import (
+	"cmp"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/hey-kong/slru/list"
+)
+
+// DefaultAutoscaleInterval and DefaultAutoscaleStep are the period and the
+// step of Autoscaling unless set.
+const (
+	DefaultAutoscaleInterval = 10 * time.Second
+	DefaultAutoscaleStep     = 0.1
+)
+
+// Autoscaling configures WithAutoscaling.
+type Autoscaling struct {
+	// MinSize and MaxSize bound the size of the cache, or of each shard of
+	// a Sharded.
+	MinSize, MaxSize int
+
+	// TargetHitRatio is the hit ratio to maintain, in (0, 1).
+	TargetHitRatio float64
+
+	// Interval is the period of the adjustments, DefaultAutoscaleInterval
+	// if zero.
+	Interval time.Duration
+
+	// Step is the share of the size added or removed per adjustment,
+	// DefaultAutoscaleStep if zero.
+	Step float64
+}
+
+// WithAutoscaling resizes the cache every interval, within the bounds of
+// cfg, to keep its hit ratio at the target as the workload shifts.
+//
+// The benefit of more space is estimated from a ghost list of the keys of
+// the last step of evictions: a new key found there would have been a hit
+// with a step more, so the share of such keys among the lookups of the
+// interval estimates the hit ratio a step would gain. The cache grows a
+// step while under the target with a gain, and shrinks a step while the
+// hit ratio would stay over the target without that gain, taken as the
+// loss of a step less.
+func WithAutoscaling[K comparable, V any](cfg Autoscaling) Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		cfg.Interval = cmp.Or(cfg.Interval, DefaultAutoscaleInterval)
+		cfg.Step = cmp.Or(cfg.Step, DefaultAutoscaleStep)
+		s.autoscaling = &cfg
+	}
+}
+
+// startAutoscaling starts the ghost list and the controller, if enabled.
+func (s *SLRU[K, V]) startAutoscaling() {
+	if s.autoscaling == nil {
+		return
+	}
+	s.ghosts = make(map[K]*list.Element)
+	s.ghostKeys = list.NewBounded(s.ghostCap(), func(v any) {
+		delete(s.ghosts, v.(K))
+	})
+	s.workers.Add(1)
+	go s.autoscale()
+}
+
+// ghostCap is the number of keys of the ghost list: a step of the size.
+func (s *SLRU[K, V]) ghostCap() int {
+	return max(int(math.Ceil(s.autoscaling.Step*float64(s.size))), 1)
+}
+
+// ghost records the key of an evicted entry in the ghost list, if any.
+func (s *SLRU[K, V]) ghost(key K) {
+	if s.ghostKeys == nil {
+		return
+	}
+	if e, ok := s.ghosts[key]; ok {
+		s.ghostKeys.MoveToFront(e)
+		return
+	}
+	if e := s.ghostKeys.PushFront(key); e != nil {
+		s.ghosts[key] = e
+	}
+}
+
+// unghost counts the insertion of key as a ghost hit if it was evicted
+// recently.
+func (s *SLRU[K, V]) unghost(key K) {
+	if e, ok := s.ghosts[key]; ok {
+		s.ghostKeys.Remove(e)
+		delete(s.ghosts, key)
+		s.ghostHits++
+	}
+}
+
+func (s *SLRU[K, V]) autoscale() {
+	defer s.workers.Done()
+	ticker := time.NewTicker(s.autoscaling.Interval)
+	defer ticker.Stop()
+	var hits, misses, ghostHits uint64
+	s.maintain(func() { hits, misses, ghostHits = s.autoscaleCounts() })
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			s.maintain(func() {
+				h, m, g := s.autoscaleCounts()
+				s.adjustSize(h-hits, h+m-hits-misses, g-ghostHits)
+				hits, misses, ghostHits = h, m, g
+			})
+		}
+	}
+}
+
+func (s *SLRU[K, V]) autoscaleCounts() (hits, misses, ghostHits uint64) {
+	s.acquire()
+	defer s.unlock()
+
+	return s.stats.hits.load(), s.stats.misses.load(), s.ghostHits
+}
+
+// adjustSize resizes the cache by a step, if its hits, lookups and ghost
+// hits over the last interval call for it.
+func (s *SLRU[K, V]) adjustSize(hits, lookups, ghostHits uint64) {
+	if lookups == 0 {
+		return
+	}
+	ratio := float64(hits) / float64(lookups)
+	gain := float64(ghostHits) / float64(lookups)
+
+	defer s.region("autoscale")()
+	s.acquire()
+	defer s.unlock()
+
+	cfg := s.autoscaling
+	size := s.size
+	step := s.ghostCap()
+	switch {
+	case ratio < cfg.TargetHitRatio && gain > 0:
+		size = min(size+step, cfg.MaxSize)
+	case ratio-gain > cfg.TargetHitRatio:
+		size = max(size-step, cfg.MinSize)
+	}
+	if size == s.size {
+		return
+	}
+	old := s.size
+	s.setSize(size)
+	evicted := s.trim()
+	s.ghostKeys.SetCap(s.ghostCap())
+	s.log(slog.LevelInfo, "slru: autoscale", "from", old, "to", size, "hitRatio", ratio, "gain", gain, "evicted", evicted)
+}
@@ -0,0 +1,69 @@
+package slru
+
+import (
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutoscalingSteps(t *testing.T) {
+	cache := newSLRU[int, int](100, WithAutoscaling[int, int](Autoscaling{
+		MinSize: 90, MaxSize: 115, TargetHitRatio: 0.9, Interval: time.Hour,
+	}))
+	defer cache.Close()
+
+	cache.adjustSize(50, 100, 10)
+	require.Equal(t, 110, cache.Config().Size)
+	cache.adjustSize(50, 100, 10)
+	require.Equal(t, 115, cache.Config().Size)
+
+	// over the target, but not without the gain of the last step
+	cache.adjustSize(95, 100, 10)
+	require.Equal(t, 115, cache.Config().Size)
+	cache.adjustSize(99, 100, 0)
+	require.Equal(t, 103, cache.Config().Size)
+	cache.adjustSize(99, 100, 0)
+	require.Equal(t, 92, cache.Config().Size)
+	cache.adjustSize(99, 100, 0)
+	require.Equal(t, 90, cache.Config().Size)
+	cache.adjustSize(0, 0, 0)
+	require.Equal(t, 90, cache.Config().Size)
+}
+
+func TestAutoscalingGhosts(t *testing.T) {
+	cache := newSLRU[int, int](10, WithAutoscaling[int, int](Autoscaling{
+		MinSize: 10, MaxSize: 100, TargetHitRatio: 0.9, Interval: time.Hour, Step: 0.5,
+	}))
+	defer cache.Close()
+	for i := range 10 {
+		cache.Set(i, i)
+	}
+	require.Len(t, cache.ghosts, 5)
+	require.False(t, cache.Contains(0))
+	cache.Set(9, 9)
+	cache.Set(0, 0)
+	require.Equal(t, uint64(0), cache.ghostHits)
+	cache.Set(7, 7)
+	require.Equal(t, uint64(1), cache.ghostHits)
+	require.NoError(t, cache.Verify())
+}
+
+func TestAutoscalingGrows(t *testing.T) {
+	cache := newSLRU[int, int](100, WithAutoscaling[int, int](Autoscaling{
+		MinSize: 100, MaxSize: 400, TargetHitRatio: 0.9, Interval: time.Millisecond,
+	}))
+	defer cache.Close()
+
+	// uniform lookups over 130 keys need a size of about 120 to hit 90%
+	require.Eventually(t, func() bool {
+		for range 1000 {
+			key := rand.IntN(130)
+			if _, ok := cache.Get(key); !ok {
+				cache.Set(key, key)
+			}
+		}
+		return cache.Config().Size >= 110
+	}, 5*time.Second, time.Millisecond)
+}
@@ -0,0 +1,118 @@
+// Package bench measures the hit ratio and throughput of a cache on
+// synthetic key streams. Streams are seeded, so runs with the same
+// configuration are reproducible.
+package bench
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/hey-kong/slru"
+)
+
+// Stream generates a sequence of keys.
+type Stream interface {
+	Next() uint64
+}
+
+type zipf struct {
+	z *rand.Zipf
+}
+
+func (z zipf) Next() uint64 {
+	return z.z.Uint64()
+}
+
+// Zipf returns a stream of keys in [0, n) following a Zipf distribution
+// with exponent s > 1, where key 0 is the most popular.
+func Zipf(seed uint64, s float64, n uint64) Stream {
+	r := rand.New(rand.NewPCG(seed, seed))
+	return zipf{rand.NewZipf(r, s, 1, n-1)}
+}
+
+type uniform struct {
+	r *rand.Rand
+	n uint64
+}
+
+func (u uniform) Next() uint64 {
+	return u.r.Uint64N(u.n)
+}
+
+// Uniform returns a stream of keys drawn uniformly from [0, n).
+func Uniform(seed, n uint64) Stream {
+	return uniform{rand.New(rand.NewPCG(seed, seed)), n}
+}
+
+type scanMix struct {
+	base    Stream
+	every   int
+	length  int
+	next    uint64
+	i, scan int
+}
+
+func (s *scanMix) Next() uint64 {
+	if s.scan > 0 {
+		s.scan--
+		s.next++
+		return s.next - 1
+	}
+	s.i++
+	if s.i%s.every == 0 {
+		s.scan = s.length
+	}
+	return s.base.Next()
+}
+
+// ScanMix interleaves base with sequential scans: after every keys of base
+// it yields length never-repeating keys counting up from start, which
+// should lie outside the key range of base.
+func ScanMix(base Stream, every, length int, start uint64) Stream {
+	return &scanMix{base: base, every: every, length: length, next: start}
+}
+
+// Result is the outcome of a run.
+type Result struct {
+	Ops     int
+	Hits    int
+	Elapsed time.Duration
+}
+
+// HitRatio returns the share of lookups that were hits.
+func (r Result) HitRatio() float64 {
+	if r.Ops == 0 {
+		return 0
+	}
+	return float64(r.Hits) / float64(r.Ops)
+}
+
+// Throughput returns the operations per second.
+func (r Result) Throughput() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+	return float64(r.Ops) / r.Elapsed.Seconds()
+}
+
+func (r Result) String() string {
+	return fmt.Sprintf("ops=%d hit_ratio=%.4f throughput=%.0f/s", r.Ops, r.HitRatio(), r.Throughput())
+}
+
+// Run looks up ops keys of stream in cache, setting each key that misses,
+// as a read-through cache would.
+func Run(cache slru.Cache[uint64, uint64], stream Stream, ops int) Result {
+	r := Result{Ops: ops}
+	start := time.Now()
+	for i := 0; i < ops; i++ {
+		key := stream.Next()
+		if _, ok := cache.Get(key); ok {
+			r.Hits++
+		} else {
+			cache.Set(key, key)
+		}
+	}
+	r.Elapsed = time.Since(start)
+	return r
+}
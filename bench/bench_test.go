@@ -0,0 +1,65 @@
+package bench
+
+import (
+	"testing"
+
+	"github.com/hey-kong/slru"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamsAreReproducible(t *testing.T) {
+	for _, newStream := range []func() Stream{
+		func() Stream { return Zipf(1, 1.1, 1000) },
+		func() Stream { return Uniform(1, 1000) },
+		func() Stream { return ScanMix(Uniform(1, 1000), 10, 5, 1000) },
+	} {
+		a, b := newStream(), newStream()
+		for i := 0; i < 100; i++ {
+			key := a.Next()
+			require.Equal(t, key, b.Next())
+			require.Less(t, key, uint64(1100))
+		}
+	}
+}
+
+func TestScanMix(t *testing.T) {
+	s := ScanMix(Uniform(1, 10), 2, 3, 100)
+	var keys []uint64
+	for i := 0; i < 7; i++ {
+		keys = append(keys, s.Next())
+	}
+	require.Equal(t, []uint64{100, 101, 102}, keys[2:5])
+	require.Less(t, keys[5], uint64(10))
+}
+
+func TestRun(t *testing.T) {
+	zipf := Run(slru.New[uint64, uint64](100), Zipf(1, 1.2, 10000), 10000)
+	uniform := Run(slru.New[uint64, uint64](100), Uniform(1, 10000), 10000)
+	require.Equal(t, 10000, zipf.Ops)
+	require.Greater(t, zipf.HitRatio(), uniform.HitRatio())
+	require.Greater(t, zipf.Throughput(), 0.0)
+	require.Contains(t, zipf.String(), "hit_ratio=")
+}
+
+func TestOptimal(t *testing.T) {
+	// with room for 2, MIN evicts 2 for 3, 3 for 2 and 1 for 4
+	trace := []int{1, 2, 3, 1, 2, 4, 2, 1}
+	r := Optimal(trace, 2)
+	require.Equal(t, 8, r.Ops)
+	require.Equal(t, 2, r.Hits)
+	require.Zero(t, Optimal(trace, 0).Hits)
+	require.Equal(t, 4, Optimal(trace, 4).Hits)
+}
+
+func TestOptimalBoundsPolicies(t *testing.T) {
+	keys := Record(ScanMix(Zipf(1, 1.1, 5000), 1000, 200, 10000), 20000)
+	opt := Optimal(keys, 200)
+	for _, cache := range []slru.Cache[uint64, uint64]{
+		slru.New[uint64, uint64](200),
+		slru.NewARC[uint64, uint64](200),
+		slru.NewLIRS[uint64, uint64](200),
+	} {
+		r := Run(cache, Replay(keys), len(keys))
+		require.Less(t, r.Hits, opt.Hits)
+	}
+}
@@ -0,0 +1,109 @@
+package bench
+
+import (
+	"container/heap"
+	"math"
+	"time"
+)
+
+// Record returns the next ops keys of stream, to replay the same trace
+// against several caches and Optimal.
+func Record(stream Stream, ops int) []uint64 {
+	keys := make([]uint64, ops)
+	for i := range keys {
+		keys[i] = stream.Next()
+	}
+	return keys
+}
+
+// Replay is a Stream over recorded keys, cycling when they run out.
+func Replay(keys []uint64) Stream {
+	return &replay{keys: keys}
+}
+
+type replay struct {
+	keys []uint64
+	next int
+}
+
+func (r *replay) Next() uint64 {
+	key := r.keys[r.next]
+	r.next = (r.next + 1) % len(r.keys)
+	return key
+}
+
+// Optimal returns the result of Belady's MIN, the clairvoyant policy that
+// evicts the key used furthest in the future, on trace with a cache of size
+// entries. Like Run, every miss is inserted. No online policy gets more
+// hits, so it bounds what tuning a cache can achieve on a trace.
+func Optimal[K comparable](trace []K, size int) Result {
+	start := time.Now()
+	// next[i] is the position of the next use of trace[i]
+	next := make([]int, len(trace))
+	last := make(map[K]int)
+	for i := len(trace) - 1; i >= 0; i-- {
+		if j, ok := last[trace[i]]; ok {
+			next[i] = j
+		} else {
+			next[i] = math.MaxInt
+		}
+		last[trace[i]] = i
+	}
+
+	r := Result{Ops: len(trace)}
+	resident := make(map[K]*optItem[K])
+	var h optHeap[K]
+	for i, key := range trace {
+		if item, ok := resident[key]; ok {
+			r.Hits++
+			item.next = next[i]
+			heap.Fix(&h, item.index)
+			continue
+		}
+		if size < 1 {
+			continue
+		}
+		if len(h) >= size {
+			delete(resident, heap.Pop(&h).(*optItem[K]).key)
+		}
+		item := &optItem[K]{key: key, next: next[i]}
+		resident[key] = item
+		heap.Push(&h, item)
+	}
+	r.Elapsed = time.Since(start)
+	return r
+}
+
+// optItem is a resident key and the position of its next use.
+type optItem[K comparable] struct {
+	key   K
+	next  int
+	index int
+}
+
+// optHeap is a max-heap of resident keys by next use.
+type optHeap[K comparable] []*optItem[K]
+
+func (h optHeap[K]) Len() int { return len(h) }
+
+func (h optHeap[K]) Less(i, j int) bool { return h[i].next > h[j].next }
+
+func (h optHeap[K]) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *optHeap[K]) Push(x any) {
+	item := x.(*optItem[K])
+	item.index = len(*h)
+	*h = append(*h, item)
+}
+
+func (h *optHeap[K]) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return item
+}
@@ -0,0 +1,136 @@
+package slru
+
+import "context"
+
+// write is a buffered SetAsync, or a Flush marker when flushed is set.
+type write[K comparable, V any] struct {
+	key     K
+	value   V
+	flushed chan struct{}
+}
+
+// WithWriteBuffer buffers up to n writes of SetAsync, applied to the cache
+// in batches by a background goroutine.
+func WithWriteBuffer[K comparable, V any](n int) Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		s.writes = make(chan write[K, V], n)
+		s.writeWake = make(chan struct{}, 1)
+	}
+}
+
+// SetAsync sets the value for key without waiting for the cache lock when
+// a write buffer is configured with WithWriteBuffer. The write becomes
+// visible once the background goroutine applies it, typically within
+// microseconds; until then Get may still return the previous value. Call
+// Flush to wait for it. SetAsync applies the write itself, like Set, when
+// the buffer is not configured, and after the buffered writes when it is
+// full or the cache closed, so the writes of a caller apply in order.
+func (s *SLRU[K, V]) SetAsync(key K, value V) {
+	key = s.normalize(key)
+	if s.writes == nil {
+		s.Set(key, value)
+		return
+	}
+	w := write[K, V]{key: key, value: value}
+	s.closeLock.RLock()
+	if !s.closed {
+		select {
+		case s.writes <- w:
+			s.closeLock.RUnlock()
+			s.wakeWriter()
+			return
+		default:
+		}
+	}
+	s.closeLock.RUnlock()
+	s.applyBatch(&w)
+}
+
+// Flush waits until the writes buffered by SetAsync before the call are
+// applied, or until ctx is done.
+func (s *SLRU[K, V]) Flush(ctx context.Context) error {
+	s.closeLock.RLock()
+	defer s.closeLock.RUnlock()
+
+	if s.writes == nil || s.closed {
+		return nil
+	}
+	flushed := make(chan struct{})
+	select {
+	case s.writes <- write[K, V]{flushed: flushed}:
+		s.wakeWriter()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-flushed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// wakeWriter wakes the background goroutine to apply the buffered writes.
+func (s *SLRU[K, V]) wakeWriter() {
+	select {
+	case s.writeWake <- struct{}{}:
+	default:
+	}
+}
+
+// applyWrites applies buffered writes until the cache is closed, then the
+// writes left.
+func (s *SLRU[K, V]) applyWrites() {
+	defer s.workers.Done()
+	for {
+		select {
+		case <-s.writeWake:
+			s.applyBatch(nil)
+		case <-s.done:
+			s.applyBatch(nil)
+			return
+		}
+	}
+}
+
+// applyBatch applies the buffered writes, then last if not nil, under a
+// single acquisition of the lock. Writes leave the buffer only under the
+// lock, so none is applied after a later one.
+func (s *SLRU[K, V]) applyBatch(last *write[K, V]) {
+	s.acquire()
+	var flushed []chan struct{}
+	for more := true; more; {
+		select {
+		case w := <-s.writes:
+			if w.flushed != nil {
+				flushed = append(flushed, w.flushed)
+			} else {
+				s.set(w.key, w.value)
+			}
+		default:
+			more = false
+		}
+	}
+	if last != nil {
+		s.set(last.key, last.value)
+	}
+	s.unlock()
+	for _, ch := range flushed {
+		close(ch)
+	}
+}
+
+func (c *Sharded[K, V]) SetAsync(key K, value V) {
+	c.shard(key).SetAsync(key, value)
+}
+
+// Flush waits until the writes buffered in every shard before the call are
+// applied, or until ctx is done.
+func (c *Sharded[K, V]) Flush(ctx context.Context) error {
+	for _, s := range c.shards {
+		if err := s.Flush(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
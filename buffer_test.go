@@ -0,0 +1,71 @@
+package slru
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetAsync(t *testing.T) {
+	cache := New[int, int](100, WithWriteBuffer[int, int](8))
+	for i := 0; i < 20; i++ {
+		cache.SetAsync(i, i)
+	}
+	require.NoError(t, cache.Flush(context.Background()))
+	require.Equal(t, 20, cache.Len())
+	v, ok := cache.Get(19)
+	require.True(t, ok)
+	require.Equal(t, 19, v)
+}
+
+func TestSetAsyncWithoutBuffer(t *testing.T) {
+	cache := New[int, int](10)
+	cache.SetAsync(1, 1)
+	require.True(t, cache.Contains(1))
+	require.NoError(t, cache.Flush(context.Background()))
+}
+
+func TestFlushHonorsContext(t *testing.T) {
+	cache := newSLRU[int, int](10, WithWriteBuffer[int, int](1))
+	cache.lock.Lock()
+	cache.SetAsync(1, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, cache.Flush(ctx), context.Canceled)
+	cache.lock.Unlock()
+
+	require.NoError(t, cache.Flush(context.Background()))
+	require.True(t, cache.Contains(1))
+}
+
+func TestSetAsyncFull(t *testing.T) {
+	cache := newSLRU[int, int](10, WithWriteBuffer[int, int](2))
+	defer cache.Close()
+
+	// writes past the full buffer apply after those it holds
+	cache.lock.Lock()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := range 100 {
+			cache.SetAsync(1, i)
+		}
+	}()
+	cache.lock.Unlock()
+	<-done
+	require.NoError(t, cache.Flush(context.Background()))
+	v, ok := cache.Get(1)
+	require.True(t, ok)
+	require.Equal(t, 99, v)
+}
+
+func TestSetAsyncOnSharded(t *testing.T) {
+	cache := NewSharded[int, int](1000, 4, nil, WithWriteBuffer[int, int](8))
+	for i := 0; i < 20; i++ {
+		cache.SetAsync(i, i)
+	}
+	require.NoError(t, cache.Flush(context.Background()))
+	require.Equal(t, 20, cache.Len())
+}
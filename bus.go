@@ -0,0 +1,182 @@
+package slru
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+// Invalidation is a message of an InvalidationBus.
+type Invalidation struct {
+	// Origin identifies the publishing cache, which ignores its own
+	// messages.
+	Origin string
+	// Keys are the invalidated keys, encoded by the key codec of the caches.
+	Keys []string
+	// All invalidates every entry.
+	All bool
+	// Tags invalidate the entries tagged with them, as InvalidateTag.
+	Tags []string `json:",omitempty"`
+}
+
+// InvalidationBus carries invalidations between caches, typically replicas
+// of the same data in different processes.
+type InvalidationBus interface {
+	// Publish sends msg to every subscriber.
+	Publish(ctx context.Context, msg Invalidation) error
+
+	// Subscribe calls fn with every message published from then on until
+	// unsubscribe is called. fn may be called concurrently with cache
+	// operations, but not with itself.
+	Subscribe(fn func(msg Invalidation)) (unsubscribe func(), err error)
+}
+
+// WithInvalidationBus attaches the cache to bus: Remove, PurgeFunc and
+// Purge publish the keys they remove, InvalidateTag its tag, and
+// invalidations published by other caches remove their keys or tags here,
+// without publishing them again. keys
+// encodes keys for the bus, e.g. JSONCodec, and must encode equal keys
+// identically. Publish errors are logged, not returned.
+func WithInvalidationBus[K comparable, V any](bus InvalidationBus, keys Codec[K]) Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		s.bus = bus
+		s.busKeys = keys
+	}
+}
+
+// setOrigin picks the random id of the cache on buses and hint exchanges.
+func (s *SLRU[K, V]) setOrigin() {
+	s.origin = fmt.Sprintf("%016x", s.randUint64())
+}
+
+// subscribe starts applying the invalidations of other caches.
+func (s *SLRU[K, V]) subscribe() {
+	unsubscribe, err := s.bus.Subscribe(s.invalidate)
+	if err != nil {
+		s.log(slog.LevelError, "slru: subscribe to invalidations", "err", err)
+		return
+	}
+	s.unsubscribe = unsubscribe
+}
+
+// invalidate applies msg unless it came from this cache.
+func (s *SLRU[K, V]) invalidate(msg Invalidation) {
+	if msg.Origin == s.origin {
+		return
+	}
+	if msg.All {
+		s.purge()
+		return
+	}
+	keys := make([]K, 0, len(msg.Keys))
+	for _, data := range msg.Keys {
+		key, err := s.busKeys.Decode([]byte(data))
+		if err != nil {
+			s.log(slog.LevelWarn, "slru: decode invalidated key", "key", data, "err", err)
+			continue
+		}
+		keys = append(keys, key)
+	}
+
+	s.acquire()
+	defer s.unlock()
+
+	for _, tag := range msg.Tags {
+		s.invalidateTag(tag)
+	}
+	for _, key := range keys {
+		s.remove(key)
+	}
+}
+
+// broadcast publishes msg from this cache, outside the cache lock.
+func (s *SLRU[K, V]) broadcast(msg Invalidation) {
+	msg.Origin = s.origin
+	if err := s.bus.Publish(context.Background(), msg); err != nil {
+		s.log(slog.LevelWarn, "slru: publish invalidation", "err", err)
+	}
+}
+
+func (s *SLRU[K, V]) encodeKeys(keys ...K) []string {
+	encoded := make([]string, 0, len(keys))
+	for _, key := range keys {
+		data, err := s.busKeys.Encode(key)
+		if err != nil {
+			s.log(slog.LevelWarn, "slru: encode invalidated key", "key", key, "err", err)
+			continue
+		}
+		encoded = append(encoded, string(data))
+	}
+	return encoded
+}
+
+// LocalBus is an InvalidationBus and a HintExchange connecting the caches
+// of one process, delivering each message synchronously from Publish or
+// ShareHint.
+type LocalBus struct {
+	invalidations fanout[Invalidation]
+	hints         fanout[Hint]
+}
+
+// NewLocalBus returns an empty LocalBus.
+func NewLocalBus() *LocalBus {
+	return &LocalBus{}
+}
+
+func (b *LocalBus) Publish(ctx context.Context, msg Invalidation) error {
+	b.invalidations.publish(msg)
+	return nil
+}
+
+func (b *LocalBus) Subscribe(fn func(msg Invalidation)) (unsubscribe func(), err error) {
+	return b.invalidations.subscribe(fn), nil
+}
+
+func (b *LocalBus) ShareHint(ctx context.Context, hint Hint) error {
+	b.hints.publish(hint)
+	return nil
+}
+
+func (b *LocalBus) SubscribeHints(fn func(hint Hint)) (unsubscribe func(), err error) {
+	return b.hints.subscribe(fn), nil
+}
+
+// fanout delivers messages to a set of subscribers, one message at a time
+// per subscriber.
+type fanout[M any] struct {
+	lock        sync.RWMutex
+	subscribers map[int]func(msg M)
+	next        int
+}
+
+func (f *fanout[M]) publish(msg M) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	for _, fn := range f.subscribers {
+		fn(msg)
+	}
+}
+
+func (f *fanout[M]) subscribe(fn func(msg M)) (unsubscribe func()) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.subscribers == nil {
+		f.subscribers = make(map[int]func(msg M))
+	}
+	id := f.next
+	f.next++
+	var serial sync.Mutex
+	f.subscribers[id] = func(msg M) {
+		serial.Lock()
+		defer serial.Unlock()
+		fn(msg)
+	}
+	return func() {
+		f.lock.Lock()
+		defer f.lock.Unlock()
+		delete(f.subscribers, id)
+	}
+}
@@ -0,0 +1,66 @@
+package slru
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvalidationBus(t *testing.T) {
+	bus := NewLocalBus()
+	a := newSLRU[int, int](100, WithInvalidationBus[int, int](bus, JSONCodec[int]{}))
+	b := newSLRU[int, int](100, WithInvalidationBus[int, int](bus, JSONCodec[int]{}))
+	for i := 0; i < 10; i++ {
+		a.Set(i, i)
+		b.Set(i, i)
+	}
+
+	require.True(t, a.Remove(1))
+	require.False(t, b.Contains(1))
+	require.False(t, b.Remove(1))
+
+	require.Equal(t, 2, b.PurgeFunc(func(key, value int) bool { return key < 3 }))
+	require.False(t, a.Contains(0))
+	require.False(t, a.Contains(2))
+	require.True(t, a.Contains(3))
+
+	// updates stay local
+	a.Set(3, 30)
+	value, ok := b.Get(3)
+	require.True(t, ok)
+	require.Equal(t, 3, value)
+
+	a.Purge()
+	require.Zero(t, b.Len())
+	require.NoError(t, a.Verify())
+	require.NoError(t, b.Verify())
+}
+
+type failingBus struct {
+	*LocalBus
+}
+
+func (*failingBus) Publish(ctx context.Context, msg Invalidation) error {
+	return errors.New("down")
+}
+
+func TestInvalidationBusError(t *testing.T) {
+	bus := &failingBus{LocalBus: NewLocalBus()}
+	cache := newSLRU[int, int](100, WithInvalidationBus[int, int](bus, JSONCodec[int]{}))
+	cache.Set(1, 1)
+	require.True(t, cache.Remove(1))
+	require.False(t, cache.Contains(1))
+}
+
+func TestLocalBusUnsubscribe(t *testing.T) {
+	bus := NewLocalBus()
+	var got []Invalidation
+	unsubscribe, err := bus.Subscribe(func(msg Invalidation) { got = append(got, msg) })
+	require.NoError(t, err)
+	require.NoError(t, bus.Publish(context.Background(), Invalidation{Keys: []string{"1"}}))
+	unsubscribe()
+	require.NoError(t, bus.Publish(context.Background(), Invalidation{All: true}))
+	require.Equal(t, []Invalidation{{Keys: []string{"1"}}}, got)
+}
@@ -0,0 +1,422 @@
+// Package bytescache is an SLRU specialized for string keys and []byte
+// values, the common case of proxies and CDNs. Unlike slru.SLRU it is not
+// generic and links its entries intrusively, so operations convert no
+// values to interfaces, and reuses the buffers of evicted values, so a
+// warm cache stores new values without allocating.
+//
+// Capacity is accounted in bytes: each entry weighs its key, the capacity
+// of its buffer and a fixed overhead.
+package bytescache
+
+import (
+	"hash/maphash"
+	"sync"
+
+	"github.com/hey-kong/slru"
+)
+
+// entryOverhead approximates the memory an entry takes besides its key and
+// buffer: the entry itself and its slot in the index.
+const entryOverhead = 64
+
+// entry is a cached value, linked in the list of its segment.
+type entry struct {
+	key        string
+	buf        []byte
+	prev, next *entry
+	protected  bool
+	hits       uint32
+	// refs counts the references of GetRef not yet released. An entry
+	// removed while referenced is retired: out of the cache, but keeping
+	// its buffer until the last release.
+	refs    int32
+	retired bool
+	// release drops a reference, allocated once per entry and kept when
+	// the entry is reused.
+	release func()
+}
+
+func (e *entry) weight() int {
+	return len(e.key) + cap(e.buf) + entryOverhead
+}
+
+// segment is a circular list of entries around a sentinel, from the most
+// recently used to the next victim.
+type segment struct {
+	root   entry
+	weight int
+	limit  int
+}
+
+func (l *segment) init() {
+	l.root.prev = &l.root
+	l.root.next = &l.root
+	l.weight = 0
+}
+
+func (l *segment) pushFront(e *entry) {
+	e.prev = &l.root
+	e.next = l.root.next
+	e.prev.next = e
+	e.next.prev = e
+	l.weight += e.weight()
+}
+
+func (l *segment) unlink(e *entry) {
+	e.prev.next = e.next
+	e.next.prev = e.prev
+	e.prev, e.next = nil, nil
+	l.weight -= e.weight()
+}
+
+// back returns the next victim, or nil if l is empty.
+func (l *segment) back() *entry {
+	if l.root.prev == &l.root {
+		return nil
+	}
+	return l.root.prev
+}
+
+// stats are the counters of a shard, under its lock.
+type stats struct {
+	hits, misses, evictions                       uint64
+	promotions, protectedEvictions, oneHitWonders uint64
+}
+
+// shard is an independently locked SLRU.
+type shard struct {
+	lock      sync.Mutex
+	items     map[string]*entry
+	probation segment
+	protected segment
+	pool      pool
+	// spare holds unlinked entries for reuse.
+	spare []*entry
+	stats stats
+}
+
+func (s *shard) init(capacity int) {
+	s.items = make(map[string]*entry)
+	s.probation.init()
+	s.protected.init()
+	s.probation.limit = int(slru.DefaultProbationRatio * float64(capacity))
+	s.protected.limit = capacity - s.probation.limit
+	s.pool.limit = capacity / 8
+}
+
+func (s *shard) segment(e *entry) *segment {
+	if e.protected {
+		return &s.protected
+	}
+	return &s.probation
+}
+
+// set stores a copy of value for key, reporting whether it did: values
+// too heavy for probation are rejected.
+func (s *shard) set(key string, value []byte) bool {
+	if e, ok := s.items[key]; ok {
+		if e.refs > 0 {
+			// readers hold the buffer: write into a fresh entry
+			e = s.replace(e)
+		}
+		l := s.segment(e)
+		l.unlink(e)
+		if cap(e.buf) < len(value) || classOf(len(value)) != classOf(cap(e.buf)) {
+			s.pool.put(e.buf)
+			e.buf = s.pool.get(len(value))
+		}
+		e.buf = append(e.buf[:0], value...)
+		l.pushFront(e)
+		s.promote(e)
+		s.trim()
+		// an update heavier than protected evicts itself
+		_, ok = s.items[key]
+		return ok
+	}
+
+	if len(key)+classSize(len(value))+entryOverhead > s.probation.limit {
+		return false
+	}
+	e := s.entry()
+	e.key = key
+	e.buf = append(s.pool.get(len(value)), value...)
+	s.items[key] = e
+	s.probation.pushFront(e)
+	s.trim()
+	return true
+}
+
+// get returns the entry of key, promoting it, or nil on a miss.
+func (s *shard) get(key string) *entry {
+	e, ok := s.items[key]
+	if !ok {
+		s.stats.misses++
+		return nil
+	}
+	s.stats.hits++
+	e.hits++
+	s.promote(e)
+	s.trim()
+	return e
+}
+
+// promote moves e to the front of protected.
+func (s *shard) promote(e *entry) {
+	if s.protected.limit < 1 {
+		l := s.segment(e)
+		l.unlink(e)
+		l.pushFront(e)
+		return
+	}
+	s.segment(e).unlink(e)
+	if !e.protected {
+		e.protected = true
+		s.stats.promotions++
+	}
+	s.protected.pushFront(e)
+}
+
+// trim evicts from the segment tails until both fit their limits.
+func (s *shard) trim() {
+	for s.protected.weight > s.protected.limit {
+		s.evict(&s.protected)
+	}
+	for s.probation.weight > s.probation.limit {
+		s.evict(&s.probation)
+	}
+}
+
+func (s *shard) evict(l *segment) {
+	e := l.back()
+	s.stats.evictions++
+	if e.protected {
+		s.stats.protectedEvictions++
+	} else if e.hits == 0 {
+		s.stats.oneHitWonders++
+	}
+	s.remove(e)
+}
+
+// remove unlinks e, recycling its entry and buffer unless referenced.
+func (s *shard) remove(e *entry) {
+	s.segment(e).unlink(e)
+	delete(s.items, e.key)
+	s.retire(e)
+}
+
+// retire recycles e once it is no longer referenced.
+func (s *shard) retire(e *entry) {
+	if e.refs > 0 {
+		e.retired = true
+		return
+	}
+	s.pool.put(e.buf)
+	*e = entry{release: e.release}
+	s.spare = append(s.spare, e)
+}
+
+// replace puts a fresh entry without a buffer in place of e, which is
+// retired, and returns it.
+func (s *shard) replace(e *entry) *entry {
+	n := s.entry()
+	n.key, n.protected, n.hits = e.key, e.protected, e.hits
+	l := s.segment(e)
+	n.prev, n.next = e, e.next
+	n.prev.next = n
+	n.next.prev = n
+	l.weight += n.weight()
+	l.unlink(e)
+	s.items[n.key] = n
+	s.retire(e)
+	return n
+}
+
+// unref drops a reference to e, recycling it if it was the last one of a
+// retired entry.
+func (s *shard) unref(e *entry) {
+	e.refs--
+	if e.refs == 0 && e.retired {
+		e.retired = false
+		s.retire(e)
+	}
+}
+
+// entry returns a blank entry, reusing a spare one if any.
+func (s *shard) entry() *entry {
+	if n := len(s.spare); n > 0 {
+		e := s.spare[n-1]
+		s.spare = s.spare[:n-1]
+		return e
+	}
+	e := new(entry)
+	e.release = func() {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+
+		s.unref(e)
+	}
+	return e
+}
+
+func (s *shard) purge() {
+	for _, e := range s.items {
+		s.retire(e)
+	}
+	s.items = make(map[string]*entry)
+	s.probation.init()
+	s.protected.init()
+}
+
+// Cache is a cache of []byte values by string key, safe for concurrent
+// use. It copies values in and out, so callers may reuse their buffers,
+// except for GetRef.
+type Cache struct {
+	shards []shard
+	seed   maphash.Seed
+	mask   uint64
+}
+
+// New returns a Cache holding up to capacity bytes.
+func New(capacity int) *Cache {
+	return NewSharded(capacity, 1)
+}
+
+// NewSharded returns a Cache holding up to capacity bytes, spread over
+// shards independently locked shards, rounded up to a power of two.
+func NewSharded(capacity, shards int) *Cache {
+	n := 1
+	for n < shards {
+		n <<= 1
+	}
+	c := &Cache{shards: make([]shard, n), seed: maphash.MakeSeed(), mask: uint64(n - 1)}
+	for i := range c.shards {
+		c.shards[i].init((capacity + n - 1) / n)
+	}
+	return c
+}
+
+func (c *Cache) shard(key string) *shard {
+	if c.mask == 0 {
+		return &c.shards[0]
+	}
+	return &c.shards[maphash.String(c.seed, key)&c.mask]
+}
+
+// Set stores a copy of value for key, reporting whether it did: values too
+// large for the probation segment are not stored.
+func (c *Cache) Set(key string, value []byte) (ok bool) {
+	s := c.shard(key)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.set(key, value)
+}
+
+// Get returns a copy of the value of key.
+func (c *Cache) Get(key string) (value []byte, ok bool) {
+	return c.Append(nil, key)
+}
+
+// Append appends the value of key to dst and returns the extended slice,
+// so callers reusing dst read hits without allocating.
+func (c *Cache) Append(dst []byte, key string) (value []byte, ok bool) {
+	s := c.shard(key)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	e := s.get(key)
+	if e == nil {
+		return dst, false
+	}
+	return append(dst, e.buf...), true
+}
+
+// GetRef returns the cached value of key itself, without copying it, for
+// hot read paths. The buffer stays valid, even if key is updated, removed
+// or evicted meanwhile, until release is called, which must happen exactly
+// once; the buffer must not be modified. Buffers of values removed while
+// referenced are not accounted in the capacity until released.
+func (c *Cache) GetRef(key string) (buf []byte, release func(), ok bool) {
+	s := c.shard(key)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	e := s.get(key)
+	if e == nil {
+		return nil, nil, false
+	}
+	e.refs++
+	return e.buf, e.release, true
+}
+
+// Contains reports whether key is cached, without promoting it.
+func (c *Cache) Contains(key string) bool {
+	s := c.shard(key)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	_, ok := s.items[key]
+	return ok
+}
+
+// Remove removes key, reporting whether it was present.
+func (c *Cache) Remove(key string) (present bool) {
+	s := c.shard(key)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	e, ok := s.items[key]
+	if ok {
+		s.remove(e)
+	}
+	return ok
+}
+
+// Len returns the number of cached values.
+func (c *Cache) Len() (n int) {
+	for i := range c.shards {
+		s := &c.shards[i]
+		s.lock.Lock()
+		n += len(s.items)
+		s.lock.Unlock()
+	}
+	return n
+}
+
+// Weight returns the bytes accounted to the cached entries.
+func (c *Cache) Weight() (n int) {
+	for i := range c.shards {
+		s := &c.shards[i]
+		s.lock.Lock()
+		n += s.probation.weight + s.protected.weight
+		s.lock.Unlock()
+	}
+	return n
+}
+
+// Purge removes every value. Their buffers stay pooled for reuse.
+func (c *Cache) Purge() {
+	for i := range c.shards {
+		s := &c.shards[i]
+		s.lock.Lock()
+		s.purge()
+		s.lock.Unlock()
+	}
+}
+
+// Stats returns the hit, miss, eviction and promotion counts of the cache.
+func (c *Cache) Stats() (stats slru.Stats) {
+	for i := range c.shards {
+		s := &c.shards[i]
+		s.lock.Lock()
+		st := s.stats
+		s.lock.Unlock()
+		stats.Hits += st.hits
+		stats.Misses += st.misses
+		stats.Evictions += st.evictions
+		stats.Promotions += st.promotions
+		stats.ProtectedEvictions += st.protectedEvictions
+		stats.OneHitWonders += st.oneHitWonders
+	}
+	return stats
+}
@@ -0,0 +1,166 @@
+package bytescache
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClasses(t *testing.T) {
+	require.Equal(t, 0, classOf(0))
+	require.Equal(t, 0, classOf(64))
+	require.Equal(t, 1, classOf(65))
+	require.Equal(t, classes-1, classOf(maxClassSize))
+	require.Equal(t, classes, classOf(maxClassSize+1))
+	require.Equal(t, 128, classSize(100))
+	require.Equal(t, maxClassSize+1, classSize(maxClassSize+1))
+}
+
+func TestCache(t *testing.T) {
+	// probation holds two 64 byte values with one byte keys
+	c := New(1290)
+	require.True(t, c.Set("a", []byte("1")))
+	value := []byte("2")
+	require.True(t, c.Set("a", value))
+	value[0] = 'x'
+	v, ok := c.Get("a")
+	require.True(t, ok)
+	require.Equal(t, "2", string(v))
+	require.Equal(t, 1, c.Len())
+	require.Equal(t, 1+64+entryOverhead, c.Weight())
+
+	buf := []byte("value: ")
+	buf, ok = c.Append(buf, "a")
+	require.True(t, ok)
+	require.Equal(t, "value: 2", string(buf))
+	_, ok = c.Append(nil, "b")
+	require.False(t, ok)
+
+	for _, k := range []string{"b", "c", "d"} {
+		require.True(t, c.Set(k, []byte(k)))
+	}
+	require.False(t, c.Contains("b"))
+	require.True(t, c.Contains("a"))
+	require.True(t, c.Remove("c"))
+	require.False(t, c.Remove("c"))
+	require.False(t, c.Set("big", make([]byte, 1000)))
+
+	stats := c.Stats()
+	require.Equal(t, uint64(2), stats.Hits)
+	require.Equal(t, uint64(1), stats.Misses)
+	require.Equal(t, uint64(1), stats.Evictions)
+	require.Equal(t, uint64(1), stats.OneHitWonders)
+
+	c.Purge()
+	require.Zero(t, c.Len())
+	require.Zero(t, c.Weight())
+}
+
+func TestCacheReusesBuffers(t *testing.T) {
+	c := New(1 << 20)
+	value := make([]byte, 1000)
+	for i := range 2000 {
+		c.Set(strconv.Itoa(i), value)
+	}
+	keys := make([]string, 1000)
+	for i := range keys {
+		keys[i] = strconv.Itoa(2000 + i)
+	}
+	i := 0
+	allocs := testing.AllocsPerRun(1000, func() {
+		c.Set(keys[i%len(keys)], value)
+		i++
+	})
+	require.Zero(t, allocs)
+}
+
+func TestShardedCache(t *testing.T) {
+	c := NewSharded(1<<20, 3)
+	require.Len(t, c.shards, 4)
+	for i := range 100 {
+		require.True(t, c.Set(strconv.Itoa(i), []byte{byte(i)}))
+	}
+	require.Equal(t, 100, c.Len())
+	for i := range 100 {
+		v, ok := c.Get(strconv.Itoa(i))
+		require.True(t, ok)
+		require.Equal(t, []byte{byte(i)}, v)
+	}
+}
+
+func BenchmarkGet(b *testing.B) {
+	c := New(1 << 24)
+	keys := make([]string, 1000)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+		c.Set(keys[i], make([]byte, 512))
+	}
+	buf := make([]byte, 0, 512)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := range b.N {
+		buf, _ = c.Append(buf[:0], keys[i%len(keys)])
+	}
+}
+
+func TestGetRef(t *testing.T) {
+	c := New(1 << 20)
+	_, _, ok := c.GetRef("a")
+	require.False(t, ok)
+
+	c.Set("a", []byte("1"))
+	buf, release, ok := c.GetRef("a")
+	require.True(t, ok)
+	require.Equal(t, "1", string(buf))
+
+	// updates and removals leave the referenced buffer alone
+	c.Set("a", []byte("2"))
+	v, _ := c.Get("a")
+	require.Equal(t, "2", string(v))
+	buf2, release2, ok := c.GetRef("a")
+	require.True(t, ok)
+	require.True(t, c.Remove("a"))
+	c.Set("b", []byte("3"))
+	c.Set("a", []byte("4"))
+	require.Equal(t, "1", string(buf))
+	require.Equal(t, "2", string(buf2))
+	release()
+	release2()
+
+	// released buffers are reused
+	s := &c.shards[0]
+	pooled := s.pool.bytes
+	c.Purge()
+	require.Greater(t, s.pool.bytes, pooled)
+	require.Zero(t, c.Len())
+
+	allocs := testing.AllocsPerRun(100, func() {
+		c.Set("a", []byte("5"))
+		_, release, _ := c.GetRef("a")
+		release()
+	})
+	require.Zero(t, allocs)
+}
+
+func TestGetRefConcurrent(t *testing.T) {
+	c := NewSharded(1<<16, 2)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := range 10000 {
+			c.Set(strconv.Itoa(i%50), []byte(strconv.Itoa(i%50)))
+			if i%7 == 0 {
+				c.Remove(strconv.Itoa(i % 50))
+			}
+		}
+	}()
+	for i := range 10000 {
+		key := strconv.Itoa(i % 50)
+		if buf, release, ok := c.GetRef(key); ok {
+			require.Equal(t, key, string(buf))
+			release()
+		}
+	}
+	<-done
+}
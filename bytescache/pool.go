@@ -0,0 +1,66 @@
+package bytescache
+
+import "math/bits"
+
+// Buffers are pooled by power-of-two size class, from minClassSize up to
+// maxClassSize bytes; larger values get buffers of their exact length,
+// which aren't pooled.
+const (
+	minClassShift = 6
+	minClassSize  = 1 << minClassShift
+	classes       = 21
+	maxClassSize  = minClassSize << (classes - 1)
+)
+
+// classOf returns the size class of buffers of n bytes, or classes if they
+// are larger than maxClassSize.
+func classOf(n int) int {
+	if n <= minClassSize {
+		return 0
+	}
+	if n > maxClassSize {
+		return classes
+	}
+	return bits.Len(uint(n-1)) - minClassShift
+}
+
+// classSize returns the capacity of the buffer holding n bytes.
+func classSize(n int) int {
+	if c := classOf(n); c < classes {
+		return minClassSize << c
+	}
+	return n
+}
+
+// pool keeps the buffers of removed values for reuse, up to limit bytes.
+type pool struct {
+	free  [classes][][]byte
+	bytes int
+	limit int
+}
+
+// get returns an empty buffer with room for n bytes.
+func (p *pool) get(n int) []byte {
+	c := classOf(n)
+	if c == classes {
+		return make([]byte, 0, n)
+	}
+	if m := len(p.free[c]); m > 0 {
+		buf := p.free[c][m-1]
+		p.free[c][m-1] = nil
+		p.free[c] = p.free[c][:m-1]
+		p.bytes -= cap(buf)
+		return buf
+	}
+	return make([]byte, 0, minClassSize<<c)
+}
+
+// put recycles buf, unless it isn't pooled or the pool is full.
+func (p *pool) put(buf []byte) {
+	c := classOf(cap(buf))
+	if c == classes || cap(buf) != minClassSize<<c || p.bytes+cap(buf) > p.limit {
+		return
+	}
+	p.free[c] = append(p.free[c], buf[:0])
+	p.bytes += cap(buf)
+}
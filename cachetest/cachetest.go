@@ -0,0 +1,524 @@
+// Package cachetest provides Fake, a slru.Cache for the tests of code
+// depending on the interface: its lookups can be scripted to hit or miss,
+// it records every call, and its clock only moves when told to.
+//
+//	cache := cachetest.New[string, User]()
+//	cache.Miss("alice") // as if evicted
+//	svc := NewService(cache)
+//	svc.Lookup("alice")
+//	require.Len(t, cache.CallsTo("GetOrLoad"), 1)
+package cachetest
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/hey-kong/slru"
+)
+
+// Epoch is the time a Fake starts at.
+var Epoch = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Call is a recorded call of a method of a Fake.
+type Call[K comparable, V any] struct {
+	// Method is the name of the method called, such as "Get".
+	Method string
+	// Key, Value and TTL are the arguments of the call, zero for methods
+	// without them. Value is the new value of CompareAndSwap.
+	Key   K
+	Value V
+	TTL   time.Duration
+	// Time is the time of the clock of the Fake at the call.
+	Time time.Time
+}
+
+// item is a stored value.
+type item[V any] struct {
+	value    V
+	expireAt time.Time
+	gen      uint64
+}
+
+// Fake is a scriptable slru.Cache. Unless scripted otherwise, it stores
+// every value, never evicts and expires entries on its own clock. It is
+// safe for concurrent use.
+type Fake[K comparable, V any] struct {
+	lock  sync.Mutex
+	now   time.Time
+	items map[K]*item[V]
+	// order holds the keys from the oldest write to the newest.
+	order       []K
+	size        int
+	gen, minGen uint64
+	stats       slru.Stats
+	calls       []Call[K, V]
+	misses      map[K]bool
+	missNext    int
+	lookup      func(key K, value V, ok bool) (V, bool)
+	keyLocks    map[K]*sync.Mutex
+}
+
+var _ slru.Cache[int, int] = (*Fake[int, int])(nil)
+
+// New returns an empty, unbounded Fake whose clock is at Epoch.
+func New[K comparable, V any]() *Fake[K, V] {
+	return &Fake[K, V]{
+		now:      Epoch,
+		items:    make(map[K]*item[V]),
+		misses:   make(map[K]bool),
+		keyLocks: make(map[K]*sync.Mutex),
+	}
+}
+
+// Now returns the time of the clock of f.
+func (f *Fake[K, V]) Now() time.Time {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return f.now
+}
+
+// Advance moves the clock of f forward by d, expiring the entries whose
+// TTL runs out.
+func (f *Fake[K, V]) Advance(d time.Duration) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.now = f.now.Add(d)
+}
+
+// Miss makes the lookups of keys miss, whatever is stored, until they are
+// written again.
+func (f *Fake[K, V]) Miss(keys ...K) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	for _, key := range keys {
+		f.misses[key] = true
+	}
+}
+
+// MissNext makes the next n lookups miss, whatever is stored.
+func (f *Fake[K, V]) MissNext(n int) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.missNext = n
+}
+
+// OnLookup scripts lookups with fn, called with each looked up key and
+// what f would return for it, and returning what f returns instead. fn
+// runs after Miss and MissNext and must not call f. A nil fn removes the
+// script.
+func (f *Fake[K, V]) OnLookup(fn func(key K, value V, ok bool) (V, bool)) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.lookup = fn
+}
+
+// Evict removes keys as if the cache had evicted them, counting them as
+// evictions, and reports how many were present.
+func (f *Fake[K, V]) Evict(keys ...K) (evicted int) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	for _, key := range keys {
+		if f.delete(key) {
+			f.stats.Evictions++
+			evicted++
+		}
+	}
+	return evicted
+}
+
+// Calls returns the calls made so far, in order.
+func (f *Fake[K, V]) Calls() []Call[K, V] {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return slices.Clone(f.calls)
+}
+
+// CallsTo returns the calls made so far to method, in order.
+func (f *Fake[K, V]) CallsTo(method string) []Call[K, V] {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	var calls []Call[K, V]
+	for _, call := range f.calls {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// Reset forgets the calls made so far.
+func (f *Fake[K, V]) Reset() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.calls = nil
+}
+
+// record logs a call.
+func (f *Fake[K, V]) record(call Call[K, V]) {
+	call.Time = f.now
+	f.calls = append(f.calls, call)
+}
+
+// live returns the item of key if it is stored and not dead.
+func (f *Fake[K, V]) live(key K) (*item[V], bool) {
+	it, ok := f.items[key]
+	if !ok || it.gen < f.minGen || (!it.expireAt.IsZero() && !f.now.Before(it.expireAt)) {
+		return nil, false
+	}
+	return it, true
+}
+
+// get looks key up as scripted, counting the outcome.
+func (f *Fake[K, V]) get(key K) (value V, ok bool) {
+	if it, live := f.live(key); live {
+		value, ok = it.value, true
+	}
+	if f.misses[key] {
+		value, ok = *new(V), false
+	}
+	if f.missNext > 0 {
+		f.missNext--
+		value, ok = *new(V), false
+	}
+	if f.lookup != nil {
+		value, ok = f.lookup(key, value, ok)
+	}
+	if ok {
+		f.stats.Hits++
+	} else {
+		f.stats.Misses++
+	}
+	return value, ok
+}
+
+// set stores key, expiring it after ttl if positive.
+func (f *Fake[K, V]) set(key K, value V, ttl time.Duration) {
+	it := &item[V]{value: value, gen: f.gen}
+	if ttl > 0 {
+		it.expireAt = f.now.Add(ttl)
+	}
+	f.delete(key)
+	f.items[key] = it
+	f.order = append(f.order, key)
+	delete(f.misses, key)
+	f.trim()
+}
+
+// delete removes key, reporting whether it was stored.
+func (f *Fake[K, V]) delete(key K) bool {
+	if _, ok := f.items[key]; !ok {
+		return false
+	}
+	delete(f.items, key)
+	f.order = slices.DeleteFunc(f.order, func(k K) bool { return k == key })
+	return true
+}
+
+// trim evicts the oldest writes beyond the size set by Resize, if any.
+func (f *Fake[K, V]) trim() (evicted int) {
+	for f.size > 0 && len(f.order) > f.size {
+		f.delete(f.order[0])
+		f.stats.Evictions++
+		evicted++
+	}
+	return evicted
+}
+
+func (f *Fake[K, V]) Set(key K, value V) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.record(Call[K, V]{Method: "Set", Key: key, Value: value})
+	f.set(key, value, 0)
+}
+
+func (f *Fake[K, V]) SetAsync(key K, value V) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.record(Call[K, V]{Method: "SetAsync", Key: key, Value: value})
+	f.set(key, value, 0)
+}
+
+func (f *Fake[K, V]) Flush(ctx context.Context) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.record(Call[K, V]{Method: "Flush"})
+	return nil
+}
+
+func (f *Fake[K, V]) Add(key K, value V) (inserted bool) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.record(Call[K, V]{Method: "Add", Key: key, Value: value})
+	_, exists := f.live(key)
+	f.set(key, value, 0)
+	return !exists
+}
+
+func (f *Fake[K, V]) Replace(key K, value V) (replaced bool) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.record(Call[K, V]{Method: "Replace", Key: key, Value: value})
+	if _, ok := f.live(key); !ok {
+		return false
+	}
+	f.set(key, value, 0)
+	return true
+}
+
+func (f *Fake[K, V]) Swap(key K, value V) (old V, existed bool) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.record(Call[K, V]{Method: "Swap", Key: key, Value: value})
+	if it, ok := f.live(key); ok {
+		old, existed = it.value, true
+	}
+	f.set(key, value, 0)
+	return old, existed
+}
+
+func (f *Fake[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.record(Call[K, V]{Method: "SetWithTTL", Key: key, Value: value, TTL: ttl})
+	f.set(key, value, ttl)
+}
+
+func (f *Fake[K, V]) Get(key K) (value V, ok bool) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.record(Call[K, V]{Method: "Get", Key: key})
+	return f.get(key)
+}
+
+// TryGet is Get, as a Fake never holds its lock for long.
+func (f *Fake[K, V]) TryGet(key K) (value V, ok, locked bool) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.record(Call[K, V]{Method: "TryGet", Key: key})
+	value, ok = f.get(key)
+	return value, ok, true
+}
+
+// TrySet is Set, as a Fake never holds its lock for long.
+func (f *Fake[K, V]) TrySet(key K, value V) (locked bool) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.record(Call[K, V]{Method: "TrySet", Key: key, Value: value})
+	f.set(key, value, 0)
+	return true
+}
+
+// GetOrLoad looks key up as Get, then loads and stores it on a miss.
+// Concurrent misses each load.
+func (f *Fake[K, V]) GetOrLoad(ctx context.Context, key K, load func(ctx context.Context, key K) (V, error)) (V, error) {
+	f.lock.Lock()
+	f.record(Call[K, V]{Method: "GetOrLoad", Key: key})
+	value, ok := f.get(key)
+	f.lock.Unlock()
+	if ok {
+		return value, nil
+	}
+
+	value, err := load(ctx, key)
+	if err != nil {
+		return value, err
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.set(key, value, 0)
+	return value, nil
+}
+
+// CompareAndSwap compares values with ==.
+func (f *Fake[K, V]) CompareAndSwap(key K, old, new V) (swapped bool) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.record(Call[K, V]{Method: "CompareAndSwap", Key: key, Value: new})
+	it, ok := f.live(key)
+	if !ok || any(it.value) != any(old) {
+		return false
+	}
+	f.set(key, new, 0)
+	return true
+}
+
+func (f *Fake[K, V]) Update(key K, fn func(old V, exists bool) (new V, store bool)) (value V, ok bool) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.record(Call[K, V]{Method: "Update", Key: key})
+	it, exists := f.live(key)
+	if exists {
+		value = it.value
+	}
+	new, store := fn(value, exists)
+	if !store {
+		return value, exists
+	}
+	f.set(key, new, 0)
+	return new, true
+}
+
+func (f *Fake[K, V]) Contains(key K) (ok bool) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.record(Call[K, V]{Method: "Contains", Key: key})
+	_, ok = f.live(key)
+	return ok
+}
+
+func (f *Fake[K, V]) Peek(key K) (value V, ok bool) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.record(Call[K, V]{Method: "Peek", Key: key})
+	if it, ok := f.live(key); ok {
+		return it.value, true
+	}
+	return value, false
+}
+
+func (f *Fake[K, V]) TTL(key K) (ttl time.Duration, ok bool) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.record(Call[K, V]{Method: "TTL", Key: key})
+	it, ok := f.live(key)
+	if !ok {
+		return 0, false
+	}
+	if !it.expireAt.IsZero() {
+		ttl = it.expireAt.Sub(f.now)
+	}
+	return ttl, true
+}
+
+func (f *Fake[K, V]) Remove(key K) (present bool) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.record(Call[K, V]{Method: "Remove", Key: key})
+	return f.delete(key)
+}
+
+func (f *Fake[K, V]) LockKey(key K) (unlock func()) {
+	f.lock.Lock()
+	f.record(Call[K, V]{Method: "LockKey", Key: key})
+	m, ok := f.keyLocks[key]
+	if !ok {
+		m = new(sync.Mutex)
+		f.keyLocks[key] = m
+	}
+	f.lock.Unlock()
+
+	m.Lock()
+	return m.Unlock
+}
+
+// Keys returns the keys from the oldest write to the newest.
+func (f *Fake[K, V]) Keys() []K {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.record(Call[K, V]{Method: "Keys"})
+	return slices.Clone(f.order)
+}
+
+func (f *Fake[K, V]) NewGeneration() (gen uint64) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.record(Call[K, V]{Method: "NewGeneration"})
+	f.gen++
+	return f.gen
+}
+
+func (f *Fake[K, V]) InvalidateBefore(gen uint64) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.record(Call[K, V]{Method: "InvalidateBefore"})
+	f.minGen = max(f.minGen, gen)
+}
+
+func (f *Fake[K, V]) Len() int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.record(Call[K, V]{Method: "Len"})
+	return len(f.items)
+}
+
+// Stats returns the hits, misses and evictions of f.
+func (f *Fake[K, V]) Stats() slru.Stats {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.record(Call[K, V]{Method: "Stats"})
+	return f.stats
+}
+
+func (f *Fake[K, V]) Purge() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.record(Call[K, V]{Method: "Purge"})
+	clear(f.items)
+	f.order = nil
+}
+
+func (f *Fake[K, V]) PurgeFunc(fn func(key K, value V) bool) (removed int) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.record(Call[K, V]{Method: "PurgeFunc"})
+	for _, key := range slices.Clone(f.order) {
+		if fn(key, f.items[key].value) {
+			f.delete(key)
+			removed++
+		}
+	}
+	return removed
+}
+
+func (f *Fake[K, V]) Compact() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.record(Call[K, V]{Method: "Compact"})
+}
+
+// Resize bounds f to size entries, evicting the oldest writes beyond it. A
+// size of zero or less makes it unbounded again.
+func (f *Fake[K, V]) Resize(size int) (evicted int) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.record(Call[K, V]{Method: "Resize"})
+	f.size = max(size, 0)
+	return f.trim()
+}
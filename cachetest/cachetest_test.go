@@ -0,0 +1,88 @@
+package cachetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFake(t *testing.T) {
+	f := New[string, int]()
+	f.Set("a", 1)
+	f.SetWithTTL("b", 2, time.Minute)
+	v, ok := f.Get("a")
+	require.True(t, ok)
+	require.Equal(t, 1, v)
+	require.True(t, f.Add("c", 3))
+	require.False(t, f.Add("c", 4))
+	require.Equal(t, []string{"a", "b", "c"}, f.Keys())
+
+	ttl, ok := f.TTL("b")
+	require.True(t, ok)
+	require.Equal(t, time.Minute, ttl)
+	f.Advance(time.Minute)
+	require.False(t, f.Contains("b"))
+	require.Equal(t, Epoch.Add(time.Minute), f.Now())
+
+	require.Equal(t, 1, f.Resize(2))
+	require.Equal(t, []string{"b", "c"}, f.Keys())
+	require.Equal(t, uint64(1), f.Stats().Evictions)
+}
+
+func TestFakeScriptedLookups(t *testing.T) {
+	f := New[string, int]()
+	f.Set("a", 1)
+	f.Set("b", 2)
+
+	f.Miss("a")
+	_, ok := f.Get("a")
+	require.False(t, ok)
+	f.Set("a", 1)
+	_, ok = f.Get("a")
+	require.True(t, ok)
+
+	f.MissNext(1)
+	_, ok = f.Get("b")
+	require.False(t, ok)
+	_, ok = f.Get("b")
+	require.True(t, ok)
+
+	f.OnLookup(func(key string, value int, ok bool) (int, bool) { return 42, true })
+	v, ok := f.Get("missing")
+	require.True(t, ok)
+	require.Equal(t, 42, v)
+	f.OnLookup(nil)
+
+	require.Equal(t, 1, f.Evict("a", "missing"))
+	stats := f.Stats()
+	require.Equal(t, uint64(3), stats.Hits)
+	require.Equal(t, uint64(2), stats.Misses)
+	require.Equal(t, uint64(1), stats.Evictions)
+}
+
+func TestFakeCalls(t *testing.T) {
+	f := New[string, int]()
+	load := func(ctx context.Context, key string) (int, error) { return len(key), nil }
+	v, err := f.GetOrLoad(context.Background(), "abc", load)
+	require.NoError(t, err)
+	require.Equal(t, 3, v)
+	f.Advance(time.Second)
+	v, err = f.GetOrLoad(context.Background(), "abc", func(context.Context, string) (int, error) {
+		return 0, errors.New("not called")
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, v)
+	f.Remove("abc")
+
+	calls := f.Calls()
+	require.Len(t, calls, 3)
+	require.Equal(t, Call[string, int]{Method: "GetOrLoad", Key: "abc", Time: Epoch.Add(time.Second)}, calls[1])
+	require.Len(t, f.CallsTo("GetOrLoad"), 2)
+	require.Equal(t, "Remove", calls[2].Method)
+
+	f.Reset()
+	require.Empty(t, f.Calls())
+}
@@ -0,0 +1,100 @@
+package slru
+
+import "sync"
+
+// Change is a change of the entry of a key, received from Changes.
+type Change[V any] struct {
+	// Op is MutationSet, or how the entry left the cache: MutationRemove,
+	// MutationEvict, MutationExpire or MutationPurge, the last also when
+	// SwapContents replaced the entries.
+	Op string
+	// Value is the value set by MutationSet.
+	Value V
+}
+
+// ChangeNotifier is a cache notifying the changes of entries, such as an
+// SLRU or a Sharded.
+type ChangeNotifier[K comparable, V any] interface {
+	Changes(key K) (changes <-chan Change[V], cancel func())
+}
+
+// Changes returns a channel receiving the changes of the entry of key, for
+// components rendering from it rather than polling: first its current value
+// if cached, then each set, removal, eviction and expiration, the latter
+// once the expired entry is discarded. The channel holds the latest change
+// only: one not yet received is replaced by the next, so a slow receiver
+// skips to the current state rather than holding up the cache. cancel
+// stops the notifications and closes the channel.
+func (s *SLRU[K, V]) Changes(key K) (changes <-chan Change[V], cancel func()) {
+	key = s.normalize(key)
+	ch := make(chan Change[V], 1)
+	s.acquire()
+	defer s.unlock()
+
+	if s.changeWatchers == nil {
+		s.changeWatchers = make(map[K]map[chan Change[V]]struct{})
+	}
+	watchers, ok := s.changeWatchers[key]
+	if !ok {
+		watchers = make(map[chan Change[V]]struct{})
+		s.changeWatchers[key] = watchers
+	}
+	watchers[ch] = struct{}{}
+	if ent, ok := s.live(key); ok {
+		ch <- Change[V]{Op: MutationSet, Value: s.clone(ent.value)}
+	}
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			s.acquire()
+			defer s.unlock()
+
+			delete(watchers, ch)
+			if len(watchers) == 0 {
+				delete(s.changeWatchers, key)
+			}
+			close(ch)
+		})
+	}
+}
+
+// notifyChange sends a mutation of ent to the watchers of its key. The
+// caller holds the lock exclusively, so the sends don't race.
+func (s *SLRU[K, V]) notifyChange(op string, ent *entry[K, V]) {
+	watchers := s.changeWatchers[ent.key]
+	if len(watchers) == 0 {
+		return
+	}
+	c := Change[V]{Op: op}
+	if op == MutationSet {
+		c.Value = s.clone(ent.value)
+	}
+	for ch := range watchers {
+		sendLatest(ch, c)
+	}
+}
+
+// notifyPurge sends a purge to every watcher.
+func (s *SLRU[K, V]) notifyPurge() {
+	for _, watchers := range s.changeWatchers {
+		for ch := range watchers {
+			sendLatest(ch, Change[V]{Op: MutationPurge})
+		}
+	}
+}
+
+// sendLatest sends c on ch, replacing the change it holds if any. Callers
+// serialize the sends, so the buffer is free after the drain.
+func sendLatest[V any](ch chan Change[V], c Change[V]) {
+	select {
+	case <-ch:
+	default:
+	}
+	ch <- c
+}
+
+// Changes is SLRU.Changes on the shard of key.
+func (c *Sharded[K, V]) Changes(key K) (changes <-chan Change[V], cancel func()) {
+	return c.shard(key).Changes(key)
+}
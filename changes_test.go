@@ -0,0 +1,55 @@
+package slru
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChanges(t *testing.T) {
+	now := time.Unix(0, 0)
+	cache := newSLRU(10, WithClock[string, int](func() time.Time { return now }))
+	cache.Set("a", 1)
+
+	changes, cancel := cache.Changes("a")
+	require.Equal(t, Change[int]{Op: MutationSet, Value: 1}, <-changes)
+	cache.Set("a", 2)
+	require.Equal(t, Change[int]{Op: MutationSet, Value: 2}, <-changes)
+
+	// a slow receiver gets the latest change
+	cache.Set("a", 3)
+	cache.Replace("a", 4)
+	require.Equal(t, Change[int]{Op: MutationSet, Value: 4}, <-changes)
+	cache.Set("b", 1)
+	require.Empty(t, changes)
+
+	cache.Remove("a")
+	require.Equal(t, Change[int]{Op: MutationRemove}, <-changes)
+	cache.SetWithTTL("a", 5, time.Second)
+	<-changes
+	now = now.Add(time.Second)
+	cache.Get("a")
+	require.Equal(t, Change[int]{Op: MutationExpire}, <-changes)
+	cache.Purge()
+	require.Equal(t, Change[int]{Op: MutationPurge}, <-changes)
+
+	cancel()
+	cancel()
+	_, ok := <-changes
+	require.False(t, ok)
+	cache.Set("a", 6)
+	require.Empty(t, cache.changeWatchers)
+}
+
+func TestChangesSharded(t *testing.T) {
+	cache := NewSharded[int, int](100, 4, nil)
+	var notifier ChangeNotifier[int, int] = cache
+	changes, cancel := notifier.Changes(1)
+	defer cancel()
+	for i := range 200 {
+		cache.Set(i, i)
+	}
+	// the entry was evicted by the later ones
+	require.Equal(t, Change[int]{Op: MutationEvict}, <-changes)
+}
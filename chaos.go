@@ -0,0 +1,96 @@
+package slru
+
+import (
+	"time"
+
+	"github.com/hey-kong/slru/list"
+)
+
+// Chaos configures the disturbances of WithChaos. Zero fields disable
+// theirs.
+type Chaos struct {
+	// Evictions is the probability that an operation taking the cache lock
+	// exclusively, such as a write or a promoting Get, also evicts a random
+	// entry, as if it were needed to make room.
+	Evictions float64
+
+	// MaxDelay delays each operation taking the cache lock by a random
+	// duration of up to MaxDelay before it does.
+	MaxDelay time.Duration
+
+	// ShuffleCallbacks passes the entries evicted by one operation, and
+	// those cleared by Purge, to the eviction callback in random order
+	// rather than from the next victim on.
+	ShuffleCallbacks bool
+}
+
+// WithChaos disturbs the cache as configured by chaos, for tests checking
+// that an application tolerates the volatility a cache may show rather
+// than depending on the behavior of a calm one: entries evicted early,
+// slow operations, callbacks in an unusual order. The cache keeps its
+// documented guarantees. Combine it with WithSeed to reproduce a failing
+// run. It is not meant for production.
+func WithChaos[K comparable, V any](chaos Chaos) Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		s.chaos = &chaos
+	}
+}
+
+// delay sleeps a random share of the chaos delay.
+func (s *SLRU[K, V]) delay() {
+	if s.chaos.MaxDelay > 0 {
+		time.Sleep(time.Duration(s.randFloat64() * float64(s.chaos.MaxDelay)))
+	}
+}
+
+// unsettle evicts a random entry with the chaos probability, then shuffles
+// the evictions held back for the callback if configured. The caller holds
+// the lock exclusively.
+func (s *SLRU[K, V]) unsettle() {
+	if n := len(s.items); n > 0 && s.chaos.Evictions > 0 && s.randFloat64() < s.chaos.Evictions {
+		i := int(s.randUint64() % uint64(n))
+		for _, l := range s.segments() {
+			if i >= l.Len() {
+				i -= l.Len()
+				continue
+			}
+			e := l.Back()
+			for ; i > 0; i-- {
+				e = e.Prev()
+			}
+			s.evictElement(l, e)
+			break
+		}
+	}
+	if s.chaos.ShuffleCallbacks {
+		s.shuffle(len(s.evicted), func(i, j int) {
+			s.evicted[i], s.evicted[j] = s.evicted[j], s.evicted[i]
+		})
+	}
+}
+
+// tearDownShuffled is tearDown passing the entries in random order.
+func (s *SLRU[K, V]) tearDownShuffled(segments ...*list.List) {
+	var evicted []eviction[K, V]
+	for _, l := range segments {
+		l.EachReverse(func(e *list.Element) bool {
+			ent := e.Value.(*entry[K, V])
+			evicted = append(evicted, eviction[K, V]{ent.key, ent.value})
+			return true
+		})
+	}
+	s.shuffle(len(evicted), func(i, j int) {
+		evicted[i], evicted[j] = evicted[j], evicted[i]
+	})
+	for _, ev := range evicted {
+		s.onEvict(ev.key, ev.value)
+	}
+}
+
+// shuffle permutes n elements with swap, drawing from the randomness of
+// the cache.
+func (s *SLRU[K, V]) shuffle(n int, swap func(i, j int)) {
+	for i := n - 1; i > 0; i-- {
+		swap(i, int(s.randUint64()%uint64(i+1)))
+	}
+}
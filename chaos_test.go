@@ -0,0 +1,56 @@
+package slru
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChaosEvictions(t *testing.T) {
+	cache := newSLRU[int, int](1000, WithSeed[int, int](1), WithChaos[int, int](Chaos{Evictions: 0.5}))
+	for i := range 100 {
+		cache.Set(i, i)
+	}
+	require.InDelta(t, 50, cache.Len(), 15)
+	require.Equal(t, uint64(100-cache.Len()), cache.Stats().Evictions)
+	require.NoError(t, cache.Verify())
+
+	// reads under the shared lock are left alone
+	n := cache.Len()
+	for _, key := range cache.Keys() {
+		cache.Peek(key)
+	}
+	require.Equal(t, n, cache.Len())
+}
+
+func TestChaosDelay(t *testing.T) {
+	cache := newSLRU[int, int](100, WithChaos[int, int](Chaos{MaxDelay: 5 * time.Millisecond}))
+	start := time.Now()
+	for i := range 20 {
+		cache.Set(i, i)
+	}
+	require.Greater(t, time.Since(start), 5*time.Millisecond)
+}
+
+func TestChaosShuffleCallbacks(t *testing.T) {
+	run := func(chaos Chaos) (evicted []int) {
+		cache := newSLRU[int, int](100,
+			WithSeed[int, int](1),
+			WithChaos[int, int](chaos),
+			WithEvictCallback(func(key, value int) { evicted = append(evicted, key) }),
+		)
+		for i := range 20 {
+			cache.Set(i, i)
+		}
+		cache.Resize(5)
+		return evicted
+	}
+	calm := run(Chaos{})
+	shuffled := run(Chaos{ShuffleCallbacks: true})
+	require.Len(t, calm, 19)
+	require.ElementsMatch(t, calm, shuffled)
+	require.NotEqual(t, calm, shuffled)
+	require.True(t, slices.IsSorted(calm))
+}
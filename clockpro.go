@@ -0,0 +1,224 @@
+package slru
+
+// NewClockPro creates a cache of up to size entries replaced by CLOCK-Pro,
+// the clock approximation of LIRS by Jiang, Chen and Zhang. Entries sit on
+// a single clock as hot, cold or, once evicted, as non-resident test keys,
+// and three hands sweeping it pick victims. A hit only sets a reference
+// bit instead of reordering a list, so it is cheap under the lock.
+func NewClockPro[K comparable, V any](size int, opts ...PolicyOption[K, V]) *Policy[K, V] {
+	return newPolicy[K, V](newClockPro[K](size), opts...)
+}
+
+// Page kinds of CLOCK-Pro.
+const (
+	clockHot = iota
+	clockCold
+	clockTest
+)
+
+// clockPage is a key on the clock.
+type clockPage[K comparable] struct {
+	key        K
+	kind       int
+	ref        bool
+	prev, next *clockPage[K]
+}
+
+// clockPro is the CLOCK-Pro replacer. The hot hand turns unreferenced hot
+// pages cold, the cold hand evicts unreferenced cold pages, keeping them
+// as test pages, or makes referenced ones hot, and the test hand forgets
+// test pages. coldTarget is the adaptive number of cold pages, which grows
+// when test pages are reused and shrinks when they expire unused.
+type clockPro[K comparable] struct {
+	size       int
+	coldTarget int
+	hot, cold  int
+	test       int
+	pages      map[K]*clockPage[K]
+
+	handHot, handCold, handTest *clockPage[K]
+}
+
+func newClockPro[K comparable](size int) *clockPro[K] {
+	c := &clockPro[K]{size: max(size, 0)}
+	c.reset()
+	return c
+}
+
+func (c *clockPro[K]) reset() {
+	c.coldTarget = c.size
+	c.hot, c.cold, c.test = 0, 0, 0
+	c.pages = make(map[K]*clockPage[K])
+	c.handHot, c.handCold, c.handTest = nil, nil, nil
+}
+
+func (c *clockPro[K]) add(key K, evict func(key K)) {
+	if c.size < 1 {
+		evict(key)
+		return
+	}
+	if p, ok := c.pages[key]; ok {
+		// reused while tested: it has a short reuse distance
+		c.coldTarget = min(c.coldTarget+1, c.size)
+		c.unlink(p)
+		c.test--
+		c.insert(&clockPage[K]{key: key, kind: clockHot}, evict)
+		c.hot++
+		return
+	}
+	c.insert(&clockPage[K]{key: key, kind: clockCold}, evict)
+	c.cold++
+}
+
+// insert makes room for p and links it behind the hot hand.
+func (c *clockPro[K]) insert(p *clockPage[K], evict func(key K)) {
+	for c.hot+c.cold >= c.size {
+		c.runCold(evict)
+	}
+	c.pages[p.key] = p
+	if c.handHot == nil {
+		p.prev, p.next = p, p
+		c.handHot, c.handCold, c.handTest = p, p, p
+		return
+	}
+	p.next = c.handHot
+	p.prev = c.handHot.prev
+	p.prev.next = p
+	c.handHot.prev = p
+	if c.handCold == c.handHot {
+		c.handCold = c.handCold.prev
+	}
+}
+
+// unlink removes p from the clock, moving the hands on it backwards.
+func (c *clockPro[K]) unlink(p *clockPage[K]) {
+	delete(c.pages, p.key)
+	if p.next == p {
+		c.handHot, c.handCold, c.handTest = nil, nil, nil
+		return
+	}
+	if p == c.handHot {
+		c.handHot = p.prev
+	}
+	if p == c.handCold {
+		c.handCold = p.prev
+	}
+	if p == c.handTest {
+		c.handTest = p.prev
+	}
+	p.prev.next = p.next
+	p.next.prev = p.prev
+	p.prev, p.next = nil, nil
+}
+
+func (c *clockPro[K]) runCold(evict func(key K)) {
+	p := c.handCold
+	if p.kind == clockCold {
+		if p.ref {
+			p.kind = clockHot
+			p.ref = false
+			c.cold--
+			c.hot++
+		} else {
+			p.kind = clockTest
+			c.cold--
+			c.test++
+			evict(p.key)
+			for c.test > c.size {
+				c.runTest(evict)
+			}
+		}
+	}
+	if c.handCold != nil {
+		c.handCold = c.handCold.next
+	}
+	for c.hot > c.size-c.coldTarget {
+		c.runHot(evict)
+	}
+}
+
+func (c *clockPro[K]) runHot(evict func(key K)) {
+	if c.handHot == c.handTest {
+		c.runTest(evict)
+	}
+	if p := c.handHot; p.kind == clockHot {
+		if p.ref {
+			p.ref = false
+		} else {
+			p.kind = clockCold
+			c.hot--
+			c.cold++
+		}
+	}
+	c.handHot = c.handHot.next
+}
+
+func (c *clockPro[K]) runTest(evict func(key K)) {
+	if c.handTest == c.handCold {
+		c.runCold(evict)
+	}
+	if p := c.handTest; p.kind == clockTest {
+		c.unlink(p)
+		c.test--
+		c.coldTarget = max(c.coldTarget-1, 1)
+	}
+	if c.handTest != nil {
+		c.handTest = c.handTest.next
+	}
+}
+
+func (c *clockPro[K]) hit(key K) {
+	if p, ok := c.pages[key]; ok && p.kind != clockTest {
+		p.ref = true
+	}
+}
+
+func (c *clockPro[K]) remove(key K) {
+	p, ok := c.pages[key]
+	if !ok || p.kind == clockTest {
+		return
+	}
+	if p.kind == clockHot {
+		c.hot--
+	} else {
+		c.cold--
+	}
+	c.unlink(p)
+}
+
+func (c *clockPro[K]) resize(size int, evict func(key K)) {
+	c.size = max(size, 0)
+	if c.size < 1 {
+		for _, p := range c.pages {
+			if p.kind != clockTest {
+				evict(p.key)
+			}
+		}
+		c.reset()
+		return
+	}
+	c.coldTarget = min(max(c.coldTarget, 1), c.size)
+	for c.hot+c.cold > c.size {
+		c.runCold(evict)
+	}
+	for c.test > c.size {
+		c.runTest(evict)
+	}
+}
+
+// keys returns the resident keys in clock order from the cold hand.
+func (c *clockPro[K]) keys() []K {
+	keys := make([]K, 0, c.hot+c.cold)
+	if c.handCold == nil {
+		return keys
+	}
+	p := c.handCold
+	for {
+		if p.kind != clockTest {
+			keys = append(keys, p.key)
+		}
+		if p = p.next; p == c.handCold {
+			return keys
+		}
+	}
+}
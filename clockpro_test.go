@@ -0,0 +1,60 @@
+package slru
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClockPro(t *testing.T) {
+	testPolicy(t, NewClockPro[int, int])
+}
+
+func TestClockProKeepsLoopsResident(t *testing.T) {
+	clock, lru := NewClockPro[int, int](100), newSLRU[int, int](100)
+	for round := 0; round < 10; round++ {
+		for i := 0; i < 110; i++ {
+			for _, cache := range []Cache[int, int]{clock, lru} {
+				if _, ok := cache.Get(i); !ok {
+					cache.Set(i, i)
+				}
+			}
+		}
+	}
+	clockStats, lruStats := clock.Stats(), lru.Stats()
+	require.Greater(t, clockStats.HitRatio(), lruStats.HitRatio())
+}
+
+func TestClockProInvariants(t *testing.T) {
+	c := newClockPro[int](20)
+	resident := make(map[int]bool)
+	evict := func(key int) {
+		require.True(t, resident[key])
+		delete(resident, key)
+	}
+	r := rand.New(rand.NewPCG(5, 6))
+	for i := 0; i < 20000; i++ {
+		key := int(r.Int64N(60))
+		switch {
+		case i%97 == 0 && resident[key]:
+			c.remove(key)
+			delete(resident, key)
+		case resident[key]:
+			c.hit(key)
+		default:
+			c.add(key, evict)
+			resident[key] = true
+		}
+		if i == 10000 {
+			c.resize(8, evict)
+		}
+
+		require.Equal(t, len(resident), c.hot+c.cold)
+		require.LessOrEqual(t, len(resident), c.size)
+		require.LessOrEqual(t, c.test, c.size)
+		require.Len(t, c.pages, c.hot+c.cold+c.test)
+		require.GreaterOrEqual(t, c.coldTarget, 1)
+		require.ElementsMatch(t, keysOf(resident), c.keys())
+	}
+}
@@ -0,0 +1,62 @@
+package slru
+
+import (
+	"errors"
+	"io"
+)
+
+var (
+	_ io.Closer = (*SLRU[int, int])(nil)
+	_ io.Closer = (*Sharded[int, int])(nil)
+)
+
+// WithOnClose calls fn once Close has stopped the background work of the
+// cache, e.g. to persist a final snapshot of its entries. Close returns the
+// error fn returns. Sharded caches call it for each shard.
+func WithOnClose[K comparable, V any](fn func(cache *SLRU[K, V]) error) Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		s.onClose = fn
+	}
+}
+
+// Close shuts the cache down cleanly: it unsubscribes from the invalidation
+// bus and hint exchange, stops the janitor and the other background
+// workers, applies the writes buffered by SetAsync, waits for the eviction
+// callbacks of earlier purges and finally calls the function of
+// WithOnClose. The cache stays usable, without background work: SetAsync
+// then writes directly. Closing it again does nothing.
+func (s *SLRU[K, V]) Close() error {
+	s.closeLock.Lock()
+	if s.closed {
+		s.closeLock.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.closeLock.Unlock()
+
+	if s.unsubscribe != nil {
+		s.unsubscribe()
+	}
+	if s.unsubscribeHints != nil {
+		s.unsubscribeHints()
+	}
+	close(s.done)
+	s.workers.Wait()
+	s.teardown.Wait()
+	if s.coarse != nil {
+		s.coarse.release()
+	}
+	if s.onClose != nil {
+		return s.onClose(s)
+	}
+	return nil
+}
+
+// Close closes every shard, returning their errors joined.
+func (c *Sharded[K, V]) Close() error {
+	var errs []error
+	for _, s := range c.shards {
+		errs = append(errs, s.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,47 @@
+package slru
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClose(t *testing.T) {
+	before := runtime.NumGoroutine()
+	cache := newSLRU[int, int](100,
+		WithJanitor[int, int](time.Millisecond),
+		WithWriteBuffer[int, int](64),
+		WithCoarseClock[int, int](time.Millisecond))
+	for i := range 20 {
+		cache.SetAsync(i, i)
+	}
+	require.NoError(t, cache.Close())
+	require.Equal(t, 20, cache.Len())
+	for i := 0; runtime.NumGoroutine() > before && i < 100; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	require.LessOrEqual(t, runtime.NumGoroutine(), before)
+
+	cache.SetAsync(20, 20)
+	require.True(t, cache.Contains(20))
+	require.NoError(t, cache.Flush(context.Background()))
+	require.NoError(t, cache.Close())
+}
+
+func TestOnClose(t *testing.T) {
+	errSave := errors.New("save failed")
+	var saved []int
+	cache := NewSharded[int, int](100, 2, nil, WithOnClose(func(s *SLRU[int, int]) error {
+		saved = append(saved, s.Keys()...)
+		return errSave
+	}))
+	cache.Set(1, 1)
+	cache.Set(2, 2)
+	require.ErrorIs(t, cache.Close(), errSave)
+	require.ElementsMatch(t, []int{1, 2}, saved)
+	require.NoError(t, cache.Close())
+}
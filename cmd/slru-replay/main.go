@@ -0,0 +1,100 @@
+// Command slru-replay replays cache traces against slru policies and
+// reports the hit ratio, evictions and throughput of each, to back ratio
+// and policy choices with data:
+//
+//	slru-replay -format arc -size 1000 -policy slru,lru,arc -optimal P1.lis
+//
+// Traces are read in one of three formats:
+//
+//   - arc: the block traces of the ARC paper, lines of a first block, a
+//     block count and two ignored fields, each a get of every block of the
+//     range.
+//   - twitter: the CSV cluster traces of Twitter, as written by
+//     slru.TraceRecorder: timestamp, key, key size, value size, client id,
+//     operation and TTL.
+//   - csv: records of a key, optionally followed by the value size, each a
+//     get of the key.
+//
+// Gets that miss set the key, as a read-through cache would. With -bytes,
+// size is in bytes, weighing entries by their key and value sizes.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("slru-replay", flag.ContinueOnError)
+	format := flags.String("format", "twitter", "trace format: arc, twitter or csv")
+	policies := flags.String("policy", "slru", "comma-separated policies: "+strings.Join(policyNames(), ", "))
+	size := flags.Int("size", 1000, "cache capacity in entries, or bytes with -bytes")
+	ratio := flags.Float64("ratio", 0, "probation ratio of slru, the default if zero")
+	shards := flags.Int("shards", 1, "number of shards of slru")
+	weighted := flags.Bool("bytes", false, "account capacity in bytes rather than entries")
+	optimal := flags.Bool("optimal", false, "also report Belady's optimal policy, on the gets of the trace")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return fmt.Errorf("slru-replay: no trace given")
+	}
+	if *optimal && *weighted {
+		return fmt.Errorf("slru-replay: -optimal counts entries, not bytes")
+	}
+
+	var events []event
+	for _, path := range flags.Args() {
+		read, err := readTrace(path, *format)
+		if err != nil {
+			return err
+		}
+		events = append(events, read...)
+	}
+
+	cfg := policyConfig{size: *size, ratio: *ratio, shards: *shards, weighted: *weighted}
+	var results []result
+	for _, name := range strings.Split(*policies, ",") {
+		name = strings.TrimSpace(name)
+		cache, err := newPolicy(name, cfg)
+		if err != nil {
+			return err
+		}
+		r := replay(cache, events)
+		r.policy = name
+		results = append(results, r)
+	}
+	if *optimal {
+		results = append(results, replayOptimal(events, *size))
+	}
+	return report(stdout, results)
+}
+
+// readTrace reads the events of the trace at path, or standard input if
+// path is "-".
+func readTrace(path, format string) ([]event, error) {
+	if path == "-" {
+		return parseTrace(os.Stdin, format)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	events, err := parseTrace(f, format)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return events, nil
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/hey-kong/slru"
+	"github.com/hey-kong/slru/bench"
+)
+
+// policyConfig are the flags shaping the replayed caches.
+type policyConfig struct {
+	size     int
+	ratio    float64
+	shards   int
+	weighted bool
+}
+
+// policies create the replayed caches by name. Values are entry weights.
+// Only slru supports weights; the others count entries.
+var policies = map[string]func(cfg policyConfig) slru.Cache[string, int]{
+	"slru": func(cfg policyConfig) slru.Cache[string, int] {
+		opts := []slru.Option[string, int]{slru.WithShards[string, int](cfg.shards, nil)}
+		if cfg.ratio > 0 {
+			opts = append(opts, slru.WithProbationRatio[string, int](cfg.ratio))
+		}
+		if cfg.weighted {
+			opts = append(opts, slru.WithWeigher(func(key string, size int) int { return size }))
+		}
+		return slru.New(cfg.size, opts...)
+	},
+	"lru": func(cfg policyConfig) slru.Cache[string, int] {
+		return slru.NewSegmented[string, int]([]int{cfg.size})
+	},
+	"fifo":     func(cfg policyConfig) slru.Cache[string, int] { return slru.NewFIFO[string, int](cfg.size) },
+	"arc":      func(cfg policyConfig) slru.Cache[string, int] { return slru.NewARC[string, int](cfg.size) },
+	"lirs":     func(cfg policyConfig) slru.Cache[string, int] { return slru.NewLIRS[string, int](cfg.size) },
+	"clockpro": func(cfg policyConfig) slru.Cache[string, int] { return slru.NewClockPro[string, int](cfg.size) },
+	"lfuda":    func(cfg policyConfig) slru.Cache[string, int] { return slru.NewLFUDA[string, int](cfg.size) },
+	"random": func(cfg policyConfig) slru.Cache[string, int] {
+		return slru.NewRandom(cfg.size, slru.WithPolicySeed[string, int](1))
+	},
+	"gdsf": func(cfg policyConfig) slru.Cache[string, int] {
+		return slru.NewGDSF(cfg.size, func(key string, size int) (float64, int) { return 1, size })
+	},
+}
+
+func policyNames() []string {
+	names := make([]string, 0, len(policies))
+	for name := range policies {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func newPolicy(name string, cfg policyConfig) (slru.Cache[string, int], error) {
+	newCache, ok := policies[name]
+	if !ok {
+		return nil, fmt.Errorf("slru-replay: unknown policy %q", name)
+	}
+	if cfg.weighted && name != "slru" {
+		return nil, fmt.Errorf("slru-replay: policy %q doesn't support -bytes", name)
+	}
+	return newCache(cfg), nil
+}
+
+// result is the outcome of replaying a trace against a policy.
+type result struct {
+	policy    string
+	ops       int
+	gets      int
+	hits      int
+	evictions uint64
+	elapsed   time.Duration
+	// optimal marks the result of Belady's MIN, which has no evictions to
+	// report.
+	optimal bool
+}
+
+func (r result) hitRatio() float64 {
+	if r.gets == 0 {
+		return 0
+	}
+	return float64(r.hits) / float64(r.gets)
+}
+
+func (r result) throughput() float64 {
+	if r.elapsed <= 0 {
+		return 0
+	}
+	return float64(r.ops) / r.elapsed.Seconds()
+}
+
+// replay applies events to cache, setting the keys of gets that miss.
+func replay(cache slru.Cache[string, int], events []event) result {
+	r := result{ops: len(events)}
+	start := time.Now()
+	for _, e := range events {
+		switch e.op {
+		case opGet:
+			r.gets++
+			if _, ok := cache.Get(e.key); ok {
+				r.hits++
+			} else {
+				cache.Set(e.key, e.size)
+			}
+		case opSet:
+			cache.Set(e.key, e.size)
+		case opDelete:
+			cache.Remove(e.key)
+		}
+	}
+	r.elapsed = time.Since(start)
+	r.evictions = cache.Stats().Evictions
+	return r
+}
+
+// replayOptimal returns the result of Belady's MIN on the gets of events
+// with a cache of size entries.
+func replayOptimal(events []event, size int) result {
+	var keys []string
+	for _, e := range events {
+		if e.op == opGet {
+			keys = append(keys, e.key)
+		}
+	}
+	opt := bench.Optimal(keys, size)
+	return result{policy: "optimal", ops: opt.Ops, gets: opt.Ops, hits: opt.Hits, elapsed: opt.Elapsed, optimal: true}
+}
+
+// report writes results as a table.
+func report(w io.Writer, results []result) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "policy\tops\tgets\thit ratio\tevictions\tops/s\t")
+	for _, r := range results {
+		evictions := "-"
+		if !r.optimal {
+			evictions = fmt.Sprint(r.evictions)
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.4f\t%s\t%.0f\t\n", r.policy, r.ops, r.gets, r.hitRatio(), evictions, r.throughput())
+	}
+	return tw.Flush()
+}
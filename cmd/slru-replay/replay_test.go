@@ -0,0 +1,72 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTrace(t *testing.T) {
+	events, err := parseTrace(strings.NewReader("10 2 0 1\n\n11 1 0 2\n"), "arc")
+	require.NoError(t, err)
+	require.Equal(t, []event{
+		{opGet, "10", arcBlockSize},
+		{opGet, "11", arcBlockSize},
+		{opGet, "11", arcBlockSize},
+	}, events)
+
+	events, err = parseTrace(strings.NewReader("0,k,1,10,0,gets,0\n1,k,1,20,0,add,60\n2,k,1,0,0,delete,0\n"), "twitter")
+	require.NoError(t, err)
+	require.Equal(t, []event{{opGet, "k", 11}, {opSet, "k", 21}, {opDelete, "k", 1}}, events)
+
+	events, err = parseTrace(strings.NewReader("a\nb,10\n"), "csv")
+	require.NoError(t, err)
+	require.Equal(t, []event{{opGet, "a", 1}, {opGet, "b", 11}}, events)
+
+	_, err = parseTrace(strings.NewReader("0,k,1,10,0,touch,0\n"), "twitter")
+	require.Error(t, err)
+	_, err = parseTrace(strings.NewReader("x 1\n"), "arc")
+	require.Error(t, err)
+	_, err = parseTrace(strings.NewReader(""), "lis")
+	require.Error(t, err)
+}
+
+func TestReplay(t *testing.T) {
+	events := []event{{opGet, "a", 1}, {opGet, "a", 1}, {opDelete, "a", 1}, {opGet, "a", 1}, {opSet, "b", 1}, {opGet, "b", 1}}
+	for _, name := range policyNames() {
+		cache, err := newPolicy(name, policyConfig{size: 10, shards: 1})
+		require.NoError(t, err)
+		r := replay(cache, events)
+		require.Equal(t, 6, r.ops, name)
+		require.Equal(t, 4, r.gets, name)
+		require.Equal(t, 2, r.hits, name)
+	}
+
+	_, err := newPolicy("lru", policyConfig{size: 10, weighted: true})
+	require.Error(t, err)
+	_, err = newPolicy("belady", policyConfig{size: 10})
+	require.Error(t, err)
+}
+
+func TestRun(t *testing.T) {
+	var trace strings.Builder
+	for i := range 1000 {
+		trace.WriteString(strings.Repeat("k", i%50+1) + "\n")
+	}
+	path := filepath.Join(t.TempDir(), "trace.csv")
+	require.NoError(t, os.WriteFile(path, []byte(trace.String()), 0o644))
+
+	var out strings.Builder
+	require.NoError(t, run([]string{"-format", "csv", "-size", "20", "-policy", "slru,lru, arc", "-optimal", path}, &out))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 5)
+	require.Contains(t, lines[0], "hit ratio")
+	require.Contains(t, lines[3], "arc")
+	require.Contains(t, lines[4], "optimal")
+
+	require.Error(t, run([]string{"-format", "csv", "-bytes", "-optimal", path}, &out))
+	require.Error(t, run(nil, &out))
+}
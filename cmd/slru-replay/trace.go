@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/hey-kong/slru"
+)
+
+// op is the kind of a replayed operation.
+type op int
+
+const (
+	opGet op = iota
+	opSet
+	opDelete
+)
+
+// event is one operation of a trace.
+type event struct {
+	op  op
+	key string
+	// size is the weight of the entry with -bytes.
+	size int
+}
+
+// arcBlockSize is the size of the blocks of ARC traces.
+const arcBlockSize = 512
+
+func parseTrace(r io.Reader, format string) ([]event, error) {
+	switch format {
+	case "arc":
+		return parseARC(r)
+	case "twitter":
+		return parseTwitter(r)
+	case "csv":
+		return parseCSV(r)
+	default:
+		return nil, fmt.Errorf("slru-replay: unknown trace format %q", format)
+	}
+}
+
+// parseARC reads a block trace of the ARC paper.
+func parseARC(r io.Reader) ([]event, error) {
+	var events []event
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("line %d: want a block and a count, got %q", line, scanner.Text())
+		}
+		start, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: bad block %q", line, fields[0])
+		}
+		n, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: bad count %q", line, fields[1])
+		}
+		for block := start; block < start+n; block++ {
+			events = append(events, event{op: opGet, key: strconv.FormatUint(block, 10), size: arcBlockSize})
+		}
+	}
+	return events, scanner.Err()
+}
+
+// parseTwitter reads a Twitter cluster trace.
+func parseTwitter(r io.Reader) ([]event, error) {
+	var events []event
+	tr := slru.NewTraceReader(r)
+	for {
+		e, err := tr.Read()
+		if errors.Is(err, io.EOF) {
+			return events, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		ev := event{key: e.Key, size: len(e.Key) + e.Size}
+		switch e.Op {
+		case slru.TraceGet, "gets":
+			ev.op = opGet
+		case slru.TraceSet, "add", "replace", "cas", "append", "prepend", "incr", "decr":
+			ev.op = opSet
+		case slru.TraceDelete:
+			ev.op = opDelete
+		default:
+			return nil, fmt.Errorf("unknown trace operation %q", e.Op)
+		}
+		events = append(events, ev)
+	}
+}
+
+// parseCSV reads records of a key and an optional value size.
+func parseCSV(r io.Reader) ([]event, error) {
+	var events []event
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return events, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		ev := event{op: opGet, key: record[0], size: len(record[0])}
+		if len(record) > 1 {
+			size, err := strconv.Atoi(record[1])
+			if err != nil {
+				return nil, fmt.Errorf("bad value size %q", record[1])
+			}
+			ev.size += size
+		}
+		events = append(events, ev)
+	}
+}
@@ -0,0 +1,49 @@
+// Command slrud serves a sharded SLRU over a subset of the Redis protocol
+// (GET, SET, DEL, TTL, INFO), so non-Go services can use the cache over the
+// network and the policy can be exercised with redis-benchmark.
+//
+// With -memcache-addr it also speaks the memcached text protocol (get, set,
+// delete, stats) on the same cache, for experiments against memcached with
+// existing client libraries.
+package main
+
+import (
+	"flag"
+	"log"
+	"net"
+)
+
+func main() {
+	addr := flag.String("addr", ":6379", "address to serve the Redis protocol on")
+	size := flag.Int("size", 64<<20, "cache capacity in bytes")
+	memcacheAddr := flag.String("memcache-addr", "", "address to serve the memcached protocol on, if any")
+	shards := flag.Int("shards", 16, "number of cache shards")
+	flag.Parse()
+
+	s := newServer(*size, *shards)
+	if *memcacheAddr != "" {
+		l, err := net.Listen("tcp", *memcacheAddr)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("slrud: serving memcached protocol on %s", l.Addr())
+		go func() { log.Fatal(serve(l, s.serveMemcache)) }()
+	}
+	l, err := net.Listen("tcp", *addr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("slrud: serving redis protocol on %s", l.Addr())
+	log.Fatal(serve(l, s.serveRedis))
+}
+
+// serve accepts connections on l, handling each one in its own goroutine.
+func serve(l net.Listener, handle func(net.Conn)) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go handle(conn)
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxRelativeExptime is the largest memcached exptime taken as relative
+// seconds; larger values are unix timestamps.
+const maxRelativeExptime = 60 * 60 * 24 * 30
+
+// maxItemSize bounds the values of set, as the item size limit of
+// memcached does, and maxLineLen the command lines.
+const (
+	maxItemSize = 1 << 20
+	maxLineLen  = 8 << 10
+)
+
+// errTooLarge closes connections announcing a value over maxItemSize,
+// whose data isn't worth reading, and errLineTooLong those sending a line
+// over maxLineLen.
+var (
+	errTooLarge    = errors.New("slrud: value too large")
+	errLineTooLong = errors.New("slrud: line too long")
+)
+
+// serveMemcache handles memcached text protocol commands on conn until it is
+// closed.
+func (s *server) serveMemcache(conn net.Conn) {
+	defer conn.Close()
+
+	r, w := bufio.NewReaderSize(conn, maxLineLen), bufio.NewWriter(conn)
+	for {
+		line, err := readLine(r)
+		if err == errLineTooLong {
+			w.WriteString("CLIENT_ERROR line too long\r\n")
+			w.Flush()
+			return
+		}
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(string(line))
+		quit := len(fields) > 0 && fields[0] == "quit"
+		if len(fields) > 0 && !quit {
+			if err := s.memcacheCommand(r, w, fields); err != nil {
+				return
+			}
+		}
+		// flush once the pipelined commands have been answered
+		if r.Buffered() == 0 || quit {
+			if err := w.Flush(); err != nil || quit {
+				return
+			}
+		}
+	}
+}
+
+// memcacheCommand handles one command, returning an error if the connection
+// can't continue.
+func (s *server) memcacheCommand(r *bufio.Reader, w *bufio.Writer, fields []string) error {
+	switch fields[0] {
+	case "get", "gets":
+		for _, key := range fields[1:] {
+			it, ok := s.cache.Get(key)
+			if !ok {
+				continue
+			}
+			if fields[0] == "gets" {
+				fmt.Fprintf(w, "VALUE %s %d %d 0\r\n", key, it.flags, len(it.value))
+			} else {
+				fmt.Fprintf(w, "VALUE %s %d %d\r\n", key, it.flags, len(it.value))
+			}
+			w.Write(it.value)
+			w.WriteString("\r\n")
+		}
+		w.WriteString("END\r\n")
+	case "set":
+		return s.memcacheSet(r, w, fields)
+	case "delete":
+		if len(fields) < 2 {
+			w.WriteString("ERROR\r\n")
+			return nil
+		}
+		ok := s.cache.Remove(fields[1])
+		if noreply(fields, 3) {
+			return nil
+		}
+		if ok {
+			w.WriteString("DELETED\r\n")
+		} else {
+			w.WriteString("NOT_FOUND\r\n")
+		}
+	case "stats":
+		stats := s.cache.Stats()
+		fmt.Fprintf(w, "STAT pid %d\r\n", os.Getpid())
+		fmt.Fprintf(w, "STAT uptime %d\r\n", int(time.Since(s.start).Seconds()))
+		fmt.Fprintf(w, "STAT curr_items %d\r\n", s.cache.Len())
+		fmt.Fprintf(w, "STAT get_hits %d\r\n", stats.Hits)
+		fmt.Fprintf(w, "STAT get_misses %d\r\n", stats.Misses)
+		fmt.Fprintf(w, "STAT evictions %d\r\n", stats.Evictions)
+		fmt.Fprintf(w, "STAT limit_maxbytes %d\r\n", s.size)
+		w.WriteString("END\r\n")
+	case "version":
+		w.WriteString("VERSION slrud\r\n")
+	default:
+		w.WriteString("ERROR\r\n")
+	}
+	return nil
+}
+
+// memcacheSet handles set <key> <flags> <exptime> <bytes> [noreply].
+func (s *server) memcacheSet(r *bufio.Reader, w *bufio.Writer, fields []string) error {
+	if len(fields) < 5 {
+		w.WriteString("ERROR\r\n")
+		return nil
+	}
+	flags, err1 := strconv.ParseUint(fields[2], 10, 32)
+	exptime, err2 := strconv.ParseInt(fields[3], 10, 64)
+	n, err3 := strconv.Atoi(fields[4])
+	if err1 != nil || err2 != nil || err3 != nil || n < 0 {
+		w.WriteString("CLIENT_ERROR bad command line format\r\n")
+		return nil
+	}
+	if n > maxItemSize {
+		w.WriteString("SERVER_ERROR object too large for cache\r\n")
+		w.Flush()
+		return errTooLarge
+	}
+
+	data := make([]byte, n+2)
+	if _, err := io.ReadFull(io.LimitReader(r, int64(len(data))), data); err != nil {
+		return err
+	}
+	if string(data[n:]) != "\r\n" {
+		w.WriteString("CLIENT_ERROR bad data chunk\r\n")
+		return nil
+	}
+
+	key, it := fields[1], item{value: data[:n], flags: uint32(flags)}
+	stored := true
+	switch {
+	case exptime < 0:
+		s.cache.Remove(key)
+	case exptime > maxRelativeExptime:
+		if ttl := time.Until(time.Unix(exptime, 0)); ttl > 0 {
+			stored = s.cache.Put(key, it, ttl)
+		} else {
+			s.cache.Remove(key)
+		}
+	default:
+		stored = s.cache.Put(key, it, time.Duration(exptime)*time.Second)
+	}
+	switch {
+	case noreply(fields, 6):
+	case !stored:
+		w.WriteString("SERVER_ERROR object too large for cache\r\n")
+	default:
+		w.WriteString("STORED\r\n")
+	}
+	return nil
+}
+
+// readLine reads a line of at most maxLineLen bytes, the size of the
+// buffer of r, returning errLineTooLong for longer ones.
+func readLine(r *bufio.Reader) ([]byte, error) {
+	line, err := r.ReadSlice('\n')
+	if err == bufio.ErrBufferFull {
+		return nil, errLineTooLong
+	}
+	return line, err
+}
+
+// noreply reports whether the command has noreply as its n-th field.
+func noreply(fields []string, n int) bool {
+	return len(fields) >= n && fields[n-1] == "noreply"
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemcacheCommands(t *testing.T) {
+	client, conn := net.Pipe()
+	s := newServer(1<<20, 4)
+	go s.serveMemcache(conn)
+	defer client.Close()
+
+	r := bufio.NewReader(client)
+	do := func(cmd string, lines int) string {
+		_, err := client.Write([]byte(cmd))
+		require.NoError(t, err)
+		out := ""
+		for i := 0; i < lines; i++ {
+			line, err := r.ReadString('\n')
+			require.NoError(t, err)
+			out += line
+		}
+		return out
+	}
+
+	require.Equal(t, "END\r\n", do("get k\r\n", 1))
+	require.Equal(t, "STORED\r\n", do("set k 42 0 5\r\nhello\r\n", 1))
+	require.Equal(t, "VALUE k 42 5\r\nhello\r\nEND\r\n", do("get k missing\r\n", 3))
+	require.Equal(t, "DELETED\r\n", do("delete k\r\n", 1))
+	require.Equal(t, "NOT_FOUND\r\n", do("delete k\r\n", 1))
+	require.Equal(t, "CLIENT_ERROR bad data chunk\r\n", do("set k 0 0 1\r\nab\r\n", 1))
+	require.Equal(t, "ERROR\r\n", do("incr k 1\r\n", 1))
+
+	// values are shared with the redis protocol
+	require.Equal(t, "VALUE shared 0 1\r\nx\r\nEND\r\n", do("set shared 0 0 1 noreply\r\nx\r\nget shared\r\n", 3))
+	it, ok := s.cache.Peek("shared")
+	require.True(t, ok)
+	require.Equal(t, "x", string(it.value))
+
+	// a value heavier than a shard can hold is not acknowledged
+	big := strings.Repeat("x", 100<<10)
+	require.Equal(t, "SERVER_ERROR object too large for cache\r\n", do("set big 0 0 "+strconv.Itoa(len(big))+"\r\n"+big+"\r\n", 1))
+	require.Equal(t, "END\r\n", do("get big\r\n", 1))
+
+	// a length no value can have closes the connection unread
+	require.Equal(t, "SERVER_ERROR object too large for cache\r\n", do("set k 0 0 9223372036854775807\r\n", 1))
+	_, err := r.ReadString('\n')
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func TestMemcacheLimits(t *testing.T) {
+	for cmd, reply := range map[string]string{
+		"set k 0 0 1048577\r\n":                      "SERVER_ERROR object too large for cache\r\n",
+		"get " + strings.Repeat("k", 8<<10) + "\r\n": "CLIENT_ERROR line too long\r\n",
+	} {
+		client, conn := net.Pipe()
+		go newServer(1<<20, 4).serveMemcache(conn)
+
+		go client.Write([]byte(cmd))
+		r := bufio.NewReader(client)
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		require.Equal(t, reply, line)
+		_, err = r.ReadString('\n')
+		require.ErrorIs(t, err, io.EOF)
+		client.Close()
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hey-kong/slru/internal/resp"
+)
+
+// serveRedis handles Redis protocol commands on conn until it is closed.
+func (s *server) serveRedis(conn net.Conn) {
+	defer conn.Close()
+
+	r, w := resp.NewReader(conn), resp.NewWriter(conn)
+	for {
+		args, err := r.ReadCommand()
+		if errors.Is(err, resp.ErrProtocol) {
+			// tell the client why, as Redis does, before hanging up
+			w.WriteError("ERR " + strings.TrimPrefix(err.Error(), "resp: "))
+			w.Flush()
+			return
+		}
+		if err != nil {
+			return
+		}
+		quit := len(args) > 0 && strings.EqualFold(string(args[0]), "QUIT")
+		if quit {
+			w.WriteSimpleString("OK")
+		} else {
+			s.redisCommand(w, args)
+		}
+		// flush once the pipelined commands have been answered
+		if r.Buffered() == 0 || quit {
+			if err := w.Flush(); err != nil || quit {
+				return
+			}
+		}
+	}
+}
+
+func (s *server) redisCommand(w *resp.Writer, args [][]byte) {
+	if len(args) == 0 {
+		return
+	}
+
+	switch name := strings.ToUpper(string(args[0])); name {
+	case "PING":
+		if len(args) > 1 {
+			w.WriteBulkString(args[1])
+			return
+		}
+		w.WriteSimpleString("PONG")
+	case "GET":
+		if len(args) != 2 {
+			wrongArgs(w, name)
+			return
+		}
+		if it, ok := s.cache.Get(string(args[1])); ok {
+			w.WriteBulkString(it.value)
+			return
+		}
+		w.WriteNull()
+	case "SET":
+		s.set(w, args)
+	case "DEL":
+		if len(args) < 2 {
+			wrongArgs(w, name)
+			return
+		}
+		n := 0
+		for _, key := range args[1:] {
+			if s.cache.Remove(string(key)) {
+				n++
+			}
+		}
+		w.WriteInteger(int64(n))
+	case "TTL":
+		if len(args) != 2 {
+			wrongArgs(w, name)
+			return
+		}
+		ttl, ok := s.cache.TTL(string(args[1]))
+		switch {
+		case !ok:
+			w.WriteInteger(-2)
+		case ttl == 0:
+			w.WriteInteger(-1)
+		default:
+			w.WriteInteger(int64((ttl + time.Second - 1) / time.Second))
+		}
+	case "INFO":
+		w.WriteBulkString([]byte(s.info()))
+	default:
+		w.WriteError(fmt.Sprintf("ERR unknown command '%s'", args[0]))
+	}
+}
+
+// set handles SET key value [EX seconds | PX milliseconds].
+func (s *server) set(w *resp.Writer, args [][]byte) {
+	if len(args) != 3 && len(args) != 5 {
+		wrongArgs(w, "SET")
+		return
+	}
+
+	var ttl time.Duration
+	if len(args) == 5 {
+		n, err := strconv.ParseInt(string(args[4]), 10, 64)
+		if err != nil || n <= 0 {
+			w.WriteError("ERR invalid expire time in 'set' command")
+			return
+		}
+		switch strings.ToUpper(string(args[3])) {
+		case "EX":
+			ttl = time.Duration(n) * time.Second
+		case "PX":
+			ttl = time.Duration(n) * time.Millisecond
+		default:
+			w.WriteError("ERR syntax error")
+			return
+		}
+	}
+
+	// the value outlives the read buffer, so it is already a private copy
+	if !s.cache.Put(string(args[1]), item{value: args[2]}, ttl) {
+		w.WriteError("ERR object too large for cache")
+		return
+	}
+	w.WriteSimpleString("OK")
+}
+
+func (s *server) info() string {
+	stats := s.cache.Stats()
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Server\r\n")
+	fmt.Fprintf(&b, "uptime_in_seconds:%d\r\n", int(time.Since(s.start).Seconds()))
+	fmt.Fprintf(&b, "# Stats\r\n")
+	fmt.Fprintf(&b, "keyspace_hits:%d\r\n", stats.Hits)
+	fmt.Fprintf(&b, "keyspace_misses:%d\r\n", stats.Misses)
+	fmt.Fprintf(&b, "evicted_keys:%d\r\n", stats.Evictions)
+	fmt.Fprintf(&b, "# Memory\r\n")
+	fmt.Fprintf(&b, "maxmemory:%d\r\n", s.size)
+	fmt.Fprintf(&b, "# Keyspace\r\n")
+	fmt.Fprintf(&b, "keys:%d\r\n", s.cache.Len())
+	return b.String()
+}
+
+func wrongArgs(w *resp.Writer, name string) {
+	w.WriteError(fmt.Sprintf("ERR wrong number of arguments for '%s' command", strings.ToLower(name)))
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hey-kong/slru/internal/resp"
+)
+
+func dialRedis(t *testing.T) (*resp.Reader, *resp.Writer) {
+	client, conn := net.Pipe()
+	go newServer(1<<20, 4).serveRedis(conn)
+	t.Cleanup(func() { client.Close() })
+	return resp.NewReader(client), resp.NewWriter(client)
+}
+
+func TestRedisCommands(t *testing.T) {
+	r, w := dialRedis(t)
+	do := func(args ...string) resp.Value {
+		require.NoError(t, w.WriteCommand(args...))
+		require.NoError(t, w.Flush())
+		v, err := r.ReadValue()
+		require.NoError(t, err)
+		return v
+	}
+
+	require.Equal(t, "PONG", string(do("PING").Str))
+	require.True(t, do("GET", "k").Null)
+	require.Equal(t, "OK", string(do("SET", "k", "v").Str))
+	require.Equal(t, "v", string(do("GET", "k").Str))
+	require.Equal(t, int64(-1), do("TTL", "k").Int)
+
+	require.Equal(t, "OK", string(do("SET", "e", "v", "EX", "100").Str))
+	require.Equal(t, int64(100), do("TTL", "e").Int)
+	require.Equal(t, int64(-2), do("TTL", "missing").Int)
+
+	require.Equal(t, int64(2), do("DEL", "k", "e", "missing").Int)
+	require.True(t, strings.Contains(string(do("INFO").Str), "keys:0"))
+	require.Equal(t, byte(resp.Error), do("FLUSHALL").Type)
+
+	// a value heavier than a shard can hold is not acknowledged
+	v := do("SET", "big", strings.Repeat("x", 100<<10))
+	require.Equal(t, byte(resp.Error), v.Type)
+	require.Equal(t, "ERR object too large for cache", string(v.Str))
+	require.True(t, do("GET", "big").Null)
+}
+
+func TestRedisProtocolError(t *testing.T) {
+	client, conn := net.Pipe()
+	go newServer(1<<20, 4).serveRedis(conn)
+	defer client.Close()
+
+	go client.Write([]byte("*1\r\n$3\r\nabcde\r\n"))
+	r := resp.NewReader(client)
+	v, err := r.ReadValue()
+	require.NoError(t, err)
+	require.Equal(t, byte(resp.Error), v.Type)
+	require.Equal(t, "ERR protocol error: bulk string not terminated by CRLF", string(v.Str))
+	_, err = r.ReadValue()
+	require.Error(t, err)
+}
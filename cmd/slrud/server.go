@@ -0,0 +1,39 @@
+package main
+
+import (
+	"hash/maphash"
+	"time"
+
+	"github.com/hey-kong/slru"
+)
+
+// item is a cached value and the opaque flags memcached clients store with it.
+type item struct {
+	value []byte
+	flags uint32
+}
+
+type server struct {
+	cache *slru.Sharded[string, item]
+	size  int
+	start time.Time
+}
+
+func newServer(size, shards int) *server {
+	return &server{
+		cache: newCache(size, shards),
+		size:  size,
+		start: time.Now(),
+	}
+}
+
+func newCache(size, shards int) *slru.Sharded[string, item] {
+	seed := maphash.MakeSeed()
+	hash := func(key string) uint64 {
+		return maphash.String(seed, key)
+	}
+	weigher := func(key string, it item) int {
+		return len(key) + len(it.value)
+	}
+	return slru.NewSharded(size, shards, hash, slru.WithWeigher(weigher))
+}
@@ -0,0 +1,74 @@
+package slru
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// coarseClock is a clock read from a timestamp refreshed by a ticker, far
+// cheaper to read than time.Now. It runs while it has users.
+type coarseClock struct {
+	resolution time.Duration
+	nanos      atomic.Int64
+
+	lock  sync.Mutex
+	users int
+	stop  chan struct{}
+}
+
+// WithCoarseClock reads the time for expiry and aging from a timestamp
+// refreshed every resolution, e.g. 10ms, instead of calling time.Now on
+// every operation, trading that much precision for throughput. Sharded
+// caches share one ticker across their shards.
+func WithCoarseClock[K comparable, V any](resolution time.Duration) Option[K, V] {
+	c := &coarseClock{resolution: resolution}
+	return func(s *SLRU[K, V]) {
+		if resolution > 0 {
+			s.coarse = c
+			s.clock = c.now
+		}
+	}
+}
+
+func (c *coarseClock) now() time.Time {
+	return time.Unix(0, c.nanos.Load())
+}
+
+// start adds a user, starting the ticker for the first one.
+func (c *coarseClock) start() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.users++
+	if c.users > 1 {
+		return
+	}
+	c.nanos.Store(time.Now().UnixNano())
+	c.stop = make(chan struct{})
+	go c.tick(c.stop)
+}
+
+// release removes a user, stopping the ticker after the last one.
+func (c *coarseClock) release() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.users--
+	if c.users == 0 {
+		close(c.stop)
+	}
+}
+
+func (c *coarseClock) tick(stop <-chan struct{}) {
+	ticker := time.NewTicker(c.resolution)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			c.nanos.Store(now.UnixNano())
+		}
+	}
+}
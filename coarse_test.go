@@ -0,0 +1,32 @@
+package slru
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoarseClock(t *testing.T) {
+	frozen := New[int, int](10, WithCoarseClock[int, int](time.Hour))
+	frozen.SetWithTTL(1, 1, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	require.True(t, frozen.Contains(1))
+
+	ticking := New[int, int](10, WithCoarseClock[int, int](time.Millisecond))
+	ticking.SetWithTTL(1, 1, time.Millisecond)
+	require.Eventually(t, func() bool { return !ticking.Contains(1) }, time.Second, time.Millisecond)
+}
+
+func TestCoarseClockSharedByShards(t *testing.T) {
+	cache := New[int, int](64, WithShards[int, int](4, nil), WithCoarseClock[int, int](time.Hour)).(*Sharded[int, int])
+	c := cache.shards[0].coarse
+	for _, s := range cache.shards {
+		require.Same(t, c, s.coarse)
+	}
+	require.Equal(t, 4, c.users)
+	for range cache.shards {
+		c.release()
+	}
+	require.Zero(t, c.users)
+}
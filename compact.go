@@ -0,0 +1,95 @@
+package slru
+
+import (
+	"log/slog"
+
+	"github.com/hey-kong/slru/list"
+)
+
+// PurgeFunc removes every entry for which fn returns true and reports how
+// many it removed. fn runs under the cache lock and must not call the
+// cache. The index is compacted when more than half the entries go.
+func (s *SLRU[K, V]) PurgeFunc(fn func(key K, value V) bool) (removed int) {
+	var keys []K
+	if s.bus != nil {
+		defer func() {
+			if len(keys) > 0 {
+				s.broadcast(Invalidation{Keys: s.encodeKeys(keys...)})
+			}
+		}()
+	}
+	s.acquire()
+	defer s.unlock()
+
+	s.dropSpilled()
+	s.removals++
+	n := len(s.items)
+	var changed []K
+	for _, l := range s.segments() {
+		l.Each(func(e *list.Element) bool {
+			if ent := e.Value.(*entry[K, V]); fn(ent.key, ent.value) {
+				if s.bus != nil {
+					keys = append(keys, ent.key)
+				}
+				delete(s.items, ent.key)
+				s.unpublish(ent.key)
+				s.mutate(MutationRemove, ent)
+				s.unlink(e)
+				s.release(e)
+				if _, ok := s.dependents[ent.key]; ok {
+					changed = append(changed, ent.key)
+				}
+				removed++
+			}
+			return true
+		})
+	}
+	for _, key := range changed {
+		s.cascade(key)
+	}
+	if removed > n/2 {
+		s.compact()
+	}
+	s.log(slog.LevelInfo, "slru: purge func", "removed", removed)
+	return removed
+}
+
+// Compact releases the memory the cache retains beyond what its current
+// entries and size need: it rebuilds the index at its current length and
+// drops preallocated entries the cache can no longer use. Resize compacts
+// when it more than halves the size.
+func (s *SLRU[K, V]) Compact() {
+	defer s.region("compact")()
+	s.acquire()
+	defer s.unlock()
+
+	s.compact()
+}
+
+func (s *SLRU[K, V]) compact() {
+	items := make(map[K]*list.Element, len(s.items))
+	for key, e := range s.items {
+		items[key] = e
+	}
+	s.items = items
+
+	if keep := max(s.size-len(s.items), 0); cap(s.free) > keep {
+		free := make([]*list.Element, min(len(s.free), keep), keep)
+		copy(free, s.free)
+		clear(s.free)
+		s.free = free
+	}
+}
+
+func (c *Sharded[K, V]) PurgeFunc(fn func(key K, value V) bool) (removed int) {
+	for _, s := range c.shards {
+		removed += s.PurgeFunc(fn)
+	}
+	return removed
+}
+
+func (c *Sharded[K, V]) Compact() {
+	for _, s := range c.shards {
+		s.Compact()
+	}
+}
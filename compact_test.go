@@ -0,0 +1,51 @@
+package slru
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPurgeFunc(t *testing.T) {
+	cache := New[int, int](100)
+	for i := 0; i < 20; i++ {
+		cache.Set(i, i)
+		cache.Get(i)
+	}
+	require.Equal(t, 10, cache.PurgeFunc(func(key, value int) bool { return value%2 == 0 }))
+	require.Equal(t, 10, cache.Len())
+	require.False(t, cache.Contains(4))
+	require.True(t, cache.Contains(5))
+}
+
+func TestCompact(t *testing.T) {
+	cache := newSLRU[int, int](100, WithPreallocation[int, int](100))
+	for i := 0; i < 100; i++ {
+		cache.Set(i, i)
+		cache.Get(i)
+	}
+	require.Equal(t, 80, cache.Len())
+
+	// only protected holds entries, 8 of them after shrinking
+	cache.Resize(10)
+	require.Equal(t, 8, cache.Len())
+	require.Equal(t, 2, cap(cache.free))
+	require.NoError(t, cache.Verify())
+
+	cache.Purge()
+	cache.Set(1, 1)
+	cache.Compact()
+	// compaction never grows the free list
+	require.Equal(t, 2, cap(cache.free))
+	require.True(t, cache.Contains(1))
+}
+
+func TestPurgeFuncOnSharded(t *testing.T) {
+	cache := NewSharded[int, int](1000, 4, nil)
+	for i := 0; i < 50; i++ {
+		cache.Set(i, i)
+	}
+	require.Equal(t, 50, cache.PurgeFunc(func(key, value int) bool { return true }))
+	require.Zero(t, cache.Len())
+	cache.Compact()
+}
@@ -0,0 +1,121 @@
+// Package compat wraps an SLRU in a cache of interface{} keys and values,
+// for code that can't thread type parameters through, such as plugins or
+// reflection-driven frameworks built on pre-generics APIs. Map stands in
+// for a sync.Map used as an unbounded cache.
+//
+// Keys must be comparable, as map keys: an unhashable key, such as a slice,
+// panics.
+package compat
+
+import (
+	"context"
+	"time"
+
+	"github.com/hey-kong/slru"
+)
+
+// Cache is a cache of any keys and values.
+type Cache struct {
+	cache slru.Cache[any, any]
+}
+
+// New returns a Cache of the given size, configured by opts as slru.New.
+func New(size int, opts ...slru.Option[any, any]) *Cache {
+	return &Cache{cache: slru.New(size, opts...)}
+}
+
+// Build returns a Cache described by cfg, as slru.Build.
+func Build(cfg slru.Config, opts ...slru.Option[any, any]) (*Cache, error) {
+	cache, err := slru.Build(cfg, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Cache{cache: cache}, nil
+}
+
+// Wrap returns a Cache over cache.
+func Wrap(cache slru.Cache[any, any]) *Cache {
+	return &Cache{cache: cache}
+}
+
+// Unwrap returns the underlying cache, for the methods Cache doesn't
+// forward.
+func (c *Cache) Unwrap() slru.Cache[any, any] {
+	return c.cache
+}
+
+// Set sets the value for the given key.
+func (c *Cache) Set(key, value any) {
+	c.cache.Set(key, value)
+}
+
+// SetWithTTL sets the value for the given key, expiring it after ttl.
+func (c *Cache) SetWithTTL(key, value any, ttl time.Duration) {
+	c.cache.SetWithTTL(key, value, ttl)
+}
+
+// Add sets the value for the given key, reporting whether it was newly
+// inserted rather than replacing an existing value.
+func (c *Cache) Add(key, value any) (inserted bool) {
+	return c.cache.Add(key, value)
+}
+
+// Get gets the value for the given key.
+func (c *Cache) Get(key any) (value any, ok bool) {
+	return c.cache.Get(key)
+}
+
+// GetOrLoad gets the value for the given key, loading and caching it with
+// load on a miss.
+func (c *Cache) GetOrLoad(ctx context.Context, key any, load func(ctx context.Context, key any) (any, error)) (any, error) {
+	return c.cache.GetOrLoad(ctx, key, load)
+}
+
+// Peek returns the value for the given key without updating its
+// recent-ness.
+func (c *Cache) Peek(key any) (value any, ok bool) {
+	return c.cache.Peek(key)
+}
+
+// Contains reports whether the given key is in the cache, without updating
+// its recent-ness.
+func (c *Cache) Contains(key any) bool {
+	return c.cache.Contains(key)
+}
+
+// TTL returns the remaining time-to-live of the given key, which is zero if
+// it never expires.
+func (c *Cache) TTL(key any) (ttl time.Duration, ok bool) {
+	return c.cache.TTL(key)
+}
+
+// Remove removes the given key, reporting whether it was present.
+func (c *Cache) Remove(key any) (present bool) {
+	return c.cache.Remove(key)
+}
+
+// Keys returns the keys in the cache, from the next victim to the hottest.
+func (c *Cache) Keys() []any {
+	return c.cache.Keys()
+}
+
+// Len returns the number of entries in the cache.
+func (c *Cache) Len() int {
+	return c.cache.Len()
+}
+
+// Stats returns the statistics of the cache.
+func (c *Cache) Stats() slru.Stats {
+	return c.cache.Stats()
+}
+
+// Purge removes every entry.
+func (c *Cache) Purge() {
+	c.cache.Purge()
+}
+
+// Resize changes the size of the cache, returning the number of evicted
+// entries. A negative size counts as 0.
+func (c *Cache) Resize(size int) (evicted int) {
+	return c.cache.Resize(size)
+}
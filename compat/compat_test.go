@@ -0,0 +1,57 @@
+package compat
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hey-kong/slru"
+)
+
+func TestCache(t *testing.T) {
+	cache := New(100)
+	cache.Set("a", 1)
+	cache.Set(2, []string{"b"})
+	require.True(t, cache.Add(struct{ X int }{3}, nil))
+
+	v, ok := cache.Get("a")
+	require.True(t, ok)
+	require.Equal(t, 1, v)
+	v, ok = cache.Peek(2)
+	require.True(t, ok)
+	require.Equal(t, []string{"b"}, v)
+	require.True(t, cache.Contains(struct{ X int }{3}))
+	require.False(t, cache.Contains("2"))
+	require.Equal(t, 3, cache.Len())
+
+	require.True(t, cache.Remove(2))
+	require.ElementsMatch(t, []any{"a", struct{ X int }{3}}, cache.Keys())
+	require.Panics(t, func() { cache.Set([]int{1}, 1) })
+
+	v, err := cache.GetOrLoad(context.Background(), "c", func(ctx context.Context, key any) (any, error) {
+		return key.(string) + "!", nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, "c!", v)
+	require.Equal(t, 3, cache.Resize(-1))
+	require.Zero(t, cache.Len())
+	cache.Resize(100)
+	cache.Set("a", 1)
+	cache.Purge()
+	require.Zero(t, cache.Len())
+}
+
+func TestBuild(t *testing.T) {
+	_, err := Build(slru.Config{})
+	require.ErrorIs(t, err, slru.ErrInvalidConfig)
+
+	cache, err := Build(slru.Config{Size: 10, TTL: time.Minute})
+	require.NoError(t, err)
+	cache.Set("a", 1)
+	ttl, ok := cache.TTL("a")
+	require.True(t, ok)
+	require.Greater(t, ttl, time.Second)
+	require.IsType(t, &slru.SLRU[any, any]{}, cache.Unwrap())
+}
@@ -0,0 +1,119 @@
+package compat
+
+import "github.com/hey-kong/slru"
+
+// Map is a bounded stand-in for a sync.Map used as a cache: it has the
+// methods of sync.Map, with their semantics, but holds its entries in an
+// SLRU, so a scan or a growing key space evicts cold entries rather than
+// growing the heap. Code holding a *sync.Map switches by changing its
+// type and constructor:
+//
+//	var sessions = compat.NewMap(10000) // was sync.Map
+//
+// Evicted entries are missed by Load as if deleted. The writes of Map lock
+// their key with LockKey, so LoadAndDelete and CompareAndDelete are atomic
+// with respect to them, but not to writes of the underlying cache made
+// otherwise.
+type Map struct {
+	cache slru.Cache[any, any]
+}
+
+// NewMap returns a Map of up to size entries, configured by opts as
+// slru.New.
+func NewMap(size int, opts ...slru.Option[any, any]) *Map {
+	return &Map{cache: slru.New(size, opts...)}
+}
+
+// WrapMap returns a Map over cache.
+func WrapMap(cache slru.Cache[any, any]) *Map {
+	return &Map{cache: cache}
+}
+
+// Unwrap returns the underlying cache.
+func (m *Map) Unwrap() slru.Cache[any, any] {
+	return m.cache
+}
+
+// Load returns the value stored for key, or nil if none, and whether it
+// was found.
+func (m *Map) Load(key any) (value any, ok bool) {
+	return m.cache.Get(key)
+}
+
+// Store sets the value for key.
+func (m *Map) Store(key, value any) {
+	defer m.cache.LockKey(key)()
+	m.cache.Set(key, value)
+}
+
+// LoadOrStore returns the value stored for key if present. Otherwise, it
+// stores and returns value. loaded reports whether the value was loaded.
+func (m *Map) LoadOrStore(key, value any) (actual any, loaded bool) {
+	defer m.cache.LockKey(key)()
+	actual, _ = m.cache.Update(key, func(old any, exists bool) (any, bool) {
+		loaded = exists
+		if exists {
+			return old, false
+		}
+		return value, true
+	})
+	return actual, loaded
+}
+
+// LoadAndDelete deletes the value for key, returning the previous value
+// if any. loaded reports whether key was present.
+func (m *Map) LoadAndDelete(key any) (value any, loaded bool) {
+	defer m.cache.LockKey(key)()
+	if value, loaded = m.cache.Peek(key); loaded {
+		m.cache.Remove(key)
+	}
+	return value, loaded
+}
+
+// Delete deletes the value for key.
+func (m *Map) Delete(key any) {
+	defer m.cache.LockKey(key)()
+	m.cache.Remove(key)
+}
+
+// Swap swaps the value for key and returns the previous value if any.
+// loaded reports whether key was present.
+func (m *Map) Swap(key, value any) (previous any, loaded bool) {
+	defer m.cache.LockKey(key)()
+	return m.cache.Swap(key, value)
+}
+
+// CompareAndSwap swaps the old and new values for key if the value stored
+// is equal to old, which must be of a comparable type.
+func (m *Map) CompareAndSwap(key, old, new any) (swapped bool) {
+	defer m.cache.LockKey(key)()
+	return m.cache.CompareAndSwap(key, old, new)
+}
+
+// CompareAndDelete deletes the entry for key if its value is equal to old,
+// which must be of a comparable type. If there is no value for key, it
+// returns false, even if old is nil.
+func (m *Map) CompareAndDelete(key, old any) (deleted bool) {
+	defer m.cache.LockKey(key)()
+	if value, ok := m.cache.Peek(key); ok && value == old {
+		return m.cache.Remove(key)
+	}
+	return false
+}
+
+// Range calls f for each key and value present, without promoting them,
+// until f returns false. As with sync.Map, it doesn't see a consistent
+// snapshot: each key is visited at most once, and writes during Range may
+// or may not be reflected. f may call any method of the Map.
+func (m *Map) Range(f func(key, value any) bool) {
+	for _, key := range m.cache.Keys() {
+		if value, ok := m.cache.Peek(key); ok && !f(key, value) {
+			return
+		}
+	}
+}
+
+// Clear deletes all the entries.
+func (m *Map) Clear() {
+	m.cache.Purge()
+}
@@ -0,0 +1,89 @@
+package compat
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMap(t *testing.T) {
+	m := NewMap(100)
+	m.Store("a", 1)
+	v, ok := m.Load("a")
+	require.True(t, ok)
+	require.Equal(t, 1, v)
+	_, ok = m.Load("b")
+	require.False(t, ok)
+
+	actual, loaded := m.LoadOrStore("a", 2)
+	require.True(t, loaded)
+	require.Equal(t, 1, actual)
+	actual, loaded = m.LoadOrStore("b", 2)
+	require.False(t, loaded)
+	require.Equal(t, 2, actual)
+
+	previous, loaded := m.Swap("b", 3)
+	require.True(t, loaded)
+	require.Equal(t, 2, previous)
+	require.False(t, m.CompareAndSwap("b", 2, 4))
+	require.True(t, m.CompareAndSwap("b", 3, 4))
+	require.False(t, m.CompareAndDelete("b", 3))
+	require.True(t, m.CompareAndDelete("b", 4))
+	require.False(t, m.CompareAndDelete("b", nil))
+
+	v, loaded = m.LoadAndDelete("a")
+	require.True(t, loaded)
+	require.Equal(t, 1, v)
+	_, loaded = m.LoadAndDelete("a")
+	require.False(t, loaded)
+
+	for i := range 10 {
+		m.Store(i, i*i)
+	}
+	m.Delete(3)
+	seen := map[any]any{}
+	m.Range(func(key, value any) bool {
+		seen[key] = value
+		return true
+	})
+	require.Len(t, seen, 9)
+	require.Equal(t, 16, seen[4])
+	n := 0
+	m.Range(func(key, value any) bool {
+		n++
+		return n < 2
+	})
+	require.Equal(t, 2, n)
+
+	m.Clear()
+	require.Zero(t, m.Unwrap().Len())
+}
+
+func TestMapBounded(t *testing.T) {
+	m := NewMap(100)
+	for i := range 1000 {
+		m.Store(i, i)
+	}
+	require.LessOrEqual(t, m.Unwrap().Len(), 100)
+}
+
+func TestMapLoadOrStoreConcurrent(t *testing.T) {
+	m := NewMap(100)
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	stored := 0
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, loaded := m.LoadOrStore("key", i); !loaded {
+				lock.Lock()
+				stored++
+				lock.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, 1, stored)
+}
@@ -0,0 +1,345 @@
+package slru
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidConfig is wrapped by the errors Build returns.
+var ErrInvalidConfig = errors.New("slru: invalid config")
+
+// Config describes a cache for Build, typically unmarshaled from a
+// configuration file. In JSON, durations are either numbers of
+// nanoseconds or strings parsed by time.ParseDuration, such as "5m".
+type Config struct {
+	// Size is the total capacity of the cache.
+	Size int
+
+	// ProbationRatio is the share of Size given to the probation segment,
+	// DefaultProbationRatio if zero.
+	ProbationRatio float64
+
+	// TotalCapacity enforces Size on both segments together, as
+	// WithTotalCapacity.
+	TotalCapacity bool
+
+	// TTL is the default time-to-live of entries, which never expire if zero.
+	TTL time.Duration
+
+	// ProbationTTL and ProtectedTTL, if either is set, replace TTL with a
+	// time-to-live per segment, as WithSegmentTTLs.
+	ProbationTTL time.Duration
+	ProtectedTTL time.Duration
+
+	// TTLJitter shortens TTLs by a random share of up to TTLJitter, as
+	// WithTTLJitter.
+	TTLJitter float64
+
+	// AdmissionProbability admits new keys with that probability, as
+	// WithAdmissionProbability, or all of them if zero.
+	AdmissionProbability float64
+
+	// Seed draws the randomness of the cache from a generator seeded with
+	// it, as WithSeed, or a random one if zero.
+	Seed uint64
+
+	// PreservedTTL keeps the deadline of entries when Set updates them, as
+	// WithPreservedTTL.
+	PreservedTTL bool
+
+	// JanitorInterval removes expired entries in the background, as
+	// WithJanitor. Zero disables the janitor.
+	JanitorInterval time.Duration
+
+	// JanitorLimit caps the entries the janitor expires per run, as
+	// WithJanitorLimit. Zero is unlimited.
+	JanitorLimit int
+
+	// Shards is the number of independently locked shards, which must be a
+	// power of two. Zero or one creates an unsharded cache.
+	Shards int
+
+	// ProtectedAging demotes protected entries idle that long, as
+	// WithProtectedAging. Zero disables aging.
+	ProtectedAging time.Duration
+
+	// WriteBuffer buffers that many writes of SetAsync, as WithWriteBuffer.
+	// Zero applies them directly.
+	WriteBuffer int
+
+	// LeaseTTL is how long leases of GetOrLease last, DefaultLeaseTTL if
+	// zero.
+	LeaseTTL time.Duration
+
+	// Tombstones is the number of invalidated versions remembered,
+	// DefaultTombstones if zero.
+	Tombstones int
+
+	// ClockResolution reads the time from a timestamp refreshed that often,
+	// as WithCoarseClock. Zero calls time.Now.
+	ClockResolution time.Duration
+
+	// LockFreeReads serves Contains and Peek from a shadow index, as
+	// WithLockFreeReads.
+	LockFreeReads bool
+
+	// LatencyHistograms records latencies in Stats, as
+	// WithLatencyHistograms.
+	LatencyHistograms bool
+
+	// LockWaitSampling times one in that many lock acquisitions, as
+	// WithLockWaitSampling. Zero disables sampling.
+	LockWaitSampling int
+}
+
+// Validate reports whether c describes a usable cache.
+func (c Config) Validate() error {
+	if c.Size < 1 {
+		return fmt.Errorf("%w: size must be at least 1, got %d", ErrInvalidConfig, c.Size)
+	}
+	if c.ProbationRatio < 0 || c.ProbationRatio >= 1 {
+		return fmt.Errorf("%w: probation ratio must be in (0, 1), got %v", ErrInvalidConfig, c.ProbationRatio)
+	}
+	if c.TTLJitter < 0 || c.TTLJitter > 1 {
+		return fmt.Errorf("%w: ttl jitter must be in [0, 1], got %v", ErrInvalidConfig, c.TTLJitter)
+	}
+	if c.AdmissionProbability < 0 || c.AdmissionProbability > 1 {
+		return fmt.Errorf("%w: admission probability must be in [0, 1], got %v", ErrInvalidConfig, c.AdmissionProbability)
+	}
+	if c.Shards < 0 || c.Shards&(c.Shards-1) != 0 {
+		return fmt.Errorf("%w: shards must be a power of two, got %d", ErrInvalidConfig, c.Shards)
+	}
+	for _, d := range []struct {
+		name string
+		d    time.Duration
+	}{
+		{"ttl", c.TTL},
+		{"probation ttl", c.ProbationTTL},
+		{"protected ttl", c.ProtectedTTL},
+		{"janitor interval", c.JanitorInterval},
+		{"protected aging", c.ProtectedAging},
+		{"lease ttl", c.LeaseTTL},
+		{"clock resolution", c.ClockResolution},
+	} {
+		if d.d < 0 {
+			return fmt.Errorf("%w: %s must not be negative, got %v", ErrInvalidConfig, d.name, d.d)
+		}
+	}
+	for _, n := range []struct {
+		name string
+		n    int
+	}{
+		{"janitor limit", c.JanitorLimit},
+		{"write buffer", c.WriteBuffer},
+		{"tombstones", c.Tombstones},
+		{"lock wait sampling", c.LockWaitSampling},
+	} {
+		if n.n < 0 {
+			return fmt.Errorf("%w: %s must not be negative, got %d", ErrInvalidConfig, n.name, n.n)
+		}
+	}
+	return nil
+}
+
+// UnmarshalJSON decodes c, accepting durations as strings too.
+func (c *Config) UnmarshalJSON(data []byte) error {
+	type config Config
+	aux := struct {
+		*config
+		TTL             jsonDuration
+		ProbationTTL    jsonDuration
+		ProtectedTTL    jsonDuration
+		JanitorInterval jsonDuration
+		ProtectedAging  jsonDuration
+		LeaseTTL        jsonDuration
+		ClockResolution jsonDuration
+	}{config: (*config)(c)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	c.TTL = time.Duration(aux.TTL)
+	c.ProbationTTL = time.Duration(aux.ProbationTTL)
+	c.ProtectedTTL = time.Duration(aux.ProtectedTTL)
+	c.JanitorInterval = time.Duration(aux.JanitorInterval)
+	c.ProtectedAging = time.Duration(aux.ProtectedAging)
+	c.LeaseTTL = time.Duration(aux.LeaseTTL)
+	c.ClockResolution = time.Duration(aux.ClockResolution)
+	return nil
+}
+
+// jsonDuration decodes a duration from nanoseconds or a duration string.
+type jsonDuration time.Duration
+
+func (d *jsonDuration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return json.Unmarshal(data, (*time.Duration)(d))
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = jsonDuration(parsed)
+	return nil
+}
+
+func (c Config) probationRatio() float64 {
+	if c.ProbationRatio == 0 {
+		return DefaultProbationRatio
+	}
+	return c.ProbationRatio
+}
+
+// Build validates cfg, then creates the cache it describes, rejecting
+// configurations that would produce a cache unable to hold anything. opts
+// apply after cfg, for what a Config can't describe, such as weighers and
+// callbacks.
+func Build[K comparable, V any](cfg Config, opts ...Option[K, V]) (Cache[K, V], error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	base := []Option[K, V]{
+		WithProbationRatio[K, V](cfg.probationRatio()),
+		WithTTL[K, V](cfg.TTL),
+		WithShards[K, V](cfg.Shards, nil),
+	}
+	if cfg.ProbationTTL > 0 || cfg.ProtectedTTL > 0 {
+		base = append(base, WithSegmentTTLs[K, V](cfg.ProbationTTL, cfg.ProtectedTTL))
+	}
+	if cfg.TTLJitter > 0 {
+		base = append(base, WithTTLJitter[K, V](cfg.TTLJitter))
+	}
+	if cfg.AdmissionProbability > 0 {
+		base = append(base, WithAdmissionProbability[K, V](cfg.AdmissionProbability))
+	}
+	if cfg.Seed != 0 {
+		base = append(base, WithSeed[K, V](cfg.Seed))
+	}
+	if cfg.PreservedTTL {
+		base = append(base, WithPreservedTTL[K, V]())
+	}
+	if cfg.TotalCapacity {
+		base = append(base, WithTotalCapacity[K, V]())
+	}
+	if cfg.JanitorInterval > 0 {
+		base = append(base, WithJanitor[K, V](cfg.JanitorInterval))
+	}
+	if cfg.JanitorLimit > 0 {
+		base = append(base, WithJanitorLimit[K, V](cfg.JanitorLimit))
+	}
+	if cfg.ProtectedAging > 0 {
+		base = append(base, WithProtectedAging[K, V](cfg.ProtectedAging))
+	}
+	if cfg.WriteBuffer > 0 {
+		base = append(base, WithWriteBuffer[K, V](cfg.WriteBuffer))
+	}
+	if cfg.LeaseTTL > 0 {
+		base = append(base, WithLeaseTTL[K, V](cfg.LeaseTTL))
+	}
+	if cfg.Tombstones > 0 {
+		base = append(base, WithTombstones[K, V](cfg.Tombstones))
+	}
+	if cfg.ClockResolution > 0 {
+		base = append(base, WithCoarseClock[K, V](cfg.ClockResolution))
+	}
+	if cfg.LockFreeReads {
+		base = append(base, WithLockFreeReads[K, V]())
+	}
+	if cfg.LatencyHistograms {
+		base = append(base, WithLatencyHistograms[K, V]())
+	}
+	if cfg.LockWaitSampling > 0 {
+		base = append(base, WithLockWaitSampling[K, V](cfg.LockWaitSampling))
+	}
+	return New[K, V](cfg.Size, append(base, opts...)...), nil
+}
+
+// EffectiveConfig is the configuration a cache runs with, as returned by
+// its Config method: Config, with defaults filled in and the resulting
+// segment sizes. Build(c.Config) creates a cache with identical settings,
+// except for options a Config can't describe, such as weighers, callbacks
+// or WithProtectedAgingOps.
+type EffectiveConfig struct {
+	Config
+
+	// ProbationSize and ProtectedSize are the capacities of the segments.
+	ProbationSize int
+	ProtectedSize int
+}
+
+// UnmarshalJSON decodes c, which would otherwise be decoded by the
+// UnmarshalJSON of Config alone.
+func (c *EffectiveConfig) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &c.Config); err != nil {
+		return err
+	}
+	var sizes struct{ ProbationSize, ProtectedSize int }
+	if err := json.Unmarshal(data, &sizes); err != nil {
+		return err
+	}
+	c.ProbationSize, c.ProtectedSize = sizes.ProbationSize, sizes.ProtectedSize
+	return nil
+}
+
+// Config returns the effective configuration of the cache.
+func (s *SLRU[K, V]) Config() EffectiveConfig {
+	s.acquireShared()
+	defer s.lock.RUnlock()
+
+	cfg := EffectiveConfig{
+		Config: Config{
+			Size:                 s.size,
+			ProbationRatio:       s.ratio,
+			TotalCapacity:        s.total,
+			TTL:                  s.ttl,
+			TTLJitter:            s.ttlJitter,
+			AdmissionProbability: cmp.Or(s.admitProbability, 1),
+			Seed:                 s.seed,
+			PreservedTTL:         s.preserveTTL,
+			JanitorInterval:      s.janitorInterval,
+			JanitorLimit:         s.janitorLimit,
+			WriteBuffer:          cap(s.writes),
+			LeaseTTL:             cmp.Or(s.leaseTTL, DefaultLeaseTTL),
+			Tombstones:           cmp.Or(s.tombstoneCap, DefaultTombstones),
+			LockFreeReads:        s.index != nil,
+			LatencyHistograms:    s.latency,
+			LockWaitSampling:     int(s.waitSample),
+		},
+		ProbationSize: s.probationSize,
+		ProtectedSize: s.protectedSize,
+	}
+	if s.coarse != nil {
+		cfg.ClockResolution = s.coarse.resolution
+	}
+	if s.segmentTTLs {
+		cfg.ProbationTTL, cfg.ProtectedTTL = s.probationTTL, s.protectedTTL
+	}
+	if !s.agingOps {
+		cfg.ProtectedAging = time.Duration(s.agingWindow)
+	}
+	return cfg
+}
+
+// Config returns the effective configuration of the cache, with the size it
+// was given and the segment sizes summed over the shards, which may exceed
+// it once rounded up per shard.
+func (c *Sharded[K, V]) Config() EffectiveConfig {
+	cfg := c.shards[0].Config()
+	cfg.Size = int(c.size.Load())
+	cfg.Shards = len(c.shards)
+	for _, s := range c.shards[1:] {
+		shard := s.Config()
+		cfg.ProbationSize += shard.ProbationSize
+		cfg.ProtectedSize += shard.ProtectedSize
+	}
+	return cfg
+}
+
+// NewWithConfig is Build without options.
+func NewWithConfig[K comparable, V any](cfg Config) (Cache[K, V], error) {
+	return Build[K, V](cfg)
+}
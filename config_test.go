@@ -0,0 +1,102 @@
+package slru
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWithConfig(t *testing.T) {
+	for _, cfg := range []Config{
+		{Size: 0},
+		{Size: 100, ProbationRatio: 1},
+		{Size: 100, ProbationRatio: -0.5},
+		{Size: 100, TTL: -time.Second},
+		{Size: 100, Shards: 3},
+	} {
+		_, err := NewWithConfig[int, int](cfg)
+		require.ErrorIs(t, err, ErrInvalidConfig, "%+v", cfg)
+	}
+
+	cache, err := NewWithConfig[int, int](Config{Size: 100, ProbationRatio: 0.5, TTL: time.Minute})
+	require.NoError(t, err)
+	require.Equal(t, 50, cache.(*SLRU[int, int]).probationSize)
+	cache.Set(1, 1)
+	ttl, _ := cache.TTL(1)
+	require.Greater(t, ttl, time.Second)
+
+	cache, err = NewWithConfig[int, int](Config{Size: 100, Shards: 4})
+	require.NoError(t, err)
+	require.Len(t, cache.(*Sharded[int, int]).shards, 4)
+	cache.Set(1, 1)
+	require.True(t, cache.Contains(1))
+}
+
+func TestBuildFromJSON(t *testing.T) {
+	var cfg Config
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"size": 100,
+		"probationRatio": 0.5,
+		"ttl": "1m",
+		"janitorInterval": 1000000000,
+		"writeBuffer": 8,
+		"latencyHistograms": true
+	}`), &cfg))
+	require.Equal(t, Config{
+		Size:              100,
+		ProbationRatio:    0.5,
+		TTL:               time.Minute,
+		JanitorInterval:   time.Second,
+		WriteBuffer:       8,
+		LatencyHistograms: true,
+	}, cfg)
+	require.Error(t, json.Unmarshal([]byte(`{"ttl": "soon"}`), &cfg))
+
+	var evicted []int
+	cache, err := Build(cfg, WithEvictCallback(func(key, value int) { evicted = append(evicted, key) }))
+	require.NoError(t, err)
+	s := cache.(*SLRU[int, int])
+	require.Equal(t, 50, s.probationSize)
+	require.True(t, s.latency)
+	require.NotNil(t, s.writes)
+	require.NotNil(t, s.wheel)
+	for i := range 51 {
+		cache.Set(i, i)
+	}
+	require.Equal(t, []int{0}, evicted)
+
+	_, err = Build[int, int](Config{Size: 100, WriteBuffer: -1})
+	require.ErrorIs(t, err, ErrInvalidConfig)
+}
+
+func TestConfigOf(t *testing.T) {
+	cfg := Config{Size: 100, ProbationRatio: 0.3, TTL: time.Minute, ProtectedAging: time.Hour, WriteBuffer: 4, LockFreeReads: true}
+	cache, err := Build[int, int](cfg)
+	require.NoError(t, err)
+	got := cache.(*SLRU[int, int]).Config()
+	require.Equal(t, 30, got.ProbationSize)
+	require.Equal(t, 70, got.ProtectedSize)
+	cfg.LeaseTTL = DefaultLeaseTTL
+	cfg.Tombstones = DefaultTombstones
+	cfg.AdmissionProbability = 1
+	require.Equal(t, cfg, got.Config)
+
+	clone, err := Build[int, int](got.Config)
+	require.NoError(t, err)
+	require.Equal(t, got, clone.(*SLRU[int, int]).Config())
+
+	require.Equal(t, DefaultProbationRatio, New[int, int](10).(*SLRU[int, int]).Config().ProbationRatio)
+
+	sharded := NewSharded[int, int](100, 4, nil).Config()
+	require.Equal(t, 4, sharded.Shards)
+	require.Equal(t, 100, sharded.Size)
+	require.Equal(t, 100, sharded.ProbationSize+sharded.ProtectedSize)
+
+	// shards are rounded up, the size isn't
+	rounded := NewSharded[int, int](100, 16, nil)
+	require.Equal(t, 100, rounded.Config().Size)
+	rounded.Resize(50)
+	require.Equal(t, 50, rounded.Config().Size)
+}
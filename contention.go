@@ -0,0 +1,44 @@
+package slru
+
+import "time"
+
+// WithLockWaitSampling records, for one in every acquisitions of the cache
+// lock, the time spent waiting for it in Stats.LockWait.
+func WithLockWaitSampling[K comparable, V any](every int) Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		s.waitSample = uint32(max(every, 0))
+	}
+}
+
+// sampled reports whether to time this acquisition of the lock.
+func (s *SLRU[K, V]) sampled() bool {
+	return s.waitSample > 0 && s.randUint32()%s.waitSample == 0
+}
+
+// acquire locks the cache exclusively.
+func (s *SLRU[K, V]) acquire() {
+	if s.chaos != nil {
+		s.delay()
+	}
+	if !s.sampled() {
+		s.lock.Lock()
+		return
+	}
+	start := time.Now()
+	s.lock.Lock()
+	s.stats.lockWait.since(start)
+}
+
+// acquireShared locks the cache for reading.
+func (s *SLRU[K, V]) acquireShared() {
+	if s.chaos != nil {
+		s.delay()
+	}
+	if !s.sampled() {
+		s.lock.RLock()
+		return
+	}
+	start := time.Now()
+	s.lock.RLock()
+	s.stats.lockWait.since(start)
+}
@@ -0,0 +1,87 @@
+package slru
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hey-kong/slru/list"
+)
+
+// DebugEntry describes a cache entry for debugging.
+type DebugEntry[K comparable] struct {
+	Key    K
+	Weight int
+	Hits   uint64
+	// Age is the time since the entry was inserted, Idle since its last hit.
+	Age  time.Duration
+	Idle time.Duration
+	// Dead is set for expired or invalidated entries not yet discarded.
+	Dead bool
+}
+
+// DebugState is a point-in-time view of both segments, each ordered from
+// the most recently used entry to the next victim.
+type DebugState[K comparable] struct {
+	Probation []DebugEntry[K]
+	Protected []DebugEntry[K]
+	// Bypass holds the entries inserted during a scan, with WithScanBypass.
+	Bypass []DebugEntry[K]
+}
+
+// DebugState returns the contents of both segments in order.
+func (s *SLRU[K, V]) DebugState() DebugState[K] {
+	s.acquireShared()
+	defer s.lock.RUnlock()
+	defer s.lockSegments()()
+
+	now := s.now()
+	state := DebugState[K]{
+		Probation: s.debugEntries(s.probation, now),
+		Protected: s.debugEntries(s.protected, now),
+	}
+	if s.bypass != nil {
+		state.Bypass = s.debugEntries(s.bypass, now)
+	}
+	return state
+}
+
+func (s *SLRU[K, V]) debugEntries(l *list.List, now time.Time) []DebugEntry[K] {
+	entries := make([]DebugEntry[K], 0, l.Len())
+	l.Each(func(e *list.Element) bool {
+		ent := e.Value.(*entry[K, V])
+		entries = append(entries, DebugEntry[K]{
+			Key:    ent.key,
+			Weight: ent.weight,
+			Hits:   ent.hits,
+			Age:    now.Sub(ent.created),
+			Idle:   now.Sub(ent.accessed),
+			Dead:   s.dead(ent, now),
+		})
+		return true
+	})
+	return entries
+}
+
+// Dump returns a human-readable rendering of DebugState.
+func (s *SLRU[K, V]) Dump() string {
+	state := s.DebugState()
+
+	var b strings.Builder
+	dump := func(name string, entries []DebugEntry[K]) {
+		fmt.Fprintf(&b, "%s (%d entries, MRU first):\n", name, len(entries))
+		for _, e := range entries {
+			fmt.Fprintf(&b, "  %v weight=%d hits=%d age=%v idle=%v", e.Key, e.Weight, e.Hits, e.Age, e.Idle)
+			if e.Dead {
+				b.WriteString(" dead")
+			}
+			b.WriteByte('\n')
+		}
+	}
+	if state.Bypass != nil {
+		dump("bypass", state.Bypass)
+	}
+	dump("probation", state.Probation)
+	dump("protected", state.Protected)
+	return b.String()
+}
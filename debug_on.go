@@ -0,0 +1,6 @@
+//go:build slrudebug
+
+package slru
+
+// verifyMutations enables invariant checks after each mutation.
+const verifyMutations = true
@@ -0,0 +1,80 @@
+package slru
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebugStateOnSLRU(t *testing.T) {
+	cache := newSLRU[string, int](20)
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Set("c", 3)
+	cache.Get("a")
+	cache.Get("a")
+
+	state := cache.DebugState()
+	require.Len(t, state.Probation, 2)
+	require.Equal(t, "c", state.Probation[0].Key)
+	require.Equal(t, "b", state.Probation[1].Key)
+	require.Len(t, state.Protected, 1)
+	require.Equal(t, "a", state.Protected[0].Key)
+	require.Equal(t, uint64(2), state.Protected[0].Hits)
+	require.GreaterOrEqual(t, state.Protected[0].Age, state.Protected[0].Idle)
+
+	dump := cache.Dump()
+	require.True(t, strings.HasPrefix(dump, "probation (2 entries, MRU first):\n  c weight=1 hits=0"))
+	require.Contains(t, dump, "protected (1 entries, MRU first):\n  a weight=1 hits=2")
+}
+
+func TestWatchedKeys(t *testing.T) {
+	cache := newSLRU[string, int](10, WithWatchedKeys[string, int](3, "a"))
+	require.Nil(t, cache.AccessLog("b"))
+
+	cache.Get("a")
+	cache.Set("a", 1)
+	cache.Get("a")
+	log := cache.AccessLog("a")
+	require.Len(t, log, 3)
+	require.Equal(t, Access{Time: log[0].Time, Outcome: AccessMiss}, log[0])
+	require.Equal(t, Access{Time: log[1].Time, Outcome: AccessSet}, log[1])
+	require.Equal(t, Access{Time: log[2].Time, Outcome: AccessHit, Segment: "probation"}, log[2])
+
+	cache.Remove("a")
+	log = cache.AccessLog("a")
+	require.Len(t, log, 3)
+	require.Equal(t, AccessSet, log[0].Outcome)
+	require.Equal(t, AccessRemoved, log[2].Outcome)
+	require.Equal(t, "protected", log[2].Segment)
+
+	cache.Watch("b")
+	cache.Set("b", 1)
+	cache.Set("c", 1)
+	cache.Set("d", 1)
+	log = cache.AccessLog("b")
+	require.Equal(t, []string{AccessSet, AccessEvicted}, []string{log[0].Outcome, log[1].Outcome})
+
+	cache.Unwatch("b")
+	require.Nil(t, cache.AccessLog("b"))
+}
+
+func TestWatchedKeysNormalized(t *testing.T) {
+	cache := newSLRU[string, int](10,
+		WithWatchedKeys[string, int](0, "A"),
+		WithKeyTransform[string, int](strings.ToLower),
+	)
+	cache.Set("a", 1)
+	cache.Get("A")
+	require.Len(t, cache.AccessLog("a"), 2)
+	require.Len(t, cache.AccessLog("A"), 2)
+}
+
+func TestWatchedKeysOnSharded(t *testing.T) {
+	cache := NewSharded[string, int](100, 4, nil)
+	cache.Watch("a")
+	cache.Set("a", 1)
+	cache.Get("a")
+	require.Len(t, cache.AccessLog("a"), 2)
+}
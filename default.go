@@ -0,0 +1,66 @@
+package slru
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// DefaultSize is the size of the default cache unless set with Configure.
+const DefaultSize = 1024
+
+// defaultCache is the cache of the package-level functions, created on
+// first use.
+var defaultCache atomic.Pointer[Cache[string, any]]
+
+// Default returns the default cache used by the package-level functions,
+// creating it with DefaultSize on first use.
+func Default() Cache[string, any] {
+	if c := defaultCache.Load(); c != nil {
+		return *c
+	}
+	c := New[string, any](DefaultSize)
+	if defaultCache.CompareAndSwap(nil, &c) {
+		return c
+	}
+	return *defaultCache.Load()
+}
+
+// Configure replaces the default cache with a new one built from cfg and
+// opts, as Build, for small programs and tests that don't pass a cache
+// around. Entries of the previous default cache are dropped.
+func Configure(cfg Config, opts ...Option[string, any]) error {
+	c, err := Build(cfg, opts...)
+	if err != nil {
+		return err
+	}
+	defaultCache.Store(&c)
+	return nil
+}
+
+// Get gets the value for the given key from the default cache. A value of
+// another type than V is reported as missing.
+func Get[V any](key string) (value V, ok bool) {
+	v, ok := Default().Get(key)
+	if !ok {
+		return value, false
+	}
+	value, ok = v.(V)
+	return value, ok
+}
+
+// Set sets the value for the given key on the default cache.
+func Set(key string, value any) {
+	Default().Set(key, value)
+}
+
+// SetWithTTL sets the value for the given key on the default cache,
+// expiring it after ttl.
+func SetWithTTL(key string, value any, ttl time.Duration) {
+	Default().SetWithTTL(key, value, ttl)
+}
+
+// Remove removes the given key from the default cache, reporting whether it
+// was present.
+func Remove(key string) (present bool) {
+	return Default().Remove(key)
+}
@@ -0,0 +1,31 @@
+package slru
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultCache(t *testing.T) {
+	defer defaultCache.Store(nil)
+
+	Set("a", 1)
+	v, ok := Get[int]("a")
+	require.True(t, ok)
+	require.Equal(t, 1, v)
+	_, ok = Get[string]("a")
+	require.False(t, ok)
+	require.Equal(t, DefaultSize, Default().(*SLRU[string, any]).Config().Size)
+
+	require.ErrorIs(t, Configure(Config{}), ErrInvalidConfig)
+	require.True(t, Remove("a"))
+	require.NoError(t, Configure(Config{Size: 10, TTL: time.Minute}))
+	_, ok = Get[int]("a")
+	require.False(t, ok)
+	SetWithTTL("b", "x", time.Hour)
+	ttl, ok := Default().TTL("b")
+	require.True(t, ok)
+	require.Greater(t, ttl, time.Minute)
+	require.Equal(t, 10, Default().(*SLRU[string, any]).Config().Size)
+}
@@ -0,0 +1,81 @@
+package slru
+
+// Dependent is a cache cascading invalidations along dependencies between
+// entries, such as an SLRU or a Sharded.
+type Dependent[K comparable, V any] interface {
+	DependOn(key K, deps ...K) (ok bool)
+}
+
+// DependOn declares that the entry of key derives from those of deps, so
+// an update or an explicit removal of any of them, by Remove, PurgeFunc,
+// InvalidateTag or an invalidation from the bus, removes key too, and in
+// turn its own dependents. Evictions and expirations don't cascade: the
+// source is unchanged. deps need not be cached yet; the dependencies last
+// as long as the entry of key. DependOn reports whether key is cached.
+func (s *SLRU[K, V]) DependOn(key K, deps ...K) (ok bool) {
+	key, deps = s.normalize(key), s.normalizeAll(deps)
+	s.acquire()
+	defer s.unlock()
+
+	return s.dependOn(key, deps)
+}
+
+func (s *SLRU[K, V]) dependOn(key K, deps []K) (ok bool) {
+	e, ok := s.items[key]
+	if !ok {
+		return false
+	}
+	ent := e.Value.(*entry[K, V])
+	if s.dependents == nil {
+		s.dependents = make(map[K]map[K]struct{})
+	}
+	for _, dep := range deps {
+		if dep == key {
+			continue
+		}
+		keys, ok := s.dependents[dep]
+		if !ok {
+			keys = make(map[K]struct{})
+			s.dependents[dep] = keys
+		}
+		if _, ok := keys[key]; !ok {
+			keys[key] = struct{}{}
+			ent.deps = append(ent.deps, dep)
+		}
+	}
+	return true
+}
+
+// undepend drops the dependencies of ent.
+func (s *SLRU[K, V]) undepend(ent *entry[K, V]) {
+	for _, dep := range ent.deps {
+		keys := s.dependents[dep]
+		delete(keys, ent.key)
+		if len(keys) == 0 {
+			delete(s.dependents, dep)
+		}
+	}
+	ent.deps = nil
+}
+
+// cascade removes the dependents of key, which changed.
+func (s *SLRU[K, V]) cascade(key K) {
+	for dependent := range s.dependents[key] {
+		s.remove(dependent)
+	}
+}
+
+// DependOn is SLRU.DependOn on the shard of key, which only tracks the
+// dependencies on the same shard, reporting false if key isn't cached or
+// any of deps is on another shard. Place related keys on the same shard
+// with a hash such as FieldHash.
+func (c *Sharded[K, V]) DependOn(key K, deps ...K) (ok bool) {
+	s := c.shard(key)
+	local := make([]K, 0, len(deps))
+	for _, dep := range deps {
+		if c.shard(dep) == s {
+			local = append(local, dep)
+		}
+	}
+	return s.DependOn(key, local...) && len(local) == len(deps)
+}
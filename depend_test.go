@@ -0,0 +1,68 @@
+package slru
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDependOn(t *testing.T) {
+	cache := newSLRU[string, int](100)
+	cache.Set("user", 1)
+	cache.Set("profile", 2)
+	cache.Set("page", 3)
+	require.True(t, cache.DependOn("profile", "user"))
+	require.True(t, cache.DependOn("page", "profile", "user"))
+	require.False(t, cache.DependOn("missing", "user"))
+
+	// updates cascade through dependents of dependents
+	cache.Set("user", 10)
+	require.True(t, cache.Contains("user"))
+	require.False(t, cache.Contains("profile"))
+	require.False(t, cache.Contains("page"))
+	require.NoError(t, cache.Verify())
+	require.Empty(t, cache.dependents)
+
+	cache.Set("profile", 2)
+	cache.DependOn("profile", "user")
+	cache.Remove("user")
+	require.False(t, cache.Contains("profile"))
+}
+
+func TestDependOnCycle(t *testing.T) {
+	cache := newSLRU[string, int](100)
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Set("c", 3)
+	cache.DependOn("a", "b")
+	cache.DependOn("b", "a")
+	require.Equal(t, 1, cache.PurgeFunc(func(key string, _ int) bool { return key == "a" }))
+	require.Equal(t, []string{"c"}, cache.Keys())
+	require.NoError(t, cache.Verify())
+}
+
+func TestDependOnEviction(t *testing.T) {
+	cache := newSLRU[int, int](10)
+	cache.Set(0, 0)
+	cache.Set(1, 1)
+	cache.Get(1)
+	cache.DependOn(1, 0)
+	for i := 2; i < 20; i++ {
+		cache.Set(i, i)
+	}
+	require.False(t, cache.Contains(0))
+	require.True(t, cache.Contains(1))
+
+	cache.Remove(1)
+	require.Empty(t, cache.dependents)
+}
+
+func TestDependOnSharded(t *testing.T) {
+	cache := NewSharded[string, int](1000, 4, FieldHash(func(key string) byte { return key[0] }))
+	cache.Set("a:base", 1)
+	cache.Set("a:derived", 2)
+	cache.Set("b:other", 3)
+	require.True(t, cache.DependOn("a:derived", "a:base"))
+	cache.Remove("a:base")
+	require.False(t, cache.Contains("a:derived"))
+}
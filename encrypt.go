@@ -0,0 +1,63 @@
+package slru
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+// ErrDecrypt is returned by EncryptedCodec for data that fails to open,
+// being corrupt, tampered with or sealed under another key.
+var ErrDecrypt = errors.New("slru: decrypting value")
+
+// EncryptedCodec is a Codec sealing the encodings of Codec with AEAD, such
+// as the AES-GCM of NewAESGCM, each under a fresh random nonce prefixed to
+// it, so encoded values holding personal data are never stored or spilled
+// in plaintext:
+//
+//	aead, err := slru.NewAESGCM(key)
+//	cache := slru.NewSerialized[string, User](1<<20, slru.EncryptedCodec[User]{Codec: slru.JSONCodec[User]{}, AEAD: aead})
+//
+// Rotating keys is up to the caller, by decoding with the old codec and
+// encoding with the new one.
+type EncryptedCodec[V any] struct {
+	Codec Codec[V]
+	AEAD  cipher.AEAD
+}
+
+// NewAESGCM returns AES-GCM under key, of 16, 24 or 32 bytes for AES-128,
+// AES-192 or AES-256.
+func NewAESGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (c EncryptedCodec[V]) Encode(value V) ([]byte, error) {
+	plain, err := c.Codec.Encode(value)
+	if err != nil {
+		return nil, err
+	}
+	n := c.AEAD.NonceSize()
+	data := make([]byte, n, n+len(plain)+c.AEAD.Overhead())
+	if _, err := rand.Read(data); err != nil {
+		return nil, err
+	}
+	return c.AEAD.Seal(data, data, plain, nil), nil
+}
+
+func (c EncryptedCodec[V]) Decode(data []byte) (value V, err error) {
+	n := c.AEAD.NonceSize()
+	if len(data) < n {
+		return value, fmt.Errorf("%w: %d bytes is shorter than a nonce", ErrDecrypt, len(data))
+	}
+	plain, err := c.AEAD.Open(nil, data[:n], data[n:], nil)
+	if err != nil {
+		return value, fmt.Errorf("%w: %w", ErrDecrypt, err)
+	}
+	return c.Codec.Decode(plain)
+}
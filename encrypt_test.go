@@ -0,0 +1,50 @@
+package slru
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptedCodec(t *testing.T) {
+	aead, err := NewAESGCM(bytes.Repeat([]byte{1}, 32))
+	require.NoError(t, err)
+	codec := EncryptedCodec[string]{Codec: JSONCodec[string]{}, AEAD: aead}
+
+	a, err := codec.Encode("alice@example.com")
+	require.NoError(t, err)
+	b, err := codec.Encode("alice@example.com")
+	require.NoError(t, err)
+	require.NotEqual(t, a, b)
+	require.NotContains(t, string(a), "alice")
+
+	v, err := codec.Decode(a)
+	require.NoError(t, err)
+	require.Equal(t, "alice@example.com", v)
+
+	a[len(a)-1] ^= 1
+	_, err = codec.Decode(a)
+	require.ErrorIs(t, err, ErrDecrypt)
+	_, err = codec.Decode(a[:4])
+	require.ErrorIs(t, err, ErrDecrypt)
+
+	other, err := NewAESGCM(bytes.Repeat([]byte{2}, 32))
+	require.NoError(t, err)
+	_, err = EncryptedCodec[string]{Codec: JSONCodec[string]{}, AEAD: other}.Decode(b)
+	require.ErrorIs(t, err, ErrDecrypt)
+
+	_, err = NewAESGCM([]byte("short"))
+	require.Error(t, err)
+}
+
+func TestEncryptedSerialized(t *testing.T) {
+	aead, err := NewAESGCM(bytes.Repeat([]byte{1}, 16))
+	require.NoError(t, err)
+	cache := NewSerialized[string, string](1<<10, EncryptedCodec[string]{Codec: GobCodec[string]{}, AEAD: aead})
+	require.NoError(t, cache.Set("a", "secret"))
+	v, ok, err := cache.Get("a")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "secret", v)
+}
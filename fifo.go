@@ -0,0 +1,70 @@
+package slru
+
+import "github.com/hey-kong/slru/list"
+
+// NewFIFO creates a cache of up to size entries evicted in insertion order
+// regardless of hits, a baseline for comparing policies that also suits
+// workloads where reordering on hits isn't worth its cost.
+func NewFIFO[K comparable, V any](size int, opts ...PolicyOption[K, V]) *Policy[K, V] {
+	return newPolicy[K, V](newFIFO[K](size), opts...)
+}
+
+// fifo is the FIFO replacer, with the oldest key at the back of the queue.
+type fifo[K comparable] struct {
+	size  int
+	queue *list.List
+	keyed map[K]*list.Element
+}
+
+func newFIFO[K comparable](size int) *fifo[K] {
+	f := &fifo[K]{size: max(size, 0)}
+	f.reset()
+	return f
+}
+
+func (f *fifo[K]) reset() {
+	f.queue = list.New()
+	f.keyed = make(map[K]*list.Element)
+}
+
+func (f *fifo[K]) add(key K, evict func(key K)) {
+	if f.size < 1 {
+		evict(key)
+		return
+	}
+	for f.queue.Len() >= f.size {
+		f.evict(evict)
+	}
+	f.keyed[key] = f.queue.PushFront(key)
+}
+
+func (f *fifo[K]) evict(evict func(key K)) {
+	key := f.queue.Remove(f.queue.Back()).(K)
+	delete(f.keyed, key)
+	evict(key)
+}
+
+func (f *fifo[K]) hit(key K) {}
+
+func (f *fifo[K]) remove(key K) {
+	if e, ok := f.keyed[key]; ok {
+		f.queue.Remove(e)
+		delete(f.keyed, key)
+	}
+}
+
+func (f *fifo[K]) resize(size int, evict func(key K)) {
+	f.size = max(size, 0)
+	for f.queue.Len() > f.size {
+		f.evict(evict)
+	}
+}
+
+// keys returns the resident keys from the oldest.
+func (f *fifo[K]) keys() []K {
+	keys := make([]K, 0, f.queue.Len())
+	for e := f.queue.Back(); e != nil; e = e.Prev() {
+		keys = append(keys, e.Value.(K))
+	}
+	return keys
+}
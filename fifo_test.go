@@ -0,0 +1,24 @@
+package slru
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFIFO(t *testing.T) {
+	testPolicy(t, NewFIFO[int, int])
+}
+
+func TestFIFOIgnoresHits(t *testing.T) {
+	cache := NewFIFO[int, int](3)
+	for i := 0; i < 3; i++ {
+		cache.Set(i, i)
+	}
+	cache.Get(0)
+	cache.Set(1, 10)
+	cache.Set(3, 3)
+	require.Equal(t, []int{1, 2, 3}, cache.Keys())
+	cache.Set(4, 4)
+	require.Equal(t, []int{2, 3, 4}, cache.Keys())
+}
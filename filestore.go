@@ -0,0 +1,156 @@
+package slru
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// FileStore is a Store keeping each entry in a file of a directory, named
+// after the hash of its encoded key, such as the overflow store of
+// WithOverflow:
+//
+//	store, err := slru.NewFileStore(dir, slru.JSONCodec[string]{}, slru.GobCodec[slru.Spilled[User]]{})
+//	cache := slru.New(1000, slru.WithOverflow[string, User](store, 10000))
+//
+// Wrap the value codec in an EncryptedCodec to keep the values off the disk
+// in plaintext. A failing Set drops the entry and a failing Get misses;
+// Err returns their errors. Files are replaced atomically, so concurrent
+// readers never see a partial write.
+type FileStore[K comparable, V any] struct {
+	dir    string
+	keys   Codec[K]
+	values Codec[V]
+
+	lock sync.Mutex
+	err  error
+}
+
+// NewFileStore returns a FileStore in dir, created if missing, encoding
+// keys and values with the codecs keys and values. It removes the entry
+// files left in dir by an earlier FileStore, as the cache that spilled them
+// no longer knows their keys; other files stay.
+func NewFileStore[K comparable, V any](dir string, keys Codec[K], values Codec[V]) (*FileStore[K, V], error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		if name := file.Name(); file.Type().IsRegular() && (entryFile(name) || strings.HasPrefix(name, ".tmp-")) {
+			if err := os.Remove(filepath.Join(dir, name)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return nil, err
+			}
+		}
+	}
+	return &FileStore[K, V]{dir: dir, keys: keys, values: values}, nil
+}
+
+// entryFile tells whether name is that of an entry file: a hex SHA-256.
+func entryFile(name string) bool {
+	if len(name) != 2*sha256.Size {
+		return false
+	}
+	_, err := hex.DecodeString(name)
+	return err == nil
+}
+
+// path returns the path of the file of key.
+func (f *FileStore[K, V]) path(key K) (string, error) {
+	data, err := f.keys.Encode(key)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return filepath.Join(f.dir, hex.EncodeToString(sum[:])), nil
+}
+
+func (f *FileStore[K, V]) Get(key K) (value V, ok bool) {
+	path, err := f.path(key)
+	if err != nil {
+		f.fail(err)
+		return value, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			f.fail(err)
+		}
+		return value, false
+	}
+	if value, err = f.values.Decode(data); err != nil {
+		f.fail(err)
+		return value, false
+	}
+	return value, true
+}
+
+func (f *FileStore[K, V]) Set(key K, value V) {
+	if err := f.set(key, value); err != nil {
+		f.fail(err)
+	}
+}
+
+func (f *FileStore[K, V]) set(key K, value V) error {
+	path, err := f.path(key)
+	if err != nil {
+		return err
+	}
+	data, err := f.values.Encode(value)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(f.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (f *FileStore[K, V]) Remove(key K) (present bool) {
+	path, err := f.path(key)
+	if err != nil {
+		f.fail(err)
+		return false
+	}
+	err = os.Remove(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		f.fail(err)
+	}
+	return err == nil
+}
+
+// Err returns the first error met since the last call to Err.
+func (f *FileStore[K, V]) Err() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	err := f.err
+	f.err = nil
+	return err
+}
+
+// fail records err for Err.
+func (f *FileStore[K, V]) fail(err error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.err == nil {
+		f.err = err
+	}
+}
@@ -0,0 +1,59 @@
+package slru
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "spill")
+	store, err := NewFileStore[string, int](dir, JSONCodec[string]{}, JSONCodec[int]{})
+	require.NoError(t, err)
+
+	_, ok := store.Get("a")
+	require.False(t, ok)
+	store.Set("a", 1)
+	store.Set("b", 2)
+	store.Set("a", 3)
+	v, ok := store.Get("a")
+	require.True(t, ok)
+	require.Equal(t, 3, v)
+
+	require.True(t, store.Remove("a"))
+	require.False(t, store.Remove("a"))
+	_, ok = store.Get("a")
+	require.False(t, ok)
+	require.NoError(t, store.Err())
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, files[0].Name()), []byte("garbage"), 0o600))
+	_, ok = store.Get("b")
+	require.False(t, ok)
+	require.Error(t, store.Err())
+	require.NoError(t, store.Err())
+}
+
+func TestFileStoreRestart(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileStore[string, int](dir, JSONCodec[string]{}, JSONCodec[int]{})
+	require.NoError(t, err)
+	store.Set("a", 1)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".tmp-1"), nil, 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes"), nil, 0o600))
+
+	// a new store drops the entries of the old one, but not other files
+	store, err = NewFileStore[string, int](dir, JSONCodec[string]{}, JSONCodec[int]{})
+	require.NoError(t, err)
+	_, ok := store.Get("a")
+	require.False(t, ok)
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	require.Equal(t, "notes", files[0].Name())
+}
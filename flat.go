@@ -0,0 +1,178 @@
+package slru
+
+import (
+	"sync"
+
+	"github.com/hey-kong/slru/list"
+)
+
+// flatEntry is the key and value of a Flat entry.
+type flatEntry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// flatRef locates an entry of a Flat: its handle in the segment, which is
+// protected or probation.
+type flatRef struct {
+	handle    int32
+	protected bool
+}
+
+// Flat is an unweighted SLRU whose segments are slice-backed lists, with
+// entries linked by index and indexed by handle. Besides whatever K and V
+// hold, it has no per-entry pointers for the garbage collector to trace,
+// unlike SLRU's linked list elements, which makes it suited to caches of
+// many millions of small entries.
+//
+// Its size is fixed and it lacks weights, TTLs and the other options of
+// SLRU.
+type Flat[K comparable, V any] struct {
+	lock      sync.Mutex
+	items     map[K]flatRef
+	probation *list.Slice[flatEntry[K, V]]
+	protected *list.Slice[flatEntry[K, V]]
+	// probationSize and protectedSize are the entry limits of the segments.
+	probationSize int
+	protectedSize int
+}
+
+// NewFlat creates a Flat holding up to size entries, split between the
+// segments like New with DefaultProbationRatio. All slots are allocated
+// up front.
+func NewFlat[K comparable, V any](size int) *Flat[K, V] {
+	probationSize := int(DefaultProbationRatio * float64(size))
+	if probationSize < 1 && size > 0 {
+		probationSize = 1
+	}
+	c := &Flat[K, V]{
+		probationSize: probationSize,
+		protectedSize: size - probationSize,
+	}
+	c.reset()
+	return c
+}
+
+func (c *Flat[K, V]) reset() {
+	c.items = make(map[K]flatRef, c.probationSize+c.protectedSize)
+	c.probation = list.NewSlice[flatEntry[K, V]](c.probationSize)
+	c.protected = list.NewSlice[flatEntry[K, V]](c.protectedSize)
+}
+
+func (c *Flat[K, V]) Set(key K, value V) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if ref, ok := c.items[key]; ok {
+		c.segment(ref).SetValue(int(ref.handle), flatEntry[K, V]{key, value})
+		c.promote(key, ref)
+		return
+	}
+	if c.probationSize < 1 {
+		return
+	}
+	if c.probation.Len() >= c.probationSize {
+		c.evict(c.probation)
+	}
+	c.items[key] = flatRef{handle: int32(c.probation.PushFront(flatEntry[K, V]{key, value}))}
+}
+
+func (c *Flat[K, V]) Get(key K) (value V, ok bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	ref, ok := c.items[key]
+	if !ok {
+		return value, false
+	}
+	value = c.segment(ref).Value(int(ref.handle)).value
+	c.promote(key, ref)
+	return value, true
+}
+
+func (c *Flat[K, V]) Peek(key K) (value V, ok bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if ref, ok := c.items[key]; ok {
+		return c.segment(ref).Value(int(ref.handle)).value, true
+	}
+	return value, false
+}
+
+func (c *Flat[K, V]) Contains(key K) (ok bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	_, ok = c.items[key]
+	return ok
+}
+
+func (c *Flat[K, V]) Remove(key K) (present bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	ref, ok := c.items[key]
+	if !ok {
+		return false
+	}
+	c.segment(ref).Remove(int(ref.handle))
+	delete(c.items, key)
+	return true
+}
+
+// Keys returns the keys in cache, from the probation tail to the protected
+// head.
+func (c *Flat[K, V]) Keys() []K {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	keys := make([]K, 0, len(c.items))
+	for _, l := range []*list.Slice[flatEntry[K, V]]{c.probation, c.protected} {
+		for h := l.Back(); h != list.Nil; h = l.Prev(h) {
+			keys = append(keys, l.Value(h).key)
+		}
+	}
+	return keys
+}
+
+func (c *Flat[K, V]) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return len(c.items)
+}
+
+func (c *Flat[K, V]) Purge() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.reset()
+}
+
+// promote moves a hit entry to the head of protected, evicting the
+// protected tail if it overflows, like SLRU.
+func (c *Flat[K, V]) promote(key K, ref flatRef) {
+	l := c.segment(ref)
+	if ref.protected || c.protectedSize < 1 {
+		l.MoveToFront(int(ref.handle))
+		return
+	}
+	ent := l.Remove(int(ref.handle))
+	if c.protected.Len() >= c.protectedSize {
+		c.evict(c.protected)
+	}
+	c.items[key] = flatRef{handle: int32(c.protected.PushFront(ent)), protected: true}
+}
+
+// evict removes the tail of l.
+func (c *Flat[K, V]) evict(l *list.Slice[flatEntry[K, V]]) {
+	delete(c.items, l.Remove(l.Back()).key)
+}
+
+func (c *Flat[K, V]) segment(ref flatRef) *list.Slice[flatEntry[K, V]] {
+	if ref.protected {
+		return c.protected
+	}
+	return c.probation
+}
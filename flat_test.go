@@ -0,0 +1,53 @@
+package slru
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlat(t *testing.T) {
+	cache := NewFlat[string, int](10)
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Get("a")
+	cache.Set("c", 3)
+	require.Equal(t, []string{"b", "c", "a"}, cache.Keys())
+
+	v, ok := cache.Peek("a")
+	require.True(t, ok)
+	require.Equal(t, 1, v)
+	require.True(t, cache.Remove("a"))
+	require.False(t, cache.Contains("a"))
+	require.Equal(t, 2, cache.Len())
+
+	cache.Purge()
+	require.Zero(t, cache.Len())
+	cache.Set("d", 4)
+	require.Equal(t, []string{"d"}, cache.Keys())
+}
+
+func TestFlatMatchesSLRU(t *testing.T) {
+	for _, size := range []int{1, 2, 10, 50} {
+		flat := NewFlat[int, int](size)
+		ref := newSLRU[int, int](size)
+		r := rand.New(rand.NewPCG(1, uint64(size)))
+		for i := 0; i < 5000; i++ {
+			key := r.IntN(size * 3)
+			switch r.IntN(4) {
+			case 0, 1:
+				v1, ok1 := flat.Get(key)
+				v2, ok2 := ref.Get(key)
+				require.Equal(t, ok2, ok1)
+				require.Equal(t, v2, v1)
+			case 2:
+				flat.Set(key, i)
+				ref.Set(key, i)
+			case 3:
+				require.Equal(t, ref.Remove(key), flat.Remove(key))
+			}
+		}
+		require.Equal(t, ref.Keys(), flat.Keys())
+	}
+}
@@ -0,0 +1,245 @@
+// Package fuzz drives caches with operations decoded from fuzzer input and
+// checks them against a shadow model after every operation, so go test
+// -fuzz can explore sequences of Set, Get, Remove, Resize and expirations
+// and report structural corruption as soon as it happens:
+//
+//	func FuzzCache(f *testing.F) {
+//		f.Fuzz(func(t *testing.T, data []byte) {
+//			if err := fuzz.New(8).Run(fuzz.Decode(data)); err != nil {
+//				t.Fatal(err)
+//			}
+//		})
+//	}
+//
+// The model tracks the last value written to each key and its deadline. A
+// cache may have evicted any key, but it must never return a value other
+// than the last one written, nor a removed or expired key, and it must pass
+// Verify and hold no more than its capacity.
+package fuzz
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hey-kong/slru"
+)
+
+// Kind is the kind of an operation.
+type Kind uint8
+
+const (
+	Set Kind = iota
+	SetWithTTL
+	Add
+	Get
+	Peek
+	Remove
+	Resize
+	Advance
+	Purge
+	numKinds
+)
+
+var kindNames = [...]string{"Set", "SetWithTTL", "Add", "Get", "Peek", "Remove", "Resize", "Advance", "Purge"}
+
+func (k Kind) String() string {
+	if k < numKinds {
+		return kindNames[k]
+	}
+	return fmt.Sprintf("Kind(%d)", k)
+}
+
+// Keys is the number of distinct keys operations use, few enough that
+// operations keep hitting the same entries.
+const Keys = 32
+
+// Op is a cache operation. Arg is the value of writes, the TTL of
+// SetWithTTL and the duration of Advance in seconds, and the size of
+// Resize.
+type Op struct {
+	Kind Kind
+	Key  int
+	Arg  int
+}
+
+func (op Op) String() string {
+	return fmt.Sprintf("%v(%d, %d)", op.Kind, op.Key, op.Arg)
+}
+
+// Decode returns the operations encoded by data, three bytes each: kind,
+// key and argument. Trailing bytes are ignored. Written values are the
+// positions of their operations, so every write is distinct.
+func Decode(data []byte) []Op {
+	ops := make([]Op, 0, len(data)/3)
+	for i := 0; i+3 <= len(data); i += 3 {
+		op := Op{Kind: Kind(data[i] % byte(numKinds)), Key: int(data[i+1] % Keys), Arg: int(data[i+2])}
+		switch op.Kind {
+		case Set, Add:
+			op.Arg = len(ops)
+		case SetWithTTL, Advance:
+			op.Arg %= 8
+		case Resize:
+			op.Arg = op.Arg%Keys + 1
+		}
+		ops = append(ops, op)
+	}
+	return ops
+}
+
+// Encode returns the data Decode turns into ops, for seed corpora. Values
+// of writes are not encoded.
+func Encode(ops ...Op) []byte {
+	data := make([]byte, 0, 3*len(ops))
+	for _, op := range ops {
+		data = append(data, byte(op.Kind), byte(op.Key), byte(op.Arg))
+	}
+	return data
+}
+
+// cache is what a Harness drives: a Cache with Verify, such as an SLRU or
+// a Sharded.
+type cache interface {
+	slru.Cache[int, int]
+	Verify() error
+}
+
+// modelEntry is the last write of a key.
+type modelEntry struct {
+	value    int
+	expireAt time.Time
+}
+
+// Harness applies operations to a cache and checks it against the model.
+// It is not safe for concurrent use.
+type Harness struct {
+	cache cache
+	size  int
+	now   time.Time
+	model map[int]modelEntry
+}
+
+// New returns a Harness over slru.New(size, opts...), with a clock of its
+// own advanced by Advance operations. opts must not replace the clock.
+func New(size int, opts ...slru.Option[int, int]) *Harness {
+	h := &Harness{size: size, now: time.Unix(0, 0), model: make(map[int]modelEntry)}
+	opts = append(opts, slru.WithClock[int, int](func() time.Time { return h.now }))
+	h.cache = slru.New(size, opts...).(cache)
+	return h
+}
+
+// Cache returns the cache driven by h.
+func (h *Harness) Cache() slru.Cache[int, int] {
+	return h.cache
+}
+
+// Run applies ops in order, returning the first violation found.
+func (h *Harness) Run(ops []Op) error {
+	for i, op := range ops {
+		if err := h.Apply(op); err != nil {
+			return fmt.Errorf("fuzz: op %d %v: %w", i, op, err)
+		}
+	}
+	return nil
+}
+
+// Apply applies op and checks the invariants of the cache.
+func (h *Harness) Apply(op Op) error {
+	if err := h.apply(op); err != nil {
+		return err
+	}
+	return h.check()
+}
+
+func (h *Harness) apply(op Op) error {
+	switch op.Kind {
+	case Set:
+		h.cache.Set(op.Key, op.Arg)
+		h.model[op.Key] = modelEntry{value: op.Arg}
+	case SetWithTTL:
+		ttl := time.Duration(op.Arg+1) * time.Second
+		h.cache.SetWithTTL(op.Key, op.Arg, ttl)
+		h.model[op.Key] = modelEntry{value: op.Arg, expireAt: h.now.Add(ttl)}
+	case Add:
+		h.cache.Add(op.Key, op.Arg)
+		h.model[op.Key] = modelEntry{value: op.Arg}
+	case Get:
+		value, ok := h.cache.Get(op.Key)
+		return h.checkValue(op.Key, value, ok)
+	case Peek:
+		value, ok := h.cache.Peek(op.Key)
+		return h.checkValue(op.Key, value, ok)
+	case Remove:
+		if h.cache.Remove(op.Key) && !h.present(op.Key) {
+			return errors.New("removed a key never written")
+		}
+		delete(h.model, op.Key)
+	case Resize:
+		h.size = op.Arg
+		h.cache.Resize(op.Arg)
+	case Advance:
+		h.now = h.now.Add(time.Duration(op.Arg) * time.Second)
+	case Purge:
+		h.cache.Purge()
+		clear(h.model)
+	}
+	return nil
+}
+
+// present reports whether key was written and not removed since.
+func (h *Harness) present(key int) bool {
+	_, ok := h.model[key]
+	return ok
+}
+
+// live reports whether key is present and not expired.
+func (h *Harness) live(key int) bool {
+	e, ok := h.model[key]
+	return ok && (e.expireAt.IsZero() || h.now.Before(e.expireAt))
+}
+
+// checkValue checks a lookup of key against the model.
+func (h *Harness) checkValue(key, value int, ok bool) error {
+	if !ok {
+		return nil
+	}
+	if !h.live(key) {
+		return fmt.Errorf("hit key %d, which is removed or expired", key)
+	}
+	if want := h.model[key].value; value != want {
+		return fmt.Errorf("key %d holds %d, last written %d", key, value, want)
+	}
+	return nil
+}
+
+// capacity returns the most entries the cache may hold: each shard of a
+// Sharded holds an equal share of the size, rounded up.
+func (h *Harness) capacity() int {
+	size := max(h.size, 1)
+	if sharded, ok := h.cache.(interface{ Shards() int }); ok {
+		n := sharded.Shards()
+		return n * ((size + n - 1) / n)
+	}
+	return size
+}
+
+// check checks the structure of the cache and its contents against the
+// model.
+func (h *Harness) check() error {
+	if err := h.cache.Verify(); err != nil {
+		return err
+	}
+	if n := h.cache.Len(); n > h.capacity() {
+		return fmt.Errorf("holds %d entries, more than its capacity of %d", n, h.capacity())
+	}
+	for _, key := range h.cache.Keys() {
+		if !h.present(key) {
+			return fmt.Errorf("holds key %d, which is removed", key)
+		}
+		value, ok := h.cache.Peek(key)
+		if err := h.checkValue(key, value, ok); err != nil {
+			return err
+		}
+	}
+	return nil
+}
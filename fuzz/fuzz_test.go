@@ -0,0 +1,53 @@
+package fuzz
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hey-kong/slru"
+)
+
+// seeds are corpus entries covering each operation.
+var seeds = [][]byte{
+	Encode(Op{Set, 1, 0}, Op{Get, 1, 0}, Op{Get, 1, 0}, Op{Remove, 1, 0}, Op{Get, 1, 0}),
+	Encode(Op{SetWithTTL, 1, 2}, Op{Advance, 0, 2}, Op{Peek, 1, 0}, Op{Advance, 0, 1}, Op{Get, 1, 0}, Op{Add, 1, 0}),
+	Encode(Op{Set, 1, 0}, Op{Set, 2, 0}, Op{Set, 3, 0}, Op{Get, 1, 0}, Op{Resize, 0, 1}, Op{Set, 4, 0}, Op{Purge, 0, 0}),
+}
+
+func FuzzSLRU(f *testing.F) {
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if err := New(8).Run(Decode(data)); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func FuzzSharded(f *testing.F) {
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if err := New(8, slru.WithShards[int, int](2, nil), slru.WithTTLJitter[int, int](0.5)).Run(Decode(data)); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestDecode(t *testing.T) {
+	ops := Decode(append(Encode(Op{Set, 33, 7}, Op{SetWithTTL, 2, 9}, Op{Resize, 0, 40}, Op{Kind(numKinds + Get), 1, 0}), 1))
+	require.Equal(t, []Op{{Set, 1, 0}, {SetWithTTL, 2, 1}, {Resize, 0, 9}, {Get, 1, 0}}, ops)
+	require.Equal(t, "SetWithTTL(2, 1)", ops[1].String())
+}
+
+func TestHarnessCatchesViolations(t *testing.T) {
+	h := New(8)
+	require.NoError(t, h.Run([]Op{{Set, 1, 5}, {Get, 1, 0}}))
+
+	// a write behind the back of the model looks like a stale value
+	h.Cache().Set(1, 6)
+	require.ErrorContains(t, h.Run([]Op{{Get, 1, 0}}), "last written 5")
+}
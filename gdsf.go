@@ -0,0 +1,152 @@
+package slru
+
+import (
+	"container/heap"
+	"slices"
+)
+
+// NewGDSF creates a cache of up to size entries replaced by
+// Greedy-Dual-Size-Frequency, for caches fronting backends whose lookups
+// vary in cost. Entries are ranked by their hit count times their cost per
+// unit of size, as returned by cost for their current value, plus the age
+// of the cache when they were last hit, where the age is the rank of the
+// last victim: cheap, large and rarely hit entries go first, and entries
+// that stopped being hit eventually give way to newer ones.
+func NewGDSF[K comparable, V any](size int, cost func(key K, value V) (cost float64, size int), opts ...PolicyOption[K, V]) *Policy[K, V] {
+	g := newGDSF[K](size)
+	p := newPolicy[K, V](g, opts...)
+	g.score = func(key K) float64 {
+		c, n := cost(key, p.items[key].value)
+		return c / float64(max(n, 1))
+	}
+	return p
+}
+
+// gdsfItem is a resident key of GDSF and its position in the heap.
+type gdsfItem[K comparable] struct {
+	key      K
+	hits     uint64
+	priority float64
+	// seq orders accesses, breaking ties in favour of the most recent.
+	seq   uint64
+	index int
+}
+
+// gdsfHeap is a min-heap of items by priority, then by access order.
+type gdsfHeap[K comparable] []*gdsfItem[K]
+
+func (h gdsfHeap[K]) Len() int { return len(h) }
+
+func (h gdsfHeap[K]) Less(i, j int) bool { return gdsfLess(h[i], h[j]) }
+
+func (h gdsfHeap[K]) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *gdsfHeap[K]) Push(x any) {
+	item := x.(*gdsfItem[K])
+	item.index = len(*h)
+	*h = append(*h, item)
+}
+
+func (h *gdsfHeap[K]) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return item
+}
+
+func gdsfLess[K comparable](a, b *gdsfItem[K]) bool {
+	if a.priority != b.priority {
+		return a.priority < b.priority
+	}
+	return a.seq < b.seq
+}
+
+// gdsf is the GDSF replacer. age is the priority of the last victim, and
+// score returns the cost per unit of size of a resident key.
+type gdsf[K comparable] struct {
+	size  int
+	age   float64
+	seq   uint64
+	heap  gdsfHeap[K]
+	items map[K]*gdsfItem[K]
+	score func(key K) float64
+}
+
+func newGDSF[K comparable](size int) *gdsf[K] {
+	g := &gdsf[K]{size: max(size, 0)}
+	g.reset()
+	return g
+}
+
+func (g *gdsf[K]) reset() {
+	g.age, g.seq = 0, 0
+	g.heap = nil
+	g.items = make(map[K]*gdsfItem[K])
+}
+
+func (g *gdsf[K]) add(key K, evict func(key K)) {
+	if g.size < 1 {
+		evict(key)
+		return
+	}
+	for len(g.heap) >= g.size {
+		g.evict(evict)
+	}
+	g.seq++
+	item := &gdsfItem[K]{key: key, hits: 1, priority: g.age + g.score(key), seq: g.seq}
+	g.items[key] = item
+	heap.Push(&g.heap, item)
+}
+
+func (g *gdsf[K]) evict(evict func(key K)) {
+	item := heap.Pop(&g.heap).(*gdsfItem[K])
+	delete(g.items, item.key)
+	g.age = item.priority
+	evict(item.key)
+}
+
+// hit records a hit or an update, scoring the current value of key.
+func (g *gdsf[K]) hit(key K) {
+	if item, ok := g.items[key]; ok {
+		g.seq++
+		item.hits++
+		item.priority = g.age + float64(item.hits)*g.score(key)
+		item.seq = g.seq
+		heap.Fix(&g.heap, item.index)
+	}
+}
+
+func (g *gdsf[K]) remove(key K) {
+	if item, ok := g.items[key]; ok {
+		heap.Remove(&g.heap, item.index)
+		delete(g.items, key)
+	}
+}
+
+func (g *gdsf[K]) resize(size int, evict func(key K)) {
+	g.size = max(size, 0)
+	for len(g.heap) > g.size {
+		g.evict(evict)
+	}
+}
+
+// keys returns the resident keys from the lowest priority.
+func (g *gdsf[K]) keys() []K {
+	items := slices.Clone(g.heap)
+	slices.SortFunc(items, func(a, b *gdsfItem[K]) int {
+		if gdsfLess(a, b) {
+			return -1
+		}
+		return 1
+	})
+	keys := make([]K, len(items))
+	for i, item := range items {
+		keys[i] = item.key
+	}
+	return keys
+}
@@ -0,0 +1,53 @@
+package slru
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// unitCost costs every entry 1 per unit of size, for testPolicy.
+func unitCost(key, value int) (cost float64, size int) {
+	return 1, 1
+}
+
+func TestGDSF(t *testing.T) {
+	testPolicy(t, func(size int, opts ...PolicyOption[int, int]) *Policy[int, int] {
+		return NewGDSF(size, unitCost, opts...)
+	})
+}
+
+func TestGDSFKeepsCostlyEntries(t *testing.T) {
+	// values are costs, keys below 10 are large
+	cache := NewGDSF(4, func(key, value int) (cost float64, size int) {
+		if key < 10 {
+			return float64(value), 10
+		}
+		return float64(value), 1
+	})
+	cache.Set(0, 100) // costly and large: 10 per unit
+	cache.Set(1, 10)  // cheap and large: 1 per unit
+	cache.Set(10, 5)  // cheap and small: 5 per unit
+	cache.Set(11, 1)  // cheapest: 1 per unit, but more recent than key 1
+	cache.Set(12, 50)
+	require.False(t, cache.Contains(1))
+	cache.Set(13, 50)
+	require.False(t, cache.Contains(11))
+	require.True(t, cache.Contains(0))
+	require.True(t, cache.Contains(10))
+	require.Equal(t, []int{10, 0, 12, 13}, cache.Keys())
+}
+
+func TestGDSFAges(t *testing.T) {
+	cache := NewGDSF(2, unitCost)
+	cache.Set(1, 1)
+	for range 5 {
+		cache.Get(1)
+	}
+	// each victim raises the age until newcomers outrank the stale key 1
+	for key := 2; key < 20; key++ {
+		cache.Set(key, key)
+		cache.Get(key)
+	}
+	require.False(t, cache.Contains(1))
+}
@@ -0,0 +1,42 @@
+// Package groupcache backs a groupcache.Getter with an SLRU, so hot keys are
+// served from a scan-resistant local cache before reaching the backend.
+//
+// groupcache does not allow replacing the LRU of a Group, so the SLRU sits
+// in front of the Group's Getter instead:
+//
+//	cache := slru.New[string, []byte](4096)
+//	group := groupcache.NewGroup("name", 0, slrugc.NewGetter(cache, backend))
+package groupcache
+
+import (
+	"context"
+
+	"github.com/golang/groupcache"
+
+	"github.com/hey-kong/slru"
+)
+
+// Getter serves keys from an SLRU, falling back to the wrapped Getter on miss.
+type Getter struct {
+	cache  slru.Cache[string, []byte]
+	getter groupcache.Getter
+}
+
+// NewGetter returns a Getter that caches the results of getter in cache.
+func NewGetter(cache slru.Cache[string, []byte], getter groupcache.Getter) *Getter {
+	return &Getter{cache: cache, getter: getter}
+}
+
+// Get implements groupcache.Getter.
+func (g *Getter) Get(ctx context.Context, key string, dest groupcache.Sink) error {
+	if b, ok := g.cache.Get(key); ok {
+		return dest.SetBytes(b)
+	}
+
+	var b []byte
+	if err := g.getter.Get(ctx, key, groupcache.AllocatingByteSliceSink(&b)); err != nil {
+		return err
+	}
+	g.cache.Set(key, b)
+	return dest.SetBytes(b)
+}
@@ -0,0 +1,27 @@
+package groupcache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/groupcache"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hey-kong/slru"
+)
+
+func TestGetter(t *testing.T) {
+	calls := 0
+	backend := groupcache.GetterFunc(func(ctx context.Context, key string, dest groupcache.Sink) error {
+		calls++
+		return dest.SetString("value of " + key)
+	})
+	g := NewGetter(slru.New[string, []byte](10), backend)
+
+	for i := 0; i < 3; i++ {
+		var s string
+		require.NoError(t, g.Get(context.Background(), "k", groupcache.StringSink(&s)))
+		require.Equal(t, "value of k", s)
+	}
+	require.Equal(t, 1, calls)
+}
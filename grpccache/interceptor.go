@@ -0,0 +1,47 @@
+// Package grpccache provides a gRPC client interceptor that caches the
+// responses of deterministic unary methods in an SLRU.
+package grpccache
+
+import (
+	"context"
+	"crypto/sha256"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/hey-kong/slru"
+)
+
+// UnaryClientInterceptor caches the responses of the methods listed in ttls,
+// keyed by the full method name and a hash of the request, each expiring
+// after the configured duration. Other methods, and requests or replies that
+// are not protocol buffer messages, bypass the cache.
+func UnaryClientInterceptor(cache slru.Cache[string, []byte], ttls map[string]time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ttl, ok := ttls[method]
+		reqMsg, reqOK := req.(proto.Message)
+		replyMsg, replyOK := reply.(proto.Message)
+		if !ok || !reqOK || !replyOK {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		b, err := proto.MarshalOptions{Deterministic: true}.Marshal(reqMsg)
+		if err != nil {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		sum := sha256.Sum256(b)
+		key := method + "/" + string(sum[:])
+
+		if cached, ok := cache.Get(key); ok {
+			return proto.Unmarshal(cached, replyMsg)
+		}
+		if err := invoker(ctx, method, req, reply, cc, opts...); err != nil {
+			return err
+		}
+		if b, err := proto.Marshal(replyMsg); err == nil {
+			cache.SetWithTTL(key, b, ttl)
+		}
+		return nil
+	}
+}
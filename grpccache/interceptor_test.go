@@ -0,0 +1,43 @@
+package grpccache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/hey-kong/slru"
+)
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	calls := 0
+	invoker := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		calls++
+		reply.(*wrapperspb.StringValue).Value = "hello " + req.(*wrapperspb.StringValue).Value
+		return nil
+	}
+	interceptor := UnaryClientInterceptor(slru.New[string, []byte](10), map[string]time.Duration{
+		"/greeter/Hello": time.Minute,
+	})
+
+	call := func(method, name string) string {
+		reply := &wrapperspb.StringValue{}
+		require.NoError(t, interceptor(context.Background(), method, wrapperspb.String(name), reply, nil, invoker))
+		return reply.Value
+	}
+
+	require.Equal(t, "hello a", call("/greeter/Hello", "a"))
+	require.Equal(t, "hello a", call("/greeter/Hello", "a"))
+	require.Equal(t, 1, calls)
+
+	require.Equal(t, "hello b", call("/greeter/Hello", "b"))
+	require.Equal(t, 2, calls)
+
+	// methods without a ttl are never cached
+	call("/greeter/Bye", "a")
+	call("/greeter/Bye", "a")
+	require.Equal(t, 4, calls)
+}
@@ -0,0 +1,92 @@
+package slru
+
+import (
+	"log/slog"
+	"time"
+)
+
+// drainBatch is the number of entries the drain evicts per hold of the
+// cache lock.
+const drainBatch = 256
+
+// WithHardLimit makes the size of the cache a soft limit, the target of
+// eviction, under a hard limit of factor times the size, for each shard of
+// a Sharded: writes only evict synchronously to stay under the hard limit,
+// so bursts overflow the size rather than evicting on every write, while
+// a background drain evicts back down to the size every interval, a batch
+// at a time between which writes proceed. Segment limits overflow by the
+// same factor. A factor of 1 or less keeps the size a hard limit.
+func WithHardLimit[K comparable, V any](factor float64, interval time.Duration) Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		s.hardFactor = factor
+		s.drainInterval = interval
+	}
+}
+
+// limits returns the weights protected and probation may hold before
+// writes evict from them.
+func (s *SLRU[K, V]) limits() (protected, probation int) {
+	if s.hardFactor <= 1 {
+		return s.protectedSize, s.probationLimit()
+	}
+	protected = int(float64(s.protectedSize) * s.hardFactor)
+	if s.total {
+		return protected, int(float64(s.size)*s.hardFactor) - s.protectedWeight
+	}
+	return protected, int(float64(s.probationSize) * s.hardFactor)
+}
+
+// startDrain starts the drain of the overflow, if enabled.
+func (s *SLRU[K, V]) startDrain() {
+	if s.hardFactor <= 1 || s.drainInterval <= 0 {
+		return
+	}
+	s.workers.Add(1)
+	go func() {
+		defer s.workers.Done()
+		ticker := time.NewTicker(s.drainInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.done:
+				return
+			case <-ticker.C:
+				s.maintain(func() { s.drain() })
+			}
+		}
+	}()
+}
+
+// drain evicts the overflow down to the soft limits, returning the number
+// of evicted entries.
+func (s *SLRU[K, V]) drain() (evicted int) {
+	defer s.region("drain")()
+	for {
+		n := s.drainSome()
+		evicted += n
+		if n < drainBatch {
+			break
+		}
+	}
+	if evicted > 0 {
+		s.log(slog.LevelDebug, "slru: drain", "evicted", evicted)
+	}
+	return evicted
+}
+
+// drainSome evicts a batch of the overflow, returning the number of
+// evicted entries.
+func (s *SLRU[K, V]) drainSome() (evicted int) {
+	s.acquire()
+	defer s.unlock()
+
+	for s.protectedWeight > s.protectedSize && evicted < drainBatch {
+		s.evict(s.protected)
+		evicted++
+	}
+	for s.probationWeight > s.probationLimit() && evicted < drainBatch {
+		s.evict(s.probation)
+		evicted++
+	}
+	return evicted
+}
@@ -0,0 +1,45 @@
+package slru
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHardLimit(t *testing.T) {
+	cache := newSLRU[int, int](100, WithHardLimit[int, int](1.5, 0))
+	for i := range 1000 {
+		cache.Set(i, i)
+		cache.Get(i)
+	}
+	for i := range 1000 {
+		cache.Set(-i, i)
+	}
+	require.Equal(t, 150, cache.Len())
+	require.NoError(t, cache.Verify())
+
+	require.Equal(t, 50, cache.drain())
+	require.Equal(t, 100, cache.Len())
+	require.Equal(t, -980, cache.Keys()[0])
+	require.Equal(t, 0, cache.drain())
+	require.NoError(t, cache.Verify())
+}
+
+func TestHardLimitDrain(t *testing.T) {
+	cache := newSLRU[int, int](100, WithHardLimit[int, int](2, time.Millisecond))
+	defer cache.Close()
+	for i := range 1000 {
+		cache.Set(i, i)
+	}
+	require.LessOrEqual(t, cache.Len(), 40)
+	require.Eventually(t, func() bool { return cache.Len() == 20 }, time.Second, time.Millisecond)
+}
+
+func TestHardLimitDisabled(t *testing.T) {
+	cache := newSLRU[int, int](100, WithHardLimit[int, int](1, time.Millisecond))
+	for i := range 1000 {
+		cache.Set(i, i)
+	}
+	require.Equal(t, 20, cache.Len())
+}
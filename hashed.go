@@ -0,0 +1,175 @@
+package slru
+
+import (
+	"context"
+	"time"
+)
+
+// HashedEntry is an entry of the cache underlying HashedKeys, holding the
+// key along with its value.
+type HashedEntry[K, V any] struct {
+	Key   K
+	Value V
+}
+
+// HashedKeys presents a cache keyed by the hashes of keys as a cache of
+// keys of any type, such as []byte or structs holding slices, which are
+// not comparable, given functions hashing and comparing them:
+//
+//	c := slru.NewHashedKeys(slru.New[uint64, slru.HashedEntry[[]byte, int]](1000),
+//		func(key []byte) uint64 { return maphash.Bytes(seed, key) }, bytes.Equal)
+//
+// Keys with the same hash share one entry: writing one displaces the
+// other, as an eviction would, and looking up one never returns the value
+// of the other. With a good 64-bit hash, that only matters in theory.
+type HashedKeys[K, V any] struct {
+	cache Cache[uint64, HashedEntry[K, V]]
+	hash  func(key K) uint64
+	equal func(a, b K) bool
+}
+
+// NewHashedKeys returns HashedKeys over cache, hashing keys with hash and
+// comparing them with equal.
+func NewHashedKeys[K, V any](cache Cache[uint64, HashedEntry[K, V]], hash func(key K) uint64, equal func(a, b K) bool) *HashedKeys[K, V] {
+	return &HashedKeys[K, V]{cache: cache, hash: hash, equal: equal}
+}
+
+// Cache returns the underlying cache.
+func (h *HashedKeys[K, V]) Cache() Cache[uint64, HashedEntry[K, V]] {
+	return h.cache
+}
+
+// lookup returns the value of ent if it holds key.
+func (h *HashedKeys[K, V]) lookup(key K, ent HashedEntry[K, V], ok bool) (value V, found bool) {
+	if !ok || !h.equal(ent.Key, key) {
+		return value, false
+	}
+	return ent.Value, true
+}
+
+func (h *HashedKeys[K, V]) Set(key K, value V) {
+	h.cache.Set(h.hash(key), HashedEntry[K, V]{key, value})
+}
+
+func (h *HashedKeys[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	h.cache.SetWithTTL(h.hash(key), HashedEntry[K, V]{key, value}, ttl)
+}
+
+// Add sets the value for the given key, reporting whether it was newly
+// inserted rather than replacing an existing value.
+func (h *HashedKeys[K, V]) Add(key K, value V) (inserted bool) {
+	h.cache.Update(h.hash(key), func(old HashedEntry[K, V], exists bool) (HashedEntry[K, V], bool) {
+		inserted = !exists || !h.equal(old.Key, key)
+		return HashedEntry[K, V]{key, value}, true
+	})
+	return inserted
+}
+
+// Replace sets the value for the given key only if it is already in cache,
+// reporting whether it was.
+func (h *HashedKeys[K, V]) Replace(key K, value V) (replaced bool) {
+	h.cache.Update(h.hash(key), func(old HashedEntry[K, V], exists bool) (HashedEntry[K, V], bool) {
+		replaced = exists && h.equal(old.Key, key)
+		return HashedEntry[K, V]{key, value}, replaced
+	})
+	return replaced
+}
+
+// Swap sets the value for the given key and returns the previous value, if
+// there was one.
+func (h *HashedKeys[K, V]) Swap(key K, value V) (old V, existed bool) {
+	h.cache.Update(h.hash(key), func(prev HashedEntry[K, V], exists bool) (HashedEntry[K, V], bool) {
+		old, existed = h.lookup(key, prev, exists)
+		return HashedEntry[K, V]{key, value}, true
+	})
+	return old, existed
+}
+
+func (h *HashedKeys[K, V]) Get(key K) (value V, ok bool) {
+	ent, ok := h.cache.Get(h.hash(key))
+	return h.lookup(key, ent, ok)
+}
+
+func (h *HashedKeys[K, V]) Peek(key K) (value V, ok bool) {
+	ent, ok := h.cache.Peek(h.hash(key))
+	return h.lookup(key, ent, ok)
+}
+
+func (h *HashedKeys[K, V]) Contains(key K) (ok bool) {
+	_, ok = h.Peek(key)
+	return ok
+}
+
+func (h *HashedKeys[K, V]) TTL(key K) (ttl time.Duration, ok bool) {
+	if !h.Contains(key) {
+		return 0, false
+	}
+	return h.cache.TTL(h.hash(key))
+}
+
+// GetOrLoad gets the value for the given key, loading and caching it with
+// load on a miss. Concurrent misses of keys with the same hash share one
+// load, so a key whose hash is taken by another is loaded on its own.
+func (h *HashedKeys[K, V]) GetOrLoad(ctx context.Context, key K, load func(ctx context.Context, key K) (V, error)) (V, error) {
+	hash := h.hash(key)
+	ent, err := h.cache.GetOrLoad(ctx, hash, func(ctx context.Context, _ uint64) (HashedEntry[K, V], error) {
+		value, err := load(ctx, key)
+		return HashedEntry[K, V]{key, value}, err
+	})
+	if err != nil || h.equal(ent.Key, key) {
+		return ent.Value, err
+	}
+	value, err := load(ctx, key)
+	if err == nil {
+		h.cache.Set(hash, HashedEntry[K, V]{key, value})
+	}
+	return value, err
+}
+
+// Remove removes the given key from cache, reporting whether it was
+// present.
+func (h *HashedKeys[K, V]) Remove(key K) (present bool) {
+	hash := h.hash(key)
+	if ent, ok := h.cache.Peek(hash); !ok || !h.equal(ent.Key, key) {
+		return false
+	}
+	return h.cache.Remove(hash)
+}
+
+// Keys returns the keys in cache, from the probation tail to the protected
+// head.
+func (h *HashedKeys[K, V]) Keys() []K {
+	hashes := h.cache.Keys()
+	keys := make([]K, 0, len(hashes))
+	for _, hash := range hashes {
+		if ent, ok := h.cache.Peek(hash); ok {
+			keys = append(keys, ent.Key)
+		}
+	}
+	return keys
+}
+
+func (h *HashedKeys[K, V]) Len() int {
+	return h.cache.Len()
+}
+
+func (h *HashedKeys[K, V]) Stats() Stats {
+	return h.cache.Stats()
+}
+
+func (h *HashedKeys[K, V]) Purge() {
+	h.cache.Purge()
+}
+
+// PurgeFunc removes the entries for which fn returns true, returning how
+// many it removed. fn runs under the cache lock and must not call the
+// cache.
+func (h *HashedKeys[K, V]) PurgeFunc(fn func(key K, value V) bool) (removed int) {
+	return h.cache.PurgeFunc(func(_ uint64, ent HashedEntry[K, V]) bool {
+		return fn(ent.Key, ent.Value)
+	})
+}
+
+func (h *HashedKeys[K, V]) Resize(size int) (evicted int) {
+	return h.cache.Resize(size)
+}
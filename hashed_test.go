@@ -0,0 +1,62 @@
+package slru
+
+import (
+	"bytes"
+	"context"
+	"hash/maphash"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashedKeys(t *testing.T) {
+	seed := maphash.MakeSeed()
+	c := NewHashedKeys(New[uint64, HashedEntry[[]byte, int]](100),
+		func(key []byte) uint64 { return maphash.Bytes(seed, key) }, bytes.Equal)
+
+	c.Set([]byte("a"), 1)
+	require.True(t, c.Add([]byte("b"), 2))
+	require.False(t, c.Add([]byte("b"), 3))
+	v, ok := c.Get([]byte("a"))
+	require.True(t, ok)
+	require.Equal(t, 1, v)
+	old, ok := c.Swap([]byte("b"), 4)
+	require.True(t, ok)
+	require.Equal(t, 3, old)
+	require.False(t, c.Replace([]byte("c"), 5))
+	require.ElementsMatch(t, [][]byte{[]byte("a"), []byte("b")}, c.Keys())
+
+	v, err := c.GetOrLoad(context.Background(), []byte("c"), func(ctx context.Context, key []byte) (int, error) {
+		return len(key), nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, v)
+	require.True(t, c.Contains([]byte("c")))
+	require.Equal(t, 1, c.PurgeFunc(func(key []byte, value int) bool { return value == 4 }))
+	require.True(t, c.Remove([]byte("a")))
+	require.Equal(t, 1, c.Len())
+}
+
+func TestHashedKeysCollisions(t *testing.T) {
+	// keys of the same length collide
+	c := NewHashedKeys(New[uint64, HashedEntry[[]byte, int]](100),
+		func(key []byte) uint64 { return uint64(len(key)) }, bytes.Equal)
+
+	c.Set([]byte("a"), 1)
+	_, ok := c.Get([]byte("b"))
+	require.False(t, ok)
+	require.False(t, c.Remove([]byte("b")))
+	require.False(t, c.Replace([]byte("b"), 2))
+	_, ok = c.TTL([]byte("b"))
+	require.False(t, ok)
+
+	// writing a key displaces the one it collides with
+	require.True(t, c.Add([]byte("b"), 2))
+	require.False(t, c.Contains([]byte("a")))
+	v, err := c.GetOrLoad(context.Background(), []byte("a"), func(ctx context.Context, key []byte) (int, error) {
+		return 3, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, v)
+	require.Equal(t, [][]byte{[]byte("a")}, c.Keys())
+}
@@ -0,0 +1,175 @@
+package slru
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/hey-kong/slru/list"
+)
+
+// Hint is a digest of the hottest keys of a cache, hottest first.
+type Hint struct {
+	// Origin identifies the sharing cache, which ignores its own hints.
+	Origin string
+	// Keys are the hot keys, encoded by the key codec of the caches.
+	Keys []string
+}
+
+// HintExchange carries hot-key hints between caches, typically replicas
+// of the same data in different processes.
+type HintExchange interface {
+	// ShareHint sends hint to every subscriber.
+	ShareHint(ctx context.Context, hint Hint) error
+
+	// SubscribeHints calls fn with every hint shared from then on until
+	// unsubscribe is called. fn may be called concurrently with cache
+	// operations, but not with itself.
+	SubscribeHints(fn func(hint Hint)) (unsubscribe func(), err error)
+}
+
+// WithHotKeyHints shares the n hottest keys of the cache on exchange every
+// interval, and pre-promotes the keys hinted by other caches that are not
+// yet protected here, so a fresh replica, e.g. after a rolling restart, doesn't
+// have to relearn the hot set from scratch. keys encodes keys for the
+// exchange and must encode equal keys identically. With WithHintPrefetch,
+// hinted keys missing here are loaded too.
+func WithHotKeyHints[K comparable, V any](exchange HintExchange, keys Codec[K], n int, interval time.Duration) Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		if n < 1 || interval <= 0 {
+			return
+		}
+		s.hints = exchange
+		s.hintKeys = keys
+		s.hintCount = n
+		s.hintInterval = interval
+	}
+}
+
+// WithHintPrefetch loads the keys hinted by other caches that are missing
+// here with load, one at a time, sharing loads with GetOrLoad. Loaded
+// entries start in probation, like any other.
+func WithHintPrefetch[K comparable, V any](load func(ctx context.Context, key K) (V, error)) Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		s.hintLoad = load
+	}
+}
+
+// startHints subscribes to the hints of other caches and starts sharing.
+func (s *SLRU[K, V]) startHints() {
+	unsubscribe, err := s.hints.SubscribeHints(func(hint Hint) {
+		s.maintain(func() { s.applyHint(hint) })
+	})
+	if err != nil {
+		s.log(slog.LevelError, "slru: subscribe to hints", "err", err)
+	} else {
+		s.unsubscribeHints = unsubscribe
+	}
+	s.workers.Add(1)
+	go s.shareHints()
+}
+
+func (s *SLRU[K, V]) shareHints() {
+	defer s.workers.Done()
+	ticker := time.NewTicker(s.hintInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			s.maintain(s.shareHint)
+		}
+	}
+}
+
+// shareHint shares the current hot keys, if any.
+func (s *SLRU[K, V]) shareHint() {
+	keys := s.hotKeys(s.hintCount)
+	if len(keys) == 0 {
+		return
+	}
+	hint := Hint{Origin: s.origin, Keys: make([]string, 0, len(keys))}
+	for _, key := range keys {
+		data, err := s.hintKeys.Encode(key)
+		if err != nil {
+			s.log(slog.LevelWarn, "slru: encode hot key", "key", key, "err", err)
+			continue
+		}
+		hint.Keys = append(hint.Keys, string(data))
+	}
+	if err := s.hints.ShareHint(context.Background(), hint); err != nil {
+		s.log(slog.LevelWarn, "slru: share hint", "err", err)
+	}
+}
+
+// hotKeys returns up to n keys from the head of protected, then of
+// probation.
+func (s *SLRU[K, V]) hotKeys(n int) []K {
+	s.acquireShared()
+	defer s.lock.RUnlock()
+	defer s.lockSegments()()
+
+	keys := make([]K, 0, min(n, len(s.items)))
+	for _, l := range []*list.List{s.protected, s.probation} {
+		l.Each(func(e *list.Element) bool {
+			keys = append(keys, e.Value.(*entry[K, V]).key)
+			return len(keys) < n
+		})
+		if len(keys) == n {
+			break
+		}
+	}
+	return keys
+}
+
+// applyHint pre-promotes the hinted keys outside protected, then prefetches the
+// missing ones, unless the hint came from this cache.
+func (s *SLRU[K, V]) applyHint(hint Hint) {
+	if hint.Origin == s.origin {
+		return
+	}
+	keys := make([]K, 0, len(hint.Keys))
+	for _, data := range hint.Keys {
+		key, err := s.hintKeys.Decode([]byte(data))
+		if err != nil {
+			s.log(slog.LevelWarn, "slru: decode hot key", "key", data, "err", err)
+			continue
+		}
+		keys = append(keys, key)
+	}
+
+	missing := s.prePromote(keys)
+	if s.hintLoad == nil {
+		return
+	}
+	for _, key := range missing {
+		if _, err := s.loads.do(context.Background(), key, s.hintLoad, s.Set); err != nil {
+			s.log(slog.LevelDebug, "slru: prefetch hot key", "key", key, "err", err)
+		}
+	}
+}
+
+// prePromote promotes the live keys outside protected, from the coldest so
+// the hottest ends up at the head of protected, and returns the missing
+// keys.
+func (s *SLRU[K, V]) prePromote(keys []K) (missing []K) {
+	s.acquire()
+	defer s.unlock()
+
+	now := s.now()
+	for i := len(keys) - 1; i >= 0; i-- {
+		e, ok := s.items[keys[i]]
+		if !ok || s.dead(e.Value.(*entry[K, V]), now) {
+			missing = append(missing, keys[i])
+			continue
+		}
+		if e.List() != s.protected {
+			s.promote(e)
+		}
+	}
+	s.trim()
+	slices.Reverse(missing)
+	return missing
+}
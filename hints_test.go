@@ -0,0 +1,47 @@
+package slru
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHotKeyHints(t *testing.T) {
+	bus := NewLocalBus()
+	var loaded []int
+	load := func(ctx context.Context, key int) (int, error) {
+		loaded = append(loaded, key)
+		return key * 10, nil
+	}
+	a := newSLRU[int, int](100, WithHotKeyHints[int, int](bus, JSONCodec[int]{}, 3, time.Hour))
+	b := newSLRU[int, int](100,
+		WithHotKeyHints[int, int](bus, JSONCodec[int]{}, 3, time.Hour),
+		WithHintPrefetch[int, int](load),
+	)
+	for i := 0; i < 5; i++ {
+		a.Set(i, i)
+		b.Set(i, i)
+	}
+	a.Get(3)
+	a.Get(2)
+	require.Equal(t, []int{2, 3, 4}, a.hotKeys(3))
+	a.Remove(4)
+	a.Set(7, 7)
+	a.Get(7)
+	require.Equal(t, []int{7, 2, 3}, a.hotKeys(3))
+
+	a.shareHint()
+	require.Equal(t, []int{7}, loaded)
+	value, ok := b.Peek(7)
+	require.True(t, ok)
+	require.Equal(t, 70, value)
+	state := b.DebugState()
+	require.Equal(t, []int{2, 3}, debugKeys(state.Protected))
+	require.NoError(t, b.Verify())
+
+	// a cache ignores its own hints
+	b.shareHint()
+	require.Equal(t, []int{7}, loaded)
+}
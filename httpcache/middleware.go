@@ -0,0 +1,196 @@
+package httpcache
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hey-kong/slru"
+)
+
+// rendered is a response captured from a handler. vary are the request
+// headers named by its Vary header and variant their values in the
+// request it answered.
+type rendered struct {
+	status  int
+	header  http.Header
+	body    []byte
+	vary    []string
+	variant string
+}
+
+// size approximates the bytes held by the response.
+func (r *rendered) size() int {
+	n := len(r.body)
+	for k, vs := range r.header {
+		for _, v := range vs {
+			n += len(k) + len(v)
+		}
+	}
+	return n
+}
+
+// Middleware caches the 200 responses of an http.Handler, shared by every
+// client: responses setting cookies or marked private or no-store by their
+// Cache-Control are served but not cached, and so are those to requests
+// with an Authorization header, unless marked public, must-revalidate or
+// s-maxage. Responses with a Vary header are served only to requests
+// matching the request headers they name, keeping one variant per key.
+// Handlers hijacking the connection bypass the cache.
+type Middleware struct {
+	// Key returns the cache key of a request, or "" to bypass the cache.
+	// If nil, GET requests are keyed by their host and URL and others
+	// bypass it.
+	Key func(r *http.Request) string
+
+	// TTL returns how long the response to a request stays cached, where a
+	// non-positive duration never expires. If nil, responses never expire.
+	TTL func(r *http.Request) time.Duration
+
+	cache slru.Cache[string, *rendered]
+}
+
+// NewMiddleware returns a Middleware caching up to capacity bytes of responses.
+func NewMiddleware(capacity int) *Middleware {
+	weigher := func(key string, r *rendered) int {
+		return len(key) + r.size()
+	}
+	return &Middleware{cache: slru.New(capacity, slru.WithWeigher(weigher))}
+}
+
+// Handler returns next wrapped by the cache.
+func (m *Middleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := m.key(r)
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if cached, ok := m.cache.Get(key); ok && cached.variant == variant(r.Header, cached.vary) {
+			for k, vs := range cached.header {
+				w.Header()[k] = vs
+			}
+			w.Header().Set(XFromCache, "1")
+			w.WriteHeader(cached.status)
+			w.Write(cached.body)
+			return
+		}
+
+		rec := &recorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		if rec.status != http.StatusOK || rec.hijacked || !shareable(r, w.Header()) {
+			return
+		}
+		vary, ok := varyHeaders(w.Header())
+		if !ok {
+			return
+		}
+		var ttl time.Duration
+		if m.TTL != nil {
+			ttl = m.TTL(r)
+		}
+		m.cache.SetWithTTL(key, &rendered{
+			status:  rec.status,
+			header:  w.Header().Clone(),
+			body:    rec.body.Bytes(),
+			vary:    vary,
+			variant: variant(r.Header, vary),
+		}, ttl)
+	})
+}
+
+// Invalidate drops the cached response for key, reporting whether it was present.
+func (m *Middleware) Invalidate(key string) bool {
+	return m.cache.Remove(key)
+}
+
+// Purge drops every cached response.
+func (m *Middleware) Purge() {
+	m.cache.Purge()
+}
+
+// shareable reports whether the response to r with header may be replayed
+// to other clients.
+func shareable(r *http.Request, header http.Header) bool {
+	if len(header.Values("Set-Cookie")) > 0 {
+		return false
+	}
+	cc := parseCacheControl(header)
+	if cc.has("private") || cc.has("no-store") {
+		return false
+	}
+	// RFC 9111 §3.5: responses to authorized requests are the client's own
+	return r.Header.Get("Authorization") == "" || cc.has("public") || cc.has("must-revalidate") || cc.has("s-maxage")
+}
+
+func (m *Middleware) key(r *http.Request) string {
+	if m.Key != nil {
+		return m.Key(r)
+	}
+	if r.Method != http.MethodGet {
+		return ""
+	}
+	return r.Host + r.URL.RequestURI()
+}
+
+// RouteTTL returns a TTL function giving each request the duration of the
+// longest path prefix in routes that matches its URL, or def if none does.
+func RouteTTL(routes map[string]time.Duration, def time.Duration) func(r *http.Request) time.Duration {
+	return func(r *http.Request) time.Duration {
+		ttl, longest := def, -1
+		for prefix, d := range routes {
+			if len(prefix) > longest && strings.HasPrefix(r.URL.Path, prefix) {
+				ttl, longest = d, len(prefix)
+			}
+		}
+		return ttl
+	}
+}
+
+// recorder writes a response through while capturing its status and body.
+// It flushes and hijacks through the ResponseWriter it wraps.
+type recorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	hijacked    bool
+	body        bytes.Buffer
+}
+
+func (r *recorder) Flush() {
+	r.wroteHeader = true
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (r *recorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	r.hijacked = true
+	return h.Hijack()
+}
+
+// Unwrap returns the ResponseWriter, for http.ResponseController.
+func (r *recorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+func (r *recorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status, r.wroteHeader = status, true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
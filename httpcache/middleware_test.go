@@ -0,0 +1,160 @@
+package httpcache
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	hits := 0
+	m := NewMiddleware(1 << 20)
+	h := m.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, "hello")
+	}))
+
+	serve := func(method, target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+		return rec
+	}
+
+	rec := serve(http.MethodGet, "/a")
+	require.Equal(t, "hello", rec.Body.String())
+	require.Empty(t, rec.Header().Get(XFromCache))
+
+	rec = serve(http.MethodGet, "/a")
+	require.Equal(t, "hello", rec.Body.String())
+	require.Equal(t, "text/plain", rec.Header().Get("Content-Type"))
+	require.Equal(t, "1", rec.Header().Get(XFromCache))
+	require.Equal(t, 1, hits)
+
+	// errors and non-GET requests are not cached
+	serve(http.MethodGet, "/missing")
+	serve(http.MethodGet, "/missing")
+	serve(http.MethodPost, "/a")
+	require.Equal(t, 4, hits)
+
+	require.True(t, m.Invalidate("example.com/a"))
+	serve(http.MethodGet, "/a")
+	require.Equal(t, 5, hits)
+}
+
+func TestRouteTTL(t *testing.T) {
+	ttl := RouteTTL(map[string]time.Duration{
+		"/api/":        time.Minute,
+		"/api/static/": time.Hour,
+	}, time.Second)
+
+	require.Equal(t, time.Hour, ttl(httptest.NewRequest(http.MethodGet, "/api/static/x", nil)))
+	require.Equal(t, time.Minute, ttl(httptest.NewRequest(http.MethodGet, "/api/x", nil)))
+	require.Equal(t, time.Second, ttl(httptest.NewRequest(http.MethodGet, "/", nil)))
+}
+
+func TestMiddlewarePrivateResponses(t *testing.T) {
+	for name, header := range map[string]http.Header{
+		"Set-Cookie": {"Set-Cookie": {"session=secret"}},
+		"private":    {"Cache-Control": {"max-age=60, Private"}},
+		"no-store":   {"Cache-Control": {"no-store"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			hits := 0
+			h := NewMiddleware(1 << 20).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				hits++
+				for k, vs := range header {
+					w.Header()[k] = vs
+				}
+				io.WriteString(w, "for one client")
+			}))
+			for range 2 {
+				rec := httptest.NewRecorder()
+				h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/me", nil))
+				require.Equal(t, "for one client", rec.Body.String())
+				require.Empty(t, rec.Header().Get(XFromCache))
+			}
+			require.Equal(t, 2, hits)
+		})
+	}
+}
+
+func TestMiddlewareKeys(t *testing.T) {
+	hits := 0
+	h := NewMiddleware(1 << 20).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		if r.URL.Query().Has("public") {
+			w.Header().Set("Cache-Control", "public")
+		}
+		w.Header().Set("Vary", "Accept-Language")
+		io.WriteString(w, r.Host+" "+r.Header.Get("Accept-Language"))
+	}))
+	serve := func(target, lang, authorization string) (string, bool) {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set("Accept-Language", lang)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Body.String(), rec.Header().Get(XFromCache) != ""
+	}
+
+	// hosts and varying headers get their own responses
+	serve("http://a.test/", "en", "")
+	body, cached := serve("http://a.test/", "en", "")
+	require.Equal(t, "a.test en", body)
+	require.True(t, cached)
+	body, cached = serve("http://b.test/", "en", "")
+	require.Equal(t, "b.test en", body)
+	require.False(t, cached)
+	body, cached = serve("http://a.test/", "fr", "")
+	require.Equal(t, "a.test fr", body)
+	require.False(t, cached)
+	require.Equal(t, 3, hits)
+
+	// responses to authorized requests are cached only if public
+	serve("http://a.test/me", "en", "Bearer x")
+	_, cached = serve("http://a.test/me", "en", "Bearer x")
+	require.False(t, cached)
+	serve("http://a.test/me?public", "en", "Bearer x")
+	_, cached = serve("http://a.test/me?public", "en", "Bearer x")
+	require.True(t, cached)
+}
+
+func TestMiddlewareStreaming(t *testing.T) {
+	h := NewMiddleware(1 << 20).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ws" {
+			conn, _, err := http.NewResponseController(w).Hijack()
+			if err == nil {
+				conn.Close()
+			}
+			return
+		}
+		io.WriteString(w, "event")
+		require.NoError(t, http.NewResponseController(w).Flush())
+	}))
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	for range 2 {
+		resp, err := http.Get(srv.URL + "/events")
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		require.NoError(t, err)
+		require.Equal(t, "event", string(body))
+	}
+	for range 2 {
+		_, err := http.Get(srv.URL + "/ws")
+		require.Error(t, err)
+	}
+}
@@ -0,0 +1,281 @@
+// Package httpcache caches HTTP responses in an SLRU bounded by their size
+// in bytes, both client-side as an http.RoundTripper and server-side as
+// middleware.
+//
+// The Transport only stores GET responses. Freshness follows the max-age
+// directive or the Expires header, and stale responses carrying an ETag or
+// Last-Modified validator are revalidated with a conditional request.
+// Responses with a Vary header are served only to requests matching the
+// request headers they name, keeping one variant per URL.
+package httpcache
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hey-kong/slru"
+)
+
+// XFromCache is the header set on responses served from the cache.
+const XFromCache = "X-From-Cache"
+
+// DefaultMaxBodySize is the size of the largest response body cached by a
+// Transport without a MaxBodySize.
+const DefaultMaxBodySize = 1 << 20
+
+// cachedResponse is a serialized response and the time it goes stale. vary
+// are the request headers named by its Vary header and variant their
+// values in the request it answered.
+type cachedResponse struct {
+	raw     []byte
+	expires time.Time
+	vary    []string
+	variant string
+}
+
+func (c *cachedResponse) response(req *http.Request) (*http.Response, error) {
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(c.raw)), req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Header.Set(XFromCache, "1")
+	return resp, nil
+}
+
+// Transport is an http.RoundTripper that serves fresh responses from cache.
+type Transport struct {
+	// Transport is used to make requests. If nil, http.DefaultTransport is used.
+	Transport http.RoundTripper
+
+	// MaxBodySize is the size of the largest response body to cache; larger
+	// responses are passed through. If zero, DefaultMaxBodySize is used.
+	MaxBodySize int64
+
+	cache slru.Cache[string, *cachedResponse]
+}
+
+// NewTransport returns a Transport caching up to capacity bytes of responses.
+func NewTransport(capacity int, transport http.RoundTripper) *Transport {
+	weigher := func(key string, c *cachedResponse) int {
+		return len(key) + len(c.raw)
+	}
+	return &Transport{
+		Transport: transport,
+		cache:     slru.New(capacity, slru.WithWeigher(weigher)),
+	}
+}
+
+// Client returns an *http.Client using the Transport.
+func (t *Transport) Client() *http.Client {
+	return &http.Client{Transport: t}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqCC := parseCacheControl(req.Header)
+	if req.Method != http.MethodGet || req.Header.Get("Range") != "" || reqCC.has("no-store") {
+		return t.transport().RoundTrip(req)
+	}
+
+	key := req.Method + " " + req.URL.String()
+	cached, ok := t.cache.Get(key)
+	if ok && cached.variant != variant(req.Header, cached.vary) {
+		ok = false
+	}
+	if ok && !reqCC.has("no-cache") && time.Now().Before(cached.expires) {
+		return cached.response(req)
+	}
+
+	outreq := req
+	if ok {
+		outreq = revalidate(req, cached)
+	}
+	resp, err := t.transport().RoundTrip(outreq)
+	if err != nil {
+		return nil, err
+	}
+
+	if ok && resp.StatusCode == http.StatusNotModified {
+		resp.Body.Close()
+		refreshed, err := refresh(req, cached, resp.Header)
+		if err != nil {
+			return nil, err
+		}
+		t.cache.Set(key, refreshed)
+		return refreshed.response(req)
+	}
+
+	respCC := parseCacheControl(resp.Header)
+	vary, varies := varyHeaders(resp.Header)
+	if resp.StatusCode != http.StatusOK || respCC.has("no-store") || !varies {
+		t.cache.Remove(key)
+		return resp, nil
+	}
+	body, full, err := t.readBody(resp)
+	if err != nil {
+		return nil, err
+	}
+	if !full {
+		t.cache.Remove(key)
+		return resp, nil
+	}
+	resp.Body = body
+	raw, err := httputil.DumpResponse(resp, true)
+	if err != nil {
+		return nil, err
+	}
+	c := &cachedResponse{raw: raw, expires: expiry(resp.Header), vary: vary, variant: variant(req.Header, vary)}
+	if respCC.has("no-cache") {
+		c.expires = time.Time{}
+	}
+	if time.Now().Before(c.expires) || hasValidator(resp.Header) {
+		t.cache.Set(key, c)
+	}
+	return resp, nil
+}
+
+// readBody reads the body of resp up to MaxBodySize, reporting whether it
+// read it in full. Otherwise resp keeps streaming its whole body.
+func (t *Transport) readBody(resp *http.Response) (body io.ReadCloser, full bool, err error) {
+	limit := t.MaxBodySize
+	if limit <= 0 {
+		limit = DefaultMaxBodySize
+	}
+	head, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		resp.Body.Close()
+		return nil, false, err
+	}
+	if int64(len(head)) > limit {
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(head), resp.Body), resp.Body}
+		return nil, false, nil
+	}
+	resp.Body.Close()
+	return io.NopCloser(bytes.NewReader(head)), true, nil
+}
+
+func (t *Transport) transport() http.RoundTripper {
+	if t.Transport != nil {
+		return t.Transport
+	}
+	return http.DefaultTransport
+}
+
+// revalidate returns a copy of req conditional on the cached validators.
+func revalidate(req *http.Request, cached *cachedResponse) *http.Request {
+	resp, err := cached.response(req)
+	if err != nil {
+		return req
+	}
+	resp.Body.Close()
+
+	outreq := req.Clone(req.Context())
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		outreq.Header.Set("If-None-Match", etag)
+	}
+	if lastModified := resp.Header.Get("Last-Modified"); lastModified != "" {
+		outreq.Header.Set("If-Modified-Since", lastModified)
+	}
+	return outreq
+}
+
+// refresh returns cached updated by the headers of a 304 revalidating it.
+func refresh(req *http.Request, cached *cachedResponse, header http.Header) (*cachedResponse, error) {
+	resp, err := cached.response(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Header.Del(XFromCache)
+	for k, vs := range header {
+		if k != "Content-Length" {
+			resp.Header[k] = vs
+		}
+	}
+	raw, err := httputil.DumpResponse(resp, true)
+	if err != nil {
+		return nil, err
+	}
+	refreshed := &cachedResponse{raw: raw, expires: expiry(resp.Header), vary: cached.vary, variant: cached.variant}
+	if parseCacheControl(resp.Header).has("no-cache") {
+		refreshed.expires = time.Time{}
+	}
+	return refreshed, nil
+}
+
+// varyHeaders returns the canonical request headers named by the Vary
+// header of a response, and false for Vary: *, which no request matches.
+func varyHeaders(h http.Header) (names []string, ok bool) {
+	for _, v := range h.Values("Vary") {
+		for _, name := range strings.Split(v, ",") {
+			name = strings.TrimSpace(name)
+			if name == "*" {
+				return nil, false
+			}
+			if name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	return names, true
+}
+
+// variant returns the values in h of the headers names, to tell whether
+// two requests get the same response varying by names.
+func variant(h http.Header, names []string) string {
+	var b strings.Builder
+	for _, name := range names {
+		v := strings.Join(h.Values(name), ",")
+		b.WriteString(strconv.Itoa(len(v)))
+		b.WriteByte(':')
+		b.WriteString(v)
+	}
+	return b.String()
+}
+
+func hasValidator(h http.Header) bool {
+	return h.Get("ETag") != "" || h.Get("Last-Modified") != ""
+}
+
+// expiry returns when a response with headers h goes stale.
+func expiry(h http.Header) time.Time {
+	now := time.Now()
+	if maxAge, ok := parseCacheControl(h)["max-age"]; ok {
+		if seconds, err := strconv.Atoi(maxAge); err == nil {
+			return now.Add(time.Duration(seconds) * time.Second)
+		}
+		return now
+	}
+	if expires, err := http.ParseTime(h.Get("Expires")); err == nil {
+		return expires
+	}
+	return now
+}
+
+type cacheControl map[string]string
+
+func (cc cacheControl) has(directive string) bool {
+	_, ok := cc[directive]
+	return ok
+}
+
+func parseCacheControl(h http.Header) cacheControl {
+	cc := cacheControl{}
+	for _, part := range strings.Split(h.Get("Cache-Control"), ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(part, "=")
+		cc[strings.ToLower(strings.TrimSpace(name))] = strings.Trim(strings.TrimSpace(value), `"`)
+	}
+	return cc
+}
@@ -0,0 +1,165 @@
+package httpcache
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func get(t *testing.T, client *http.Client, url string) (string, bool) {
+	resp, err := client.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(body), resp.Header.Get(XFromCache) != ""
+}
+
+func TestTransportMaxAge(t *testing.T) {
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Cache-Control", "max-age=60")
+		io.WriteString(w, "hello")
+	}))
+	defer srv.Close()
+
+	client := NewTransport(1<<20, nil).Client()
+	body, cached := get(t, client, srv.URL)
+	require.Equal(t, "hello", body)
+	require.False(t, cached)
+
+	body, cached = get(t, client, srv.URL)
+	require.Equal(t, "hello", body)
+	require.True(t, cached)
+	require.Equal(t, 1, hits)
+}
+
+func TestTransportETag(t *testing.T) {
+	hits, notModified := 0, 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		io.WriteString(w, "hello")
+	}))
+	defer srv.Close()
+
+	client := NewTransport(1<<20, nil).Client()
+	get(t, client, srv.URL)
+	body, cached := get(t, client, srv.URL)
+	require.Equal(t, "hello", body)
+	require.True(t, cached)
+	require.Equal(t, 2, hits)
+	require.Equal(t, 1, notModified)
+}
+
+func TestTransportNoStore(t *testing.T) {
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Cache-Control", "no-store, max-age=60")
+		io.WriteString(w, "hello")
+	}))
+	defer srv.Close()
+
+	client := NewTransport(1<<20, nil).Client()
+	get(t, client, srv.URL)
+	_, cached := get(t, client, srv.URL)
+	require.False(t, cached)
+	require.Equal(t, 2, hits)
+}
+
+func TestTransportVary(t *testing.T) {
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("Vary", "Accept-Language")
+		io.WriteString(w, r.Header.Get("Accept-Language"))
+	}))
+	defer srv.Close()
+
+	client := NewTransport(1<<20, nil).Client()
+	getIn := func(lang string) (string, bool) {
+		req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+		require.NoError(t, err)
+		req.Header.Set("Accept-Language", lang)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(body), resp.Header.Get(XFromCache) != ""
+	}
+	getIn("en")
+	body, cached := getIn("en")
+	require.Equal(t, "en", body)
+	require.True(t, cached)
+	body, cached = getIn("fr")
+	require.Equal(t, "fr", body)
+	require.False(t, cached)
+	require.Equal(t, 2, hits)
+}
+
+func TestTransportMaxBodySize(t *testing.T) {
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Cache-Control", "max-age=60")
+		io.WriteString(w, strings.Repeat("x", 100))
+	}))
+	defer srv.Close()
+
+	transport := NewTransport(1<<20, nil)
+	transport.MaxBodySize = 99
+	body, _ := get(t, transport.Client(), srv.URL)
+	require.Len(t, body, 100)
+	_, cached := get(t, transport.Client(), srv.URL)
+	require.False(t, cached)
+	require.Equal(t, 2, hits)
+
+	transport.MaxBodySize = 100
+	get(t, transport.Client(), srv.URL)
+	_, cached = get(t, transport.Client(), srv.URL)
+	require.True(t, cached)
+}
+
+func TestTransportRevalidationUpdatesHeaders(t *testing.T) {
+	version := "1"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("X-Version", version)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.Header().Set("Cache-Control", "max-age=60")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		io.WriteString(w, "hello")
+	}))
+	defer srv.Close()
+
+	client := NewTransport(1<<20, nil).Client()
+	get(t, client, srv.URL)
+	version = "2"
+	get(t, client, srv.URL)
+
+	// the 304 made the response fresh, with its headers
+	version = "3"
+	resp, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(body))
+	require.Equal(t, "1", resp.Header.Get(XFromCache))
+	require.Equal(t, "2", resp.Header.Get("X-Version"))
+}
@@ -0,0 +1,61 @@
+package slru
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// snapshot is the immutable state of an entry published to lock-free
+// readers.
+type snapshot[V any] struct {
+	value    V
+	expireAt time.Time
+	gen      uint64
+}
+
+// readIndex shadows the cache index for readers that must not touch the
+// cache lock. Writers update it under the lock after changing an entry.
+type readIndex[K comparable, V any] struct {
+	m      atomic.Pointer[sync.Map]
+	minGen atomic.Uint64
+}
+
+// WithLockFreeReads makes Contains and Peek read a shadow index instead of
+// taking the cache lock, at the cost of updating the shadow on every write.
+// They may observe a write slightly before Get does.
+func WithLockFreeReads[K comparable, V any]() Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		s.index = &readIndex[K, V]{}
+		s.index.m.Store(new(sync.Map))
+	}
+}
+
+// publish stores the state of ent in the read index, if any.
+func (s *SLRU[K, V]) publish(ent *entry[K, V]) {
+	if s.index == nil {
+		return
+	}
+	s.index.m.Load().Store(ent.key, &snapshot[V]{value: ent.value, expireAt: ent.expireAt, gen: ent.gen})
+}
+
+// unpublish removes key from the read index, if any.
+func (s *SLRU[K, V]) unpublish(key K) {
+	if s.index == nil {
+		return
+	}
+	s.index.m.Load().Delete(key)
+}
+
+// readLive returns the snapshot of key if it's live, without locking.
+func (s *SLRU[K, V]) readLive(key K) (*snapshot[V], bool) {
+	v, ok := s.index.m.Load().Load(key)
+	if !ok {
+		return nil, false
+	}
+	snap := v.(*snapshot[V])
+	if snap.gen < s.index.minGen.Load() || (!snap.expireAt.IsZero() && !s.now().Before(snap.expireAt)) {
+		return nil, false
+	}
+	return snap, true
+}
@@ -0,0 +1,65 @@
+package slru
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockFreeReads(t *testing.T) {
+	cache := newSLRU[int, int](10, WithLockFreeReads[int, int]())
+	cache.Set(1, 1)
+	cache.SetWithTTL(2, 2, time.Nanosecond)
+
+	cache.lock.Lock()
+	require.True(t, cache.Contains(1))
+	v, ok := cache.Peek(1)
+	require.True(t, ok)
+	require.Equal(t, 1, v)
+	require.False(t, cache.Contains(2))
+	cache.lock.Unlock()
+
+	cache.Set(1, 10)
+	v, _ = cache.Peek(1)
+	require.Equal(t, 10, v)
+
+	cache.Remove(1)
+	require.False(t, cache.Contains(1))
+
+	// evictions leave the shadow index
+	for i := 0; i < 10; i++ {
+		cache.Set(i, i)
+	}
+	require.False(t, cache.Contains(0))
+	require.NoError(t, cache.Verify())
+
+	cache.InvalidateBefore(cache.NewGeneration())
+	require.False(t, cache.Contains(9))
+
+	cache.Set(3, 3)
+	cache.Purge()
+	require.False(t, cache.Contains(3))
+}
+
+func TestLockFreeReadsConcurrently(t *testing.T) {
+	cache := newSLRU[int, int](100, WithLockFreeReads[int, int]())
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				if g%2 == 0 {
+					cache.Set(i%150, i)
+				} else {
+					cache.Peek(i % 150)
+					cache.Contains(i % 150)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	require.NoError(t, cache.Verify())
+}
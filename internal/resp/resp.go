@@ -0,0 +1,296 @@
+// Package resp implements the subset of the Redis serialization protocol
+// (RESP2) needed by the slru servers and clients.
+package resp
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Types of RESP values.
+const (
+	SimpleString = '+'
+	Error        = '-'
+	Integer      = ':'
+	BulkString   = '$'
+	Array        = '*'
+)
+
+// ErrProtocol is returned for malformed input.
+var ErrProtocol = errors.New("resp: protocol error")
+
+// MaxBulkLen and MaxArrayLen bound the bulk strings and arrays ReadValue
+// accepts, as Redis does, so a header alone can't exhaust memory, and
+// MaxDepth bounds how deep arrays nest, so input can't exhaust the stack.
+const (
+	MaxBulkLen  = 512 << 20
+	MaxArrayLen = 1 << 20
+	MaxDepth    = 32
+)
+
+// preallocated bounds what ReadValue allocates for a value ahead of its
+// data, which grows as it arrives.
+const preallocated = 64 << 10
+
+// Value is a decoded RESP value. Null bulk strings and arrays have Null set.
+type Value struct {
+	Type  byte
+	Str   []byte
+	Int   int64
+	Array []Value
+	Null  bool
+}
+
+// Reader decodes RESP values.
+type Reader struct {
+	r *bufio.Reader
+}
+
+// NewReader returns a Reader reading from r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// Buffered returns the number of bytes that can be read without blocking.
+func (r *Reader) Buffered() int {
+	return r.r.Buffered()
+}
+
+// ReadCommand reads a command sent as an array of bulk strings, or as an
+// inline command of space-separated words.
+func (r *Reader) ReadCommand() ([][]byte, error) {
+	b, err := r.r.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+	if b[0] != Array {
+		line, err := r.readLine()
+		if err != nil {
+			return nil, err
+		}
+		var args [][]byte
+		for _, f := range strings.Fields(string(line)) {
+			args = append(args, []byte(f))
+		}
+		return args, nil
+	}
+
+	// commands are flat, so read the bulk strings without recursing
+	line, err := r.readLine()
+	if err != nil {
+		return nil, err
+	}
+	n, err := parseLen(line[1:], MaxArrayLen, "multibulk length")
+	if err != nil {
+		return nil, err
+	}
+	if n < 0 {
+		return nil, protocolError("invalid multibulk length")
+	}
+	args := make([][]byte, 0, min(n, preallocated/64))
+	for range n {
+		line, err := r.readLine()
+		if err != nil {
+			return nil, err
+		}
+		if len(line) == 0 || line[0] != BulkString {
+			return nil, protocolError("expected '$'")
+		}
+		n, err := parseLen(line[1:], MaxBulkLen, "bulk length")
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, protocolError("invalid bulk length")
+		}
+		arg, err := r.readBulk(n)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	return args, nil
+}
+
+// ReadValue reads a single RESP value, nested at most MaxDepth deep.
+func (r *Reader) ReadValue() (Value, error) {
+	return r.readValue(0)
+}
+
+// readValue reads a value nested in depth arrays.
+func (r *Reader) readValue(depth int) (Value, error) {
+	line, err := r.readLine()
+	if err != nil {
+		return Value{}, err
+	}
+	if len(line) == 0 {
+		return Value{}, protocolError("empty line")
+	}
+
+	v := Value{Type: line[0]}
+	switch v.Type {
+	case SimpleString, Error:
+		v.Str = line[1:]
+	case Integer:
+		if v.Int, err = strconv.ParseInt(string(line[1:]), 10, 64); err != nil {
+			return Value{}, protocolError("invalid integer")
+		}
+	case BulkString:
+		n, err := parseLen(line[1:], MaxBulkLen, "bulk length")
+		if err != nil {
+			return Value{}, err
+		}
+		if n < 0 {
+			v.Null = true
+			return v, nil
+		}
+		if v.Str, err = r.readBulk(n); err != nil {
+			return Value{}, err
+		}
+	case Array:
+		if depth >= MaxDepth {
+			return Value{}, protocolError("arrays nested too deep")
+		}
+		n, err := parseLen(line[1:], MaxArrayLen, "multibulk length")
+		if err != nil {
+			return Value{}, err
+		}
+		if n < 0 {
+			v.Null = true
+			return v, nil
+		}
+		v.Array = make([]Value, 0, min(n, preallocated/64))
+		for range n {
+			elem, err := r.readValue(depth + 1)
+			if err != nil {
+				return Value{}, err
+			}
+			v.Array = append(v.Array, elem)
+		}
+	default:
+		return Value{}, protocolError("unknown type")
+	}
+	return v, nil
+}
+
+// parseLen parses the length of a bulk string or array of at most limit,
+// -1 if null.
+func parseLen(b []byte, limit int, what string) (int, error) {
+	n, err := strconv.Atoi(string(b))
+	if err != nil || n < -1 || n > limit {
+		return 0, protocolError("invalid " + what)
+	}
+	return n, nil
+}
+
+// readBulk reads the n bytes of a bulk string and their CRLF terminator.
+func (r *Reader) readBulk(n int) ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, 0, min(n+2, preallocated)))
+	if _, err := io.CopyN(buf, r.r, int64(n+2)); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	if b := buf.Bytes(); b[n] != '\r' || b[n+1] != '\n' {
+		return nil, protocolError("bulk string not terminated by CRLF")
+	}
+	return buf.Bytes()[:n], nil
+}
+
+// readLine reads a line terminated by CRLF, without the terminator.
+func (r *Reader) readLine() ([]byte, error) {
+	line, err := r.r.ReadSlice('\n')
+	if err == bufio.ErrBufferFull {
+		return nil, protocolError("line too long")
+	}
+	if err != nil {
+		return nil, err
+	}
+	n := len(line) - 1
+	if n > 0 && line[n-1] == '\r' {
+		n--
+	}
+	return append([]byte(nil), line[:n]...), nil
+}
+
+// protocolError returns an ErrProtocol explained by reason.
+func protocolError(reason string) error {
+	return fmt.Errorf("%w: %s", ErrProtocol, reason)
+}
+
+// Writer encodes RESP values.
+type Writer struct {
+	w *bufio.Writer
+}
+
+// NewWriter returns a Writer writing to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: bufio.NewWriter(w)}
+}
+
+// WriteSimpleString writes a simple string.
+func (w *Writer) WriteSimpleString(s string) error {
+	_, err := fmt.Fprintf(w.w, "+%s\r\n", s)
+	return err
+}
+
+// WriteError writes an error.
+func (w *Writer) WriteError(s string) error {
+	_, err := fmt.Fprintf(w.w, "-%s\r\n", s)
+	return err
+}
+
+// WriteInteger writes an integer.
+func (w *Writer) WriteInteger(n int64) error {
+	_, err := fmt.Fprintf(w.w, ":%d\r\n", n)
+	return err
+}
+
+// WriteBulkString writes a bulk string.
+func (w *Writer) WriteBulkString(b []byte) error {
+	if _, err := fmt.Fprintf(w.w, "$%d\r\n", len(b)); err != nil {
+		return err
+	}
+	if _, err := w.w.Write(b); err != nil {
+		return err
+	}
+	_, err := w.w.WriteString("\r\n")
+	return err
+}
+
+// WriteNull writes a null bulk string.
+func (w *Writer) WriteNull() error {
+	_, err := w.w.WriteString("$-1\r\n")
+	return err
+}
+
+// WriteArrayHeader writes the header of an array of n values, which the
+// caller writes next.
+func (w *Writer) WriteArrayHeader(n int) error {
+	_, err := fmt.Fprintf(w.w, "*%d\r\n", n)
+	return err
+}
+
+// WriteCommand writes a command as an array of bulk strings.
+func (w *Writer) WriteCommand(args ...string) error {
+	if err := w.WriteArrayHeader(len(args)); err != nil {
+		return err
+	}
+	for _, a := range args {
+		if err := w.WriteBulkString([]byte(a)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush writes any buffered data to the underlying writer.
+func (w *Writer) Flush() error {
+	return w.w.Flush()
+}
@@ -0,0 +1,89 @@
+package resp
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadCommand(t *testing.T) {
+	r := NewReader(strings.NewReader("*3\r\n$3\r\nSET\r\n$1\r\nk\r\n$5\r\nv a l\r\nPING\r\n"))
+
+	args, err := r.ReadCommand()
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{[]byte("SET"), []byte("k"), []byte("v a l")}, args)
+
+	args, err = r.ReadCommand()
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{[]byte("PING")}, args)
+}
+
+func TestRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	require.NoError(t, w.WriteArrayHeader(4))
+	require.NoError(t, w.WriteSimpleString("OK"))
+	require.NoError(t, w.WriteInteger(-2))
+	require.NoError(t, w.WriteBulkString([]byte("hello")))
+	require.NoError(t, w.WriteNull())
+	require.NoError(t, w.Flush())
+
+	v, err := NewReader(&buf).ReadValue()
+	require.NoError(t, err)
+	require.Equal(t, byte(Array), v.Type)
+	require.Len(t, v.Array, 4)
+	require.Equal(t, "OK", string(v.Array[0].Str))
+	require.Equal(t, int64(-2), v.Array[1].Int)
+	require.Equal(t, "hello", string(v.Array[2].Str))
+	require.True(t, v.Array[3].Null)
+}
+
+func TestReadValueBounds(t *testing.T) {
+	for _, header := range []string{
+		"$9223372036854775807\r\n",
+		"$536870913\r\n",
+		"$-2\r\n",
+		"*9223372036854775807\r\n",
+		"*1048577\r\n",
+		"*-2\r\n",
+	} {
+		_, err := NewReader(strings.NewReader(header)).ReadValue()
+		require.ErrorIs(t, err, ErrProtocol, header)
+	}
+
+	// lengths in bounds allocate as the data arrives
+	_, err := NewReader(strings.NewReader("$536870912\r\nshort")).ReadValue()
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	_, err = NewReader(strings.NewReader("*1048576\r\n:1\r\n")).ReadValue()
+	require.ErrorIs(t, err, io.EOF)
+
+	v, err := NewReader(strings.NewReader("*-1\r\n")).ReadValue()
+	require.NoError(t, err)
+	require.True(t, v.Null)
+}
+
+func TestReadMalformed(t *testing.T) {
+	// commands are flat, and values nest at most MaxDepth deep
+	deep := strings.Repeat("*1\r\n", 1<<20)
+	_, err := NewReader(strings.NewReader(deep)).ReadCommand()
+	require.ErrorIs(t, err, ErrProtocol)
+	_, err = NewReader(strings.NewReader(deep)).ReadValue()
+	require.ErrorIs(t, err, ErrProtocol)
+	_, err = NewReader(strings.NewReader(strings.Repeat("*1\r\n", MaxDepth) + ":1\r\n")).ReadValue()
+	require.NoError(t, err)
+
+	for _, command := range []string{
+		"*1\r\n:1\r\n",
+		"*1\r\n$-1\r\n",
+		"*1\r\n$3\r\nabcde\r\n",
+		strings.Repeat("x", 8192) + "\r\n",
+	} {
+		_, err := NewReader(strings.NewReader(command)).ReadCommand()
+		require.ErrorIs(t, err, ErrProtocol, command)
+	}
+	_, err = NewReader(strings.NewReader("$3\r\nabcde\r\n")).ReadValue()
+	require.ErrorIs(t, err, ErrProtocol)
+}
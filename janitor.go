@@ -0,0 +1,84 @@
+package slru
+
+import (
+	"log/slog"
+	"time"
+)
+
+// WithJanitor removes expired entries in the background every interval, so
+// they don't hold capacity until they are accessed or evicted. Expirations
+// are scheduled in a timing wheel ticking every interval, so each run only
+// visits the entries actually due.
+func WithJanitor[K comparable, V any](interval time.Duration) Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		s.janitorInterval = interval
+	}
+}
+
+// WithJanitorLimit caps the expired entries the janitor removes per run to
+// n, carrying the rest over to the next runs, so a wave of simultaneous
+// expirations doesn't hold the cache lock in one long burst.
+func WithJanitorLimit[K comparable, V any](n int) Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		s.janitorLimit = max(n, 0)
+	}
+}
+
+// startJanitor schedules expirations and starts the janitor, if enabled.
+func (s *SLRU[K, V]) startJanitor() {
+	if s.janitorInterval <= 0 {
+		return
+	}
+	s.wheel = newTimingWheel[K, V](s.janitorInterval, s.now())
+	s.workers.Add(1)
+	go s.janitor(s.janitorInterval)
+}
+
+func (s *SLRU[K, V]) janitor(interval time.Duration) {
+	defer s.workers.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			s.maintain(func() { s.expire() })
+		}
+	}
+}
+
+// expire removes the entries whose expiry passed, up to the janitor limit,
+// returning how many.
+func (s *SLRU[K, V]) expire() (expired int) {
+	defer s.region("expire")()
+	s.acquire()
+	defer s.unlock()
+
+	s.wheel.advance(s.now(), s.janitorLimit, func(ent *entry[K, V]) {
+		e := s.items[ent.key]
+		delete(s.items, ent.key)
+		s.unpublish(ent.key)
+		s.mutate(MutationExpire, ent)
+		s.observe(ent.key, AccessExpired, e.List())
+		s.unlink(e)
+		s.release(e)
+		s.stats.expirations.inc()
+		expired++
+	})
+	if expired > 0 {
+		s.log(slog.LevelDebug, "slru: expire", "entries", expired)
+	}
+	return expired
+}
+
+// reschedule updates the expiry of ent in the timing wheel, if any.
+func (s *SLRU[K, V]) reschedule(ent *entry[K, V]) {
+	if s.wheel == nil {
+		return
+	}
+	s.wheel.unschedule(ent)
+	if !ent.expireAt.IsZero() {
+		s.wheel.schedule(ent)
+	}
+}
@@ -0,0 +1,38 @@
+package slru
+
+import (
+	"hash/maphash"
+	"sync"
+)
+
+// keyLockStripes is the number of mutexes keys are spread over by LockKey.
+const keyLockStripes = 64
+
+// keyLocks are striped mutexes serializing callers per key. Different keys
+// may share a stripe, so holders must not lock a second key.
+type keyLocks[K comparable] struct {
+	hash    func(key K) uint64
+	stripes [keyLockStripes]sync.Mutex
+}
+
+func (l *keyLocks[K]) lock(key K) func() {
+	m := &l.stripes[l.hash(key)%keyLockStripes]
+	m.Lock()
+	return m.Unlock
+}
+
+// LockKey locks key and returns the function unlocking it, so callers can
+// serialize work around a key, e.g. a read-modify-write of its value that
+// is too slow for Update. It doesn't lock the cache, and keys share a
+// small set of locks, so a caller holding one must not lock another key.
+func (s *SLRU[K, V]) LockKey(key K) (unlock func()) {
+	key = s.normalize(key)
+	s.keyLocksOnce.Do(func() {
+		s.keyLocks = &keyLocks[K]{hash: defaultHash[K](maphash.MakeSeed())}
+	})
+	return s.keyLocks.lock(key)
+}
+
+func (c *Sharded[K, V]) LockKey(key K) (unlock func()) {
+	return c.shard(key).LockKey(key)
+}
@@ -0,0 +1,109 @@
+package slru
+
+import (
+	"fmt"
+
+	"github.com/hey-kong/slru/list"
+)
+
+// KeyPager is a cache listing its keys a page at a time, such as an SLRU
+// or a Sharded.
+type KeyPager[K comparable] interface {
+	KeysPage(cursor Cursor, limit int) (keys []K, next Cursor)
+}
+
+// Cursor is the position of a walk of the keys by KeysPage. The zero
+// Cursor starts a walk, and KeysPage returns it after the last page. A
+// Cursor holds the last key visited, to resume right after it; one
+// decoded from text resumes by position instead, which takes time linear
+// in it.
+type Cursor struct {
+	shard, segment, offset int
+	// anchor is the key of the last entry visited and moves its count of
+	// moves then, to check the entry is still where the walk left it.
+	anchor any
+	moves  uint32
+}
+
+// MarshalText encodes the position of c, without its last key.
+func (c Cursor) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%d.%d.%d", c.shard, c.segment, c.offset)), nil
+}
+
+func (c *Cursor) UnmarshalText(text []byte) error {
+	var d Cursor
+	if _, err := fmt.Sscanf(string(text), "%d.%d.%d", &d.shard, &d.segment, &d.offset); err != nil {
+		return fmt.Errorf("slru: invalid cursor %q: %w", text, err)
+	}
+	if d.shard < 0 || d.segment < 0 || d.offset < 0 {
+		return fmt.Errorf("slru: invalid cursor %q", text)
+	}
+	*c = d
+	return nil
+}
+
+// KeysPage returns up to limit keys from cursor on, in the order of Keys,
+// and the cursor of the next page, to walk the keys of a large cache
+// without copying them all or holding off writers for a full scan. The
+// walk is not a snapshot: keys cached throughout it are returned at least
+// once, unless aging demotes them meanwhile, and keys moving while it runs,
+// when hit or written, may be returned twice.
+func (s *SLRU[K, V]) KeysPage(cursor Cursor, limit int) (keys []K, next Cursor) {
+	limit = max(limit, 1)
+	s.acquireShared()
+	defer s.lock.RUnlock()
+	defer s.lockSegments()()
+
+	now := s.now()
+	segments := s.segments()
+	keys = make([]K, 0, limit)
+	for next = cursor; next.segment < len(segments); next.segment, next.offset, next.anchor = next.segment+1, 0, nil {
+		for e := s.resume(segments[next.segment], next); e != nil; e = e.Prev() {
+			if len(keys) == limit {
+				return keys, next
+			}
+			ent := e.Value.(*entry[K, V])
+			if !s.dead(ent, now) {
+				keys = append(keys, ent.key)
+			}
+			next.offset++
+			next.anchor, next.moves = ent.key, ent.moves
+		}
+	}
+	return keys, Cursor{}
+}
+
+// resume returns the element of segment l the walk of cursor visits next:
+// the one after the last visited if it didn't move, or else the one at
+// the offset of the cursor.
+func (s *SLRU[K, V]) resume(l *list.List, cursor Cursor) *list.Element {
+	if key, ok := cursor.anchor.(K); ok {
+		if e, ok := s.items[key]; ok && e.List() == l && e.Value.(*entry[K, V]).moves == cursor.moves {
+			return e.Prev()
+		}
+	}
+	e := l.Back()
+	for i := 0; i < cursor.offset && e != nil; i++ {
+		e = e.Prev()
+	}
+	return e
+}
+
+// KeysPage is SLRU.KeysPage across shards, walking them one after the
+// other.
+func (c *Sharded[K, V]) KeysPage(cursor Cursor, limit int) (keys []K, next Cursor) {
+	limit = max(limit, 1)
+	for i := cursor.shard; i < len(c.shards); i++ {
+		page, inner := c.shards[i].KeysPage(cursor, limit-len(keys))
+		keys = append(keys, page...)
+		if inner != (Cursor{}) {
+			inner.shard = i
+			return keys, inner
+		}
+		cursor = Cursor{}
+		if len(keys) == limit && i+1 < len(c.shards) {
+			return keys, Cursor{shard: i + 1}
+		}
+	}
+	return keys, Cursor{}
+}
@@ -0,0 +1,72 @@
+package slru
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// walk returns the keys of every page of cache from cursor on.
+func walk(t *testing.T, cache KeyPager[int], cursor Cursor, limit int, between func()) (keys []int) {
+	for {
+		page, next := cache.KeysPage(cursor, limit)
+		require.LessOrEqual(t, len(page), limit)
+		keys = append(keys, page...)
+		if next == (Cursor{}) {
+			return keys
+		}
+		if between != nil {
+			between()
+		}
+		cursor = next
+	}
+}
+
+func TestKeysPage(t *testing.T) {
+	cache := newSLRU[int, int](100)
+	for i := range 50 {
+		cache.Set(i, i)
+	}
+	for i := range 10 {
+		cache.Get(i)
+	}
+	require.Equal(t, cache.Keys(), walk(t, cache, Cursor{}, 7, nil))
+	require.Equal(t, cache.Keys(), walk(t, cache, Cursor{}, 100, nil))
+
+	// keys hit or written during the walk may come twice, never missed
+	i := 0
+	keys := walk(t, cache, Cursor{}, 3, func() {
+		cache.Get(i)
+		cache.Set(49-i, i)
+		i++
+	})
+	require.Subset(t, keys, cache.Keys())
+	require.Greater(t, len(keys), cache.Len())
+}
+
+func TestKeysPageCursorText(t *testing.T) {
+	cache := newSLRU[int, int](100)
+	for i := range 20 {
+		cache.Set(i, i)
+	}
+	page, next := cache.KeysPage(Cursor{}, 5)
+	text, err := next.MarshalText()
+	require.NoError(t, err)
+	require.Equal(t, "0.0.5", string(text))
+
+	var decoded Cursor
+	require.NoError(t, decoded.UnmarshalText(text))
+	keys := append(page, walk(t, cache, decoded, 5, nil)...)
+	require.Equal(t, cache.Keys(), keys)
+	require.Error(t, decoded.UnmarshalText([]byte("0.-1.2")))
+	require.Error(t, decoded.UnmarshalText([]byte("next")))
+}
+
+func TestShardedKeysPage(t *testing.T) {
+	cache := NewSharded[int, int](1000, 4, nil)
+	for i := range 100 {
+		cache.Set(i, i)
+	}
+	require.ElementsMatch(t, cache.Keys(), walk(t, cache, Cursor{}, 9, nil))
+	require.ElementsMatch(t, cache.Keys(), walk(t, cache, Cursor{}, 25, nil))
+}
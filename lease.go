@@ -0,0 +1,216 @@
+package slru
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"time"
+)
+
+// DefaultLeaseTTL is how long a lease lasts unless set with WithLeaseTTL.
+const DefaultLeaseTTL = 10 * time.Second
+
+// ErrLeaseHeld is returned by GetOrLease when another caller holds the
+// lease of a missing key, which it is about to fill: try later.
+var ErrLeaseHeld = errors.New("slru: lease held")
+
+// ErrNoLeases is returned by Tiered.GetOrLease when neither tier grants
+// leases.
+var ErrNoLeases = errors.New("slru: no tier grants leases")
+
+// lease is the right of a caller to fill a missing key. done is closed
+// when the lease ends, by a fill, a release, a removal or a newer lease.
+type lease struct {
+	token   uint64
+	expires time.Time
+	done    chan struct{}
+}
+
+// LeaseStore is a Store granting leases on misses, such as an SLRU, or a
+// client of a remote cache granting them across processes.
+type LeaseStore[K comparable, V any] interface {
+	Store[K, V]
+	GetOrLease(key K) (value V, token uint64, err error)
+	SetWithLease(key K, value V, token uint64) (stored bool)
+	ReleaseLease(key K, token uint64)
+}
+
+// WithLeaseTTL makes the leases of GetOrLease last ttl, after which
+// another caller can take over a fill that didn't complete.
+func WithLeaseTTL[K comparable, V any](ttl time.Duration) Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		s.leaseTTL = ttl
+	}
+}
+
+// GetOrLease gets the value for the given key, or, on a miss, grants the
+// caller a lease to fill it with SetWithLease, identified by a non-zero
+// token. While a lease is held, other misses get ErrLeaseHeld instead of
+// all loading the value at once. Removing the key revokes its lease, so a
+// fill computed before an invalidation can't store stale data.
+func (s *SLRU[K, V]) GetOrLease(key K) (value V, token uint64, err error) {
+	key = s.normalize(key)
+	value, token, _, err = s.getOrLease(key)
+	return value, token, err
+}
+
+// AwaitLease is GetOrLease, but waits for a held lease to end, then tries
+// again, until it gets the value or a lease, or until ctx is done.
+func (s *SLRU[K, V]) AwaitLease(ctx context.Context, key K) (value V, token uint64, err error) {
+	key = s.normalize(key)
+	for {
+		value, token, l, err := s.getOrLease(key)
+		if !errors.Is(err, ErrLeaseHeld) {
+			return value, token, err
+		}
+		timer := time.NewTimer(time.Until(l.expires))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return value, 0, ctx.Err()
+		case <-l.done:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+// getOrLease is GetOrLease, also returning the lease held by another
+// caller with ErrLeaseHeld.
+func (s *SLRU[K, V]) getOrLease(key K) (value V, token uint64, held *lease, err error) {
+	s.acquire()
+	defer s.unlock()
+
+	if value, ok := s.get(key); ok {
+		return value, 0, nil, nil
+	}
+	now := s.now()
+	if l, ok := s.leases[key]; ok {
+		if now.Before(l.expires) {
+			return value, 0, l, ErrLeaseHeld
+		}
+		close(l.done)
+	}
+	if s.leases == nil {
+		s.leases = make(map[K]*lease)
+	}
+	s.leaseSeq++
+	s.leases[key] = &lease{
+		token:   s.leaseSeq,
+		expires: now.Add(cmp.Or(s.leaseTTL, DefaultLeaseTTL)),
+		done:    make(chan struct{}),
+	}
+	return value, s.leaseSeq, nil, nil
+}
+
+// SetWithLease fills the given key under the lease of token, reporting
+// whether it did: it doesn't if the lease expired, was revoked by a
+// removal or was taken over.
+func (s *SLRU[K, V]) SetWithLease(key K, value V, token uint64) (stored bool) {
+	key = s.normalize(key)
+	s.acquire()
+	defer s.unlock()
+
+	l, ok := s.leases[key]
+	if !ok || l.token != token || !s.now().Before(l.expires) {
+		return false
+	}
+	s.endLease(key)
+	s.set(key, value)
+	return true
+}
+
+// ReleaseLease gives up the lease of token on the given key, if still
+// held, so another caller can fill it.
+func (s *SLRU[K, V]) ReleaseLease(key K, token uint64) {
+	key = s.normalize(key)
+	s.acquire()
+	defer s.unlock()
+
+	if l, ok := s.leases[key]; ok && l.token == token {
+		s.endLease(key)
+	}
+}
+
+// endLease ends the lease on key, if any, waking its waiters.
+func (s *SLRU[K, V]) endLease(key K) {
+	if l, ok := s.leases[key]; ok {
+		close(l.done)
+		delete(s.leases, key)
+	}
+}
+
+// endLeases ends every lease.
+func (s *SLRU[K, V]) endLeases() {
+	for _, l := range s.leases {
+		close(l.done)
+	}
+	s.leases = nil
+}
+
+// GetOrLease is SLRU.GetOrLease on the shard of key.
+func (c *Sharded[K, V]) GetOrLease(key K) (value V, token uint64, err error) {
+	return c.shard(key).GetOrLease(key)
+}
+
+// AwaitLease is SLRU.AwaitLease on the shard of key.
+func (c *Sharded[K, V]) AwaitLease(ctx context.Context, key K) (value V, token uint64, err error) {
+	return c.shard(key).AwaitLease(ctx, key)
+}
+
+// SetWithLease is SLRU.SetWithLease on the shard of key.
+func (c *Sharded[K, V]) SetWithLease(key K, value V, token uint64) (stored bool) {
+	return c.shard(key).SetWithLease(key, value, token)
+}
+
+// ReleaseLease is SLRU.ReleaseLease on the shard of key.
+func (c *Sharded[K, V]) ReleaseLease(key K, token uint64) {
+	c.shard(key).ReleaseLease(key, token)
+}
+
+// leaser returns the tier granting leases, and whether it's L2: L2 if it
+// grants them, so leases hold across the processes sharing it, else L1.
+func (t *Tiered[K, V]) leaser() (l LeaseStore[K, V], l2, ok bool) {
+	if l, ok := t.l2.(LeaseStore[K, V]); ok {
+		return l, true, true
+	}
+	l, ok = t.l1.(LeaseStore[K, V])
+	return l, false, ok
+}
+
+// GetOrLease gets the value from L1, or from L2 after storing it in L1,
+// or, on a miss, grants a lease from L2 if it grants them, else from L1.
+func (t *Tiered[K, V]) GetOrLease(key K) (value V, token uint64, err error) {
+	if value, ok := t.l1.Get(key); ok {
+		return value, 0, nil
+	}
+	l, l2, ok := t.leaser()
+	if !ok {
+		return value, 0, ErrNoLeases
+	}
+	value, token, err = l.GetOrLease(key)
+	if err == nil && token == 0 && l2 {
+		t.l1.Set(key, value)
+	}
+	return value, token, err
+}
+
+// SetWithLease fills the key under a lease of GetOrLease, in the tier that
+// granted it and in L1, reporting whether the lease was still valid.
+func (t *Tiered[K, V]) SetWithLease(key K, value V, token uint64) (stored bool) {
+	l, l2, ok := t.leaser()
+	if !ok || !l.SetWithLease(key, value, token) {
+		return false
+	}
+	if l2 {
+		t.l1.Set(key, value)
+	}
+	return true
+}
+
+// ReleaseLease gives up a lease of GetOrLease.
+func (t *Tiered[K, V]) ReleaseLease(key K, token uint64) {
+	if l, _, ok := t.leaser(); ok {
+		l.ReleaseLease(key, token)
+	}
+}
@@ -0,0 +1,111 @@
+package slru
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLease(t *testing.T) {
+	cache := newSLRU[int, int](100)
+	_, token, err := cache.GetOrLease(1)
+	require.NoError(t, err)
+	require.NotZero(t, token)
+	_, _, err = cache.GetOrLease(1)
+	require.ErrorIs(t, err, ErrLeaseHeld)
+
+	require.False(t, cache.SetWithLease(1, 10, token+1))
+	require.True(t, cache.SetWithLease(1, 10, token))
+	require.False(t, cache.SetWithLease(1, 11, token))
+	value, token, err := cache.GetOrLease(1)
+	require.NoError(t, err)
+	require.Zero(t, token)
+	require.Equal(t, 10, value)
+
+	// a removal revokes the lease of a fill computed before it
+	cache.Remove(1)
+	_, token, err = cache.GetOrLease(1)
+	require.NoError(t, err)
+	cache.Remove(1)
+	require.False(t, cache.SetWithLease(1, 10, token))
+
+	_, token, err = cache.GetOrLease(2)
+	require.NoError(t, err)
+	cache.ReleaseLease(2, token)
+	_, next, err := cache.GetOrLease(2)
+	require.NoError(t, err)
+	require.Greater(t, next, token)
+
+	cache.Purge()
+	require.False(t, cache.SetWithLease(2, 20, next))
+}
+
+func TestLeaseExpiry(t *testing.T) {
+	cache := newSLRU[int, int](100, WithLeaseTTL[int, int](time.Millisecond))
+	_, token, err := cache.GetOrLease(1)
+	require.NoError(t, err)
+	time.Sleep(2 * time.Millisecond)
+	require.False(t, cache.SetWithLease(1, 10, token))
+	_, next, err := cache.GetOrLease(1)
+	require.NoError(t, err)
+	require.NotEqual(t, token, next)
+}
+
+func TestAwaitLease(t *testing.T) {
+	cache := newSLRU[int, int](100)
+	_, token, err := cache.GetOrLease(1)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	values := make([]int, 4)
+	for i := range values {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			values[i], _, _ = cache.AwaitLease(context.Background(), 1)
+		}()
+	}
+	time.Sleep(time.Millisecond)
+	require.True(t, cache.SetWithLease(1, 10, token))
+	wg.Wait()
+	require.Equal(t, []int{10, 10, 10, 10}, values)
+
+	_, _, err = cache.GetOrLease(2)
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	_, _, err = cache.AwaitLease(ctx, 2)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestTieredLease(t *testing.T) {
+	shared := newSLRU[int, int](100)
+	a := NewTiered[int, int](New[int, int](10), shared, false)
+	b := NewTiered[int, int](New[int, int](10), shared, false)
+
+	_, token, err := a.GetOrLease(1)
+	require.NoError(t, err)
+	_, _, err = b.GetOrLease(1)
+	require.ErrorIs(t, err, ErrLeaseHeld)
+	require.True(t, a.SetWithLease(1, 10, token))
+	require.True(t, a.L1().Contains(1))
+
+	value, token, err := b.GetOrLease(1)
+	require.NoError(t, err)
+	require.Zero(t, token)
+	require.Equal(t, 10, value)
+	require.True(t, b.L1().Contains(1))
+
+	local := NewTiered[int, int](New[int, int](10), NewFIFO[int, int](10), false)
+	_, token, err = local.GetOrLease(1)
+	require.NoError(t, err)
+	require.True(t, local.SetWithLease(1, 10, token))
+	require.True(t, local.L1().Contains(1))
+
+	none := NewTiered[int, int](NewARC[int, int](10), NewFIFO[int, int](10), false)
+	_, _, err = none.GetOrLease(1)
+	require.ErrorIs(t, err, ErrNoLeases)
+}
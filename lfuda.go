@@ -0,0 +1,142 @@
+package slru
+
+import (
+	"container/heap"
+	"slices"
+)
+
+// NewLFUDA creates a cache of up to size entries replaced by LFU with
+// Dynamic Aging. Entries are ranked by their hit count plus the age of the
+// cache when they were last hit, where the age is the rank of the last
+// victim, so once popular entries that stopped being hit eventually give
+// way to newer ones. It suits workloads dominated by frequency rather than
+// recency.
+func NewLFUDA[K comparable, V any](size int, opts ...PolicyOption[K, V]) *Policy[K, V] {
+	return newPolicy[K, V](newLFUDA[K](size), opts...)
+}
+
+// lfudaItem is a resident key of LFUDA and its position in the heap.
+type lfudaItem[K comparable] struct {
+	key      K
+	hits     uint64
+	priority uint64
+	// seq orders accesses, breaking ties in favour of the most recent.
+	seq   uint64
+	index int
+}
+
+// lfudaHeap is a min-heap of items by priority, then by access order.
+type lfudaHeap[K comparable] []*lfudaItem[K]
+
+func (h lfudaHeap[K]) Len() int { return len(h) }
+
+func (h lfudaHeap[K]) Less(i, j int) bool { return lfudaLess(h[i], h[j]) }
+
+func (h lfudaHeap[K]) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *lfudaHeap[K]) Push(x any) {
+	item := x.(*lfudaItem[K])
+	item.index = len(*h)
+	*h = append(*h, item)
+}
+
+func (h *lfudaHeap[K]) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return item
+}
+
+func lfudaLess[K comparable](a, b *lfudaItem[K]) bool {
+	if a.priority != b.priority {
+		return a.priority < b.priority
+	}
+	return a.seq < b.seq
+}
+
+// lfuda is the LFUDA replacer. age is the priority of the last victim.
+type lfuda[K comparable] struct {
+	size  int
+	age   uint64
+	seq   uint64
+	heap  lfudaHeap[K]
+	items map[K]*lfudaItem[K]
+}
+
+func newLFUDA[K comparable](size int) *lfuda[K] {
+	l := &lfuda[K]{size: max(size, 0)}
+	l.reset()
+	return l
+}
+
+func (l *lfuda[K]) reset() {
+	l.age, l.seq = 0, 0
+	l.heap = nil
+	l.items = make(map[K]*lfudaItem[K])
+}
+
+func (l *lfuda[K]) add(key K, evict func(key K)) {
+	if l.size < 1 {
+		evict(key)
+		return
+	}
+	for len(l.heap) >= l.size {
+		l.evict(evict)
+	}
+	l.seq++
+	item := &lfudaItem[K]{key: key, hits: 1, priority: l.age + 1, seq: l.seq}
+	l.items[key] = item
+	heap.Push(&l.heap, item)
+}
+
+func (l *lfuda[K]) evict(evict func(key K)) {
+	item := heap.Pop(&l.heap).(*lfudaItem[K])
+	delete(l.items, item.key)
+	l.age = item.priority
+	evict(item.key)
+}
+
+func (l *lfuda[K]) hit(key K) {
+	if item, ok := l.items[key]; ok {
+		l.seq++
+		item.hits++
+		item.priority = item.hits + l.age
+		item.seq = l.seq
+		heap.Fix(&l.heap, item.index)
+	}
+}
+
+func (l *lfuda[K]) remove(key K) {
+	if item, ok := l.items[key]; ok {
+		heap.Remove(&l.heap, item.index)
+		delete(l.items, key)
+	}
+}
+
+func (l *lfuda[K]) resize(size int, evict func(key K)) {
+	l.size = max(size, 0)
+	for len(l.heap) > l.size {
+		l.evict(evict)
+	}
+}
+
+// keys returns the resident keys from the lowest priority.
+func (l *lfuda[K]) keys() []K {
+	items := slices.Clone(l.heap)
+	slices.SortFunc(items, func(a, b *lfudaItem[K]) int {
+		if lfudaLess(a, b) {
+			return -1
+		}
+		return 1
+	})
+	keys := make([]K, len(items))
+	for i, item := range items {
+		keys[i] = item.key
+	}
+	return keys
+}
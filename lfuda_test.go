@@ -0,0 +1,47 @@
+package slru
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLFUDA(t *testing.T) {
+	testPolicy(t, NewLFUDA[int, int])
+}
+
+func TestLFUDAKeepsFrequentEntries(t *testing.T) {
+	cache := NewLFUDA[int, int](4)
+	for i := 0; i < 4; i++ {
+		cache.Set(i, i)
+	}
+	for i := 0; i < 3; i++ {
+		cache.Get(0)
+		cache.Get(1)
+	}
+	cache.Get(2)
+	for i := 10; i < 14; i++ {
+		cache.Set(i, i)
+	}
+	require.True(t, cache.Contains(0))
+	require.True(t, cache.Contains(1))
+	require.False(t, cache.Contains(2))
+	require.Equal(t, []int{12, 0, 1, 13}, cache.Keys())
+}
+
+func TestLFUDAAges(t *testing.T) {
+	l := newLFUDA[int](2)
+	var evicted []int
+	evict := func(key int) { evicted = append(evicted, key) }
+	l.add(1, evict)
+	for i := 0; i < 5; i++ {
+		l.hit(1)
+	}
+	// each victim raises the age until newcomers outrank the stale key 1
+	for key := 2; key < 10; key++ {
+		l.add(key, evict)
+		l.hit(key)
+	}
+	require.Contains(t, evicted, 1)
+	require.Greater(t, l.age, uint64(6))
+}
@@ -0,0 +1,241 @@
+package slru
+
+import "github.com/hey-kong/slru/list"
+
+// lirsHIRRatio is the share of a LIRS cache holding HIR entries.
+const lirsHIRRatio = 0.01
+
+// NewLIRS creates a cache of up to size entries replaced by LIRS, the Low
+// Inter-reference Recency Set policy of Jiang and Zhang. Entries are ranked
+// by the recency of their second-to-last access rather than their last,
+// so those re-accessed at short intervals (LIR) stay while entries seen
+// once or rarely (HIR) pass through a small queue, which suits looping and
+// scanning access patterns such as block I/O.
+func NewLIRS[K comparable, V any](size int, opts ...PolicyOption[K, V]) *Policy[K, V] {
+	return newPolicy[K, V](newLIRS[K](size), opts...)
+}
+
+// lirsNode is the state of a key known to LIRS. Its elements in the stack,
+// the queue and the ghost list are nil when it isn't there.
+type lirsNode[K comparable] struct {
+	key      K
+	lir      bool
+	resident bool
+	s, q     *list.Element
+	ghost    *list.Element
+}
+
+// lirs is the LIRS replacer. The stack s orders keys by recency, top at
+// the front, and is pruned so that its bottom is always LIR. The queue q
+// holds the resident HIR keys, next victim at the back. Non-resident HIR
+// keys stay in the stack to detect their reuse, and are forgotten in the
+// order of ghosts once there are more than size of them.
+type lirs[K comparable] struct {
+	size, lirSize int
+	lirs          int
+	s, q, ghosts  *list.List
+	nodes         map[K]*lirsNode[K]
+}
+
+func newLIRS[K comparable](size int) *lirs[K] {
+	l := &lirs[K]{}
+	l.setSize(size)
+	l.reset()
+	return l
+}
+
+func (l *lirs[K]) setSize(size int) {
+	l.size = max(size, 0)
+	hir := max(int(lirsHIRRatio*float64(l.size)), 1)
+	l.lirSize = max(l.size-hir, 0)
+}
+
+func (l *lirs[K]) reset() {
+	l.lirs = 0
+	l.s, l.q, l.ghosts = list.New(), list.New(), list.New()
+	l.nodes = make(map[K]*lirsNode[K])
+}
+
+func (l *lirs[K]) add(key K, evict func(key K)) {
+	if l.size < 1 {
+		evict(key)
+		return
+	}
+	if l.lirs+l.q.Len() >= l.size {
+		l.evictHIR(evict)
+	}
+	n, ok := l.nodes[key]
+	if l.lirs < l.lirSize {
+		// warming up, or refilling after removals: new keys become LIR
+		if ok {
+			l.forgetGhost(n)
+			l.s.MoveToFront(n.s)
+		} else {
+			n = &lirsNode[K]{key: key}
+			n.s = l.s.PushFront(n)
+			l.nodes[key] = n
+		}
+		n.lir, n.resident = true, true
+		l.lirs++
+		return
+	}
+	if ok && n.s != nil {
+		// a non-resident HIR key reused within the stack becomes LIR
+		l.forgetGhost(n)
+		n.lir, n.resident = true, true
+		l.s.MoveToFront(n.s)
+		l.lirs++
+		l.rebalance()
+	} else {
+		if !ok {
+			n = &lirsNode[K]{key: key}
+			l.nodes[key] = n
+		}
+		n.resident = true
+		n.s = l.s.PushFront(n)
+		n.q = l.q.PushFront(n)
+	}
+	l.trimGhosts()
+}
+
+func (l *lirs[K]) hit(key K) {
+	n, ok := l.nodes[key]
+	if !ok || !n.resident {
+		return
+	}
+	if n.lir {
+		l.s.MoveToFront(n.s)
+		l.prune()
+		return
+	}
+	if n.s != nil {
+		// reused within the stack: HIR becomes LIR
+		n.lir = true
+		l.q.Remove(n.q)
+		n.q = nil
+		l.s.MoveToFront(n.s)
+		l.lirs++
+		l.rebalance()
+		return
+	}
+	n.s = l.s.PushFront(n)
+	l.q.MoveToFront(n.q)
+}
+
+func (l *lirs[K]) remove(key K) {
+	n, ok := l.nodes[key]
+	if !ok || !n.resident {
+		return
+	}
+	if n.lir {
+		l.lirs--
+	}
+	if n.q != nil {
+		l.q.Remove(n.q)
+	}
+	if n.s != nil {
+		l.s.Remove(n.s)
+	}
+	delete(l.nodes, key)
+	l.prune()
+}
+
+func (l *lirs[K]) resize(size int, evict func(key K)) {
+	l.setSize(size)
+	l.rebalance()
+	for l.lirs+l.q.Len() > l.size {
+		l.evictHIR(evict)
+	}
+	l.trimGhosts()
+}
+
+// evictHIR evicts the resident HIR key at the back of the queue, which
+// stays in the stack as a ghost if it is there.
+func (l *lirs[K]) evictHIR(evict func(key K)) {
+	e := l.q.Back()
+	if e == nil {
+		return
+	}
+	n := l.q.Remove(e).(*lirsNode[K])
+	n.q = nil
+	n.resident = false
+	if n.s != nil {
+		n.ghost = l.ghosts.PushFront(n)
+	} else {
+		delete(l.nodes, n.key)
+	}
+	evict(n.key)
+}
+
+// rebalance demotes LIR keys while there are more than lirSize.
+func (l *lirs[K]) rebalance() {
+	for l.lirs > l.lirSize {
+		l.demote()
+	}
+	l.prune()
+}
+
+// demote turns the LIR key at the bottom of the stack into a resident HIR
+// key at the front of the queue.
+func (l *lirs[K]) demote() {
+	l.prune()
+	e := l.s.Back()
+	if e == nil {
+		return
+	}
+	n := l.s.Remove(e).(*lirsNode[K])
+	n.s = nil
+	n.lir = false
+	n.q = l.q.PushFront(n)
+	l.lirs--
+	l.prune()
+}
+
+// prune removes HIR keys from the bottom of the stack until an LIR key is
+// there, forgetting the non-resident ones.
+func (l *lirs[K]) prune() {
+	for e := l.s.Back(); e != nil; e = l.s.Back() {
+		n := e.Value.(*lirsNode[K])
+		if n.lir {
+			return
+		}
+		l.s.Remove(e)
+		n.s = nil
+		if !n.resident {
+			l.forgetGhost(n)
+			delete(l.nodes, n.key)
+		}
+	}
+}
+
+func (l *lirs[K]) forgetGhost(n *lirsNode[K]) {
+	if n.ghost != nil {
+		l.ghosts.Remove(n.ghost)
+		n.ghost = nil
+	}
+}
+
+// trimGhosts forgets the oldest non-resident keys over size.
+func (l *lirs[K]) trimGhosts() {
+	for l.ghosts.Len() > l.size {
+		n := l.ghosts.Remove(l.ghosts.Back()).(*lirsNode[K])
+		n.ghost = nil
+		l.s.Remove(n.s)
+		delete(l.nodes, n.key)
+	}
+}
+
+// keys returns the resident HIR keys from the back of the queue, then the
+// LIR keys from the bottom of the stack.
+func (l *lirs[K]) keys() []K {
+	keys := make([]K, 0, l.lirs+l.q.Len())
+	for e := l.q.Back(); e != nil; e = e.Prev() {
+		keys = append(keys, e.Value.(*lirsNode[K]).key)
+	}
+	for e := l.s.Back(); e != nil; e = e.Prev() {
+		if n := e.Value.(*lirsNode[K]); n.lir {
+			keys = append(keys, n.key)
+		}
+	}
+	return keys
+}
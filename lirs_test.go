@@ -0,0 +1,72 @@
+package slru
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLIRS(t *testing.T) {
+	testPolicy(t, NewLIRS[int, int])
+}
+
+func TestLIRSKeepsLoopsResident(t *testing.T) {
+	// a loop slightly larger than the cache defeats LRU entirely
+	lirs, lru := NewLIRS[int, int](100), newSLRU[int, int](100)
+	for round := 0; round < 10; round++ {
+		for i := 0; i < 110; i++ {
+			for _, cache := range []Cache[int, int]{lirs, lru} {
+				if _, ok := cache.Get(i); !ok {
+					cache.Set(i, i)
+				}
+			}
+		}
+	}
+	lirsStats, lruStats := lirs.Stats(), lru.Stats()
+	require.Greater(t, lirsStats.HitRatio(), 0.8)
+	require.Greater(t, lirsStats.HitRatio(), lruStats.HitRatio())
+}
+
+func TestLIRSInvariants(t *testing.T) {
+	l := newLIRS[int](20)
+	resident := make(map[int]bool)
+	evict := func(key int) {
+		require.True(t, resident[key])
+		delete(resident, key)
+	}
+	r := rand.New(rand.NewPCG(3, 4))
+	for i := 0; i < 20000; i++ {
+		key := int(r.Int64N(60))
+		switch {
+		case i%97 == 0 && resident[key]:
+			l.remove(key)
+			delete(resident, key)
+		case resident[key]:
+			l.hit(key)
+		default:
+			l.add(key, evict)
+			resident[key] = true
+		}
+		if i == 10000 {
+			l.resize(8, evict)
+		}
+
+		require.LessOrEqual(t, len(resident), l.size)
+		require.LessOrEqual(t, l.lirs, l.lirSize)
+		require.Equal(t, len(resident), l.lirs+l.q.Len())
+		require.LessOrEqual(t, l.ghosts.Len(), l.size)
+		require.ElementsMatch(t, keysOf(resident), l.keys())
+		if e := l.s.Back(); e != nil {
+			require.True(t, e.Value.(*lirsNode[int]).lir)
+		}
+	}
+}
+
+func keysOf(m map[int]bool) []int {
+	keys := make([]int, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,86 @@
+package list
+
+// BoundedList is a list holding at most a fixed number of elements. Pushing
+// onto a full list displaces the element at the other end, which is passed
+// to the displace callback, so a bounded LRU queue takes a single call per
+// insertion.
+type BoundedList struct {
+	l        List
+	capacity int
+	displace func(v any)
+}
+
+// NewBounded returns an empty list holding up to capacity elements. If not
+// nil, displace is called with the value of each displaced element.
+func NewBounded(capacity int, displace func(v any)) *BoundedList {
+	b := &BoundedList{capacity: capacity, displace: displace}
+	b.l.Init()
+	return b
+}
+
+// Len returns the number of elements of b.
+func (b *BoundedList) Len() int { return b.l.Len() }
+
+// Cap returns the maximum number of elements of b.
+func (b *BoundedList) Cap() int { return b.capacity }
+
+// Front returns the first element of b or nil if it is empty.
+func (b *BoundedList) Front() *Element { return b.l.Front() }
+
+// Back returns the last element of b or nil if it is empty.
+func (b *BoundedList) Back() *Element { return b.l.Back() }
+
+// PushFront inserts v at the front of b, displacing the back element if b
+// is full, and returns the new element, or nil if b has no capacity.
+func (b *BoundedList) PushFront(v any) *Element {
+	if b.capacity < 1 {
+		b.dropped(v)
+		return nil
+	}
+	if b.l.Len() >= b.capacity {
+		b.dropped(b.l.Remove(b.l.Back()))
+	}
+	return b.l.PushFront(v)
+}
+
+// PushBack inserts v at the back of b, displacing the front element if b
+// is full, and returns the new element, or nil if b has no capacity.
+func (b *BoundedList) PushBack(v any) *Element {
+	if b.capacity < 1 {
+		b.dropped(v)
+		return nil
+	}
+	if b.l.Len() >= b.capacity {
+		b.dropped(b.l.Remove(b.l.Front()))
+	}
+	return b.l.PushBack(v)
+}
+
+// Remove removes e from b if it is an element of b and returns its value.
+func (b *BoundedList) Remove(e *Element) any {
+	if e.list != &b.l {
+		return e.Value
+	}
+	return b.l.Remove(e)
+}
+
+// MoveToFront moves e to the front of b.
+func (b *BoundedList) MoveToFront(e *Element) { b.l.MoveToFront(e) }
+
+// MoveToBack moves e to the back of b.
+func (b *BoundedList) MoveToBack(e *Element) { b.l.MoveToBack(e) }
+
+// SetCap changes the capacity of b, displacing elements from the back
+// until they fit.
+func (b *BoundedList) SetCap(capacity int) {
+	b.capacity = capacity
+	for b.l.Len() > max(capacity, 0) {
+		b.dropped(b.l.Remove(b.l.Back()))
+	}
+}
+
+func (b *BoundedList) dropped(v any) {
+	if b.displace != nil {
+		b.displace(v)
+	}
+}
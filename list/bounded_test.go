@@ -0,0 +1,53 @@
+package list
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func boundedValues(t *testing.T, b *BoundedList) []any {
+	t.Helper()
+	return values(t, &b.l)
+}
+
+func TestBoundedList(t *testing.T) {
+	var displaced []any
+	b := NewBounded(3, func(v any) { displaced = append(displaced, v) })
+	e1 := b.PushFront(1)
+	b.PushFront(2)
+	b.PushFront(3)
+	require.Empty(t, displaced)
+	require.Equal(t, 3, b.Len())
+	require.Equal(t, 3, b.Cap())
+
+	b.MoveToFront(e1)
+	b.PushFront(4)
+	require.Equal(t, []any{4, 1, 3}, boundedValues(t, b))
+	require.Equal(t, []any{2}, displaced)
+
+	b.PushBack(5)
+	require.Equal(t, []any{1, 3, 5}, boundedValues(t, b))
+	require.Equal(t, []any{2, 4}, displaced)
+
+	b.MoveToBack(e1)
+	require.Equal(t, 1, b.Remove(b.Back()))
+	require.Equal(t, 3, b.Front().Value)
+	require.Equal(t, 5, b.Back().Value)
+
+	// foreign elements are left alone
+	other := New()
+	e := other.PushBack(6)
+	require.Equal(t, 6, b.Remove(e))
+	require.Equal(t, 1, other.Len())
+
+	b.SetCap(1)
+	require.Equal(t, []any{3}, boundedValues(t, b))
+	require.Equal(t, []any{2, 4, 5}, displaced)
+
+	b.SetCap(0)
+	require.Nil(t, b.PushFront(7))
+	require.Nil(t, b.PushBack(8))
+	require.Equal(t, []any{2, 4, 5, 3, 7, 8}, displaced)
+	require.Zero(t, b.Len())
+}
@@ -0,0 +1,56 @@
+package list
+
+import "fmt"
+
+// Check validates the invariants of l: the root sentinel closes the ring,
+// every element links back to its neighbours and belongs to l, the length
+// matches the elements, and the free list holds only detached elements. It
+// takes time linear in the length of l and is meant for tests and debug
+// builds.
+func (l *List) Check() error {
+	if l.root.next == nil || l.root.prev == nil {
+		if l.root.next != l.root.prev || l.len != 0 {
+			return fmt.Errorf("list: uninitialized root with length %d", l.len)
+		}
+		return l.checkFree()
+	}
+	if l.root.list != nil || l.root.Value != nil {
+		return fmt.Errorf("list: root sentinel holds a list or value")
+	}
+	n := 0
+	for e := &l.root; ; e = e.next {
+		if e.next == nil || e.next.prev != e {
+			return fmt.Errorf("list: element %d not linked back by its successor", n)
+		}
+		if e.next == &l.root {
+			break
+		}
+		n++
+		if e.next.list != l {
+			return fmt.Errorf("list: element %d belongs to another list", n)
+		}
+		if n > l.len {
+			return fmt.Errorf("list: more elements than its length %d", l.len)
+		}
+	}
+	if n != l.len {
+		return fmt.Errorf("list: %d elements, length %d", n, l.len)
+	}
+	return l.checkFree()
+}
+
+func (l *List) checkFree() error {
+	n := 0
+	for e := l.free; e != nil; e = e.next {
+		if e.list != nil || e.prev != nil || e.Value != nil {
+			return fmt.Errorf("list: free element %d is not detached", n)
+		}
+		if n++; n > l.maxFree {
+			return fmt.Errorf("list: free list exceeds its bound %d", l.maxFree)
+		}
+	}
+	if n != l.nfree {
+		return fmt.Errorf("list: %d free elements, counted %d", n, l.nfree)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package list
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	var zero List
+	require.NoError(t, zero.Check())
+
+	l := NewWithFreeList(2)
+	e1 := l.PushBack(1)
+	e2 := l.PushBack(2)
+	l.PushBack(3)
+	require.NoError(t, l.Check())
+	l.Remove(e1)
+	require.NoError(t, l.Check())
+
+	l.len++
+	require.ErrorContains(t, l.Check(), "2 elements, length 3")
+	l.len--
+
+	e2.next.prev = e2.prev
+	require.ErrorContains(t, l.Check(), "not linked back")
+	e2.next.prev = e2
+
+	other := New()
+	e2.list = other
+	require.ErrorContains(t, l.Check(), "belongs to another list")
+	e2.list = l
+
+	l.free.Value = 1
+	require.ErrorContains(t, l.Check(), "not detached")
+	l.free.Value = nil
+	l.nfree++
+	require.ErrorContains(t, l.Check(), "1 free elements, counted 2")
+	l.nfree--
+	require.NoError(t, l.Check())
+}
@@ -0,0 +1,113 @@
+package list
+
+import "sync"
+
+// Concurrent is a List guarded by a mutex, safe for use by multiple
+// goroutines. Each method holds the lock for its whole operation; Do runs
+// compound operations, like popping the back element and pushing it
+// elsewhere, atomically.
+//
+// Elements returned by its methods serve as handles for later calls. Their
+// links must only be followed, and their values only changed, within Do or
+// Each.
+//
+// The zero value is an empty list ready to use.
+type Concurrent struct {
+	lock sync.Mutex
+	l    List
+}
+
+// NewConcurrent returns an empty concurrent list.
+func NewConcurrent() *Concurrent { return new(Concurrent) }
+
+// Len returns the number of elements of c.
+func (c *Concurrent) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.l.Len()
+}
+
+// PushFront inserts v at the front of c and returns its element.
+func (c *Concurrent) PushFront(v any) *Element {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.l.PushFront(v)
+}
+
+// PushBack inserts v at the back of c and returns its element.
+func (c *Concurrent) PushBack(v any) *Element {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.l.PushBack(v)
+}
+
+// Remove removes e from c if it is an element of c and returns its value.
+func (c *Concurrent) Remove(e *Element) any {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.l.Remove(e)
+}
+
+// PopFront removes the first element of c and returns its value, or false
+// if c is empty.
+func (c *Concurrent) PopFront() (v any, ok bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if e := c.l.Front(); e != nil {
+		return c.l.Remove(e), true
+	}
+	return nil, false
+}
+
+// PopBack removes the last element of c and returns its value, or false if
+// c is empty.
+func (c *Concurrent) PopBack() (v any, ok bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if e := c.l.Back(); e != nil {
+		return c.l.Remove(e), true
+	}
+	return nil, false
+}
+
+// MoveToFront moves e to the front of c.
+// If e is not an element of c, the list is not modified.
+func (c *Concurrent) MoveToFront(e *Element) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.l.MoveToFront(e)
+}
+
+// MoveToBack moves e to the back of c.
+// If e is not an element of c, the list is not modified.
+func (c *Concurrent) MoveToBack(e *Element) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.l.MoveToBack(e)
+}
+
+// Each calls fn for each element of c from front to back, like List.Each,
+// holding the lock throughout. fn must not call c.
+func (c *Concurrent) Each(fn func(e *Element) bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.l.Each(fn)
+}
+
+// Do calls fn with the underlying list under the lock. fn must not call c
+// or retain l.
+func (c *Concurrent) Do(fn func(l *List)) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	fn(&c.l)
+}
@@ -0,0 +1,52 @@
+package list
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConcurrent(t *testing.T) {
+	var c Concurrent
+	_, ok := c.PopBack()
+	require.False(t, ok)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				e := c.PushFront(i)
+				c.MoveToBack(e)
+				c.PushBack(i)
+				c.MoveToFront(e)
+				c.Remove(e)
+				c.PopFront()
+			}
+		}()
+	}
+	wg.Wait()
+	require.Zero(t, c.Len())
+
+	c.PushBack(1)
+	c.PushBack(2)
+	c.PushFront(0)
+	c.Do(func(l *List) {
+		l.MoveToBack(l.Front())
+	})
+	var got []any
+	c.Each(func(e *Element) bool {
+		got = append(got, e.Value)
+		return true
+	})
+	require.Equal(t, []any{1, 2, 0}, got)
+
+	v, ok := c.PopBack()
+	require.True(t, ok)
+	require.Equal(t, 0, v)
+	v, _ = c.PopFront()
+	require.Equal(t, 1, v)
+	require.Equal(t, 1, NewConcurrent().PushFront(1).Value)
+}
@@ -0,0 +1,25 @@
+package list
+
+// Each calls fn for each element of l from front to back until fn returns
+// false. fn may remove the element it is given, or move it elsewhere,
+// without disturbing the iteration.
+func (l *List) Each(fn func(e *Element) bool) {
+	for e := l.Front(); e != nil; {
+		next := e.Next()
+		if !fn(e) {
+			return
+		}
+		e = next
+	}
+}
+
+// EachReverse is Each from back to front.
+func (l *List) EachReverse(fn func(e *Element) bool) {
+	for e := l.Back(); e != nil; {
+		prev := e.Prev()
+		if !fn(e) {
+			return
+		}
+		e = prev
+	}
+}
@@ -0,0 +1,138 @@
+package list
+
+// Links are the link fields of an element of an Intrusive list. A type
+// joins intrusive lists by holding Links in a field and returning them from
+// its Links method, so list membership costs no allocation of its own and
+// elements are reached without a type assertion.
+type Links[E comparable] struct {
+	next, prev E
+	list       *owner
+}
+
+// owner identifies the Intrusive list an element is in.
+type owner struct{ _ byte }
+
+// Linked is implemented by pointers to element types holding Links.
+type Linked[E comparable] interface {
+	comparable
+	Links() *Links[E]
+}
+
+// Intrusive is a doubly linked list of elements that embed their own links,
+// such as cache entries, rather than being wrapped in an Element. An
+// element is in at most one list at a time.
+//
+// The zero value is an empty list ready to use.
+type Intrusive[E Linked[E]] struct {
+	head, tail E
+	len        int
+	self       owner
+}
+
+// NewIntrusive returns an empty intrusive list.
+func NewIntrusive[E Linked[E]]() *Intrusive[E] { return new(Intrusive[E]) }
+
+// Len returns the number of elements of l.
+func (l *Intrusive[E]) Len() int { return l.len }
+
+// Front returns the first element of l or the zero E if it is empty.
+func (l *Intrusive[E]) Front() E { return l.head }
+
+// Back returns the last element of l or the zero E if it is empty.
+func (l *Intrusive[E]) Back() E { return l.tail }
+
+// Next returns the element after e or the zero E.
+func (l *Intrusive[E]) Next(e E) E { return e.Links().next }
+
+// Prev returns the element before e or the zero E.
+func (l *Intrusive[E]) Prev(e E) E { return e.Links().prev }
+
+// Contains reports whether e is an element of l.
+func (l *Intrusive[E]) Contains(e E) bool { return e.Links().list == &l.self }
+
+// PushFront inserts e, which must not be in any list, at the front of l.
+// If e is already in a list, nothing is modified.
+func (l *Intrusive[E]) PushFront(e E) {
+	var zero E
+	if e.Links().list != nil {
+		return
+	}
+	l.insertAfter(e, zero)
+}
+
+// PushBack inserts e, which must not be in any list, at the back of l.
+// If e is already in a list, nothing is modified.
+func (l *Intrusive[E]) PushBack(e E) {
+	if e.Links().list != nil {
+		return
+	}
+	l.insertAfter(e, l.tail)
+}
+
+// Remove removes e from l if e is an element of l.
+func (l *Intrusive[E]) Remove(e E) {
+	if e.Links().list != &l.self {
+		return
+	}
+	l.unlink(e)
+	*e.Links() = Links[E]{}
+}
+
+// MoveToFront moves e to the front of l.
+// If e is not an element of l, the list is not modified.
+func (l *Intrusive[E]) MoveToFront(e E) {
+	var zero E
+	if e.Links().list != &l.self || l.head == e {
+		return
+	}
+	l.unlink(e)
+	l.insertAfter(e, zero)
+}
+
+// MoveToBack moves e to the back of l.
+// If e is not an element of l, the list is not modified.
+func (l *Intrusive[E]) MoveToBack(e E) {
+	if e.Links().list != &l.self || l.tail == e {
+		return
+	}
+	l.unlink(e)
+	l.insertAfter(e, l.tail)
+}
+
+// insertAfter links e after at, or at the front if at is the zero E.
+func (l *Intrusive[E]) insertAfter(e, at E) {
+	var zero E
+	links := e.Links()
+	links.list = &l.self
+	links.prev = at
+	if at == zero {
+		links.next = l.head
+		l.head = e
+	} else {
+		links.next = at.Links().next
+		at.Links().next = e
+	}
+	if links.next == zero {
+		l.tail = e
+	} else {
+		links.next.Links().prev = e
+	}
+	l.len++
+}
+
+// unlink detaches e from its neighbours, leaving its own links stale.
+func (l *Intrusive[E]) unlink(e E) {
+	var zero E
+	links := e.Links()
+	if links.prev == zero {
+		l.head = links.next
+	} else {
+		links.prev.Links().next = links.next
+	}
+	if links.next == zero {
+		l.tail = links.prev
+	} else {
+		links.next.Links().prev = links.prev
+	}
+	l.len--
+}
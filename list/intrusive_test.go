@@ -0,0 +1,98 @@
+package list
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type item struct {
+	links Links[*item]
+	value int
+}
+
+func (i *item) Links() *Links[*item] { return &i.links }
+
+func intrusiveValues(t *testing.T, l *Intrusive[*item]) []int {
+	t.Helper()
+	var forward, backward []int
+	for e := l.Front(); e != nil; e = l.Next(e) {
+		require.True(t, l.Contains(e))
+		forward = append(forward, e.value)
+	}
+	for e := l.Back(); e != nil; e = l.Prev(e) {
+		backward = append([]int{e.value}, backward...)
+	}
+	require.Equal(t, forward, backward)
+	require.Len(t, forward, l.Len())
+	return forward
+}
+
+func TestIntrusive(t *testing.T) {
+	var l Intrusive[*item]
+	items := make([]*item, 5)
+	for i := range items {
+		items[i] = &item{value: i}
+	}
+	require.Nil(t, l.Front())
+	l.PushBack(items[1])
+	l.PushFront(items[0])
+	l.PushBack(items[2])
+	l.PushBack(items[3])
+	require.Equal(t, []int{0, 1, 2, 3}, intrusiveValues(t, &l))
+
+	// an element is in one list at a time
+	other := NewIntrusive[*item]()
+	other.PushBack(items[0])
+	other.Remove(items[0])
+	other.MoveToFront(items[0])
+	require.Zero(t, other.Len())
+	l.PushBack(items[0])
+	require.Equal(t, []int{0, 1, 2, 3}, intrusiveValues(t, &l))
+
+	l.MoveToFront(items[2])
+	l.MoveToBack(items[0])
+	require.Equal(t, []int{2, 1, 3, 0}, intrusiveValues(t, &l))
+
+	l.Remove(items[1])
+	l.Remove(items[2])
+	l.Remove(items[0])
+	require.False(t, l.Contains(items[0]))
+	require.Equal(t, []int{3}, intrusiveValues(t, &l))
+
+	other.PushFront(items[0])
+	require.Equal(t, []int{0}, intrusiveValues(t, other))
+	l.Remove(items[3])
+	require.Empty(t, intrusiveValues(t, &l))
+	require.Nil(t, l.Back())
+}
+
+func BenchmarkIntrusiveChurn(b *testing.B) {
+	const n = 1 << 12
+	b.Run("List", func(b *testing.B) {
+		l := New()
+		for i := 0; i < n; i++ {
+			l.PushFront(&item{value: i})
+		}
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			l.MoveToFront(l.Back())
+			l.Remove(l.Back())
+			l.PushFront(&item{value: i})
+		}
+	})
+	b.Run("Intrusive", func(b *testing.B) {
+		l := NewIntrusive[*item]()
+		for i := 0; i < n; i++ {
+			l.PushFront(&item{value: i})
+		}
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			l.MoveToFront(l.Back())
+			l.Remove(l.Back())
+			l.PushFront(&item{value: i})
+		}
+	})
+}
@@ -0,0 +1,23 @@
+//go:build go1.23
+
+package list
+
+import "iter"
+
+// All returns an iterator over the elements of l from front to back. The
+// loop body may remove the current element, as with Each.
+func (l *List) All() iter.Seq[*Element] {
+	return l.Each
+}
+
+// Backward returns an iterator over the elements of l from back to front.
+func (l *List) Backward() iter.Seq[*Element] {
+	return l.EachReverse
+}
+
+// Values returns an iterator over the values of l from front to back.
+func (l *List) Values() iter.Seq[any] {
+	return func(yield func(any) bool) {
+		l.Each(func(e *Element) bool { return yield(e.Value) })
+	}
+}
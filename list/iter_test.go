@@ -0,0 +1,40 @@
+//go:build go1.23
+
+package list
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIterators(t *testing.T) {
+	l := New()
+	for i := 1; i <= 4; i++ {
+		l.PushBack(i)
+	}
+
+	var got []any
+	for e := range l.All() {
+		got = append(got, e.Value)
+		if e.Value == 2 {
+			l.Remove(e)
+		}
+	}
+	require.Equal(t, []any{1, 2, 3, 4}, got)
+
+	got = nil
+	for e := range l.Backward() {
+		got = append(got, e.Value)
+		if e.Value == 3 {
+			break
+		}
+	}
+	require.Equal(t, []any{4, 3}, got)
+
+	got = nil
+	for v := range l.Values() {
+		got = append(got, v)
+	}
+	require.Equal(t, []any{1, 3, 4}, got)
+}
@@ -53,6 +53,12 @@ func (e *Element) List() *List {
 type List struct {
 	root Element // sentinel list element, only &root, root.prev, and root.next are used
 	len  int     // current list length excluding (this) sentinel element
+
+	// free is a stack of removed elements linked by next, holding up to
+	// maxFree of them for reuse.
+	free    *Element
+	nfree   int
+	maxFree int
 }
 
 // Init initializes or clears list l.
@@ -66,6 +72,15 @@ func (l *List) Init() *List {
 // New returns an initialized list.
 func New() *List { return new(List).Init() }
 
+// NewWithFreeList returns an initialized list that keeps up to n removed
+// elements for reuse by later insertions, so churn doesn't allocate.
+// Elements removed from such a list must not be used after Remove.
+func NewWithFreeList(n int) *List {
+	l := New()
+	l.maxFree = n
+	return l
+}
+
 // Len returns the number of elements of list l.
 // The complexity is O(1).
 func (l *List) Len() int { return l.len }
@@ -106,7 +121,16 @@ func (l *List) insert(e, at *Element) *Element {
 
 // insertValue is a convenience wrapper for insert(&Element{Value: v}, at).
 func (l *List) insertValue(v any, at *Element) *Element {
-	return l.insert(&Element{Value: v}, at)
+	e := l.free
+	if e != nil {
+		l.free = e.next
+		l.nfree--
+		e.next = nil
+		e.Value = v
+	} else {
+		e = &Element{Value: v}
+	}
+	return l.insert(e, at)
 }
 
 // remove removes e from its list, decrements l.len
@@ -141,6 +165,14 @@ func (l *List) Remove(e *Element) any {
 		// if e.list == l, l must have been initialized when e was inserted
 		// in l or l == nil (e is a zero Element) and l.remove will crash
 		l.remove(e)
+		if l.nfree < l.maxFree {
+			v := e.Value
+			e.Value = nil
+			e.next = l.free
+			l.free = e
+			l.nfree++
+			return v
+		}
 	}
 	return e.Value
 }
@@ -151,6 +183,17 @@ func (l *List) PushFront(v any) *Element {
 	return l.insertValue(v, &l.root)
 }
 
+// PushFrontElement inserts e, which must not be in any list, e.g. after
+// Remove, at the front of list l and returns it. It lets callers reuse
+// elements rather than allocate new ones.
+func (l *List) PushFrontElement(e *Element) *Element {
+	if e.list != nil {
+		return e
+	}
+	l.lazyInit()
+	return l.insert(e, &l.root)
+}
+
 // PushBack inserts a new element e with value v at the back of list l and returns e.
 func (l *List) PushBack(v any) *Element {
 	l.lazyInit()
@@ -221,6 +264,44 @@ func (l *List) MoveAfter(e, mark *Element) {
 	l.move(e, mark)
 }
 
+// Swap exchanges the positions of elements e and f.
+// If e or f is not an element of l, or e == f, the list is not modified.
+// The elements must not be nil.
+func (l *List) Swap(e, f *Element) {
+	if e.list != l || e == f || f.list != l {
+		return
+	}
+	if prev := e.prev; prev == f {
+		l.move(e, f.prev)
+	} else {
+		l.move(e, f)
+		l.move(f, prev)
+	}
+}
+
+// InsertAt inserts a new element e with value v at position i, counting
+// from 0 at the front, and returns e, so that InsertAt(v, 0) is PushFront
+// and InsertAt(v, l.Len()) is PushBack. It walks from the nearer end.
+// If i is out of range, the list is not modified and nil is returned.
+func (l *List) InsertAt(v any, i int) *Element {
+	if i < 0 || i > l.len {
+		return nil
+	}
+	l.lazyInit()
+	at := &l.root
+	if i <= l.len/2 {
+		for ; i > 0; i-- {
+			at = at.next
+		}
+	} else {
+		for i = l.len - i; i > 0; i-- {
+			at = at.prev
+		}
+		at = at.prev
+	}
+	return l.insertValue(v, at)
+}
+
 // PushBackList inserts a copy of another list at the back of list l.
 // The lists l and other may be the same. They must not be nil.
 func (l *List) PushBackList(other *List) {
@@ -238,3 +319,37 @@ func (l *List) PushFrontList(other *List) {
 		l.insertValue(e.Value, &l.root)
 	}
 }
+
+// SpliceBack moves all elements of other to the back of list l, leaving
+// other empty. The elements themselves move, so no element is allocated,
+// and references to them stay valid. Relinking takes constant time;
+// updating the list each element belongs to is linear in other's length.
+// If other is l, the list is not modified.
+func (l *List) SpliceBack(other *List) {
+	l.lazyInit()
+	l.splice(other, l.root.prev)
+}
+
+// SpliceFront moves all elements of other to the front of list l, leaving
+// other empty, like SpliceBack.
+func (l *List) SpliceFront(other *List) {
+	l.splice(other, &l.root)
+}
+
+// splice moves the elements of other after at.
+func (l *List) splice(other *List, at *Element) {
+	if other == l || other.len == 0 {
+		return
+	}
+	l.lazyInit()
+	first, last := other.root.next, other.root.prev
+	for e := first; e != &other.root; e = e.next {
+		e.list = l
+	}
+	first.prev = at
+	last.next = at.next
+	at.next.prev = last
+	at.next = first
+	l.len += other.len
+	other.Init()
+}
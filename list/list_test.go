@@ -0,0 +1,229 @@
+package list
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// values returns the values of l from front to back, checking the links
+// in both directions agree.
+func values(t *testing.T, l *List) []any {
+	t.Helper()
+	var forward, backward []any
+	for e := l.Front(); e != nil; e = e.Next() {
+		require.Equal(t, l, e.List())
+		forward = append(forward, e.Value)
+	}
+	for e := l.Back(); e != nil; e = e.Prev() {
+		backward = append([]any{e.Value}, backward...)
+	}
+	require.Equal(t, forward, backward)
+	require.Len(t, forward, l.Len())
+	return forward
+}
+
+func TestMoves(t *testing.T) {
+	l := New()
+	e1 := l.PushBack(1)
+	e2 := l.PushBack(2)
+	e3 := l.PushBack(3)
+	e4 := l.PushBack(4)
+
+	l.MoveToBack(e1)
+	require.Equal(t, []any{2, 3, 4, 1}, values(t, l))
+	l.MoveToBack(e1)
+	require.Equal(t, []any{2, 3, 4, 1}, values(t, l))
+
+	l.MoveToFront(e1)
+	require.Equal(t, []any{1, 2, 3, 4}, values(t, l))
+
+	l.MoveBefore(e4, e2)
+	require.Equal(t, []any{1, 4, 2, 3}, values(t, l))
+	l.MoveBefore(e4, e2)
+	require.Equal(t, []any{1, 4, 2, 3}, values(t, l))
+
+	l.MoveAfter(e1, e3)
+	require.Equal(t, []any{4, 2, 3, 1}, values(t, l))
+	l.MoveAfter(e1, e3)
+	require.Equal(t, []any{4, 2, 3, 1}, values(t, l))
+
+	// moving relative to itself is a no-op
+	l.MoveBefore(e2, e2)
+	l.MoveAfter(e2, e2)
+	require.Equal(t, []any{4, 2, 3, 1}, values(t, l))
+}
+
+func TestMovesIgnoreForeignElements(t *testing.T) {
+	l, other := New(), New()
+	e := l.PushBack(1)
+	l.PushBack(2)
+	foreign := other.PushBack(3)
+
+	l.MoveToFront(foreign)
+	l.MoveToBack(foreign)
+	l.MoveBefore(foreign, e)
+	l.MoveAfter(e, foreign)
+	require.Equal(t, []any{1, 2}, values(t, l))
+	require.Equal(t, []any{3}, values(t, other))
+}
+
+func TestSwap(t *testing.T) {
+	l := New()
+	e1 := l.PushBack(1)
+	e2 := l.PushBack(2)
+	e3 := l.PushBack(3)
+	e4 := l.PushBack(4)
+
+	l.Swap(e1, e4)
+	require.Equal(t, []any{4, 2, 3, 1}, values(t, l))
+	l.Swap(e2, e3)
+	require.Equal(t, []any{4, 3, 2, 1}, values(t, l))
+	l.Swap(e2, e3)
+	require.Equal(t, []any{4, 2, 3, 1}, values(t, l))
+	l.Swap(e1, e3)
+	require.Equal(t, []any{4, 2, 1, 3}, values(t, l))
+
+	l.Swap(e2, e2)
+	l.Swap(e2, New().PushBack(5))
+	require.Equal(t, []any{4, 2, 1, 3}, values(t, l))
+}
+
+func TestInsertAt(t *testing.T) {
+	var l List
+	require.Nil(t, l.InsertAt(0, 1))
+	l.InsertAt(2, 0)
+	l.InsertAt(0, 0)
+	l.InsertAt(5, 2)
+	l.InsertAt(1, 1)
+	l.InsertAt(4, 3)
+	l.InsertAt(3, 3)
+	require.Equal(t, []any{0, 1, 2, 3, 4, 5}, values(t, &l))
+
+	require.Nil(t, l.InsertAt(6, -1))
+	require.Nil(t, l.InsertAt(6, 7))
+	require.Equal(t, 6, l.InsertAt(6, 6).Value)
+	require.Equal(t, []any{0, 1, 2, 3, 4, 5, 6}, values(t, &l))
+}
+
+func TestPushFrontElement(t *testing.T) {
+	l, other := New(), New()
+	e := l.PushBack(1)
+	l.PushBack(2)
+
+	l.Remove(e)
+	require.Nil(t, e.List())
+	require.Same(t, e, other.PushFrontElement(e))
+	require.Equal(t, []any{1}, values(t, other))
+	require.Equal(t, []any{2}, values(t, l))
+
+	// an element still in a list is left where it is
+	l.PushFrontElement(e)
+	require.Equal(t, []any{2}, values(t, l))
+
+	var zero List
+	zero.PushFrontElement(&Element{Value: 3})
+	require.Equal(t, []any{3}, values(t, &zero))
+}
+
+func TestFreeList(t *testing.T) {
+	l := NewWithFreeList(2)
+	e1 := l.PushBack(1)
+	e2 := l.PushBack(2)
+	e3 := l.PushBack(3)
+
+	require.Equal(t, 1, l.Remove(e1))
+	require.Equal(t, 2, l.Remove(e2))
+	require.Equal(t, 3, l.Remove(e3))
+	require.Equal(t, 2, l.nfree)
+	require.Nil(t, e2.Value)
+
+	// removed elements are reused, most recent first
+	require.Same(t, e2, l.PushFront(4))
+	require.Same(t, e1, l.PushBack(5))
+	require.Equal(t, []any{4, 5}, values(t, l))
+	require.Zero(t, l.nfree)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		l.Remove(l.Front())
+		l.PushBack(6)
+	})
+	require.Zero(t, allocs)
+
+	// lists without a free list never reuse elements
+	plain := New()
+	e := plain.PushBack(1)
+	plain.Remove(e)
+	require.Equal(t, 1, e.Value)
+	require.NotSame(t, e, plain.PushBack(2))
+}
+
+func TestSplice(t *testing.T) {
+	l, other := New(), New()
+	l.PushBack(1)
+	l.PushBack(2)
+	e := other.PushBack(3)
+	other.PushBack(4)
+
+	l.SpliceBack(other)
+	require.Equal(t, []any{1, 2, 3, 4}, values(t, l))
+	require.Empty(t, values(t, other))
+	require.Equal(t, l, e.List())
+
+	other.PushBack(5)
+	other.PushBack(6)
+	l.SpliceFront(other)
+	require.Equal(t, []any{5, 6, 1, 2, 3, 4}, values(t, l))
+	require.Zero(t, other.Len())
+
+	// splicing empty lists or a list into itself changes nothing
+	l.SpliceBack(other)
+	l.SpliceFront(l)
+	require.Equal(t, []any{5, 6, 1, 2, 3, 4}, values(t, l))
+
+	var zero List
+	zero.SpliceFront(l)
+	require.Equal(t, []any{5, 6, 1, 2, 3, 4}, values(t, &zero))
+	zero.Remove(e)
+	require.Equal(t, []any{5, 6, 1, 2, 4}, values(t, &zero))
+
+	var back List
+	back.SpliceBack(&zero)
+	require.Equal(t, []any{5, 6, 1, 2, 4}, values(t, &back))
+	require.Zero(t, zero.Len())
+
+	// zero lists are ready as the source too
+	var empty List
+	back.SpliceBack(&empty)
+	back.SpliceFront(&empty)
+	empty.SpliceBack(&List{})
+	require.Equal(t, 5, back.Len())
+	require.Empty(t, values(t, &empty))
+}
+
+func TestEach(t *testing.T) {
+	l := New()
+	for i := 1; i <= 4; i++ {
+		l.PushBack(i)
+	}
+
+	// removing the current element doesn't end the iteration
+	var got []any
+	l.Each(func(e *Element) bool {
+		got = append(got, e.Value)
+		l.Remove(e)
+		return true
+	})
+	require.Equal(t, []any{1, 2, 3, 4}, got)
+	require.Zero(t, l.Len())
+
+	l.PushBack(1)
+	l.PushBack(2)
+	l.PushBack(3)
+	got = nil
+	l.EachReverse(func(e *Element) bool {
+		got = append(got, e.Value)
+		return e.Value != 2
+	})
+	require.Equal(t, []any{3, 2}, got)
+}
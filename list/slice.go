@@ -0,0 +1,166 @@
+package list
+
+// Nil is the handle of no element in a Slice.
+const Nil = -1
+
+// node is an element of a Slice, linked to its neighbours by index.
+type node[T any] struct {
+	value      T
+	prev, next int32
+}
+
+// Slice is a doubly linked list whose elements live in one slice and are
+// referenced by integer handles rather than pointers. Elements sit next to
+// each other in memory, take no separate allocation, and carry no pointers
+// of their own for the garbage collector, at the cost of handles being
+// valid only until their element is removed, after which they are reused.
+//
+// The zero value is an empty list ready to use.
+type Slice[T any] struct {
+	nodes      []node[T]
+	head, tail int32
+	// free is the stack of removed nodes, linked by next.
+	free int32
+	len  int
+	init bool
+}
+
+// NewSlice returns an empty list with room for capacity elements.
+func NewSlice[T any](capacity int) *Slice[T] {
+	l := &Slice[T]{nodes: make([]node[T], 0, capacity)}
+	l.lazyInit()
+	return l
+}
+
+func (l *Slice[T]) lazyInit() {
+	if !l.init {
+		l.head, l.tail, l.free = Nil, Nil, Nil
+		l.init = true
+	}
+}
+
+// Len returns the number of elements of l.
+func (l *Slice[T]) Len() int { return l.len }
+
+// Front returns the first element of l or Nil if it is empty.
+func (l *Slice[T]) Front() int {
+	if !l.init {
+		return Nil
+	}
+	return int(l.head)
+}
+
+// Back returns the last element of l or Nil if it is empty.
+func (l *Slice[T]) Back() int {
+	if !l.init {
+		return Nil
+	}
+	return int(l.tail)
+}
+
+// Next returns the element after h or Nil.
+func (l *Slice[T]) Next(h int) int { return int(l.nodes[h].next) }
+
+// Prev returns the element before h or Nil.
+func (l *Slice[T]) Prev(h int) int { return int(l.nodes[h].prev) }
+
+// Value returns the value of element h.
+func (l *Slice[T]) Value(h int) T { return l.nodes[h].value }
+
+// SetValue replaces the value of element h.
+func (l *Slice[T]) SetValue(h int, v T) { l.nodes[h].value = v }
+
+// PushFront inserts v at the front of l and returns its handle.
+func (l *Slice[T]) PushFront(v T) int {
+	h := l.alloc(v)
+	l.linkFront(h)
+	return int(h)
+}
+
+// PushBack inserts v at the back of l and returns its handle.
+func (l *Slice[T]) PushBack(v T) int {
+	h := l.alloc(v)
+	l.linkBack(h)
+	return int(h)
+}
+
+// Remove removes element h from l and returns its value. h is reused by
+// later insertions.
+func (l *Slice[T]) Remove(h int) T {
+	l.unlink(int32(h))
+	n := &l.nodes[h]
+	v := n.value
+	var zero T
+	n.value = zero
+	n.next = l.free
+	l.free = int32(h)
+	return v
+}
+
+// MoveToFront moves element h to the front of l.
+func (l *Slice[T]) MoveToFront(h int) {
+	if int32(h) == l.head {
+		return
+	}
+	l.unlink(int32(h))
+	l.linkFront(int32(h))
+}
+
+// MoveToBack moves element h to the back of l.
+func (l *Slice[T]) MoveToBack(h int) {
+	if int32(h) == l.tail {
+		return
+	}
+	l.unlink(int32(h))
+	l.linkBack(int32(h))
+}
+
+func (l *Slice[T]) alloc(v T) int32 {
+	l.lazyInit()
+	if h := l.free; h != Nil {
+		l.free = l.nodes[h].next
+		l.nodes[h] = node[T]{value: v}
+		return h
+	}
+	l.nodes = append(l.nodes, node[T]{value: v})
+	return int32(len(l.nodes) - 1)
+}
+
+func (l *Slice[T]) linkFront(h int32) {
+	n := &l.nodes[h]
+	n.prev, n.next = Nil, l.head
+	if l.head != Nil {
+		l.nodes[l.head].prev = h
+	} else {
+		l.tail = h
+	}
+	l.head = h
+	l.len++
+}
+
+func (l *Slice[T]) linkBack(h int32) {
+	n := &l.nodes[h]
+	n.prev, n.next = l.tail, Nil
+	if l.tail != Nil {
+		l.nodes[l.tail].next = h
+	} else {
+		l.head = h
+	}
+	l.tail = h
+	l.len++
+}
+
+func (l *Slice[T]) unlink(h int32) {
+	n := &l.nodes[h]
+	if n.prev != Nil {
+		l.nodes[n.prev].next = n.next
+	} else {
+		l.head = n.next
+	}
+	if n.next != Nil {
+		l.nodes[n.next].prev = n.prev
+	} else {
+		l.tail = n.prev
+	}
+	l.len--
+}
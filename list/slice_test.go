@@ -0,0 +1,84 @@
+package list
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// sliceValues returns the values of l from front to back, checking the
+// links in both directions agree.
+func sliceValues[T any](t *testing.T, l *Slice[T]) []T {
+	t.Helper()
+	var forward, backward []T
+	for h := l.Front(); h != Nil; h = l.Next(h) {
+		forward = append(forward, l.Value(h))
+	}
+	for h := l.Back(); h != Nil; h = l.Prev(h) {
+		backward = append([]T{l.Value(h)}, backward...)
+	}
+	require.Equal(t, forward, backward)
+	require.Len(t, forward, l.Len())
+	return forward
+}
+
+func TestSlice(t *testing.T) {
+	var l Slice[int]
+	require.Equal(t, Nil, l.Front())
+	require.Equal(t, Nil, l.Back())
+
+	h1 := l.PushBack(1)
+	h2 := l.PushBack(2)
+	h3 := l.PushFront(3)
+	require.Equal(t, []int{3, 1, 2}, sliceValues(t, &l))
+
+	l.MoveToFront(h2)
+	require.Equal(t, []int{2, 3, 1}, sliceValues(t, &l))
+	l.MoveToBack(h3)
+	require.Equal(t, []int{2, 1, 3}, sliceValues(t, &l))
+	l.MoveToFront(h2)
+	l.MoveToBack(h3)
+	require.Equal(t, []int{2, 1, 3}, sliceValues(t, &l))
+
+	require.Equal(t, 1, l.Remove(h1))
+	require.Equal(t, []int{2, 3}, sliceValues(t, &l))
+
+	// removed handles are reused
+	require.Equal(t, h1, l.PushFront(4))
+	l.SetValue(h1, 5)
+	require.Equal(t, []int{5, 2, 3}, sliceValues(t, &l))
+
+	l.Remove(h2)
+	l.Remove(h1)
+	l.Remove(h3)
+	require.Empty(t, sliceValues(t, &l))
+	require.Len(t, l.nodes, 3)
+}
+
+func BenchmarkChurn(b *testing.B) {
+	const n = 1 << 12
+	b.Run("List", func(b *testing.B) {
+		l := New()
+		for i := 0; i < n; i++ {
+			l.PushFront(i)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			l.MoveToFront(l.Back())
+			l.Remove(l.Back())
+			l.PushFront(i)
+		}
+	})
+	b.Run("Slice", func(b *testing.B) {
+		l := NewSlice[int](n)
+		for i := 0; i < n; i++ {
+			l.PushFront(i)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			l.MoveToFront(l.Back())
+			l.Remove(l.Back())
+			l.PushFront(i)
+		}
+	})
+}
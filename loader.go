@@ -0,0 +1,126 @@
+package slru
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// call is a load in flight shared by concurrent GetOrLoad callers.
+type call[V any] struct {
+	done    chan struct{}
+	value   V
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// loadGroup coalesces the concurrent loads of each key.
+type loadGroup[K comparable, V any] struct {
+	lock  sync.Mutex
+	loads map[K]*call[V]
+}
+
+// GetOrLoad returns the value of key, calling load to fetch and cache it on
+// a miss. Concurrent callers missing the same key share a single load.
+//
+// Each caller waits until the load completes or its ctx is done, in which
+// case it returns ctx.Err(). The load runs with the values of the first
+// caller's ctx and is canceled once every caller waiting for it gave up.
+// Errors are returned to the waiting callers and not cached, and so are
+// values loaded while the key was removed, as by Remove or Purge, which
+// may predate the removal. A nil load uses the loader of WithLoaders for
+// the class of key, as Load does.
+func (s *SLRU[K, V]) GetOrLoad(ctx context.Context, key K, load func(ctx context.Context, key K) (V, error)) (V, error) {
+	key = s.normalize(key)
+	if value, ok := s.lookup(key); ok {
+		return value, nil
+	}
+	if load == nil {
+		return s.loadRegistered(ctx, key)
+	}
+	return s.loads.do(ctx, key, load, s.storeLoaded(0))
+}
+
+// storeLoaded returns the store of a load starting now, expiring values
+// after a positive ttl or as Set does otherwise. It drops the values of
+// loads overlapping a removal.
+func (s *SLRU[K, V]) storeLoaded(ttl time.Duration) func(key K, value V) {
+	s.acquireShared()
+	removals := s.removals
+	s.lock.RUnlock()
+	return func(key K, value V) {
+		s.acquire()
+		defer s.unlock()
+
+		if s.removals != removals {
+			return
+		}
+		if ttl > 0 {
+			s.setEntry(key, value, s.weigh(key, value), ttl)
+		} else {
+			s.set(key, value)
+		}
+	}
+}
+
+// do waits for the load of key, starting it if none is in flight, and
+// passes loaded values to store.
+func (g *loadGroup[K, V]) do(ctx context.Context, key K, load func(ctx context.Context, key K) (V, error), store func(key K, value V)) (V, error) {
+	g.lock.Lock()
+	c, ok := g.loads[key]
+	if !ok {
+		if g.loads == nil {
+			g.loads = make(map[K]*call[V])
+		}
+		loadCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		c = &call[V]{done: make(chan struct{}), cancel: cancel}
+		g.loads[key] = c
+		go g.load(loadCtx, key, c, load, store)
+	}
+	c.waiters++
+	g.lock.Unlock()
+
+	select {
+	case <-c.done:
+		return c.value, c.err
+	case <-ctx.Done():
+		g.lock.Lock()
+		c.waiters--
+		if c.waiters == 0 {
+			// nobody waits for the result anymore, later callers start over
+			c.cancel()
+			if g.loads[key] == c {
+				delete(g.loads, key)
+			}
+		}
+		g.lock.Unlock()
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+func (g *loadGroup[K, V]) load(ctx context.Context, key K, c *call[V], load func(ctx context.Context, key K) (V, error), store func(key K, value V)) {
+	defer close(c.done)
+	defer c.cancel()
+	defer func() {
+		if r := recover(); r != nil {
+			c.err = fmt.Errorf("slru: load of %v panicked: %v", key, r)
+		}
+		g.lock.Lock()
+		if g.loads[key] == c {
+			delete(g.loads, key)
+		}
+		g.lock.Unlock()
+	}()
+
+	c.value, c.err = load(ctx, key)
+	if c.err == nil {
+		store(key, c.value)
+	}
+}
+
+func (c *Sharded[K, V]) GetOrLoad(ctx context.Context, key K, load func(ctx context.Context, key K) (V, error)) (V, error) {
+	return c.shard(key).GetOrLoad(ctx, key, load)
+}
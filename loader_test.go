@@ -0,0 +1,106 @@
+package slru
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetOrLoadCoalesces(t *testing.T) {
+	cache := New[string, int](10)
+	var loads atomic.Int32
+	release := make(chan struct{})
+	load := func(ctx context.Context, key string) (int, error) {
+		loads.Add(1)
+		<-release
+		return 42, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := cache.GetOrLoad(context.Background(), "a", load)
+			require.NoError(t, err)
+			require.Equal(t, 42, v)
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	require.Equal(t, int32(1), loads.Load())
+	v, ok := cache.Get("a")
+	require.True(t, ok)
+	require.Equal(t, 42, v)
+}
+
+func TestGetOrLoadErrors(t *testing.T) {
+	cache := New[string, int](10)
+	_, err := cache.GetOrLoad(context.Background(), "a", func(ctx context.Context, key string) (int, error) {
+		return 0, errors.New("boom")
+	})
+	require.EqualError(t, err, "boom")
+	require.False(t, cache.Contains("a"))
+
+	_, err = cache.GetOrLoad(context.Background(), "a", func(ctx context.Context, key string) (int, error) {
+		panic("oops")
+	})
+	require.EqualError(t, err, "slru: load of a panicked: oops")
+}
+
+func TestGetOrLoadHonorsContext(t *testing.T) {
+	cache := New[string, int](10)
+	canceled := make(chan struct{})
+	wedged := func(ctx context.Context, key string) (int, error) {
+		<-ctx.Done()
+		close(canceled)
+		return 0, ctx.Err()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := cache.GetOrLoad(ctx, "a", wedged)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// the abandoned load is canceled and a later caller starts afresh
+	<-canceled
+	v, err := cache.GetOrLoad(context.Background(), "a", func(ctx context.Context, key string) (int, error) {
+		return 1, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, v)
+}
+
+func TestGetOrLoadDropsValuesOfRemovedKeys(t *testing.T) {
+	cache := New[string, int](10)
+	for _, remove := range []func(){
+		func() { cache.Remove("a") },
+		cache.Purge,
+	} {
+		started, release := make(chan struct{}), make(chan struct{})
+		done := make(chan int)
+		go func() {
+			v, err := cache.GetOrLoad(context.Background(), "a", func(ctx context.Context, key string) (int, error) {
+				close(started)
+				<-release
+				return 1, nil
+			})
+			require.NoError(t, err)
+			done <- v
+		}()
+		<-started
+		remove()
+		close(release)
+
+		// the caller gets the value but it predates the removal
+		require.Equal(t, 1, <-done)
+		require.False(t, cache.Contains("a"))
+	}
+}
@@ -0,0 +1,118 @@
+package slru
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNoLoader is returned by Load when no loader is registered for the
+// class of a key.
+var ErrNoLoader = errors.New("slru: no loader")
+
+// Loaders is a registry of loaders by class of keys, such as a key prefix
+// or the type of a key, so call sites load keys without passing a closure
+// and each family of keys gets its own load and TTL:
+//
+//	loaders := slru.NewLoaders[string, []byte](slru.PrefixClass("user:", "order:"))
+//	loaders.Register("user:", loadUser, time.Minute)
+//	loaders.Register("order:", loadOrder, 0)
+//	cache := slru.New(1000, slru.WithLoaders(loaders))
+//	value, err := cache.(slru.Loading[string, []byte]).Load(ctx, "user:42")
+type Loaders[K comparable, V any] struct {
+	classify func(key K) string
+
+	lock    sync.RWMutex
+	loaders map[string]classLoader[K, V]
+}
+
+// classLoader is the loader of a class and the TTL of its values.
+type classLoader[K comparable, V any] struct {
+	load func(ctx context.Context, key K) (V, error)
+	ttl  time.Duration
+}
+
+// NewLoaders returns an empty registry classifying keys with classify.
+func NewLoaders[K comparable, V any](classify func(key K) string) *Loaders[K, V] {
+	return &Loaders[K, V]{classify: classify, loaders: make(map[string]classLoader[K, V])}
+}
+
+// PrefixClass returns a classifier giving keys the longest of prefixes
+// they start with as class, or "" if none.
+func PrefixClass(prefixes ...string) func(key string) string {
+	return func(key string) (class string) {
+		for _, prefix := range prefixes {
+			if len(prefix) > len(class) && strings.HasPrefix(key, prefix) {
+				class = prefix
+			}
+		}
+		return class
+	}
+}
+
+// Register loads the keys of class with load, caching the values for ttl,
+// or as configured for writes without an explicit TTL if ttl is not
+// positive. The loader of class "" loads the keys whose class has none.
+// Registering a class again replaces its loader.
+func (l *Loaders[K, V]) Register(class string, load func(ctx context.Context, key K) (V, error), ttl time.Duration) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.loaders[class] = classLoader[K, V]{load, ttl}
+}
+
+// lookup returns the loader of the class of key.
+func (l *Loaders[K, V]) lookup(key K) (classLoader[K, V], error) {
+	class := l.classify(key)
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	if loader, ok := l.loaders[class]; ok {
+		return loader, nil
+	}
+	if loader, ok := l.loaders[""]; ok {
+		return loader, nil
+	}
+	return classLoader[K, V]{}, fmt.Errorf("%w for %v of class %q", ErrNoLoader, key, class)
+}
+
+// Loading is a cache loading keys with the loaders of WithLoaders, such as
+// an SLRU or a Sharded.
+type Loading[K comparable, V any] interface {
+	Load(ctx context.Context, key K) (V, error)
+}
+
+// WithLoaders loads the keys missed by Load, or by GetOrLoad with a nil
+// load, with the loader registered in loaders for their class.
+func WithLoaders[K comparable, V any](loaders *Loaders[K, V]) Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		s.loaders = loaders
+	}
+}
+
+// Load is GetOrLoad with the loader of WithLoaders for the class of key,
+// caching the value with the TTL of the class. It returns ErrNoLoader if
+// the class has no loader.
+func (s *SLRU[K, V]) Load(ctx context.Context, key K) (V, error) {
+	return s.GetOrLoad(ctx, key, nil)
+}
+
+// loadRegistered loads key with the loader of its class.
+func (s *SLRU[K, V]) loadRegistered(ctx context.Context, key K) (value V, err error) {
+	if s.loaders == nil {
+		return value, fmt.Errorf("%w for %v: no loaders configured", ErrNoLoader, key)
+	}
+	loader, err := s.loaders.lookup(key)
+	if err != nil {
+		return value, err
+	}
+	return s.loads.do(ctx, key, loader.load, s.storeLoaded(loader.ttl))
+}
+
+// Load is SLRU.Load on the shard of key.
+func (c *Sharded[K, V]) Load(ctx context.Context, key K) (V, error) {
+	return c.shard(key).Load(ctx, key)
+}
@@ -0,0 +1,53 @@
+package slru
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoaders(t *testing.T) {
+	loaders := NewLoaders[string, string](PrefixClass("user:", "user:admin:", "order:"))
+	loaders.Register("user:", func(ctx context.Context, key string) (string, error) {
+		return "user " + strings.TrimPrefix(key, "user:"), nil
+	}, time.Minute)
+	loaders.Register("user:admin:", func(ctx context.Context, key string) (string, error) {
+		return "admin", nil
+	}, 0)
+
+	for name, cache := range map[string]Cache[string, string]{
+		"SLRU":    New(100, WithLoaders(loaders)),
+		"Sharded": NewSharded(100, 4, nil, WithLoaders(loaders)),
+	} {
+		t.Run(name, func(t *testing.T) {
+			v, err := cache.(Loading[string, string]).Load(context.Background(), "user:42")
+			require.NoError(t, err)
+			require.Equal(t, "user 42", v)
+			ttl, ok := cache.TTL("user:42")
+			require.True(t, ok)
+			require.InDelta(t, time.Minute, ttl, float64(time.Second))
+
+			v, err = cache.GetOrLoad(context.Background(), "user:admin:1", nil)
+			require.NoError(t, err)
+			require.Equal(t, "admin", v)
+			ttl, _ = cache.TTL("user:admin:1")
+			require.Zero(t, ttl)
+
+			_, err = cache.GetOrLoad(context.Background(), "order:1", nil)
+			require.ErrorIs(t, err, ErrNoLoader)
+			require.EqualError(t, err, `slru: no loader for order:1 of class "order:"`)
+		})
+	}
+
+	// the loader of class "" is the default
+	loaders.Register("", func(ctx context.Context, key string) (string, error) { return "default", nil }, 0)
+	v, err := New(100, WithLoaders(loaders)).GetOrLoad(context.Background(), "order:1", nil)
+	require.NoError(t, err)
+	require.Equal(t, "default", v)
+
+	_, err = newSLRU[string, string](100).Load(context.Background(), "a")
+	require.ErrorIs(t, err, ErrNoLoader)
+}
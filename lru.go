@@ -0,0 +1,66 @@
+package slru
+
+import "errors"
+
+// LRU adapts SLRU to the method set of hashicorp/golang-lru, so code written
+// against lru.Cache can switch to SLRU by replacing only its constructor.
+type LRU[K comparable, V any] struct {
+	s *SLRU[K, V]
+}
+
+// NewLRU creates an LRU-compatible cache of the given size.
+func NewLRU[K comparable, V any](size int) (*LRU[K, V], error) {
+	if size <= 0 {
+		return nil, errors.New("must provide a positive size")
+	}
+	return &LRU[K, V]{s: newSLRU[K, V](size)}, nil
+}
+
+// Add adds a value to the cache, returning true if an eviction occurred.
+func (c *LRU[K, V]) Add(key K, value V) (evicted bool) {
+	key = c.s.normalize(key)
+	c.s.acquire()
+	defer c.s.unlock()
+
+	return c.s.set(key, value)
+}
+
+// Get looks up a key's value from the cache.
+func (c *LRU[K, V]) Get(key K) (value V, ok bool) {
+	return c.s.Get(key)
+}
+
+// Contains checks if a key is in the cache, without updating the recent-ness.
+func (c *LRU[K, V]) Contains(key K) bool {
+	return c.s.Contains(key)
+}
+
+// Peek returns the key's value without updating the recent-ness.
+func (c *LRU[K, V]) Peek(key K) (value V, ok bool) {
+	return c.s.Peek(key)
+}
+
+// Remove removes the provided key from the cache, returning if the key was contained.
+func (c *LRU[K, V]) Remove(key K) (present bool) {
+	return c.s.Remove(key)
+}
+
+// Keys returns a slice of the keys in the cache, from oldest to newest.
+func (c *LRU[K, V]) Keys() []K {
+	return c.s.Keys()
+}
+
+// Len returns the number of items in the cache.
+func (c *LRU[K, V]) Len() int {
+	return c.s.Len()
+}
+
+// Purge is used to completely clear the cache.
+func (c *LRU[K, V]) Purge() {
+	c.s.Purge()
+}
+
+// Resize changes the cache size, returning the number of evicted entries.
+func (c *LRU[K, V]) Resize(size int) (evicted int) {
+	return c.s.Resize(size)
+}
@@ -0,0 +1,33 @@
+package slru
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLRUAdapter(t *testing.T) {
+	_, err := NewLRU[int, int](0)
+	require.Error(t, err)
+
+	cache, err := NewLRU[int, int](10)
+	require.NoError(t, err)
+
+	require.False(t, cache.Add(1, 1))
+	require.False(t, cache.Add(2, 2))
+	// probation holds two entries, so the third insert evicts
+	require.True(t, cache.Add(3, 3))
+	require.False(t, cache.Contains(1))
+
+	v, ok := cache.Peek(2)
+	require.True(t, ok)
+	require.Equal(t, 2, v)
+	require.Equal(t, []int{2, 3}, cache.Keys())
+
+	require.True(t, cache.Remove(2))
+	require.False(t, cache.Remove(2))
+	require.Equal(t, 1, cache.Len())
+
+	cache.Purge()
+	require.Equal(t, 0, cache.Len())
+}
@@ -0,0 +1,56 @@
+package slru
+
+// PauseMaintenance stops the background maintenance of the cache, such as
+// the janitor, shedding soft values and exchanging hot-key hints, e.g. for
+// a latency-critical window or a coordinated snapshot. It waits for runs in
+// progress, and runs due meanwhile are skipped, so the janitor catches up
+// on resuming. Writes buffered by SetAsync are still applied. Calls nest:
+// maintenance resumes after as many calls to ResumeMaintenance.
+func (s *SLRU[K, V]) PauseMaintenance() {
+	s.pauseLock.Lock()
+	defer s.pauseLock.Unlock()
+
+	s.pauses++
+	if s.pauses == 1 {
+		s.maintenance.Lock()
+	}
+}
+
+// ResumeMaintenance resumes the maintenance stopped by PauseMaintenance.
+func (s *SLRU[K, V]) ResumeMaintenance() {
+	s.pauseLock.Lock()
+	defer s.pauseLock.Unlock()
+
+	if s.pauses == 0 {
+		return
+	}
+	s.pauses--
+	if s.pauses == 0 {
+		s.maintenance.Unlock()
+	}
+}
+
+// maintain runs fn unless maintenance is paused, reporting whether it did.
+func (s *SLRU[K, V]) maintain(fn func()) bool {
+	if !s.maintenance.TryRLock() {
+		return false
+	}
+	defer s.maintenance.RUnlock()
+
+	fn()
+	return true
+}
+
+// PauseMaintenance is SLRU.PauseMaintenance on every shard.
+func (c *Sharded[K, V]) PauseMaintenance() {
+	for _, s := range c.shards {
+		s.PauseMaintenance()
+	}
+}
+
+// ResumeMaintenance is SLRU.ResumeMaintenance on every shard.
+func (c *Sharded[K, V]) ResumeMaintenance() {
+	for _, s := range c.shards {
+		s.ResumeMaintenance()
+	}
+}
@@ -0,0 +1,27 @@
+package slru
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPauseMaintenance(t *testing.T) {
+	cache := newSLRU[int, int](100, WithJanitor[int, int](time.Millisecond))
+	cache.PauseMaintenance()
+	cache.PauseMaintenance()
+	cache.SetWithTTL(1, 1, time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	require.Equal(t, 1, cache.Len())
+	require.False(t, cache.maintain(func() {}))
+
+	cache.ResumeMaintenance()
+	time.Sleep(10 * time.Millisecond)
+	require.Equal(t, 1, cache.Len())
+
+	cache.ResumeMaintenance()
+	cache.ResumeMaintenance()
+	require.Eventually(t, func() bool { return cache.Len() == 0 }, time.Second, time.Millisecond)
+	require.True(t, cache.maintain(func() {}))
+}
@@ -0,0 +1,70 @@
+package slru
+
+import "time"
+
+// mergeEntry is an entry of the cache merged by Merge.
+type mergeEntry[K comparable, V any] struct {
+	key   K
+	value V
+	ttl   time.Duration
+}
+
+// mergeEntries returns the live entries of other, from its next victim to
+// its hottest.
+func mergeEntries[K comparable, V any](other Cache[K, V]) []mergeEntry[K, V] {
+	keys := other.Keys()
+	entries := make([]mergeEntry[K, V], 0, len(keys))
+	for _, key := range keys {
+		value, ok := other.Peek(key)
+		if !ok {
+			continue
+		}
+		ttl, ok := other.TTL(key)
+		if !ok {
+			continue
+		}
+		entries = append(entries, mergeEntry[K, V]{key, value, ttl})
+	}
+	return entries
+}
+
+// Merge copies the entries of other into the cache, such as those of a
+// staging cache filled by one goroutine during a batch. Keys present in
+// both get the value onConflict returns for the current value and that of
+// other, or that of other if onConflict is nil. Entries keep their TTL and
+// are inserted from the least to the most recently used in other, so when
+// the cache overflows, the hottest entries of other stay and the coldest
+// are evicted first. The entries are inserted atomically, but other is
+// read before, not atomically, so it must not be the cache itself.
+func (s *SLRU[K, V]) Merge(other Cache[K, V], onConflict func(a, b V) V) {
+	s.merge(mergeEntries(other), onConflict)
+}
+
+func (s *SLRU[K, V]) merge(entries []mergeEntry[K, V], onConflict func(a, b V) V) {
+	defer s.region("merge")()
+	s.acquire()
+	defer s.unlock()
+
+	for _, m := range entries {
+		m.key = s.normalize(m.key)
+		if ent, ok := s.live(m.key); ok && onConflict != nil {
+			m.value = onConflict(ent.value, m.value)
+		}
+		s.setEntry(m.key, m.value, s.weigh(m.key, m.value), m.ttl)
+	}
+}
+
+// Merge is SLRU.Merge across shards, inserting the entries of each shard
+// atomically.
+func (c *Sharded[K, V]) Merge(other Cache[K, V], onConflict func(a, b V) V) {
+	shards := make([][]mergeEntry[K, V], len(c.shards))
+	for _, m := range mergeEntries(other) {
+		i := c.hash(m.key) & c.mask
+		shards[i] = append(shards[i], m)
+	}
+	for i, entries := range shards {
+		if len(entries) > 0 {
+			c.shards[i].merge(entries, onConflict)
+		}
+	}
+}
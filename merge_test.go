@@ -0,0 +1,56 @@
+package slru
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerge(t *testing.T) {
+	cache := newSLRU[string, int](100)
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+
+	staging := New[string, int](100)
+	staging.Set("b", 20)
+	staging.SetWithTTL("c", 30, time.Hour)
+
+	cache.Merge(staging, func(a, b int) int { return a + b })
+	v, _ := cache.Get("b")
+	require.Equal(t, 22, v)
+	v, _ = cache.Get("c")
+	require.Equal(t, 30, v)
+	ttl, _ := cache.TTL("c")
+	require.Greater(t, ttl, time.Minute)
+	v, _ = cache.Get("a")
+	require.Equal(t, 1, v)
+
+	cache.Merge(staging, nil)
+	v, _ = cache.Get("b")
+	require.Equal(t, 20, v)
+}
+
+func TestMergeKeepsHottest(t *testing.T) {
+	cache := newSLRU[int, int](10)
+	staging := newSLRU[int, int](100)
+	for i := range 20 {
+		staging.Set(i, i)
+	}
+	staging.Get(0)
+
+	cache.Merge(staging, nil)
+	require.True(t, cache.Contains(0))
+	require.True(t, cache.Contains(19))
+	require.False(t, cache.Contains(1))
+}
+
+func TestMergeOnSharded(t *testing.T) {
+	cache := NewSharded[int, int](1000, 4, nil)
+	staging := New[int, int](1000)
+	for i := range 50 {
+		staging.Set(i, i)
+	}
+	cache.Merge(staging, nil)
+	require.Equal(t, 50, cache.Len())
+}
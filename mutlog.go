@@ -0,0 +1,188 @@
+package slru
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/hey-kong/slru/list"
+)
+
+// Mutation operations.
+const (
+	MutationSet    = "set"
+	MutationRemove = "remove"
+	MutationEvict  = "evict"
+	MutationExpire = "expire"
+	MutationPurge  = "purge"
+)
+
+// ErrMutationsLost is returned by MutationLog.Read when the mutations
+// asked for were already dropped from the log. The reader has to resync,
+// e.g. from SLRU.MutationSnapshot.
+var ErrMutationsLost = errors.New("slru: mutations lost")
+
+// Mutation is a change of the cache contents.
+type Mutation[K comparable, V any] struct {
+	// Seq numbers the mutations of a log from 1, without gaps.
+	Seq uint64
+	Op  string
+	// Key is the zero value for MutationPurge.
+	Key K
+	// Value and ExpireAt are those set by MutationSet, where a zero
+	// ExpireAt never expires.
+	Value    V
+	ExpireAt time.Time
+}
+
+// MutationLog keeps the latest mutations of a cache, in order, for readers
+// replicating its contents to a standby or warming a new replica. It never
+// blocks the cache: once it holds capacity mutations, each one drops the
+// oldest, and readers that fell that far behind get ErrMutationsLost.
+type MutationLog[K comparable, V any] struct {
+	lock sync.Mutex
+	ring []Mutation[K, V]
+	// next is the sequence number of the next mutation.
+	next uint64
+	// wake is closed on the next mutation, if a reader waits for it.
+	wake chan struct{}
+}
+
+// NewMutationLog returns a MutationLog keeping up to capacity mutations.
+func NewMutationLog[K comparable, V any](capacity int) *MutationLog[K, V] {
+	return &MutationLog[K, V]{ring: make([]Mutation[K, V], max(capacity, 1)), next: 1}
+}
+
+// WithMutationLog appends every change of the cache contents to log: sets,
+// removals, evictions, expirations and purges. A log records a single
+// cache.
+func WithMutationLog[K comparable, V any](log *MutationLog[K, V]) Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		s.mutations = log
+	}
+}
+
+// Next returns the sequence number of the next mutation.
+func (l *MutationLog[K, V]) Next() uint64 {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	return l.next
+}
+
+// Read returns up to n mutations from sequence number from, waiting until
+// there is at least one or ctx is done. Readers resume from the Seq of the
+// last mutation returned plus one.
+func (l *MutationLog[K, V]) Read(ctx context.Context, from uint64, n int) ([]Mutation[K, V], error) {
+	for {
+		l.lock.Lock()
+		if oldest := l.oldest(); from < oldest {
+			l.lock.Unlock()
+			return nil, ErrMutationsLost
+		}
+		if from < l.next {
+			muts := make([]Mutation[K, V], 0, min(uint64(n), l.next-from))
+			for seq := from; seq < l.next && len(muts) < n; seq++ {
+				muts = append(muts, l.ring[seq%uint64(len(l.ring))])
+			}
+			l.lock.Unlock()
+			return muts, nil
+		}
+		if l.wake == nil {
+			l.wake = make(chan struct{})
+		}
+		wake := l.wake
+		l.lock.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-wake:
+		}
+	}
+}
+
+// oldest returns the sequence number of the oldest mutation kept.
+func (l *MutationLog[K, V]) oldest() uint64 {
+	if n := uint64(len(l.ring)); l.next > n {
+		return l.next - n
+	}
+	return 1
+}
+
+func (l *MutationLog[K, V]) append(m Mutation[K, V]) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	m.Seq = l.next
+	l.ring[m.Seq%uint64(len(l.ring))] = m
+	l.next++
+	if l.wake != nil {
+		close(l.wake)
+		l.wake = nil
+	}
+}
+
+// mutate appends a mutation of ent to the mutation log, if any, and
+// notifies the watchers of its key.
+func (s *SLRU[K, V]) mutate(op string, ent *entry[K, V]) {
+	s.notifyChange(op, ent)
+	if s.mutations == nil {
+		return
+	}
+	m := Mutation[K, V]{Op: op, Key: ent.key}
+	if op == MutationSet {
+		m.Value, m.ExpireAt = ent.value, ent.expireAt
+	}
+	s.mutations.append(m)
+}
+
+// MutationSnapshot returns the live entries as sets, from the next victim
+// to the hottest, and the sequence number of the first mutation of the log
+// after them. Applying them, then the log from that number on, replicates
+// the cache. It returns nil and 0 without a mutation log.
+func (s *SLRU[K, V]) MutationSnapshot() (sets []Mutation[K, V], next uint64) {
+	if s.mutations == nil {
+		return nil, 0
+	}
+	s.acquireShared()
+	defer s.lock.RUnlock()
+	defer s.lockSegments()()
+
+	now := s.now()
+	sets = make([]Mutation[K, V], 0, len(s.items))
+	for _, l := range s.segments() {
+		l.EachReverse(func(e *list.Element) bool {
+			if ent := e.Value.(*entry[K, V]); !s.dead(ent, now) {
+				sets = append(sets, Mutation[K, V]{Op: MutationSet, Key: ent.key, Value: ent.value, ExpireAt: ent.expireAt})
+			}
+			return true
+		})
+	}
+	return sets, s.mutations.Next()
+}
+
+// ApplyMutation applies m to cache, typically a standby replicating
+// another cache. Evictions and expirations remove the key too, so the
+// contents follow those of the source.
+func ApplyMutation[K comparable, V any](cache Cache[K, V], m Mutation[K, V]) {
+	switch m.Op {
+	case MutationSet:
+		if c, ok := cache.(interface {
+			SetWithExpireAt(key K, value V, at time.Time)
+		}); ok {
+			c.SetWithExpireAt(m.Key, m.Value, m.ExpireAt)
+		} else if m.ExpireAt.IsZero() {
+			cache.SetWithTTL(m.Key, m.Value, 0)
+		} else if ttl := time.Until(m.ExpireAt); ttl > 0 {
+			cache.SetWithTTL(m.Key, m.Value, ttl)
+		} else {
+			cache.Remove(m.Key)
+		}
+	case MutationRemove, MutationEvict, MutationExpire:
+		cache.Remove(m.Key)
+	case MutationPurge:
+		cache.Purge()
+	}
+}
@@ -0,0 +1,88 @@
+package slru
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMutationLog(t *testing.T) {
+	log := NewMutationLog[int, int](4)
+	// probation holds a single entry
+	cache := newSLRU[int, int](5, WithMutationLog[int, int](log))
+	cache.Set(1, 10)
+	cache.SetWithTTL(2, 20, time.Hour)
+	cache.Remove(2)
+
+	ctx := context.Background()
+	muts, err := log.Read(ctx, 1, 10)
+	require.NoError(t, err)
+	require.Len(t, muts, 4)
+	require.Equal(t, Mutation[int, int]{Seq: 1, Op: MutationSet, Key: 1, Value: 10}, muts[0])
+	require.Equal(t, MutationSet, muts[1].Op)
+	require.False(t, muts[1].ExpireAt.IsZero())
+	require.Equal(t, Mutation[int, int]{Seq: 3, Op: MutationEvict, Key: 1}, muts[2])
+	require.Equal(t, Mutation[int, int]{Seq: 4, Op: MutationRemove, Key: 2}, muts[3])
+
+	muts, err = log.Read(ctx, 2, 1)
+	require.NoError(t, err)
+	require.Len(t, muts, 1)
+	require.Equal(t, uint64(2), muts[0].Seq)
+
+	cache.Purge()
+	_, err = log.Read(ctx, 1, 10)
+	require.ErrorIs(t, err, ErrMutationsLost)
+	muts, err = log.Read(ctx, 5, 10)
+	require.NoError(t, err)
+	require.Equal(t, []Mutation[int, int]{{Seq: 5, Op: MutationPurge}}, muts)
+	require.Equal(t, uint64(6), log.Next())
+
+	ctx, cancel := context.WithTimeout(ctx, time.Millisecond)
+	defer cancel()
+	_, err = log.Read(ctx, 6, 10)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestMutationLogReplication(t *testing.T) {
+	log := NewMutationLog[int, int](1000)
+	primary := newSLRU[int, int](100, WithMutationLog[int, int](log))
+	for i := 0; i < 10; i++ {
+		primary.Set(i, i)
+	}
+	primary.Get(3)
+
+	standby := newSLRU[int, int](100)
+	sets, next := primary.MutationSnapshot()
+	require.Len(t, sets, 10)
+	require.Equal(t, 3, sets[len(sets)-1].Key)
+	for _, m := range sets {
+		ApplyMutation[int, int](standby, m)
+	}
+
+	done := make(chan error)
+	go func() {
+		for from := next; from < next+3; {
+			muts, err := log.Read(context.Background(), from, 10)
+			if err != nil {
+				done <- err
+				return
+			}
+			for _, m := range muts {
+				ApplyMutation[int, int](standby, m)
+				from = m.Seq + 1
+			}
+		}
+		done <- nil
+	}()
+	primary.Set(20, 20)
+	primary.Remove(5)
+	primary.SetWithTTL(21, 21, time.Hour)
+	require.NoError(t, <-done)
+
+	require.ElementsMatch(t, primary.Keys(), standby.Keys())
+	ttl, ok := standby.TTL(21)
+	require.True(t, ok)
+	require.Greater(t, ttl, time.Minute)
+}
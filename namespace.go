@@ -0,0 +1,167 @@
+package slru
+
+import (
+	"context"
+	"time"
+)
+
+// NamespacedKey is a key of a namespace of Namespaces.
+type NamespacedKey[K comparable] struct {
+	Namespace string
+	Key       K
+}
+
+// Namespaces presents one cache as many logical caches, isolated by name
+// but sharing its capacity, so caches with uneven or shifting loads don't
+// each have to be sized for their peak:
+//
+//	ns := slru.NewNamespaces(slru.New[slru.NamespacedKey[string], []byte](100000))
+//	users, sessions := ns.Namespace("users"), ns.Namespace("sessions")
+type Namespaces[K comparable, V any] struct {
+	cache Cache[NamespacedKey[K], V]
+}
+
+// NewNamespaces returns Namespaces over cache.
+func NewNamespaces[K comparable, V any](cache Cache[NamespacedKey[K], V]) *Namespaces[K, V] {
+	return &Namespaces[K, V]{cache: cache}
+}
+
+// Namespace returns the view of the keys of namespace name. Its Purge,
+// PurgeFunc, Keys and Len only cover the namespace, the last two in time
+// linear in the size of the shared cache. Stats, generations, Compact and
+// Resize are those of the shared cache.
+func (n *Namespaces[K, V]) Namespace(name string) Cache[K, V] {
+	return &namespace[K, V]{name: name, cache: n.cache}
+}
+
+// Shared returns the cache shared by the namespaces.
+func (n *Namespaces[K, V]) Shared() Cache[NamespacedKey[K], V] {
+	return n.cache
+}
+
+type namespace[K comparable, V any] struct {
+	name  string
+	cache Cache[NamespacedKey[K], V]
+}
+
+func (n *namespace[K, V]) key(key K) NamespacedKey[K] {
+	return NamespacedKey[K]{n.name, key}
+}
+
+func (n *namespace[K, V]) Set(key K, value V) {
+	n.cache.Set(n.key(key), value)
+}
+
+func (n *namespace[K, V]) SetAsync(key K, value V) {
+	n.cache.SetAsync(n.key(key), value)
+}
+
+func (n *namespace[K, V]) Flush(ctx context.Context) error {
+	return n.cache.Flush(ctx)
+}
+
+func (n *namespace[K, V]) Add(key K, value V) (inserted bool) {
+	return n.cache.Add(n.key(key), value)
+}
+
+func (n *namespace[K, V]) Replace(key K, value V) (replaced bool) {
+	return n.cache.Replace(n.key(key), value)
+}
+
+func (n *namespace[K, V]) Swap(key K, value V) (old V, existed bool) {
+	return n.cache.Swap(n.key(key), value)
+}
+
+func (n *namespace[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	n.cache.SetWithTTL(n.key(key), value, ttl)
+}
+
+func (n *namespace[K, V]) Get(key K) (value V, ok bool) {
+	return n.cache.Get(n.key(key))
+}
+
+func (n *namespace[K, V]) TryGet(key K) (value V, ok, locked bool) {
+	return n.cache.TryGet(n.key(key))
+}
+
+func (n *namespace[K, V]) TrySet(key K, value V) (locked bool) {
+	return n.cache.TrySet(n.key(key), value)
+}
+
+func (n *namespace[K, V]) GetOrLoad(ctx context.Context, key K, load func(ctx context.Context, key K) (V, error)) (V, error) {
+	return n.cache.GetOrLoad(ctx, n.key(key), func(ctx context.Context, key NamespacedKey[K]) (V, error) {
+		return load(ctx, key.Key)
+	})
+}
+
+func (n *namespace[K, V]) CompareAndSwap(key K, old, new V) (swapped bool) {
+	return n.cache.CompareAndSwap(n.key(key), old, new)
+}
+
+func (n *namespace[K, V]) Update(key K, fn func(old V, exists bool) (new V, store bool)) (value V, ok bool) {
+	return n.cache.Update(n.key(key), fn)
+}
+
+func (n *namespace[K, V]) Contains(key K) (ok bool) {
+	return n.cache.Contains(n.key(key))
+}
+
+func (n *namespace[K, V]) Peek(key K) (value V, ok bool) {
+	return n.cache.Peek(n.key(key))
+}
+
+func (n *namespace[K, V]) TTL(key K) (ttl time.Duration, ok bool) {
+	return n.cache.TTL(n.key(key))
+}
+
+func (n *namespace[K, V]) Remove(key K) (present bool) {
+	return n.cache.Remove(n.key(key))
+}
+
+func (n *namespace[K, V]) LockKey(key K) (unlock func()) {
+	return n.cache.LockKey(n.key(key))
+}
+
+func (n *namespace[K, V]) Keys() []K {
+	var keys []K
+	for _, key := range n.cache.Keys() {
+		if key.Namespace == n.name {
+			keys = append(keys, key.Key)
+		}
+	}
+	return keys
+}
+
+func (n *namespace[K, V]) NewGeneration() (gen uint64) {
+	return n.cache.NewGeneration()
+}
+
+func (n *namespace[K, V]) InvalidateBefore(gen uint64) {
+	n.cache.InvalidateBefore(gen)
+}
+
+func (n *namespace[K, V]) Len() int {
+	return len(n.Keys())
+}
+
+func (n *namespace[K, V]) Stats() Stats {
+	return n.cache.Stats()
+}
+
+func (n *namespace[K, V]) Purge() {
+	n.PurgeFunc(func(K, V) bool { return true })
+}
+
+func (n *namespace[K, V]) PurgeFunc(fn func(key K, value V) bool) (removed int) {
+	return n.cache.PurgeFunc(func(key NamespacedKey[K], value V) bool {
+		return key.Namespace == n.name && fn(key.Key, value)
+	})
+}
+
+func (n *namespace[K, V]) Compact() {
+	n.cache.Compact()
+}
+
+func (n *namespace[K, V]) Resize(size int) (evicted int) {
+	return n.cache.Resize(size)
+}
@@ -0,0 +1,41 @@
+package slru
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNamespaces(t *testing.T) {
+	ns := NewNamespaces(New[NamespacedKey[int], string](100))
+	users, sessions := ns.Namespace("users"), ns.Namespace("sessions")
+	users.Set(1, "alice")
+	users.Set(2, "bob")
+	sessions.Set(1, "s1")
+
+	v, ok := users.Get(1)
+	require.True(t, ok)
+	require.Equal(t, "alice", v)
+	v, ok = sessions.Get(1)
+	require.True(t, ok)
+	require.Equal(t, "s1", v)
+	require.False(t, sessions.Contains(2))
+	require.ElementsMatch(t, []int{1, 2}, users.Keys())
+	require.Equal(t, 1, sessions.Len())
+	require.Equal(t, 3, ns.Shared().Len())
+
+	v, err := sessions.GetOrLoad(context.Background(), 3, func(ctx context.Context, key int) (string, error) {
+		require.Equal(t, 3, key)
+		return "s3", nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, "s3", v)
+
+	users.Purge()
+	require.Zero(t, users.Len())
+	require.Equal(t, 2, sessions.Len())
+	require.Equal(t, 1, sessions.PurgeFunc(func(key int, value string) bool { return key == 3 }))
+	require.True(t, sessions.Remove(1))
+	require.Zero(t, ns.Shared().Len())
+}
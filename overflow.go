@@ -0,0 +1,205 @@
+package slru
+
+import (
+	"time"
+
+	"github.com/hey-kong/slru/list"
+)
+
+// Spilled is an entry spilled to the store of WithOverflow: its value and
+// when it expires, zero if never.
+type Spilled[V any] struct {
+	Value    V
+	ExpireAt time.Time
+}
+
+// WithOverflow spills the entries evicted to make room to store, such as a
+// FileStore, giving up to n moderately cold keys a second chance: Get and
+// GetOrLoad missing a spilled key restore it from store into probation,
+// with the TTL it had left, and remove it from store. The cache keeps the
+// spilled keys in memory, and drops the least recently spilled from store
+// beyond n. A background writer applies the writes to store in the order
+// they were made, so a key only restores once its spill is written.
+// Entries with tags or dependencies are not spilled, as their
+// invalidations wouldn't reach them. Setting or removing a spilled key
+// drops it from store; Purge, PurgeFunc and InvalidateBefore drop every
+// spilled entry. Only the keys spilled by the cache are read back, so
+// store holds no entries of its own. An n of 0 or less disables it.
+func WithOverflow[K comparable, V any](store Store[K, Spilled[V]], n int) Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		if n <= 0 {
+			s.overflow = nil
+			return
+		}
+		s.overflow = store
+		s.overflowSize = n
+		s.spilled = make(map[K]*list.Element)
+		s.spillOrder = list.New()
+		s.spillWake = make(chan struct{}, 1)
+	}
+}
+
+// spilledKey is a key of the spill index: seq numbers its spill, and
+// written tells whether that spill reached the store.
+type spilledKey[K comparable] struct {
+	key     K
+	seq     uint64
+	written bool
+}
+
+// spill is a write to the store of WithOverflow, pending for the writer:
+// the entry to spill, or the key to drop.
+type spill[K comparable, V any] struct {
+	key     K
+	seq     uint64
+	entry   Spilled[V]
+	dropped bool
+}
+
+// startSpilling starts the writer of the spills, if enabled.
+func (s *SLRU[K, V]) startSpilling() {
+	if s.overflow == nil {
+		return
+	}
+	s.workers.Add(1)
+	go func() {
+		defer s.workers.Done()
+		for {
+			select {
+			case <-s.done:
+				s.writeSpills()
+				return
+			case <-s.spillWake:
+				s.writeSpills()
+			}
+		}
+	}()
+}
+
+// queueSpill queues sp for the writer. The caller holds the lock
+// exclusively, which orders the spills.
+func (s *SLRU[K, V]) queueSpill(sp spill[K, V]) {
+	s.spills = append(s.spills, sp)
+	select {
+	case s.spillWake <- struct{}{}:
+	default:
+	}
+}
+
+// spillEntry spills the evicted ent, if it may be, dropping the least
+// recently spilled key beyond the limit.
+func (s *SLRU[K, V]) spillEntry(ent *entry[K, V]) {
+	if s.overflow == nil || len(ent.tags) > 0 || len(ent.deps) > 0 {
+		return
+	}
+	if e, ok := s.spilled[ent.key]; ok {
+		s.spillOrder.Remove(e)
+	}
+	s.spillSeq++
+	s.spilled[ent.key] = s.spillOrder.PushFront(&spilledKey[K]{key: ent.key, seq: s.spillSeq})
+	s.queueSpill(spill[K, V]{key: ent.key, seq: s.spillSeq, entry: Spilled[V]{ent.value, ent.expireAt}})
+	if s.spillOrder.Len() > s.overflowSize {
+		s.unspill(s.spillOrder.Back().Value.(*spilledKey[K]).key)
+	}
+}
+
+// unspill drops key from the store, if spilled.
+func (s *SLRU[K, V]) unspill(key K) {
+	if s.overflow == nil {
+		return
+	}
+	if e, ok := s.spilled[key]; ok {
+		delete(s.spilled, key)
+		s.spillOrder.Remove(e)
+		s.queueSpill(spill[K, V]{key: key, dropped: true})
+	}
+}
+
+// dropSpilled drops every spilled entry from the store.
+func (s *SLRU[K, V]) dropSpilled() {
+	for key := range s.spilled {
+		s.unspill(key)
+	}
+}
+
+// flushSpills writes the pending spills once Close stopped the writer.
+func (s *SLRU[K, V]) flushSpills() {
+	if s.overflow == nil {
+		return
+	}
+	select {
+	case <-s.done:
+		s.writeSpills()
+	default:
+	}
+}
+
+// writeSpills applies the pending spills to the store, in order and
+// outside the cache lock, then marks the spilled keys as written. It
+// releases the lock without flushing, as it is the flush.
+func (s *SLRU[K, V]) writeSpills() {
+	s.spillLock.Lock()
+	defer s.spillLock.Unlock()
+
+	s.acquire()
+	spills := s.spills
+	s.spills = nil
+	s.notify(s.unlockEvicted())
+	if len(spills) == 0 {
+		return
+	}
+	for _, sp := range spills {
+		if sp.dropped {
+			s.overflow.Remove(sp.key)
+		} else {
+			s.overflow.Set(sp.key, sp.entry)
+		}
+	}
+
+	s.acquire()
+	defer func() { s.notify(s.unlockEvicted()) }()
+
+	for _, sp := range spills {
+		if e, ok := s.spilled[sp.key]; ok && !sp.dropped {
+			if k := e.Value.(*spilledKey[K]); k.seq == sp.seq {
+				k.written = true
+			}
+		}
+	}
+}
+
+// restore reads the spilled key back from the store into probation,
+// unless it expired, returning its value. It misses keys whose spill is
+// not written yet.
+func (s *SLRU[K, V]) restore(key K) (value V, ok bool) {
+	s.acquireShared()
+	var seq uint64
+	if e, spilled := s.spilled[key]; spilled {
+		if k := e.Value.(*spilledKey[K]); k.written {
+			seq, ok = k.seq, true
+		}
+	}
+	s.lock.RUnlock()
+	if !ok {
+		return value, false
+	}
+	spilled, ok := s.overflow.Get(key)
+	if !ok {
+		return value, false
+	}
+
+	s.acquire()
+	defer s.unlock()
+
+	// the store held the spill read if no write to key came after it
+	if e, ok := s.spilled[key]; !ok || e.Value.(*spilledKey[K]).seq != seq {
+		return value, false
+	}
+	s.unspill(key)
+	now := s.now()
+	if !spilled.ExpireAt.IsZero() && !now.Before(spilled.ExpireAt) {
+		return value, false
+	}
+	s.setEntryAt(key, spilled.Value, s.weigh(key, spilled.Value), now, spilled.ExpireAt, false)
+	return s.clone(spilled.Value), true
+}
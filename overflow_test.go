@@ -0,0 +1,209 @@
+package slru
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// mapStore is a Store in a map.
+type mapStore[K comparable, V any] struct {
+	lock sync.Mutex
+	m    map[K]V
+}
+
+func newMapStore[K comparable, V any]() *mapStore[K, V] {
+	return &mapStore[K, V]{m: make(map[K]V)}
+}
+
+func (m *mapStore[K, V]) Get(key K) (value V, ok bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	value, ok = m.m[key]
+	return value, ok
+}
+
+func (m *mapStore[K, V]) Set(key K, value V) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.m[key] = value
+}
+
+func (m *mapStore[K, V]) Remove(key K) (present bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	_, present = m.m[key]
+	delete(m.m, key)
+	return present
+}
+
+// len returns the number of entries in the store.
+func (m *mapStore[K, V]) len() int {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return len(m.m)
+}
+
+// contains tells whether key is in the store.
+func (m *mapStore[K, V]) contains(key K) bool {
+	_, ok := m.Get(key)
+	return ok
+}
+
+func TestOverflow(t *testing.T) {
+	store := newMapStore[int, Spilled[int]]()
+	cache := newSLRU[int, int](10, WithOverflow[int, int](store, 100))
+	defer cache.Close()
+	for i := range 10 {
+		cache.Set(i, i)
+	}
+	cache.writeSpills()
+	require.Equal(t, 8, store.len())
+	require.False(t, cache.Contains(0))
+
+	v, ok := cache.Get(0)
+	require.True(t, ok)
+	require.Equal(t, 0, v)
+	require.True(t, cache.Contains(0))
+	cache.writeSpills()
+	require.False(t, store.contains(0))
+	require.Equal(t, 8, store.len())
+	require.NoError(t, cache.Verify())
+
+	// writes and removals drop the spilled entry
+	cache.Set(1, 10)
+	require.False(t, cache.Remove(2))
+	cache.writeSpills()
+	require.False(t, store.contains(1))
+	require.False(t, store.contains(2))
+	_, ok = cache.Get(2)
+	require.False(t, ok)
+
+	value, err := cache.GetOrLoad(context.Background(), 3, func(context.Context, int) (int, error) {
+		return 0, context.Canceled
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, value)
+
+	cache.Purge()
+	cache.writeSpills()
+	require.Zero(t, store.len())
+}
+
+func TestOverflowBound(t *testing.T) {
+	store := newMapStore[int, Spilled[int]]()
+	cache := newSLRU[int, int](2, WithOverflow[int, int](store, 3))
+	defer cache.Close()
+	for i := range 100 {
+		cache.Set(i, i)
+	}
+	cache.writeSpills()
+	require.Equal(t, 3, store.len())
+	require.Len(t, cache.spilled, 3)
+	for _, key := range []int{96, 97, 98} {
+		require.True(t, store.contains(key), key)
+	}
+	_, ok := cache.Get(0)
+	require.False(t, ok)
+}
+
+func TestOverflowPending(t *testing.T) {
+	store := newMapStore[int, Spilled[int]]()
+	cache := newSLRU[int, int](2, WithOverflow[int, int](store, 10))
+	defer cache.Close()
+	cache.spillLock.Lock()
+	cache.Set(0, 0)
+	cache.Set(1, 1)
+	cache.Set(2, 2)
+
+	// the spill of 0 is not written yet, nor its drop by the write after
+	_, ok := cache.Get(0)
+	require.False(t, ok)
+	cache.Set(0, 10)
+	cache.spillLock.Unlock()
+	cache.writeSpills()
+	require.False(t, store.contains(0))
+	v, ok := cache.Get(0)
+	require.True(t, ok)
+	require.Equal(t, 10, v)
+}
+
+func TestOverflowTTL(t *testing.T) {
+	now := time.Unix(0, 0)
+	store := newMapStore[int, Spilled[int]]()
+	cache := newSLRU[int, int](5,
+		WithClock[int, int](func() time.Time { return now }),
+		WithOverflow[int, int](store, 10),
+	)
+	defer cache.Close()
+	cache.SetWithTTL(0, 0, time.Minute)
+	cache.SetWithTTL(1, 1, time.Second)
+	cache.Set(2, 2)
+	cache.writeSpills()
+	require.Equal(t, 2, store.len())
+
+	now = now.Add(2 * time.Second)
+	_, ok := cache.Get(1)
+	require.False(t, ok)
+	cache.writeSpills()
+	require.False(t, store.contains(1))
+	_, ok = cache.Get(0)
+	require.True(t, ok)
+	ttl, ok := cache.TTL(0)
+	require.True(t, ok)
+	require.Equal(t, 58*time.Second, ttl)
+}
+
+func TestOverflowSkipsTagged(t *testing.T) {
+	store := newMapStore[int, Spilled[int]]()
+	cache := newSLRU[int, int](5, WithOverflow[int, int](store, 10))
+	defer cache.Close()
+	cache.SetWithTags(0, 0, "t")
+	cache.Set(1, 1)
+	cache.writeSpills()
+	require.Zero(t, store.len())
+}
+
+func TestOverflowClose(t *testing.T) {
+	store := newMapStore[int, Spilled[int]]()
+	cache := newSLRU[int, int](2, WithOverflow[int, int](store, 10))
+	cache.Set(0, 0)
+	cache.Set(1, 1)
+	cache.Set(2, 2)
+	require.NoError(t, cache.Close())
+	require.True(t, store.contains(0))
+
+	// writes after Close go to the store directly
+	cache.Set(3, 3)
+	require.True(t, store.contains(1))
+}
+
+func TestOverflowFileStore(t *testing.T) {
+	store, err := NewFileStore(t.TempDir(), JSONCodec[string]{}, GobCodec[Spilled[string]]{})
+	require.NoError(t, err)
+	cache := NewSharded[string, string](20, 2, nil, WithOverflow[string, string](store, 100))
+	defer cache.Close()
+	keys := []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"}
+	for _, key := range keys {
+		cache.Set(key, key+key)
+	}
+	for _, s := range cache.shards {
+		s.writeSpills()
+	}
+	for _, key := range keys {
+		v, ok := cache.Get(key)
+		require.True(t, ok, key)
+		require.Equal(t, key+key, v)
+		for _, s := range cache.shards {
+			s.writeSpills()
+		}
+	}
+	require.NoError(t, store.Err())
+}
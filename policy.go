@@ -0,0 +1,467 @@
+package slru
+
+import (
+	"context"
+	"hash/maphash"
+	"sync"
+	"time"
+)
+
+// replacer is a replacement policy run by a Policy cache. It tracks the
+// resident keys, along with whatever history of past keys it keeps, and
+// decides which of them to evict. Its capacity is a number of entries.
+type replacer[K comparable] interface {
+	// add records the insertion of key, which is not resident, calling
+	// evict with each resident key that has to make room for it.
+	add(key K, evict func(key K))
+	// hit records an access to resident key.
+	hit(key K)
+	// remove forgets resident key, which was removed or expired.
+	remove(key K)
+	// resize changes the capacity, calling evict with each key that no
+	// longer fits.
+	resize(size int, evict func(key K))
+	// keys returns the resident keys, from the next victim to the last one.
+	keys() []K
+	// reset forgets every key.
+	reset()
+}
+
+// Policy is a cache run by a replacement policy other than SLRU, such as
+// ARC, for comparing policies behind the same Cache interface. It counts
+// capacity in entries and shares the entries, statistics, loading and
+// callbacks of SLRU, but not its per-segment features.
+type Policy[K comparable, V any] struct {
+	lock         sync.Mutex
+	items        map[K]*entry[K, V]
+	policy       replacer[K]
+	ttl          time.Duration
+	equal        func(a, b V) bool
+	onEvict      func(key K, value V)
+	evicted      []eviction[K, V]
+	gen          uint64
+	minGen       uint64
+	stats        stats
+	loads        loadGroup[K, V]
+	keyLocks     *keyLocks[K]
+	keyLocksOnce sync.Once
+	// seed is the seed of WithPolicySeed, if seeded.
+	seed   uint64
+	seeded bool
+}
+
+// PolicyOption configures a Policy.
+type PolicyOption[K comparable, V any] func(*Policy[K, V])
+
+// WithPolicyTTL expires entries set without an explicit TTL after ttl.
+func WithPolicyTTL[K comparable, V any](ttl time.Duration) PolicyOption[K, V] {
+	return func(p *Policy[K, V]) {
+		p.ttl = ttl
+	}
+}
+
+// WithPolicyEqual is WithEqual for a Policy.
+func WithPolicyEqual[K comparable, V any](equal func(a, b V) bool) PolicyOption[K, V] {
+	return func(p *Policy[K, V]) {
+		p.equal = equal
+	}
+}
+
+// WithPolicyEvictCallback calls fn with each entry evicted to make room,
+// once the evicting operation has released the cache lock, and with purged
+// entries after Purge returns. fn may call the cache.
+func WithPolicyEvictCallback[K comparable, V any](fn func(key K, value V)) PolicyOption[K, V] {
+	return func(p *Policy[K, V]) {
+		p.onEvict = fn
+	}
+}
+
+// WithPolicySeed is WithSeed for a Policy, seeding the victims of
+// NewRandom.
+func WithPolicySeed[K comparable, V any](seed uint64) PolicyOption[K, V] {
+	return func(p *Policy[K, V]) {
+		p.seed = seed
+		p.seeded = true
+	}
+}
+
+func newPolicy[K comparable, V any](r replacer[K], opts ...PolicyOption[K, V]) *Policy[K, V] {
+	p := &Policy[K, V]{
+		items:  make(map[K]*entry[K, V]),
+		policy: r,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if r, ok := r.(interface{ reseed(seed uint64) }); ok && p.seeded {
+		r.reseed(p.seed)
+	}
+	return p
+}
+
+func (p *Policy[K, V]) Set(key K, value V) {
+	p.lock.Lock()
+	defer p.unlock()
+
+	p.set(key, value, p.ttl)
+}
+
+// SetAsync is Set, as a Policy doesn't buffer writes.
+func (p *Policy[K, V]) SetAsync(key K, value V) {
+	p.Set(key, value)
+}
+
+// Flush returns nil, as a Policy doesn't buffer writes.
+func (p *Policy[K, V]) Flush(ctx context.Context) error {
+	return nil
+}
+
+func (p *Policy[K, V]) Add(key K, value V) (inserted bool) {
+	p.lock.Lock()
+	defer p.unlock()
+
+	_, exists := p.live(key)
+	p.set(key, value, p.ttl)
+	_, ok := p.items[key]
+	return !exists && ok
+}
+
+func (p *Policy[K, V]) Replace(key K, value V) (replaced bool) {
+	p.lock.Lock()
+	defer p.unlock()
+
+	if _, ok := p.live(key); !ok {
+		return false
+	}
+	p.set(key, value, p.ttl)
+	return true
+}
+
+func (p *Policy[K, V]) Swap(key K, value V) (old V, existed bool) {
+	p.lock.Lock()
+	defer p.unlock()
+
+	if ent, ok := p.live(key); ok {
+		old, existed = ent.value, true
+	}
+	p.set(key, value, p.ttl)
+	return old, existed
+}
+
+func (p *Policy[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	p.lock.Lock()
+	defer p.unlock()
+
+	p.set(key, value, ttl)
+}
+
+// set adds or updates key, where a non-positive ttl never expires. Updates
+// count as accesses.
+func (p *Policy[K, V]) set(key K, value V, ttl time.Duration) {
+	now := time.Now()
+	var expireAt time.Time
+	if ttl > 0 {
+		expireAt = now.Add(ttl)
+	}
+	if ent, ok := p.items[key]; ok {
+		ent.value = value
+		ent.expireAt = expireAt
+		ent.gen = p.gen
+		ent.accessed = now
+		p.policy.hit(key)
+		return
+	}
+	p.items[key] = &entry[K, V]{
+		key:      key,
+		value:    value,
+		weight:   1,
+		expireAt: expireAt,
+		gen:      p.gen,
+		created:  now,
+		accessed: now,
+	}
+	p.policy.add(key, p.evict)
+}
+
+func (p *Policy[K, V]) Get(key K) (value V, ok bool) {
+	p.lock.Lock()
+	defer p.unlock()
+
+	return p.get(key)
+}
+
+func (p *Policy[K, V]) get(key K) (value V, ok bool) {
+	ent, ok := p.items[key]
+	if !ok {
+		p.stats.misses.inc()
+		return value, false
+	}
+	now := time.Now()
+	if p.dead(ent, now) {
+		p.remove(key)
+		p.stats.misses.inc()
+		return value, false
+	}
+	ent.accessed = now
+	ent.hits++
+	p.policy.hit(key)
+	p.stats.hits.inc()
+	return ent.value, true
+}
+
+func (p *Policy[K, V]) TryGet(key K) (value V, ok, locked bool) {
+	if !p.lock.TryLock() {
+		return value, false, false
+	}
+	defer p.unlock()
+
+	value, ok = p.get(key)
+	return value, ok, true
+}
+
+func (p *Policy[K, V]) TrySet(key K, value V) (locked bool) {
+	if !p.lock.TryLock() {
+		return false
+	}
+	defer p.unlock()
+
+	p.set(key, value, p.ttl)
+	return true
+}
+
+// GetOrLoad is SLRU.GetOrLoad.
+func (p *Policy[K, V]) GetOrLoad(ctx context.Context, key K, load func(ctx context.Context, key K) (V, error)) (V, error) {
+	if value, ok := p.Get(key); ok {
+		return value, nil
+	}
+	return p.loads.do(ctx, key, load, p.Set)
+}
+
+func (p *Policy[K, V]) CompareAndSwap(key K, old, new V) (swapped bool) {
+	p.lock.Lock()
+	defer p.unlock()
+
+	ent, ok := p.live(key)
+	if !ok || !p.equals(ent.value, old) {
+		return false
+	}
+	p.set(key, new, p.ttl)
+	return true
+}
+
+func (p *Policy[K, V]) Update(key K, fn func(old V, exists bool) (new V, store bool)) (value V, ok bool) {
+	p.lock.Lock()
+	defer p.unlock()
+
+	ent, exists := p.live(key)
+	if exists {
+		value = ent.value
+	}
+	new, store := fn(value, exists)
+	if !store {
+		return value, exists
+	}
+	p.set(key, new, p.ttl)
+	_, ok = p.items[key]
+	return new, ok
+}
+
+func (p *Policy[K, V]) Contains(key K) (ok bool) {
+	p.lock.Lock()
+	defer p.unlock()
+
+	_, ok = p.live(key)
+	return ok
+}
+
+func (p *Policy[K, V]) Peek(key K) (value V, ok bool) {
+	p.lock.Lock()
+	defer p.unlock()
+
+	if ent, ok := p.live(key); ok {
+		return ent.value, true
+	}
+	return value, false
+}
+
+func (p *Policy[K, V]) TTL(key K) (ttl time.Duration, ok bool) {
+	p.lock.Lock()
+	defer p.unlock()
+
+	ent, ok := p.live(key)
+	if !ok {
+		return 0, false
+	}
+	if !ent.expireAt.IsZero() {
+		ttl = time.Until(ent.expireAt)
+	}
+	return ttl, true
+}
+
+func (p *Policy[K, V]) Remove(key K) (present bool) {
+	p.lock.Lock()
+	defer p.unlock()
+
+	if _, ok := p.items[key]; !ok {
+		return false
+	}
+	p.remove(key)
+	return true
+}
+
+// LockKey is SLRU.LockKey.
+func (p *Policy[K, V]) LockKey(key K) (unlock func()) {
+	p.keyLocksOnce.Do(func() {
+		p.keyLocks = &keyLocks[K]{hash: defaultHash[K](maphash.MakeSeed())}
+	})
+	return p.keyLocks.lock(key)
+}
+
+// Keys returns the keys in cache, from the next victim of the policy to the
+// last one.
+func (p *Policy[K, V]) Keys() []K {
+	p.lock.Lock()
+	defer p.unlock()
+
+	return p.policy.keys()
+}
+
+func (p *Policy[K, V]) NewGeneration() (gen uint64) {
+	p.lock.Lock()
+	defer p.unlock()
+
+	p.gen++
+	return p.gen
+}
+
+func (p *Policy[K, V]) InvalidateBefore(gen uint64) {
+	p.lock.Lock()
+	defer p.unlock()
+
+	p.minGen = max(p.minGen, gen)
+}
+
+func (p *Policy[K, V]) Len() int {
+	p.lock.Lock()
+	defer p.unlock()
+
+	return len(p.items)
+}
+
+func (p *Policy[K, V]) Stats() Stats {
+	return p.stats.load()
+}
+
+func (p *Policy[K, V]) Purge() {
+	p.lock.Lock()
+	items := p.items
+	p.items = make(map[K]*entry[K, V])
+	p.policy.reset()
+	p.lock.Unlock()
+
+	if p.onEvict != nil {
+		for _, ent := range items {
+			p.onEvict(ent.key, ent.value)
+		}
+	}
+}
+
+func (p *Policy[K, V]) PurgeFunc(fn func(key K, value V) bool) (removed int) {
+	p.lock.Lock()
+	defer p.unlock()
+
+	n := len(p.items)
+	for key, ent := range p.items {
+		if fn(key, ent.value) {
+			p.remove(key)
+			removed++
+		}
+	}
+	if removed > n/2 {
+		p.compact()
+	}
+	return removed
+}
+
+func (p *Policy[K, V]) Compact() {
+	p.lock.Lock()
+	defer p.unlock()
+
+	p.compact()
+}
+
+func (p *Policy[K, V]) compact() {
+	items := make(map[K]*entry[K, V], len(p.items))
+	for key, ent := range p.items {
+		items[key] = ent
+	}
+	p.items = items
+}
+
+func (p *Policy[K, V]) Resize(size int) (evicted int) {
+	p.lock.Lock()
+	defer p.unlock()
+
+	p.policy.resize(size, func(key K) {
+		p.evict(key)
+		evicted++
+	})
+	return evicted
+}
+
+// live returns the entry of key if it is present and not expired.
+func (p *Policy[K, V]) live(key K) (*entry[K, V], bool) {
+	if ent, ok := p.items[key]; ok && !p.dead(ent, time.Now()) {
+		return ent, true
+	}
+	return nil, false
+}
+
+// dead reports whether ent has expired or was invalidated by generation.
+func (p *Policy[K, V]) dead(ent *entry[K, V], now time.Time) bool {
+	return ent.gen < p.minGen || ent.expired(now)
+}
+
+func (p *Policy[K, V]) equals(a, b V) bool {
+	if p.equal != nil {
+		return p.equal(a, b)
+	}
+	return any(a) == any(b)
+}
+
+// remove drops key from the index and the policy.
+func (p *Policy[K, V]) remove(key K) {
+	delete(p.items, key)
+	p.policy.remove(key)
+}
+
+// evict drops a victim of the policy, which has already forgotten it as
+// resident.
+func (p *Policy[K, V]) evict(key K) {
+	ent, ok := p.items[key]
+	if !ok {
+		return
+	}
+	delete(p.items, key)
+	now := time.Now()
+	p.stats.evictions.inc()
+	if ent.hits == 0 {
+		p.stats.oneHitWonders.inc()
+	}
+	p.stats.evictionAge.observe(now.Sub(ent.created))
+	p.stats.evictionIdle.observe(now.Sub(ent.accessed))
+	if p.onEvict != nil {
+		p.evicted = append(p.evicted, eviction[K, V]{ent.key, ent.value})
+	}
+}
+
+// unlock releases the cache lock, then passes the entries evicted
+// meanwhile to the eviction callback.
+func (p *Policy[K, V]) unlock() {
+	evicted := p.evicted
+	p.evicted = nil
+	p.lock.Unlock()
+	for _, ev := range evicted {
+		p.onEvict(ev.key, ev.value)
+	}
+}
@@ -0,0 +1,155 @@
+package slru
+
+import (
+	"context"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testPolicy checks the behaviour every replacement policy shares, running
+// it on caches created by newCache.
+func testPolicy(t *testing.T, newCache func(size int, opts ...PolicyOption[int, int]) *Policy[int, int]) {
+	t.Run("Basics", func(t *testing.T) {
+		var cache Cache[int, int] = newCache(10)
+		for i := 0; i < 5; i++ {
+			cache.Set(i, i*10)
+		}
+		require.Equal(t, 5, cache.Len())
+		value, ok := cache.Get(3)
+		require.True(t, ok)
+		require.Equal(t, 30, value)
+		require.True(t, cache.Contains(4))
+		require.True(t, cache.Remove(4))
+		require.False(t, cache.Remove(4))
+		require.False(t, cache.Contains(4))
+		require.ElementsMatch(t, []int{0, 1, 2, 3}, cache.Keys())
+
+		require.False(t, cache.Add(1, 11))
+		require.True(t, cache.Replace(1, 12))
+		require.False(t, cache.Replace(9, 9))
+		old, existed := cache.Swap(1, 13)
+		require.True(t, existed)
+		require.Equal(t, 12, old)
+		require.True(t, cache.CompareAndSwap(1, 13, 14))
+		require.False(t, cache.CompareAndSwap(1, 13, 15))
+		value, ok = cache.Update(1, func(old int, exists bool) (int, bool) { return old + 1, exists })
+		require.True(t, ok)
+		require.Equal(t, 15, value)
+		value, ok = cache.Peek(1)
+		require.True(t, ok)
+		require.Equal(t, 15, value)
+
+		value, err := cache.GetOrLoad(context.Background(), 7, func(ctx context.Context, key int) (int, error) {
+			return key * 10, nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, 70, value)
+		require.True(t, cache.Contains(7))
+
+		stats := cache.Stats()
+		require.Equal(t, uint64(1), stats.Hits)
+		require.Equal(t, uint64(1), stats.Misses)
+	})
+
+	t.Run("Capacity", func(t *testing.T) {
+		var evicted []int
+		cache := newCache(50, WithPolicyEvictCallback(func(key, _ int) { evicted = append(evicted, key) }))
+		r := rand.New(rand.NewPCG(1, 2))
+		for i := 0; i < 10000; i++ {
+			key := int(r.Int64N(200))
+			if _, ok := cache.Get(key); !ok {
+				cache.Set(key, key)
+			}
+			require.LessOrEqual(t, cache.Len(), 50)
+		}
+		keys := cache.Keys()
+		require.Len(t, keys, cache.Len())
+		for _, key := range keys {
+			require.True(t, cache.Contains(key))
+		}
+		require.Equal(t, uint64(len(evicted)), cache.Stats().Evictions)
+		for _, key := range evicted[len(evicted)-10:] {
+			if !cache.Contains(key) {
+				return
+			}
+		}
+		t.Fatal("no recent victim was gone")
+	})
+
+	t.Run("Resize", func(t *testing.T) {
+		cache := newCache(20)
+		for i := 0; i < 20; i++ {
+			cache.Set(i, i)
+			cache.Get(i % 5)
+		}
+		require.Equal(t, 20, cache.Len())
+		require.Equal(t, 15, cache.Resize(5))
+		require.Equal(t, 5, cache.Len())
+		require.Len(t, cache.Keys(), 5)
+		for i := 20; i < 30; i++ {
+			cache.Set(i, i)
+		}
+		require.Equal(t, 5, cache.Len())
+
+		require.Equal(t, 5, cache.Resize(0))
+		cache.Set(1, 1)
+		require.Zero(t, cache.Len())
+		cache.Resize(10)
+		cache.Set(1, 1)
+		require.Equal(t, 1, cache.Len())
+	})
+
+	t.Run("Expiry", func(t *testing.T) {
+		cache := newCache(10, WithPolicyTTL[int, int](time.Hour))
+		cache.Set(1, 1)
+		cache.SetWithTTL(2, 2, time.Nanosecond)
+		time.Sleep(time.Millisecond)
+		ttl, ok := cache.TTL(1)
+		require.True(t, ok)
+		require.Greater(t, ttl, 59*time.Minute)
+		require.False(t, cache.Contains(2))
+		_, ok = cache.Get(2)
+		require.False(t, ok)
+		require.Equal(t, 1, cache.Len())
+
+		gen := cache.NewGeneration()
+		cache.Set(3, 3)
+		cache.InvalidateBefore(gen)
+		require.False(t, cache.Contains(1))
+		require.True(t, cache.Contains(3))
+	})
+
+	t.Run("ReentrantEvictCallback", func(t *testing.T) {
+		var cache *Policy[int, int]
+		evicted := 0
+		cache = newCache(4, WithPolicyEvictCallback(func(key, _ int) {
+			// the cache is unlocked: calling it doesn't deadlock
+			require.False(t, cache.Contains(key))
+			evicted++
+		}))
+		for i := range 10 {
+			cache.Set(i, i)
+		}
+		require.Equal(t, 10-cache.Len(), evicted)
+	})
+
+	t.Run("Purge", func(t *testing.T) {
+		var evicted []int
+		cache := newCache(10, WithPolicyEvictCallback(func(key, _ int) { evicted = append(evicted, key) }))
+		for i := 0; i < 10; i++ {
+			cache.Set(i, i)
+		}
+		require.Equal(t, 3, cache.PurgeFunc(func(key, _ int) bool { return key < 3 }))
+		require.Equal(t, 7, cache.Len())
+		cache.Compact()
+		cache.Purge()
+		require.Zero(t, cache.Len())
+		require.Empty(t, cache.Keys())
+		require.Len(t, evicted, 7)
+		cache.Set(1, 1)
+		require.Equal(t, []int{1}, cache.Keys())
+	})
+}
@@ -0,0 +1,83 @@
+package slru
+
+import "math/rand/v2"
+
+// NewRandom creates a cache of up to size entries evicting a uniformly
+// random one to make room, a baseline free of any recency or frequency
+// bookkeeping.
+func NewRandom[K comparable, V any](size int, opts ...PolicyOption[K, V]) *Policy[K, V] {
+	return newPolicy[K, V](newRandom[K](size, rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))), opts...)
+}
+
+// random is the random replacer. keys holds the resident keys densely and
+// index the position of each.
+type random[K comparable] struct {
+	size  int
+	rand  *rand.Rand
+	keyed []K
+	index map[K]int
+}
+
+func newRandom[K comparable](size int, r *rand.Rand) *random[K] {
+	c := &random[K]{size: max(size, 0), rand: r}
+	c.reset()
+	return c
+}
+
+// reseed draws the victims from a generator seeded with seed.
+func (c *random[K]) reseed(seed uint64) {
+	c.rand = rand.New(rand.NewPCG(seed, splitmix(seed)))
+}
+
+func (c *random[K]) reset() {
+	c.keyed = nil
+	c.index = make(map[K]int)
+}
+
+func (c *random[K]) add(key K, evict func(key K)) {
+	if c.size < 1 {
+		evict(key)
+		return
+	}
+	for len(c.keyed) >= c.size {
+		c.evict(evict)
+	}
+	c.index[key] = len(c.keyed)
+	c.keyed = append(c.keyed, key)
+}
+
+func (c *random[K]) evict(evict func(key K)) {
+	key := c.keyed[c.rand.IntN(len(c.keyed))]
+	c.remove(key)
+	evict(key)
+}
+
+func (c *random[K]) hit(key K) {}
+
+// remove moves the last key into the place of key.
+func (c *random[K]) remove(key K) {
+	i, ok := c.index[key]
+	if !ok {
+		return
+	}
+	last := len(c.keyed) - 1
+	c.keyed[i] = c.keyed[last]
+	c.index[c.keyed[i]] = i
+	var zero K
+	c.keyed[last] = zero
+	c.keyed = c.keyed[:last]
+	delete(c.index, key)
+}
+
+func (c *random[K]) resize(size int, evict func(key K)) {
+	c.size = max(size, 0)
+	for len(c.keyed) > c.size {
+		c.evict(evict)
+	}
+}
+
+// keys returns the resident keys in no particular order, as every one is
+// as likely to be the next victim.
+func (c *random[K]) keys() []K {
+	return append([]K(nil), c.keyed...)
+}
@@ -0,0 +1,27 @@
+package slru
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRandom(t *testing.T) {
+	testPolicy(t, NewRandom[int, int])
+}
+
+func TestRandomEvictsUniformly(t *testing.T) {
+	counts := make(map[int]int)
+	for i := 0; i < 4000; i++ {
+		c := newRandom[int](4, rand.New(rand.NewPCG(uint64(i), 0)))
+		for key := 0; key < 4; key++ {
+			c.add(key, nil)
+		}
+		c.add(4, func(key int) { counts[key]++ })
+	}
+	require.Len(t, counts, 4)
+	for _, n := range counts {
+		require.InDelta(t, 1000, n, 150)
+	}
+}
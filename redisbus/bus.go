@@ -0,0 +1,368 @@
+// Package redisbus implements slru.InvalidationBus over Redis pub/sub, so
+// the local caches of a fleet of processes stay coherent:
+//
+//	bus := redisbus.New("redis:6379")
+//	defer bus.Close()
+//	cache := slru.New(4096, slru.WithInvalidationBus[string, []byte](bus, redisbus.KeyCodec{}))
+//
+// With WithTracking, it also relays the invalidation messages of Redis
+// client-side caching, which Redis sends when keys under a tracked prefix
+// are written by any client, whether or not it uses a cache.
+package redisbus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hey-kong/slru"
+	"github.com/hey-kong/slru/internal/resp"
+)
+
+// DefaultChannel is the channel invalidations are published to.
+const DefaultChannel = "slru:invalidate"
+
+// trackingChannel is the channel Redis redirects tracking invalidations to.
+const trackingChannel = "__redis__:invalidate"
+
+// TrackingOrigin is the Origin of the invalidations relayed from Redis
+// client-side caching.
+const TrackingOrigin = "redis"
+
+// ErrClosed is returned by Publish and Subscribe after Close.
+var ErrClosed = errors.New("redisbus: closed")
+
+// Bus is an slru.InvalidationBus over Redis pub/sub. It publishes on one
+// connection, dialed on first use and again after an error, and subscribes
+// with a connection per subscriber, which is redialed after it fails.
+type Bus struct {
+	addr       string
+	channel    string
+	dial       func(ctx context.Context, network, addr string) (net.Conn, error)
+	retryDelay time.Duration
+	tracking   bool
+	prefixes   []string
+
+	lock   sync.Mutex
+	conn   net.Conn
+	r      *resp.Reader
+	w      *resp.Writer
+	closed bool
+	subs   map[*subscription]struct{}
+}
+
+// Option configures a Bus.
+type Option func(*Bus)
+
+// WithChannel publishes and subscribes on channel instead of
+// DefaultChannel.
+func WithChannel(channel string) Option {
+	return func(b *Bus) {
+		b.channel = channel
+	}
+}
+
+// WithDialer dials Redis with dial instead of a net.Dialer.
+func WithDialer(dial func(ctx context.Context, network, addr string) (net.Conn, error)) Option {
+	return func(b *Bus) {
+		b.dial = dial
+	}
+}
+
+// WithRetryDelay waits delay before redialing a failed subscription,
+// instead of a second.
+func WithRetryDelay(delay time.Duration) Option {
+	return func(b *Bus) {
+		b.retryDelay = delay
+	}
+}
+
+// WithTracking enables Redis client-side caching in broadcasting mode for
+// each subscriber, which then also receives the Redis keys written under
+// prefixes, or all keys if there are none, as invalidations from
+// TrackingOrigin. The caches must encode their keys as the Redis keys,
+// e.g. with KeyCodec.
+func WithTracking(prefixes ...string) Option {
+	return func(b *Bus) {
+		b.tracking = true
+		b.prefixes = prefixes
+	}
+}
+
+// New returns a Bus for the Redis server at addr. It doesn't connect until
+// used.
+func New(addr string, opts ...Option) *Bus {
+	var d net.Dialer
+	b := &Bus{
+		addr:       addr,
+		channel:    DefaultChannel,
+		dial:       d.DialContext,
+		retryDelay: time.Second,
+		subs:       make(map[*subscription]struct{}),
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// Publish sends msg to every subscriber of the channel.
+func (b *Bus) Publish(ctx context.Context, msg slru.Invalidation) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.closed {
+		return ErrClosed
+	}
+	if b.conn == nil {
+		conn, err := b.dial(ctx, "tcp", b.addr)
+		if err != nil {
+			return err
+		}
+		b.conn, b.r, b.w = conn, resp.NewReader(conn), resp.NewWriter(conn)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		b.conn.SetDeadline(deadline)
+		defer b.conn.SetDeadline(time.Time{})
+	}
+	if _, err := command(b.r, b.w, "PUBLISH", b.channel, string(payload)); err != nil {
+		b.conn.Close()
+		b.conn = nil
+		return err
+	}
+	return nil
+}
+
+// Subscribe calls fn with the messages published to the channel, from a
+// goroutine of its own, until unsubscribe is called. After a subscription
+// fails and is redialed, fn is called with an invalidation of everything,
+// as messages may have been missed in between.
+func (b *Bus) Subscribe(fn func(msg slru.Invalidation)) (unsubscribe func(), err error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.closed {
+		return nil, ErrClosed
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	sub := &subscription{bus: b, fn: fn, cancel: cancel, done: make(chan struct{})}
+	b.subs[sub] = struct{}{}
+	go sub.run(ctx)
+	return func() {
+		b.lock.Lock()
+		delete(b.subs, sub)
+		b.lock.Unlock()
+		sub.stop()
+	}, nil
+}
+
+// Close closes the publishing connection and ends every subscription.
+func (b *Bus) Close() error {
+	b.lock.Lock()
+	b.closed = true
+	conn := b.conn
+	b.conn = nil
+	subs := b.subs
+	b.subs = nil
+	b.lock.Unlock()
+
+	for sub := range subs {
+		sub.stop()
+	}
+	if conn != nil {
+		return conn.Close()
+	}
+	return nil
+}
+
+// subscription is a subscriber of a Bus.
+type subscription struct {
+	bus    *Bus
+	fn     func(msg slru.Invalidation)
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	lock  sync.Mutex
+	conns []net.Conn
+}
+
+func (s *subscription) stop() {
+	s.cancel()
+	s.lock.Lock()
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+	s.lock.Unlock()
+	<-s.done
+}
+
+// run subscribes until ctx is done, redialing after failures.
+func (s *subscription) run(ctx context.Context) {
+	defer close(s.done)
+
+	for connected := false; ; {
+		s.listen(ctx, func() {
+			if connected {
+				s.fn(slru.Invalidation{All: true})
+			}
+			connected = true
+		})
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(s.bus.retryDelay):
+		}
+	}
+}
+
+// listen subscribes on a new connection, calls ready once subscribed, then
+// delivers messages until the connection fails.
+func (s *subscription) listen(ctx context.Context, ready func()) error {
+	b := s.bus
+	conn, err := s.open(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.closeAll()
+	r, w := resp.NewReader(conn), resp.NewWriter(conn)
+
+	channels := []string{b.channel}
+	if b.tracking {
+		id, err := command(r, w, "CLIENT", "ID")
+		if err != nil {
+			return err
+		}
+		if err := s.track(ctx, id.Int); err != nil {
+			return err
+		}
+		channels = append(channels, trackingChannel)
+	}
+	if err := w.WriteCommand(append([]string{"SUBSCRIBE"}, channels...)...); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	for range channels {
+		if _, err := r.ReadValue(); err != nil {
+			return err
+		}
+	}
+	ready()
+
+	for {
+		v, err := r.ReadValue()
+		if err != nil {
+			return err
+		}
+		if msg, ok := s.parse(v); ok {
+			s.fn(msg)
+		}
+	}
+}
+
+// track enables broadcast tracking redirected to the client id on another
+// connection, which has to stay open for tracking to go on.
+func (s *subscription) track(ctx context.Context, id int64) error {
+	conn, err := s.open(ctx)
+	if err != nil {
+		return err
+	}
+	args := []string{"CLIENT", "TRACKING", "ON", "REDIRECT", strconv.FormatInt(id, 10), "BCAST"}
+	for _, prefix := range s.bus.prefixes {
+		args = append(args, "PREFIX", prefix)
+	}
+	_, err = command(resp.NewReader(conn), resp.NewWriter(conn), args...)
+	return err
+}
+
+// open dials a connection that stop closes.
+func (s *subscription) open(ctx context.Context) (net.Conn, error) {
+	conn, err := s.bus.dial(ctx, "tcp", s.bus.addr)
+	if err != nil {
+		return nil, err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if ctx.Err() != nil {
+		conn.Close()
+		return nil, ctx.Err()
+	}
+	s.conns = append(s.conns, conn)
+	return conn, nil
+}
+
+func (s *subscription) closeAll() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+	s.conns = nil
+}
+
+// parse converts a pushed message into an invalidation.
+func (s *subscription) parse(v resp.Value) (msg slru.Invalidation, ok bool) {
+	if v.Type != resp.Array || len(v.Array) != 3 || string(v.Array[0].Str) != "message" {
+		return msg, false
+	}
+	switch channel, payload := string(v.Array[1].Str), v.Array[2]; {
+	case channel == trackingChannel:
+		msg.Origin = TrackingOrigin
+		if payload.Null {
+			// the tracking table or the database was flushed
+			msg.All = true
+			return msg, true
+		}
+		for _, key := range payload.Array {
+			msg.Keys = append(msg.Keys, string(key.Str))
+		}
+		return msg, true
+	case channel == s.bus.channel:
+		return msg, json.Unmarshal(payload.Str, &msg) == nil
+	}
+	return msg, false
+}
+
+// command sends a command and reads its reply, turning error replies into
+// errors.
+func command(r *resp.Reader, w *resp.Writer, args ...string) (resp.Value, error) {
+	if err := w.WriteCommand(args...); err != nil {
+		return resp.Value{}, err
+	}
+	if err := w.Flush(); err != nil {
+		return resp.Value{}, err
+	}
+	v, err := r.ReadValue()
+	if err != nil {
+		return resp.Value{}, err
+	}
+	if v.Type == resp.Error {
+		return v, fmt.Errorf("redisbus: %s", v.Str)
+	}
+	return v, nil
+}
+
+// KeyCodec encodes string keys as themselves, so they match the Redis keys
+// of tracking invalidations.
+type KeyCodec struct{}
+
+func (KeyCodec) Encode(key string) ([]byte, error) {
+	return []byte(key), nil
+}
+
+func (KeyCodec) Decode(data []byte) (string, error) {
+	return string(data), nil
+}
@@ -0,0 +1,229 @@
+package redisbus
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hey-kong/slru"
+	"github.com/hey-kong/slru/internal/resp"
+)
+
+// fakeRedis serves the pub/sub and tracking commands used by a Bus.
+type fakeRedis struct {
+	t  *testing.T
+	ln net.Listener
+
+	lock     sync.Mutex
+	nextID   int64
+	conns    map[int64]*fakeConn
+	redirect []int64
+}
+
+type fakeConn struct {
+	conn     net.Conn
+	w        *resp.Writer
+	channels map[string]bool
+}
+
+func newFakeRedis(t *testing.T) *fakeRedis {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	f := &fakeRedis{t: t, ln: ln, conns: make(map[int64]*fakeConn)}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+	return f
+}
+
+func (f *fakeRedis) addr() string {
+	return f.ln.Addr().String()
+}
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	f.lock.Lock()
+	f.nextID++
+	id := f.nextID
+	c := &fakeConn{conn: conn, w: resp.NewWriter(conn), channels: make(map[string]bool)}
+	f.conns[id] = c
+	f.lock.Unlock()
+	defer func() {
+		f.lock.Lock()
+		delete(f.conns, id)
+		f.lock.Unlock()
+	}()
+
+	r := resp.NewReader(conn)
+	for {
+		args, err := r.ReadCommand()
+		if err != nil {
+			return
+		}
+		f.lock.Lock()
+		switch cmd := strings.ToUpper(string(args[0])); cmd {
+		case "PUBLISH":
+			n := f.push(string(args[1]), func(w *resp.Writer) { w.WriteBulkString(args[2]) })
+			c.w.WriteInteger(int64(n))
+		case "SUBSCRIBE":
+			for i, ch := range args[1:] {
+				c.channels[string(ch)] = true
+				c.w.WriteArrayHeader(3)
+				c.w.WriteBulkString([]byte("subscribe"))
+				c.w.WriteBulkString(ch)
+				c.w.WriteInteger(int64(i + 1))
+			}
+		case "CLIENT":
+			if strings.EqualFold(string(args[1]), "ID") {
+				c.w.WriteInteger(id)
+			} else {
+				target, _ := strconv.ParseInt(string(args[4]), 10, 64)
+				f.redirect = append(f.redirect, target)
+				c.w.WriteSimpleString("OK")
+			}
+		default:
+			c.w.WriteError("ERR unknown command " + cmd)
+		}
+		c.w.Flush()
+		f.lock.Unlock()
+	}
+}
+
+// push sends a message with a payload written by payload to the
+// subscribers of channel, under the lock.
+func (f *fakeRedis) push(channel string, payload func(w *resp.Writer)) int {
+	n := 0
+	for _, c := range f.conns {
+		if !c.channels[channel] {
+			continue
+		}
+		c.w.WriteArrayHeader(3)
+		c.w.WriteBulkString([]byte("message"))
+		c.w.WriteBulkString([]byte(channel))
+		payload(c.w)
+		c.w.Flush()
+		n++
+	}
+	return n
+}
+
+// invalidate sends a tracking invalidation of keys, or a flush if there are
+// none, to the redirected clients.
+func (f *fakeRedis) invalidate(keys ...string) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	for _, id := range f.redirect {
+		if c, ok := f.conns[id]; ok {
+			c.w.WriteArrayHeader(3)
+			c.w.WriteBulkString([]byte("message"))
+			c.w.WriteBulkString([]byte(trackingChannel))
+			if len(keys) == 0 {
+				c.w.WriteArrayHeader(-1)
+			} else {
+				c.w.WriteCommand(keys...)
+			}
+			c.w.Flush()
+		}
+	}
+}
+
+// subscribers returns the number of connections subscribed to channel.
+func (f *fakeRedis) subscribers(channel string) int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	n := 0
+	for _, c := range f.conns {
+		if c.channels[channel] {
+			n++
+		}
+	}
+	return n
+}
+
+// drop closes every connection.
+func (f *fakeRedis) drop() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	for _, c := range f.conns {
+		c.conn.Close()
+	}
+}
+
+func TestBus(t *testing.T) {
+	f := newFakeRedis(t)
+	busA, busB := New(f.addr()), New(f.addr())
+	defer busA.Close()
+	defer busB.Close()
+	a := slru.New(100, slru.WithInvalidationBus[string, int](busA, KeyCodec{}))
+	b := slru.New(100, slru.WithInvalidationBus[string, int](busB, KeyCodec{}))
+	require.Eventually(t, func() bool { return f.subscribers(DefaultChannel) == 2 }, time.Second, time.Millisecond)
+
+	for _, key := range []string{"a", "b", "c"} {
+		a.Set(key, 1)
+		b.Set(key, 1)
+	}
+	require.True(t, a.Remove("a"))
+	require.Eventually(t, func() bool { return !b.Contains("a") }, time.Second, time.Millisecond)
+	require.True(t, a.Contains("b"))
+
+	b.Purge()
+	require.Eventually(t, func() bool { return a.Len() == 0 }, time.Second, time.Millisecond)
+}
+
+func TestBusReconnect(t *testing.T) {
+	f := newFakeRedis(t)
+	bus := New(f.addr(), WithRetryDelay(time.Millisecond))
+	defer bus.Close()
+	msgs := make(chan slru.Invalidation, 10)
+	unsubscribe, err := bus.Subscribe(func(msg slru.Invalidation) { msgs <- msg })
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return f.subscribers(DefaultChannel) == 1 }, time.Second, time.Millisecond)
+
+	f.drop()
+	require.Equal(t, slru.Invalidation{All: true}, <-msgs)
+	require.Eventually(t, func() bool { return f.subscribers(DefaultChannel) == 1 }, time.Second, time.Millisecond)
+
+	ctx := context.Background()
+	require.NoError(t, bus.Publish(ctx, slru.Invalidation{Keys: []string{"k"}}))
+	require.Equal(t, slru.Invalidation{Keys: []string{"k"}}, <-msgs)
+
+	unsubscribe()
+	require.Eventually(t, func() bool { return f.subscribers(DefaultChannel) == 0 }, time.Second, time.Millisecond)
+	require.NoError(t, bus.Close())
+	require.ErrorIs(t, bus.Publish(ctx, slru.Invalidation{}), ErrClosed)
+	_, err = bus.Subscribe(func(slru.Invalidation) {})
+	require.ErrorIs(t, err, ErrClosed)
+}
+
+func TestBusTracking(t *testing.T) {
+	f := newFakeRedis(t)
+	bus := New(f.addr(), WithTracking("user:"))
+	defer bus.Close()
+	cache := slru.New(100, slru.WithInvalidationBus[string, int](bus, KeyCodec{}))
+	require.Eventually(t, func() bool { return f.subscribers(trackingChannel) == 1 }, time.Second, time.Millisecond)
+
+	cache.Set("user:1", 1)
+	cache.Set("user:2", 2)
+	f.invalidate("user:1")
+	require.Eventually(t, func() bool { return !cache.Contains("user:1") }, time.Second, time.Millisecond)
+	require.True(t, cache.Contains("user:2"))
+
+	f.invalidate()
+	require.Eventually(t, func() bool { return cache.Len() == 0 }, time.Second, time.Millisecond)
+}
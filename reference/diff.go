@@ -0,0 +1,224 @@
+package reference
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/hey-kong/slru"
+)
+
+// Op is an operation applied to the caches compared by Diff. Do returns
+// what the operation observed, compared with reflect.DeepEqual.
+type Op[K comparable, V any] struct {
+	Name string
+	Do   func(c slru.Cache[K, V]) any
+	// external runs once for both caches instead of Do.
+	external func()
+}
+
+// External returns the operation running fn once for both caches, for
+// changes outside them such as advancing the clock they share.
+func External[K comparable, V any](name string, fn func()) Op[K, V] {
+	return Op[K, V]{Name: name, external: fn}
+}
+
+func (op Op[K, V]) String() string {
+	return op.Name
+}
+
+// Mismatch is the error Diff returns where two caches first behave
+// differently.
+type Mismatch struct {
+	// Index is the position of the operation in the sequence, and Op its
+	// name.
+	Index int
+	Op    string
+	// What is the observation that differs: the result of the operation,
+	// the keys or the stats after it.
+	What      string
+	Want, Got any
+}
+
+func (m *Mismatch) Error() string {
+	return fmt.Sprintf("reference: op %d %s: %s: want %v, got %v", m.Index, m.Op, m.What, m.Want, m.Got)
+}
+
+// Diff applies ops in order to want and got, typically a reference Cache
+// and the cache under test, and returns a *Mismatch at the first operation
+// whose result, or the keys in order, the length or the hit, miss,
+// eviction and promotion counts after it differ between them.
+func Diff[K comparable, V any](want, got slru.Cache[K, V], ops []Op[K, V]) error {
+	for i, op := range ops {
+		do := op.Do
+		if op.external != nil {
+			op.external()
+			do = func(slru.Cache[K, V]) any { return nil }
+		}
+		observations := []struct {
+			what string
+			fn   func(c slru.Cache[K, V]) any
+		}{
+			{"result", do},
+			{"keys", func(c slru.Cache[K, V]) any { return keys(c) }},
+			{"len", func(c slru.Cache[K, V]) any { return c.Len() }},
+			{"stats", func(c slru.Cache[K, V]) any { return counters(c.Stats()) }},
+		}
+		for _, o := range observations {
+			w, g := o.fn(want), o.fn(got)
+			if !reflect.DeepEqual(w, g) {
+				return &Mismatch{Index: i, Op: op.Name, What: o.what, Want: w, Got: g}
+			}
+		}
+	}
+	return nil
+}
+
+// keys returns the keys of c, nil if none.
+func keys[K comparable, V any](c slru.Cache[K, V]) []K {
+	if keys := c.Keys(); len(keys) > 0 {
+		return keys
+	}
+	return nil
+}
+
+// counters returns the stats the reference keeps.
+func counters(s slru.Stats) slru.Stats {
+	return slru.Stats{
+		Hits:               s.Hits,
+		Misses:             s.Misses,
+		Evictions:          s.Evictions,
+		Promotions:         s.Promotions,
+		ProtectedEvictions: s.ProtectedEvictions,
+		OneHitWonders:      s.OneHitWonders,
+	}
+}
+
+// results bundles the results of an operation returning several.
+func results(values ...any) []any {
+	return values
+}
+
+// Set returns the operation setting key to value.
+func Set[K comparable, V any](key K, value V) Op[K, V] {
+	return Op[K, V]{Name: fmt.Sprintf("Set(%v, %v)", key, value), Do: func(c slru.Cache[K, V]) any {
+		c.Set(key, value)
+		return nil
+	}}
+}
+
+// SetWithTTL returns the operation setting key to value for ttl.
+func SetWithTTL[K comparable, V any](key K, value V, ttl time.Duration) Op[K, V] {
+	return Op[K, V]{Name: fmt.Sprintf("SetWithTTL(%v, %v, %v)", key, value, ttl), Do: func(c slru.Cache[K, V]) any {
+		c.SetWithTTL(key, value, ttl)
+		return nil
+	}}
+}
+
+// Add returns the operation adding key with value.
+func Add[K comparable, V any](key K, value V) Op[K, V] {
+	return Op[K, V]{Name: fmt.Sprintf("Add(%v, %v)", key, value), Do: func(c slru.Cache[K, V]) any {
+		return c.Add(key, value)
+	}}
+}
+
+// Replace returns the operation replacing the value of key.
+func Replace[K comparable, V any](key K, value V) Op[K, V] {
+	return Op[K, V]{Name: fmt.Sprintf("Replace(%v, %v)", key, value), Do: func(c slru.Cache[K, V]) any {
+		return c.Replace(key, value)
+	}}
+}
+
+// Swap returns the operation swapping the value of key.
+func Swap[K comparable, V any](key K, value V) Op[K, V] {
+	return Op[K, V]{Name: fmt.Sprintf("Swap(%v, %v)", key, value), Do: func(c slru.Cache[K, V]) any {
+		return results(c.Swap(key, value))
+	}}
+}
+
+// CompareAndSwap returns the operation swapping the value of key from old
+// to new.
+func CompareAndSwap[K comparable, V any](key K, old, new V) Op[K, V] {
+	return Op[K, V]{Name: fmt.Sprintf("CompareAndSwap(%v, %v, %v)", key, old, new), Do: func(c slru.Cache[K, V]) any {
+		return c.CompareAndSwap(key, old, new)
+	}}
+}
+
+// Get returns the operation getting key.
+func Get[K comparable, V any](key K) Op[K, V] {
+	return Op[K, V]{Name: fmt.Sprintf("Get(%v)", key), Do: func(c slru.Cache[K, V]) any {
+		return results(c.Get(key))
+	}}
+}
+
+// Peek returns the operation peeking at key.
+func Peek[K comparable, V any](key K) Op[K, V] {
+	return Op[K, V]{Name: fmt.Sprintf("Peek(%v)", key), Do: func(c slru.Cache[K, V]) any {
+		return results(c.Peek(key))
+	}}
+}
+
+// Contains returns the operation checking for key.
+func Contains[K comparable, V any](key K) Op[K, V] {
+	return Op[K, V]{Name: fmt.Sprintf("Contains(%v)", key), Do: func(c slru.Cache[K, V]) any {
+		return c.Contains(key)
+	}}
+}
+
+// TTL returns the operation reading the time-to-live of key.
+func TTL[K comparable, V any](key K) Op[K, V] {
+	return Op[K, V]{Name: fmt.Sprintf("TTL(%v)", key), Do: func(c slru.Cache[K, V]) any {
+		return results(c.TTL(key))
+	}}
+}
+
+// Remove returns the operation removing key.
+func Remove[K comparable, V any](key K) Op[K, V] {
+	return Op[K, V]{Name: fmt.Sprintf("Remove(%v)", key), Do: func(c slru.Cache[K, V]) any {
+		return c.Remove(key)
+	}}
+}
+
+// PurgeFunc returns the operation removing the entries fn returns true
+// for. The keys visited are part of the result.
+func PurgeFunc[K comparable, V any](name string, fn func(key K, value V) bool) Op[K, V] {
+	return Op[K, V]{Name: fmt.Sprintf("PurgeFunc(%s)", name), Do: func(c slru.Cache[K, V]) any {
+		var visited []K
+		removed := c.PurgeFunc(func(key K, value V) bool {
+			visited = append(visited, key)
+			return fn(key, value)
+		})
+		return results(removed, visited)
+	}}
+}
+
+// Purge returns the operation removing every entry.
+func Purge[K comparable, V any]() Op[K, V] {
+	return Op[K, V]{Name: "Purge()", Do: func(c slru.Cache[K, V]) any {
+		c.Purge()
+		return nil
+	}}
+}
+
+// Resize returns the operation resizing the cache to size.
+func Resize[K comparable, V any](size int) Op[K, V] {
+	return Op[K, V]{Name: fmt.Sprintf("Resize(%d)", size), Do: func(c slru.Cache[K, V]) any {
+		return c.Resize(size)
+	}}
+}
+
+// NewGeneration returns the operation starting a generation.
+func NewGeneration[K comparable, V any]() Op[K, V] {
+	return Op[K, V]{Name: "NewGeneration()", Do: func(c slru.Cache[K, V]) any {
+		return c.NewGeneration()
+	}}
+}
+
+// InvalidateBefore returns the operation invalidating the generations
+// before gen.
+func InvalidateBefore[K comparable, V any](gen uint64) Op[K, V] {
+	return Op[K, V]{Name: fmt.Sprintf("InvalidateBefore(%d)", gen), Do: func(c slru.Cache[K, V]) any {
+		c.InvalidateBefore(gen)
+		return nil
+	}}
+}
@@ -0,0 +1,418 @@
+// Package reference is a deliberately simple model of slru.SLRU for
+// differential testing: a map of entries and two slices ordering the
+// segments, every operation a linear scan, one lock around everything. It
+// implements slru.Cache with the observable behavior of an SLRU created by
+// slru.New(size) with no other options than a clock, so the changes of a
+// redesign can be checked against it with Diff:
+//
+//	err := reference.Diff(reference.New[int, int](100, nil), slru.New[int, int](100), ops)
+//
+// It is far too slow for anything but tests.
+package reference
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/hey-kong/slru"
+)
+
+// item is a cached value.
+type item[V any] struct {
+	value    V
+	expireAt time.Time
+	gen      uint64
+	hits     uint64
+}
+
+// Cache is the reference SLRU. It is safe for concurrent use.
+type Cache[K comparable, V any] struct {
+	lock  sync.Mutex
+	now   func() time.Time
+	items map[K]*item[V]
+	// probation and protected hold the keys of each segment from the next
+	// victim to the most recently used.
+	probation, protected         []K
+	probationSize, protectedSize int
+	gen, minGen                  uint64
+	stats                        slru.Stats
+	keyLocks                     map[K]*sync.Mutex
+}
+
+var _ slru.Cache[int, int] = (*Cache[int, int])(nil)
+
+// New returns a reference cache of size entries, reading the time from now,
+// or time.Now if nil.
+func New[K comparable, V any](size int, now func() time.Time) *Cache[K, V] {
+	c := &Cache[K, V]{now: now, items: make(map[K]*item[V]), keyLocks: make(map[K]*sync.Mutex)}
+	if c.now == nil {
+		c.now = time.Now
+	}
+	c.setSize(size)
+	return c
+}
+
+// setSize splits size between the segments as the SLRU does.
+func (c *Cache[K, V]) setSize(size int) {
+	c.probationSize = int(slru.DefaultProbationRatio * float64(size))
+	if c.probationSize < 1 && size > 0 {
+		c.probationSize = 1
+	}
+	c.protectedSize = size - c.probationSize
+}
+
+// dead reports whether it has expired or was invalidated by generation.
+func (c *Cache[K, V]) dead(it *item[V]) bool {
+	return it.gen < c.minGen || (!it.expireAt.IsZero() && !c.now().Before(it.expireAt))
+}
+
+// live returns the item of key if it is present and not dead.
+func (c *Cache[K, V]) live(key K) (*item[V], bool) {
+	it, ok := c.items[key]
+	if !ok || c.dead(it) {
+		return nil, false
+	}
+	return it, true
+}
+
+// segment returns the segment holding key.
+func (c *Cache[K, V]) segment(key K) *[]K {
+	if slices.Contains(c.protected, key) {
+		return &c.protected
+	}
+	return &c.probation
+}
+
+// unlink removes key from its segment.
+func (c *Cache[K, V]) unlink(key K) {
+	l := c.segment(key)
+	*l = slices.DeleteFunc(*l, func(k K) bool { return k == key })
+}
+
+// promote moves a hit key to the most recently used end of protected, or
+// of probation if protected has no room at all.
+func (c *Cache[K, V]) promote(key K) {
+	l := c.segment(key)
+	c.unlink(key)
+	if c.protectedSize < 1 {
+		*l = append(*l, key)
+		return
+	}
+	if l == &c.probation {
+		c.stats.Promotions++
+	}
+	c.protected = append(c.protected, key)
+}
+
+// trim evicts the oldest keys of protected, then of probation, until both
+// fit their sizes, returning how many.
+func (c *Cache[K, V]) trim() (evicted int) {
+	for len(c.protected) > c.protectedSize {
+		key := c.protected[0]
+		c.protected = c.protected[1:]
+		delete(c.items, key)
+		c.stats.Evictions++
+		c.stats.ProtectedEvictions++
+		evicted++
+	}
+	for len(c.probation) > c.probationSize {
+		key := c.probation[0]
+		c.probation = c.probation[1:]
+		if c.items[key].hits == 0 {
+			c.stats.OneHitWonders++
+		}
+		delete(c.items, key)
+		c.stats.Evictions++
+		evicted++
+	}
+	return evicted
+}
+
+// set writes key, expiring it at expireAt unless zero.
+func (c *Cache[K, V]) set(key K, value V, expireAt time.Time) {
+	if it, ok := c.items[key]; ok {
+		it.value, it.expireAt, it.gen = value, expireAt, c.gen
+		c.promote(key)
+		c.trim()
+		return
+	}
+	if c.probationSize < 1 {
+		return
+	}
+	c.items[key] = &item[V]{value: value, expireAt: expireAt, gen: c.gen}
+	c.probation = append(c.probation, key)
+	c.trim()
+}
+
+func (c *Cache[K, V]) get(key K) (value V, ok bool) {
+	it, ok := c.items[key]
+	if !ok {
+		c.stats.Misses++
+		return value, false
+	}
+	if c.dead(it) {
+		c.unlink(key)
+		delete(c.items, key)
+		c.stats.Misses++
+		return value, false
+	}
+	c.stats.Hits++
+	it.hits++
+	c.promote(key)
+	c.trim()
+	return it.value, true
+}
+
+func (c *Cache[K, V]) Set(key K, value V) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.set(key, value, time.Time{})
+}
+
+func (c *Cache[K, V]) SetAsync(key K, value V) {
+	c.Set(key, value)
+}
+
+func (c *Cache[K, V]) Flush(ctx context.Context) error {
+	return nil
+}
+
+func (c *Cache[K, V]) Add(key K, value V) (inserted bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	_, exists := c.live(key)
+	c.set(key, value, time.Time{})
+	_, ok := c.items[key]
+	return !exists && ok
+}
+
+func (c *Cache[K, V]) Replace(key K, value V) (replaced bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if _, ok := c.live(key); !ok {
+		return false
+	}
+	c.set(key, value, time.Time{})
+	return true
+}
+
+func (c *Cache[K, V]) Swap(key K, value V) (old V, existed bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if it, ok := c.live(key); ok {
+		old, existed = it.value, true
+	}
+	c.set(key, value, time.Time{})
+	return old, existed
+}
+
+func (c *Cache[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	var expireAt time.Time
+	if ttl > 0 {
+		expireAt = c.now().Add(ttl)
+	}
+	c.set(key, value, expireAt)
+}
+
+func (c *Cache[K, V]) Get(key K) (value V, ok bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.get(key)
+}
+
+func (c *Cache[K, V]) TryGet(key K) (value V, ok, locked bool) {
+	value, ok = c.Get(key)
+	return value, ok, true
+}
+
+func (c *Cache[K, V]) TrySet(key K, value V) (locked bool) {
+	c.Set(key, value)
+	return true
+}
+
+// GetOrLoad is Get, then load and Set on a miss. Concurrent misses each
+// load.
+func (c *Cache[K, V]) GetOrLoad(ctx context.Context, key K, load func(ctx context.Context, key K) (V, error)) (V, error) {
+	if value, ok := c.Get(key); ok {
+		return value, nil
+	}
+	value, err := load(ctx, key)
+	if err != nil {
+		return value, err
+	}
+	c.Set(key, value)
+	return value, nil
+}
+
+// CompareAndSwap compares values with ==.
+func (c *Cache[K, V]) CompareAndSwap(key K, old, new V) (swapped bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	it, ok := c.live(key)
+	if !ok || any(it.value) != any(old) {
+		return false
+	}
+	c.set(key, new, time.Time{})
+	return true
+}
+
+func (c *Cache[K, V]) Update(key K, fn func(old V, exists bool) (new V, store bool)) (value V, ok bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	it, exists := c.live(key)
+	if exists {
+		value = it.value
+	}
+	new, store := fn(value, exists)
+	if !store {
+		return value, exists
+	}
+	c.set(key, new, time.Time{})
+	_, ok = c.items[key]
+	return new, ok
+}
+
+func (c *Cache[K, V]) Contains(key K) (ok bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	_, ok = c.live(key)
+	return ok
+}
+
+func (c *Cache[K, V]) Peek(key K) (value V, ok bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if it, ok := c.live(key); ok {
+		return it.value, true
+	}
+	return value, false
+}
+
+func (c *Cache[K, V]) TTL(key K) (ttl time.Duration, ok bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	it, ok := c.live(key)
+	if !ok {
+		return 0, false
+	}
+	if !it.expireAt.IsZero() {
+		ttl = it.expireAt.Sub(c.now())
+	}
+	return ttl, true
+}
+
+func (c *Cache[K, V]) Remove(key K) (present bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if _, ok := c.items[key]; !ok {
+		return false
+	}
+	c.unlink(key)
+	delete(c.items, key)
+	return true
+}
+
+// LockKey locks a mutex of its own for each key ever locked.
+func (c *Cache[K, V]) LockKey(key K) (unlock func()) {
+	c.lock.Lock()
+	m, ok := c.keyLocks[key]
+	if !ok {
+		m = new(sync.Mutex)
+		c.keyLocks[key] = m
+	}
+	c.lock.Unlock()
+
+	m.Lock()
+	return m.Unlock
+}
+
+func (c *Cache[K, V]) Keys() []K {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return slices.Concat(c.probation, c.protected)
+}
+
+func (c *Cache[K, V]) NewGeneration() (gen uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.gen++
+	return c.gen
+}
+
+func (c *Cache[K, V]) InvalidateBefore(gen uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.minGen = max(c.minGen, gen)
+}
+
+func (c *Cache[K, V]) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return len(c.items)
+}
+
+// Stats returns the counters of hits, misses, evictions and promotions;
+// the other statistics stay zero.
+func (c *Cache[K, V]) Stats() slru.Stats {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.stats
+}
+
+func (c *Cache[K, V]) Purge() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	clear(c.items)
+	c.probation, c.protected = nil, nil
+}
+
+// PurgeFunc visits each segment from its most recently used key, probation
+// first.
+func (c *Cache[K, V]) PurgeFunc(fn func(key K, value V) bool) (removed int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, l := range []*[]K{&c.probation, &c.protected} {
+		for i := len(*l) - 1; i >= 0; i-- {
+			key := (*l)[i]
+			if fn(key, c.items[key].value) {
+				*l = slices.Delete(*l, i, i+1)
+				delete(c.items, key)
+				removed++
+			}
+		}
+	}
+	return removed
+}
+
+func (c *Cache[K, V]) Compact() {}
+
+func (c *Cache[K, V]) Resize(size int) (evicted int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.setSize(size)
+	return c.trim()
+}
@@ -0,0 +1,83 @@
+package reference
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hey-kong/slru"
+	"github.com/hey-kong/slru/fuzz"
+)
+
+func TestDiff(t *testing.T) {
+	now := time.Unix(0, 0)
+	clock := func() time.Time { return now }
+	advance := External[int, int]("Advance(1s)", func() { now = now.Add(time.Second) })
+
+	var ops []Op[int, int]
+	for i := range 30 {
+		ops = append(ops, Set(i, i), Get[int, int](i/2), Peek[int, int](i/3))
+	}
+	ops = append(ops,
+		SetWithTTL(1, 10, 2*time.Second), TTL[int, int](1), advance, TTL[int, int](1), advance, Get[int, int](1),
+		Add(2, 20), Add(2, 21), Replace(3, 30), Swap(4, 40), CompareAndSwap(4, 40, 41), Contains[int, int](4),
+		PurgeFunc("odd", func(key, value int) bool { return key%2 == 1 }),
+		NewGeneration[int, int](), Set(5, 50), InvalidateBefore[int, int](1), Get[int, int](5), Get[int, int](20),
+		Resize[int, int](4), Remove[int, int](29), Purge[int, int](), Set(6, 60),
+	)
+	require.NoError(t, Diff[int, int](New[int, int](10, clock), slru.New(10, slru.WithClock[int, int](clock)), ops))
+}
+
+func TestDiffReportsMismatch(t *testing.T) {
+	// a larger probation makes the caches evict differently
+	err := Diff[int, int](New[int, int](10, nil), slru.New[int, int](15), []Op[int, int]{Set(1, 1), Set(2, 2), Set(3, 3)})
+	var mismatch *Mismatch
+	require.ErrorAs(t, err, &mismatch)
+	require.Equal(t, 2, mismatch.Index)
+	require.Equal(t, "keys", mismatch.What)
+	require.Equal(t, []int{2, 3}, mismatch.Want)
+	require.Equal(t, []int{1, 2, 3}, mismatch.Got)
+}
+
+// ops translates operations of package fuzz.
+func ops(fuzzOps []fuzz.Op, now *time.Time) []Op[int, int] {
+	ops := make([]Op[int, int], 0, len(fuzzOps))
+	for _, op := range fuzzOps {
+		switch op.Kind {
+		case fuzz.Set:
+			ops = append(ops, Set(op.Key, op.Arg))
+		case fuzz.SetWithTTL:
+			ops = append(ops, SetWithTTL(op.Key, op.Arg, time.Duration(op.Arg+1)*time.Second))
+		case fuzz.Add:
+			ops = append(ops, Add(op.Key, op.Arg))
+		case fuzz.Get:
+			ops = append(ops, Get[int, int](op.Key))
+		case fuzz.Peek:
+			ops = append(ops, Peek[int, int](op.Key))
+		case fuzz.Remove:
+			ops = append(ops, Remove[int, int](op.Key))
+		case fuzz.Resize:
+			ops = append(ops, Resize[int, int](op.Arg))
+		case fuzz.Advance:
+			d := time.Duration(op.Arg) * time.Second
+			ops = append(ops, External[int, int](op.String(), func() { *now = now.Add(d) }))
+		case fuzz.Purge:
+			ops = append(ops, Purge[int, int]())
+		}
+	}
+	return ops
+}
+
+func FuzzDiff(f *testing.F) {
+	f.Add(fuzz.Encode(fuzz.Op{Kind: fuzz.Set, Key: 1}, fuzz.Op{Kind: fuzz.Get, Key: 1}, fuzz.Op{Kind: fuzz.Resize, Arg: 1}))
+	f.Add(fuzz.Encode(fuzz.Op{Kind: fuzz.SetWithTTL, Key: 1, Arg: 1}, fuzz.Op{Kind: fuzz.Advance, Arg: 2}, fuzz.Op{Kind: fuzz.Get, Key: 1}))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		now := time.Unix(0, 0)
+		clock := func() time.Time { return now }
+		err := Diff[int, int](New[int, int](8, clock), slru.New(8, slru.WithClock[int, int](clock)), ops(fuzz.Decode(data), &now))
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+}
@@ -0,0 +1,41 @@
+package slru
+
+import (
+	"context"
+	"fmt"
+	"runtime/trace"
+)
+
+// WithTraceRegions annotates the operations that may hold the cache lock
+// for long, such as resizes, janitor sweeps, snapshots, merges and shard
+// resizes, with runtime/trace regions named "slru.<operation>", and logs
+// the eviction bursts of writes in the "slru" category, so they show up
+// in execution traces when looking into latency spikes. Regions include
+// the wait for the lock. They cost nothing while no trace is running.
+func WithTraceRegions[K comparable, V any]() Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		s.regions = true
+	}
+}
+
+// region starts the region of operation name if enabled and a trace is
+// running, returning the function ending it.
+func (s *SLRU[K, V]) region(name string) (end func()) {
+	if !s.regions || !trace.IsEnabled() {
+		return func() {}
+	}
+	return trace.StartRegion(context.Background(), "slru."+name).End
+}
+
+// traceEvictions logs a burst of n evictions to the running trace, if
+// enabled.
+func (s *SLRU[K, V]) traceEvictions(n int) {
+	if n > 1 && s.regions && trace.IsEnabled() {
+		trace.Log(context.Background(), "slru", fmt.Sprintf("evicted %d entries", n))
+	}
+}
+
+// region is SLRU.region for an operation spanning the shards.
+func (c *Sharded[K, V]) region(name string) (end func()) {
+	return c.shards[0].region(name)
+}
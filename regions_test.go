@@ -0,0 +1,30 @@
+package slru
+
+import (
+	"bytes"
+	"runtime/trace"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTraceRegions(t *testing.T) {
+	run := func(opts ...Option[int, int]) []byte {
+		cache := newSLRU(100, opts...)
+		for i := range 100 {
+			cache.Set(i, i)
+		}
+		var buf bytes.Buffer
+		require.NoError(t, trace.Start(&buf))
+		cache.Resize(10)
+		cache.Snapshot()
+		trace.Stop()
+		return buf.Bytes()
+	}
+	require.NotContains(t, string(run()), "slru.resize")
+
+	traced := string(run(WithTraceRegions[int, int]()))
+	require.Contains(t, traced, "slru.resize")
+	require.Contains(t, traced, "slru.snapshot")
+	require.Contains(t, traced, "evicted 18 entries")
+}
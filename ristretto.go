@@ -0,0 +1,70 @@
+package slru
+
+import "errors"
+
+// Ristretto adapts SLRU to the method set of dgraph-io/ristretto, so code
+// written against ristretto.Cache can evaluate SLRU without rewrites.
+type Ristretto[K comparable, V any] struct {
+	s *SLRU[K, V]
+}
+
+// NewRistretto creates a ristretto-compatible cache bounded by maxCost. The
+// cost of an entry is the one passed to Set, or given by the weigher when
+// that is zero; entries cost 1 without a weigher.
+func NewRistretto[K comparable, V any](maxCost int64, opts ...Option[K, V]) (*Ristretto[K, V], error) {
+	if maxCost <= 0 {
+		return nil, errors.New("MaxCost can't be zero")
+	}
+	return &Ristretto[K, V]{s: newSLRU[K, V](int(maxCost), opts...)}, nil
+}
+
+// Get returns the value for the given key, if any.
+func (c *Ristretto[K, V]) Get(key K) (V, bool) {
+	return c.s.Get(key)
+}
+
+// Set stores the value with the given cost, reporting whether it was admitted.
+// Values costing more than the probation segment can hold are rejected.
+func (c *Ristretto[K, V]) Set(key K, value V, cost int64) bool {
+	key = c.s.normalize(key)
+	c.s.acquire()
+	defer c.s.unlock()
+
+	if cost == 0 {
+		c.s.set(key, value)
+	} else {
+		c.s.setWeighted(key, value, int(cost))
+	}
+	_, ok := c.s.items[key]
+	return ok
+}
+
+// Del deletes the value for the given key.
+func (c *Ristretto[K, V]) Del(key K) {
+	c.s.Remove(key)
+}
+
+// Wait blocks until all buffered writes have been applied. SLRU applies
+// writes synchronously, so Wait returns immediately.
+func (c *Ristretto[K, V]) Wait() {}
+
+// Clear empties the cache.
+func (c *Ristretto[K, V]) Clear() {
+	c.s.Purge()
+}
+
+// Close releases the cache. It is safe to call more than once.
+func (c *Ristretto[K, V]) Close() {}
+
+// MaxCost returns the max cost of the cache.
+func (c *Ristretto[K, V]) MaxCost() int64 {
+	c.s.acquireShared()
+	defer c.s.lock.RUnlock()
+
+	return int64(c.s.size)
+}
+
+// UpdateMaxCost updates the maximum cost of the cache.
+func (c *Ristretto[K, V]) UpdateMaxCost(maxCost int64) {
+	c.s.Resize(int(maxCost))
+}
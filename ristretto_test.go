@@ -0,0 +1,36 @@
+package slru
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRistrettoAdapter(t *testing.T) {
+	_, err := NewRistretto[string, int](0)
+	require.Error(t, err)
+
+	cache, err := NewRistretto[string, int](10)
+	require.NoError(t, err)
+
+	require.True(t, cache.Set("a", 1, 1))
+	cache.Wait()
+	v, ok := cache.Get("a")
+	require.True(t, ok)
+	require.Equal(t, 1, v)
+
+	cache.Del("a")
+	_, ok = cache.Get("a")
+	require.False(t, ok)
+
+	cache.UpdateMaxCost(20)
+	require.Equal(t, int64(20), cache.MaxCost())
+
+	// probation holds a cost of 4 out of 20
+	require.False(t, cache.Set("big", 0, 5))
+	require.True(t, cache.Set("b", 2, 4))
+	cache.Clear()
+	_, ok = cache.Get("b")
+	require.False(t, ok)
+	cache.Close()
+}
@@ -0,0 +1,204 @@
+package slru
+
+import (
+	"cmp"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultVirtualNodes is the number of points each node of a Router has on
+// the hash ring.
+const DefaultVirtualNodes = 128
+
+// Router spreads keys over named caches, e.g. local caches or clients of
+// remote ones, by consistent hashing: each node owns the arcs of a hash
+// ring preceding its virtual nodes, so adding or removing a node only moves
+// the keys of the arcs it gains or loses, about 1/n of them.
+type Router[K comparable, V any] struct {
+	lock     sync.RWMutex
+	hash     func(key K) uint64
+	replicas int
+	ring     []routerPoint
+	nodes    map[string]Cache[K, V]
+}
+
+// routerPoint is a virtual node on the ring.
+type routerPoint struct {
+	hash uint64
+	node string
+}
+
+// NewRouter creates a Router placing replicas virtual nodes per node, or
+// DefaultVirtualNodes if replicas is not positive. To route keys the same
+// way in every process, hash must not depend on a per-process seed; if it
+// is nil, keys are hashed with FNV-1a, strings and integers directly and
+// other keys through their fmt representation.
+func NewRouter[K comparable, V any](replicas int, hash func(key K) uint64) *Router[K, V] {
+	if replicas < 1 {
+		replicas = DefaultVirtualNodes
+	}
+	if hash == nil {
+		hash = stableHash[K]
+	}
+	return &Router[K, V]{
+		hash:     hash,
+		replicas: replicas,
+		nodes:    make(map[string]Cache[K, V]),
+	}
+}
+
+// AddNode adds or replaces the node called name.
+func (r *Router[K, V]) AddNode(name string, cache Cache[K, V]) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if _, ok := r.nodes[name]; !ok {
+		for i := 0; i < r.replicas; i++ {
+			r.ring = append(r.ring, routerPoint{hash: pointHash(name, i), node: name})
+		}
+		// ties are settled by name, the same way in every process
+		slices.SortFunc(r.ring, func(a, b routerPoint) int {
+			return cmp.Or(cmp.Compare(a.hash, b.hash), cmp.Compare(a.node, b.node))
+		})
+	}
+	r.nodes[name] = cache
+}
+
+// RemoveNode removes the node called name, reporting whether there was
+// one. Its keys move to the nodes that follow its virtual nodes.
+func (r *Router[K, V]) RemoveNode(name string) (removed bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if _, ok := r.nodes[name]; !ok {
+		return false
+	}
+	delete(r.nodes, name)
+	r.ring = slices.DeleteFunc(r.ring, func(p routerPoint) bool { return p.node == name })
+	return true
+}
+
+// Nodes returns the names of the nodes, sorted.
+func (r *Router[K, V]) Nodes() []string {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	names := make([]string, 0, len(r.nodes))
+	for name := range r.nodes {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Node returns the node owning key, or false if there are no nodes.
+func (r *Router[K, V]) Node(key K) (name string, cache Cache[K, V], ok bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	if len(r.ring) == 0 {
+		return "", nil, false
+	}
+	h := r.hash(key)
+	i, _ := slices.BinarySearchFunc(r.ring, h, func(p routerPoint, h uint64) int {
+		return cmp.Compare(p.hash, h)
+	})
+	if i == len(r.ring) {
+		i = 0
+	}
+	name = r.ring[i].node
+	return name, r.nodes[name], true
+}
+
+func (r *Router[K, V]) Set(key K, value V) {
+	if _, c, ok := r.Node(key); ok {
+		c.Set(key, value)
+	}
+}
+
+func (r *Router[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	if _, c, ok := r.Node(key); ok {
+		c.SetWithTTL(key, value, ttl)
+	}
+}
+
+func (r *Router[K, V]) Get(key K) (value V, ok bool) {
+	if _, c, ok := r.Node(key); ok {
+		return c.Get(key)
+	}
+	return value, false
+}
+
+func (r *Router[K, V]) Peek(key K) (value V, ok bool) {
+	if _, c, ok := r.Node(key); ok {
+		return c.Peek(key)
+	}
+	return value, false
+}
+
+func (r *Router[K, V]) Contains(key K) (ok bool) {
+	if _, c, ok := r.Node(key); ok {
+		return c.Contains(key)
+	}
+	return false
+}
+
+func (r *Router[K, V]) Remove(key K) (present bool) {
+	if _, c, ok := r.Node(key); ok {
+		return c.Remove(key)
+	}
+	return false
+}
+
+// pointHash places virtual node i of a node on the ring.
+func pointHash(name string, i int) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	h.Write([]byte{'#'})
+	h.Write(strconv.AppendInt(nil, int64(i), 10))
+	return splitmix(h.Sum64())
+}
+
+// stableHash hashes keys the same way in every process.
+func stableHash[K comparable](key K) uint64 {
+	switch k := any(key).(type) {
+	case string:
+		h := fnv.New64a()
+		h.Write([]byte(k))
+		return splitmix(h.Sum64())
+	case int:
+		return splitmix(uint64(k))
+	case int32:
+		return splitmix(uint64(k))
+	case int64:
+		return splitmix(uint64(k))
+	case uint:
+		return splitmix(uint64(k))
+	case uint32:
+		return splitmix(uint64(k))
+	case uint64:
+		return splitmix(k)
+	case float64:
+		if k == 0 {
+			k = 0
+		}
+		return splitmix(math.Float64bits(k))
+	default:
+		return stableHash(fmt.Sprintf("%#v", key))
+	}
+}
+
+// splitmix is the finalizer of SplitMix64, spreading the bits of n.
+func splitmix(n uint64) uint64 {
+	n ^= n >> 30
+	n *= 0xbf58476d1ce4e5b9
+	n ^= n >> 27
+	n *= 0x94d049bb133111eb
+	n ^= n >> 31
+	return n
+}
@@ -0,0 +1,78 @@
+package slru
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouter(t *testing.T) {
+	r := NewRouter[string, int](0, nil)
+	r.Set("a", 1)
+	_, ok := r.Get("a")
+	require.False(t, ok)
+	_, _, ok = r.Node("a")
+	require.False(t, ok)
+
+	for i := 0; i < 4; i++ {
+		r.AddNode(fmt.Sprintf("node%d", i), New[string, int](10000))
+	}
+	require.Equal(t, []string{"node0", "node1", "node2", "node3"}, r.Nodes())
+
+	owners := make(map[string]string)
+	counts := make(map[string]int)
+	for i := 0; i < 2000; i++ {
+		key := fmt.Sprint(i)
+		r.Set(key, i)
+		name, c, ok := r.Node(key)
+		require.True(t, ok)
+		require.True(t, c.Contains(key))
+		owners[key] = name
+		counts[name]++
+	}
+	for _, n := range counts {
+		require.InDelta(t, 500, n, 150)
+	}
+	value, ok := r.Get("7")
+	require.True(t, ok)
+	require.Equal(t, 7, value)
+	require.True(t, r.Remove("7"))
+	require.False(t, r.Contains("7"))
+
+	// adding a node only moves keys to it
+	r.AddNode("node4", New[string, int](10000))
+	moved := 0
+	for key, owner := range owners {
+		if name, _, _ := r.Node(key); name != owner {
+			require.Equal(t, "node4", name)
+			moved++
+		}
+	}
+	require.InDelta(t, 400, moved, 150)
+
+	// removing it moves them back
+	require.True(t, r.RemoveNode("node4"))
+	require.False(t, r.RemoveNode("node4"))
+	for key, owner := range owners {
+		name, _, _ := r.Node(key)
+		require.Equal(t, owner, name)
+	}
+}
+
+func TestRouterIsStable(t *testing.T) {
+	a, b := NewRouter[int, int](16, nil), NewRouter[int, int](16, nil)
+	for _, name := range []string{"x", "y", "z"} {
+		a.AddNode(name, New[int, int](10))
+	}
+	for _, name := range []string{"z", "y", "x"} {
+		b.AddNode(name, New[int, int](10))
+	}
+	for key := 0; key < 100; key++ {
+		na, _, _ := a.Node(key)
+		nb, _, _ := b.Node(key)
+		require.Equal(t, na, nb)
+	}
+	require.Equal(t, stableHash("k"), stableHash("k"))
+	require.Equal(t, uint64(0xa759ea27d4727622), stableHash(42))
+}
@@ -0,0 +1,42 @@
+package slru
+
+import "github.com/hey-kong/slru/list"
+
+// WithScanBypass detects scans, such as backups or table scans, as runs of
+// more than threshold insertions of uncached keys with no hit in between,
+// and keeps the keys inserted during a scan in a bypass buffer of up to
+// buffer entries instead of probation, so they don't flush the working
+// set. Bypassed entries are evicted in insertion order unless hit, which
+// promotes them like probation entries and ends the scan. The buffer is
+// held in addition to the size of the cache.
+func WithScanBypass[K comparable, V any](threshold, buffer int) Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		if threshold < 1 || buffer < 1 {
+			return
+		}
+		s.scanThreshold = threshold
+		s.bypassSize = buffer
+		s.bypass = list.New()
+	}
+}
+
+// scanning counts an insertion of an uncached key and reports whether it
+// belongs to a scan.
+func (s *SLRU[K, V]) scanning() bool {
+	return s.bypass != nil && s.scanRun.Add(1) > int64(s.scanThreshold)
+}
+
+// reused ends any scan on a hit or an update.
+func (s *SLRU[K, V]) reused() {
+	if s.bypass != nil && s.scanRun.Load() != 0 {
+		s.scanRun.Store(0)
+	}
+}
+
+// segments returns the lists holding entries, the bypass buffer first.
+func (s *SLRU[K, V]) segments() []*list.List {
+	if s.bypass != nil {
+		return []*list.List{s.bypass, s.probation, s.protected}
+	}
+	return []*list.List{s.probation, s.protected}
+}
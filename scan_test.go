@@ -0,0 +1,53 @@
+package slru
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanBypass(t *testing.T) {
+	for _, bypass := range []bool{false, true} {
+		var opts []Option[int, int]
+		if bypass {
+			opts = append(opts, WithScanBypass[int, int](8, 4))
+		}
+		cache := newSLRU[int, int](100, opts...)
+		cache.Set(-1, -1)
+		cache.Get(-1)
+		for i := 0; i < 10; i++ {
+			cache.Set(i, i)
+			cache.Get(-1)
+		}
+		for i := 1000; i < 1500; i++ {
+			cache.Set(i, i)
+		}
+		require.NoError(t, cache.Verify())
+		if !bypass {
+			require.False(t, cache.Contains(0))
+			continue
+		}
+
+		// the scan keys after the first 8 only churned the buffer
+		for i := 0; i < 10; i++ {
+			require.True(t, cache.Contains(i))
+		}
+		require.Equal(t, []int{1499, 1498, 1497, 1496}, debugKeys(cache.DebugState().Bypass))
+		require.Equal(t, uint64(488), cache.Stats().Evictions)
+		require.Equal(t, 23, cache.Len())
+		require.Equal(t, []int{1496, 1497, 1498, 1499}, cache.Keys()[:4])
+
+		// a hit promotes a bypassed entry and ends the scan
+		_, ok := cache.Get(1499)
+		require.True(t, ok)
+		cache.Set(2000, 2000)
+		state := cache.DebugState()
+		require.Equal(t, 1499, state.Protected[0].Key)
+		require.Equal(t, 2000, state.Probation[0].Key)
+		require.NoError(t, cache.Verify())
+
+		cache.Purge()
+		require.Zero(t, cache.Len())
+		require.Empty(t, cache.DebugState().Bypass)
+	}
+}
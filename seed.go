@@ -0,0 +1,74 @@
+package slru
+
+import (
+	"math/rand/v2"
+	"sync"
+)
+
+// WithSeed draws the randomness of the cache from a generator seeded with
+// seed instead of a random one: TTL jitter, admission probability, lock
+// wait sampling and the id on buses, and, for NewSharded and WithShards
+// without a hash, the placement of keys on shards. The same operations in
+// the same order then behave identically from run to run, for reproducible
+// tests and simulations. Each shard of a Sharded draws its own sequence,
+// seeded with seed plus its index.
+func WithSeed[K comparable, V any](seed uint64) Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		s.seed = seed
+		s.seeded = true
+	}
+}
+
+// SeededHash returns a key hash seeded with seed, placing keys the same way
+// in every process, unlike MaphashHash. It hashes strings and integers
+// directly and other keys by their Go syntax, which is slower.
+func SeededHash[K comparable](seed uint64) func(key K) uint64 {
+	return func(key K) uint64 {
+		return splitmix(stableHash(key) ^ seed)
+	}
+}
+
+// seededRand is a seeded random source safe for concurrent use.
+type seededRand struct {
+	lock sync.Mutex
+	rand *rand.Rand
+}
+
+func newSeededRand(seed uint64) *seededRand {
+	return &seededRand{rand: rand.New(rand.NewPCG(seed, splitmix(seed)))}
+}
+
+func (r *seededRand) float64() float64 {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.rand.Float64()
+}
+
+func (r *seededRand) uint64() uint64 {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.rand.Uint64()
+}
+
+func (s *SLRU[K, V]) randFloat64() float64 {
+	if s.rand == nil {
+		return rand.Float64()
+	}
+	return s.rand.float64()
+}
+
+func (s *SLRU[K, V]) randUint32() uint32 {
+	if s.rand == nil {
+		return rand.Uint32()
+	}
+	return uint32(s.rand.uint64())
+}
+
+func (s *SLRU[K, V]) randUint64() uint64 {
+	if s.rand == nil {
+		return rand.Uint64()
+	}
+	return s.rand.uint64()
+}
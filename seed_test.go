@@ -0,0 +1,78 @@
+package slru
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeed(t *testing.T) {
+	now := time.Now()
+	run := func(seed uint64) (ttls map[int]time.Duration) {
+		cache := newSLRU[int, int](10000,
+			WithSeed[int, int](seed),
+			WithClock[int, int](func() time.Time { return now }),
+			WithTTLJitter[int, int](0.5),
+			WithAdmissionProbability[int, int](0.5),
+		)
+		for i := range 1000 {
+			cache.SetWithTTL(i, i, time.Hour)
+		}
+		ttls = make(map[int]time.Duration)
+		for _, key := range cache.Keys() {
+			ttls[key], _ = cache.TTL(key)
+		}
+		return ttls
+	}
+	ttls := run(1)
+	require.InDelta(t, 500, len(ttls), 100)
+	require.Equal(t, ttls, run(1))
+	require.NotEqual(t, ttls, run(2))
+}
+
+func TestSeedSharded(t *testing.T) {
+	a := NewSharded[string, int](1000, 8, nil, WithSeed[string, int](1))
+	b := NewSharded[string, int](1000, 8, nil, WithSeed[string, int](1))
+	c := NewSharded[string, int](1000, 8, nil, WithSeed[string, int](2))
+	differ := false
+	for _, key := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		require.Equal(t, a.ShardOf(key), b.ShardOf(key))
+		differ = differ || a.ShardOf(key) != c.ShardOf(key)
+	}
+	require.True(t, differ)
+}
+
+func TestSeedConfig(t *testing.T) {
+	cache, err := Build[int, int](Config{Size: 100, Seed: 7})
+	require.NoError(t, err)
+	require.Equal(t, uint64(7), cache.(*SLRU[int, int]).Config().Seed)
+}
+
+func TestPolicySeed(t *testing.T) {
+	run := func(seed uint64) []int {
+		cache := NewRandom[int, int](10, WithPolicySeed[int, int](seed))
+		for i := range 100 {
+			cache.Set(i, i)
+		}
+		keys := cache.Keys()
+		slices.Sort(keys)
+		return keys
+	}
+	require.Equal(t, run(1), run(1))
+	require.NotEqual(t, run(1), run(2))
+}
+
+func TestSeedOptionIsPure(t *testing.T) {
+	opt := WithSeed[int, uint64](3)
+	a, b := newSLRU[int, uint64](10, opt), newSLRU[int, uint64](10, opt)
+	require.Equal(t, a.randUint64(), b.randUint64())
+
+	// shards draw their own sequences, whatever applied the option before
+	c := NewSharded[int, uint64](16, 2, nil, opt)
+	d := NewSharded[int, uint64](16, 2, nil, opt)
+	require.Equal(t, c.shards[1].randUint64(), d.shards[1].randUint64())
+	require.NotEqual(t, c.shards[0].randUint64(), c.shards[1].randUint64())
+	require.Equal(t, newSeededRand(4).uint64(), NewSharded[int, uint64](16, 2, nil, opt).shards[1].randUint64())
+}
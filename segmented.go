@@ -0,0 +1,153 @@
+package slru
+
+import "github.com/hey-kong/slru/list"
+
+// NewSegmented creates an SLRU generalized to len(sizes) segments, holding
+// as many entries as the sizes add up to. New entries enter segment 0, a
+// hit in segment i promotes the entry to segment i+1, or to the head of the
+// last segment, and a segment over its size demotes its tail to the head of
+// the one below, so that only segment 0 evicts. Segment 0 also holds the
+// room the others leave unused. Three segments give, e.g., new, warm and
+// hot tiers.
+func NewSegmented[K comparable, V any](sizes []int, opts ...PolicyOption[K, V]) *Policy[K, V] {
+	return newPolicy[K, V](newSegmented[K](sizes), opts...)
+}
+
+// segmentedNode is a key and the segment it is in.
+type segmentedNode[K comparable] struct {
+	key     K
+	segment int
+}
+
+// segmented is the N-segment SLRU replacer. ratios keep the share of each
+// segment for resizing.
+type segmented[K comparable] struct {
+	sizes    []int
+	ratios   []float64
+	segments []*list.List
+	keyed    map[K]*list.Element
+}
+
+func newSegmented[K comparable](sizes []int) *segmented[K] {
+	s := &segmented[K]{
+		sizes:  make([]int, max(len(sizes), 1)),
+		ratios: make([]float64, max(len(sizes), 1)),
+	}
+	total := 0
+	for i, size := range sizes {
+		s.sizes[i] = max(size, 0)
+		total += s.sizes[i]
+	}
+	for i, size := range s.sizes {
+		if total > 0 {
+			s.ratios[i] = float64(size) / float64(total)
+		}
+	}
+	s.reset()
+	return s
+}
+
+func (s *segmented[K]) reset() {
+	s.segments = make([]*list.List, len(s.sizes))
+	for i := range s.segments {
+		s.segments[i] = list.New()
+	}
+	s.keyed = make(map[K]*list.Element)
+}
+
+func (s *segmented[K]) add(key K, evict func(key K)) {
+	if s.limit() < 1 {
+		evict(key)
+		return
+	}
+	for s.segments[0].Len() >= s.limit() {
+		s.evict(evict)
+	}
+	s.keyed[key] = s.segments[0].PushFront(&segmentedNode[K]{key: key})
+}
+
+func (s *segmented[K]) hit(key K) {
+	e, ok := s.keyed[key]
+	if !ok {
+		return
+	}
+	n := e.Value.(*segmentedNode[K])
+	to := n.segment + 1
+	for to < len(s.segments) && s.sizes[to] < 1 {
+		to++
+	}
+	if to >= len(s.segments) {
+		s.segments[n.segment].MoveToFront(e)
+		return
+	}
+	s.segments[n.segment].Remove(e)
+	n.segment = to
+	s.keyed[key] = s.segments[to].PushFront(n)
+	s.balance(to, nil)
+}
+
+// balance demotes the overflow of segments from top down to segment 0,
+// evicting its overflow if evict is set.
+func (s *segmented[K]) balance(top int, evict func(key K)) {
+	for i := top; i > 0; i-- {
+		for s.segments[i].Len() > s.sizes[i] {
+			e := s.segments[i].Back()
+			n := s.segments[i].Remove(e).(*segmentedNode[K])
+			n.segment = i - 1
+			s.keyed[n.key] = s.segments[i-1].PushFront(n)
+		}
+	}
+	if evict != nil {
+		for s.segments[0].Len() > s.limit() {
+			s.evict(evict)
+		}
+	}
+}
+
+// limit returns the length segment 0 may reach: its size and the room
+// unused by the other segments.
+func (s *segmented[K]) limit() int {
+	limit := s.sizes[0]
+	for i := 1; i < len(s.segments); i++ {
+		limit += max(s.sizes[i]-s.segments[i].Len(), 0)
+	}
+	return limit
+}
+
+func (s *segmented[K]) evict(evict func(key K)) {
+	l := s.segments[0]
+	key := l.Remove(l.Back()).(*segmentedNode[K]).key
+	delete(s.keyed, key)
+	evict(key)
+}
+
+func (s *segmented[K]) remove(key K) {
+	if e, ok := s.keyed[key]; ok {
+		s.segments[e.Value.(*segmentedNode[K]).segment].Remove(e)
+		delete(s.keyed, key)
+	}
+}
+
+// resize splits size between the segments in their original proportions,
+// giving the rounding remainder to the last one.
+func (s *segmented[K]) resize(size int, evict func(key K)) {
+	size = max(size, 0)
+	rest := size
+	for i := range s.sizes {
+		s.sizes[i] = int(s.ratios[i] * float64(size))
+		rest -= s.sizes[i]
+	}
+	s.sizes[len(s.sizes)-1] += rest
+	s.balance(len(s.segments)-1, evict)
+}
+
+// keys returns the keys of each segment from its tail, from segment 0 up.
+func (s *segmented[K]) keys() []K {
+	keys := make([]K, 0, len(s.keyed))
+	for _, l := range s.segments {
+		for e := l.Back(); e != nil; e = e.Prev() {
+			keys = append(keys, e.Value.(*segmentedNode[K]).key)
+		}
+	}
+	return keys
+}
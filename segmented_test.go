@@ -0,0 +1,48 @@
+package slru
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSegmented(t *testing.T) {
+	testPolicy(t, func(size int, opts ...PolicyOption[int, int]) *Policy[int, int] {
+		return NewSegmented([]int{size / 2, size - size/2}, opts...)
+	})
+}
+
+func TestSegmentedTiers(t *testing.T) {
+	s := newSegmented[int]([]int{2, 2, 1})
+	var evicted []int
+	evict := func(key int) { evicted = append(evicted, key) }
+	for i := 0; i < 6; i++ {
+		s.add(i, evict)
+	}
+	require.Equal(t, []int{0}, evicted)
+	require.Equal(t, []int{1, 2, 3, 4, 5}, s.keys())
+
+	s.hit(2)
+	s.hit(2)
+	s.hit(3)
+	require.Equal(t, []int{1, 4, 5, 3, 2}, s.keys())
+	require.Equal(t, 2, s.keyed[2].Value.(*segmentedNode[int]).segment)
+
+	// promoting 3 into the full hot tier demotes 2 to warm
+	s.hit(3)
+	require.Equal(t, []int{1, 4, 5, 2, 3}, s.keys())
+	require.Equal(t, 1, s.keyed[2].Value.(*segmentedNode[int]).segment)
+
+	s.hit(4)
+	s.hit(4)
+	require.Equal(t, []int{1, 5, 2, 3, 4}, s.keys())
+	s.add(6, evict)
+	require.Equal(t, []int{0, 1}, evicted)
+
+	// resizing keeps the proportions and demotes before evicting
+	s.resize(10, evict)
+	require.Equal(t, []int{4, 4, 2}, s.sizes)
+	s.resize(2, evict)
+	require.Equal(t, []int{0, 0, 2}, s.sizes)
+	require.Equal(t, []int{3, 4}, s.keys())
+}
@@ -0,0 +1,96 @@
+package slru
+
+import "time"
+
+// Serialized is a cache storing values encoded by a Codec. The heap holds
+// one flat buffer per entry instead of the value's object graph, which
+// cuts garbage collection work for caches of large structs, and capacity
+// is accounted in encoded bytes. Every Get decodes a fresh copy, so callers
+// can't mutate what other readers see.
+type Serialized[K comparable, V any] struct {
+	cache *SLRU[K, []byte]
+	codec Codec[V]
+}
+
+// NewSerialized creates a Serialized cache holding up to capacity bytes of
+// encoded values. opts configure the underlying cache of encodings; a
+// weigher among them replaces the byte accounting.
+func NewSerialized[K comparable, V any](capacity int, codec Codec[V], opts ...Option[K, []byte]) *Serialized[K, V] {
+	opts = append([]Option[K, []byte]{WithWeigher(func(key K, data []byte) int { return len(data) })}, opts...)
+	return &Serialized[K, V]{
+		cache: newSLRU[K, []byte](capacity, opts...),
+		codec: codec,
+	}
+}
+
+// Set encodes value and sets it for key.
+func (c *Serialized[K, V]) Set(key K, value V) error {
+	data, err := c.codec.Encode(value)
+	if err != nil {
+		return err
+	}
+	c.cache.Set(key, data)
+	return nil
+}
+
+// SetWithTTL encodes value and sets it for key, expiring it after ttl.
+func (c *Serialized[K, V]) SetWithTTL(key K, value V, ttl time.Duration) error {
+	data, err := c.codec.Encode(value)
+	if err != nil {
+		return err
+	}
+	c.cache.SetWithTTL(key, data, ttl)
+	return nil
+}
+
+// Get returns the decoded value of key. An entry that fails to decode is
+// removed and its error returned.
+func (c *Serialized[K, V]) Get(key K) (value V, ok bool, err error) {
+	data, ok := c.cache.Get(key)
+	if !ok {
+		return value, false, nil
+	}
+	return c.decode(key, data)
+}
+
+// Peek is Get without updating the recent-ness.
+func (c *Serialized[K, V]) Peek(key K) (value V, ok bool, err error) {
+	data, ok := c.cache.Peek(key)
+	if !ok {
+		return value, false, nil
+	}
+	return c.decode(key, data)
+}
+
+func (c *Serialized[K, V]) decode(key K, data []byte) (value V, ok bool, err error) {
+	if value, err = c.codec.Decode(data); err != nil {
+		c.cache.Remove(key)
+		return value, false, err
+	}
+	return value, true, nil
+}
+
+// Contains checks if key is in cache without updating the recent-ness.
+func (c *Serialized[K, V]) Contains(key K) bool {
+	return c.cache.Contains(key)
+}
+
+// Remove removes key, reporting whether it was present.
+func (c *Serialized[K, V]) Remove(key K) (present bool) {
+	return c.cache.Remove(key)
+}
+
+// Len returns the number of entries.
+func (c *Serialized[K, V]) Len() int {
+	return c.cache.Len()
+}
+
+// Purge clears all entries.
+func (c *Serialized[K, V]) Purge() {
+	c.cache.Purge()
+}
+
+// Stats returns the statistics of the cache.
+func (c *Serialized[K, V]) Stats() Stats {
+	return c.cache.Stats()
+}
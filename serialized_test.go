@@ -0,0 +1,54 @@
+package slru
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type user struct {
+	Name  string
+	Roles []string
+}
+
+func TestSerialized(t *testing.T) {
+	for _, codec := range []Codec[user]{GobCodec[user]{}, JSONCodec[user]{}} {
+		cache := NewSerialized[string, user](1<<10, codec)
+		require.NoError(t, cache.Set("a", user{Name: "a", Roles: []string{"admin"}}))
+
+		u, ok, err := cache.Get("a")
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, user{Name: "a", Roles: []string{"admin"}}, u)
+
+		// readers get their own copy
+		u.Roles[0] = "guest"
+		u, _, _ = cache.Peek("a")
+		require.Equal(t, "admin", u.Roles[0])
+
+		_, ok, err = cache.Get("b")
+		require.NoError(t, err)
+		require.False(t, ok)
+		require.Equal(t, 1, cache.Len())
+	}
+}
+
+func TestSerializedAccountsBytes(t *testing.T) {
+	// probation holds 20 bytes and each encoding takes 12
+	cache := NewSerialized[string, string](100, JSONCodec[string]{})
+	require.NoError(t, cache.Set("a", "0123456789"))
+	require.NoError(t, cache.Set("b", "0123456789"))
+	require.False(t, cache.Contains("a"))
+	require.True(t, cache.Contains("b"))
+}
+
+func TestSerializedDecodeError(t *testing.T) {
+	cache := NewSerialized[string, int](100, JSONCodec[int]{})
+	cache.cache.Set("a", []byte("nope"))
+	_, ok, err := cache.Get("a")
+	require.Error(t, err)
+	require.False(t, ok)
+	require.False(t, cache.Contains("a"))
+
+	require.Error(t, NewSerialized[string, func()](100, JSONCodec[func()]{}).Set("f", func() {}))
+}
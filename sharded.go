@@ -0,0 +1,254 @@
+package slru
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/maphash"
+	"math"
+	"slices"
+	"sync/atomic"
+	"time"
+)
+
+// Sharded spreads keys over independently locked SLRUs by their hash, so
+// concurrent operations on different shards don't contend. size is the
+// total size given to NewSharded or Resize, before shardSize rounds it.
+type Sharded[K comparable, V any] struct {
+	shards []*SLRU[K, V]
+	hash   func(key K) uint64
+	mask   uint64
+	size   atomic.Int64
+}
+
+// NewSharded creates a sharded cache of the given total size. The number of
+// shards is rounded up to a power of two and each one holds an equal share
+// of size; opts apply to every shard. If hash is nil, keys are hashed with
+// a randomly seeded maphash, or with SeededHash if opts include WithSeed.
+func NewSharded[K comparable, V any](size, shards int, hash func(key K) uint64, opts ...Option[K, V]) *Sharded[K, V] {
+	var probe SLRU[K, V]
+	for _, opt := range opts {
+		opt(&probe)
+	}
+	if hash == nil {
+		if probe.seeded {
+			hash = SeededHash[K](probe.seed)
+		} else {
+			hash = defaultHash[K](maphash.MakeSeed())
+		}
+	}
+	if transform := probe.keyTransform; transform != nil {
+		keyHash := hash
+		hash = func(key K) uint64 { return keyHash(transform(key)) }
+	}
+	n := 1
+	for n < shards {
+		n <<= 1
+	}
+	c := &Sharded[K, V]{
+		shards: make([]*SLRU[K, V], n),
+		hash:   hash,
+		mask:   uint64(n - 1),
+	}
+	for i := range c.shards {
+		c.shards[i] = newSLRU[K, V](shardSize(size, n), append(slices.Clip(opts), inShard[K, V](i, n))...)
+	}
+	c.size.Store(int64(size))
+	return c
+}
+
+// MaphashHash returns the default key hash seeded with seed, to place keys
+// on the same shards across caches sharing the seed.
+func MaphashHash[K comparable](seed maphash.Seed) func(key K) uint64 {
+	return defaultHash[K](seed)
+}
+
+// FieldHash returns a key hash using only the part of the key returned by
+// field, so keys sharing it, e.g. a tenant id in a struct key, land on the
+// same shard.
+func FieldHash[K, F comparable](field func(key K) F) func(key K) uint64 {
+	hash := defaultHash[F](maphash.MakeSeed())
+	return func(key K) uint64 {
+		return hash(field(key))
+	}
+}
+
+// Shards returns the number of shards.
+func (c *Sharded[K, V]) Shards() int {
+	return len(c.shards)
+}
+
+// ShardOf returns the index of the shard holding key.
+func (c *Sharded[K, V]) ShardOf(key K) int {
+	return int(c.hash(key) & c.mask)
+}
+
+// shardSize returns the share of size held by each of n shards.
+// inShard places the cache as shard i of the n shards of a Sharded.
+func inShard[K comparable, V any](i, n int) Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		s.shard, s.shardCount = i, n
+	}
+}
+
+func shardSize(size, n int) int {
+	return (size + n - 1) / n
+}
+
+// defaultHash hashes strings and numbers directly and other keys through
+// their fmt representation.
+func defaultHash[K comparable](seed maphash.Seed) func(key K) uint64 {
+	return func(key K) uint64 {
+		switch k := any(key).(type) {
+		case string:
+			return maphash.String(seed, k)
+		case int:
+			return mix(seed, uint64(k))
+		case int32:
+			return mix(seed, uint64(k))
+		case int64:
+			return mix(seed, uint64(k))
+		case uint:
+			return mix(seed, uint64(k))
+		case uint32:
+			return mix(seed, uint64(k))
+		case uint64:
+			return mix(seed, k)
+		case float64:
+			// +0 and -0 are equal keys and must land on the same shard
+			if k == 0 {
+				k = 0
+			}
+			return mix(seed, math.Float64bits(k))
+		default:
+			return maphash.String(seed, fmt.Sprintf("%#v", key))
+		}
+	}
+}
+
+func mix(seed maphash.Seed, n uint64) uint64 {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], n)
+	return maphash.Bytes(seed, b[:])
+}
+
+func (c *Sharded[K, V]) shard(key K) *SLRU[K, V] {
+	return c.shards[c.hash(key)&c.mask]
+}
+
+func (c *Sharded[K, V]) Set(key K, value V) {
+	c.shard(key).Set(key, value)
+}
+
+func (c *Sharded[K, V]) Add(key K, value V) (inserted bool) {
+	return c.shard(key).Add(key, value)
+}
+
+func (c *Sharded[K, V]) Replace(key K, value V) (replaced bool) {
+	return c.shard(key).Replace(key, value)
+}
+
+func (c *Sharded[K, V]) Swap(key K, value V) (old V, existed bool) {
+	return c.shard(key).Swap(key, value)
+}
+
+func (c *Sharded[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	c.shard(key).SetWithTTL(key, value, ttl)
+}
+
+// Put is SLRU.Put on the shard of key.
+func (c *Sharded[K, V]) Put(key K, value V, ttl time.Duration) (stored bool) {
+	return c.shard(key).Put(key, value, ttl)
+}
+
+// GetStale is SLRU.GetStale on the shard of key.
+func (c *Sharded[K, V]) GetStale(key K) (value V, staleFor time.Duration, ok bool) {
+	return c.shard(key).GetStale(key)
+}
+
+// SetWithExpireAt is SLRU.SetWithExpireAt on the shard of key.
+func (c *Sharded[K, V]) SetWithExpireAt(key K, value V, at time.Time) {
+	c.shard(key).SetWithExpireAt(key, value, at)
+}
+
+func (c *Sharded[K, V]) Get(key K) (value V, ok bool) {
+	return c.shard(key).Get(key)
+}
+
+func (c *Sharded[K, V]) CompareAndSwap(key K, old, new V) (swapped bool) {
+	return c.shard(key).CompareAndSwap(key, old, new)
+}
+
+func (c *Sharded[K, V]) Update(key K, fn func(old V, exists bool) (new V, store bool)) (value V, ok bool) {
+	return c.shard(key).Update(key, fn)
+}
+
+func (c *Sharded[K, V]) Contains(key K) (ok bool) {
+	return c.shard(key).Contains(key)
+}
+
+func (c *Sharded[K, V]) Peek(key K) (value V, ok bool) {
+	return c.shard(key).Peek(key)
+}
+
+func (c *Sharded[K, V]) TTL(key K) (ttl time.Duration, ok bool) {
+	return c.shard(key).TTL(key)
+}
+
+func (c *Sharded[K, V]) Remove(key K) (present bool) {
+	return c.shard(key).Remove(key)
+}
+
+// Keys returns the keys of every shard, one shard after another.
+func (c *Sharded[K, V]) Keys() []K {
+	var keys []K
+	for _, s := range c.shards {
+		keys = append(keys, s.Keys()...)
+	}
+	return keys
+}
+
+func (c *Sharded[K, V]) NewGeneration() (gen uint64) {
+	for _, s := range c.shards {
+		gen = s.NewGeneration()
+	}
+	return gen
+}
+
+func (c *Sharded[K, V]) InvalidateBefore(gen uint64) {
+	for _, s := range c.shards {
+		s.InvalidateBefore(gen)
+	}
+}
+
+func (c *Sharded[K, V]) Len() int {
+	n := 0
+	for _, s := range c.shards {
+		n += s.Len()
+	}
+	return n
+}
+
+// String is SLRU.String summed over the shards.
+func (c *Sharded[K, V]) String() string {
+	var fill segmentFill
+	for _, s := range c.shards {
+		fill = fill.add(s.fill())
+	}
+	stats := c.Stats()
+	return fmt.Sprintf("Sharded(%d shards, %s, hit ratio %.1f%%)", len(c.shards), fill, 100*stats.HitRatio())
+}
+
+func (c *Sharded[K, V]) Purge() {
+	for _, s := range c.shards {
+		s.Purge()
+	}
+}
+
+func (c *Sharded[K, V]) Resize(size int) (evicted int) {
+	defer c.region("sharded.resize")()
+	c.size.Store(int64(max(size, 0)))
+	for _, s := range c.shards {
+		evicted += s.Resize(shardSize(size, len(c.shards)))
+	}
+	return evicted
+}
@@ -0,0 +1,48 @@
+package slru
+
+import (
+	"hash/maphash"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAndSetOnSharded(t *testing.T) {
+	cache := NewSharded[int, int](200, 3, func(key int) uint64 { return uint64(key) })
+	require.Len(t, cache.shards, 4)
+
+	for i := 0; i < 20; i++ {
+		cache.Set(i, i*10)
+	}
+	require.Equal(t, 20, cache.Len())
+	require.Len(t, cache.Keys(), 20)
+	for i := 0; i < 20; i++ {
+		v, ok := cache.Get(i)
+		require.True(t, ok)
+		require.Equal(t, i*10, v)
+	}
+
+	require.True(t, cache.Remove(3))
+	require.False(t, cache.Contains(3))
+	cache.Purge()
+	require.Equal(t, 0, cache.Len())
+}
+
+func TestShardHashes(t *testing.T) {
+	type key struct {
+		tenant string
+		id     int
+	}
+	cache := NewSharded[key, int](100, 16, FieldHash(func(k key) string { return k.tenant }))
+	require.Equal(t, 16, cache.Shards())
+	for i := 0; i < 10; i++ {
+		require.Equal(t, cache.ShardOf(key{"a", 0}), cache.ShardOf(key{"a", i}))
+	}
+
+	seed := maphash.MakeSeed()
+	a := NewSharded[string, int](100, 8, MaphashHash[string](seed))
+	b := NewSharded[string, int](100, 8, MaphashHash[string](seed))
+	for _, k := range []string{"x", "y", "z"} {
+		require.Equal(t, a.ShardOf(k), b.ShardOf(k))
+	}
+}
@@ -0,0 +1,46 @@
+package sim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hey-kong/slru"
+	"github.com/hey-kong/slru/bench"
+)
+
+func TestSimulate(t *testing.T) {
+	keys := bench.Record(bench.Zipf(1, 1.1, 10000), 50000)
+	points := Simulate(keys, Options{Sizes: []int{100, 1000}, ProbationRatios: []float64{0.1, 0.5}})
+	require.Len(t, points, 4)
+	require.Equal(t, Point{Size: 100, ProbationRatio: 0.1, Ops: 50000, Hits: points[0].Hits}, points[0])
+	require.Equal(t, 1000, points[2].Size)
+	require.Greater(t, points[2].HitRatio(), points[0].HitRatio())
+
+	want := bench.Run(slru.New(1000, slru.WithProbationRatio[uint64, uint64](0.5)), bench.Replay(keys), len(keys))
+	require.Equal(t, want.Hits, points[3].Hits)
+}
+
+func TestSimulateSampled(t *testing.T) {
+	keys := bench.Record(bench.Zipf(1, 1.1, 100000), 200000)
+	full := Simulate(keys, Options{Sizes: []int{5000}})
+	for seed := range uint64(5) {
+		sampled := Simulate(keys, Options{Sizes: []int{5000}, SampleRate: 0.1, Seed: seed})
+		require.Equal(t, 20000, sampled[0].Ops)
+		require.InDelta(t, full[0].HitRatio(), sampled[0].HitRatio(), 0.03)
+	}
+}
+
+func TestSmallest(t *testing.T) {
+	points := []Point{
+		{Size: 10, ProbationRatio: 0.2, Ops: 10, Hits: 5},
+		{Size: 20, ProbationRatio: 0.2, Ops: 10, Hits: 7},
+		{Size: 20, ProbationRatio: 0.5, Ops: 10, Hits: 8},
+		{Size: 30, ProbationRatio: 0.2, Ops: 10, Hits: 9},
+	}
+	p, ok := Smallest(points, 0.7)
+	require.True(t, ok)
+	require.Equal(t, points[2], p)
+	_, ok = Smallest(points, 0.95)
+	require.False(t, ok)
+}
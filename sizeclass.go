@@ -0,0 +1,312 @@
+package slru
+
+import (
+	"context"
+	"hash/maphash"
+	"sort"
+	"sync"
+	"time"
+)
+
+// SizeClass is a class of SizeClasses: the values up to MaxSize, held by a
+// cache of size Capacity. A zero MaxSize is unbounded. The class with the
+// largest MaxSize takes every larger value too.
+type SizeClass struct {
+	MaxSize  int
+	Capacity int
+}
+
+// SizeClasses is a cache routing entries to a separate cache per class of
+// value size, each with its own capacity, so a few huge values can't evict
+// thousands of small hot ones:
+//
+//	cache := slru.NewSizeClasses[string, []byte](
+//		func(key string, value []byte) int { return len(value) },
+//		[]slru.SizeClass{{MaxSize: 1 << 10, Capacity: 100000}, {MaxSize: 1 << 20, Capacity: 1000}, {Capacity: 10}})
+//
+// Reads look the key up in each class in turn, from the smallest values,
+// so its misses count in the stats of the last class.
+type SizeClasses[K comparable, V any] struct {
+	size    func(key K, value V) int
+	limits  []int
+	classes []Cache[K, V]
+	// writes serializes the writes to each key, which may move it between
+	// classes.
+	writes keyLocks[K]
+	loads  loadGroup[K, V]
+	// capacities are the sizes of the classes, shared by Resize.
+	resizeLock sync.Mutex
+	capacities []int
+}
+
+// NewSizeClasses returns SizeClasses of classes, whose caches are built
+// with opts, sizing values with size.
+func NewSizeClasses[K comparable, V any](size func(key K, value V) int, classes []SizeClass, opts ...Option[K, V]) *SizeClasses[K, V] {
+	classes = append([]SizeClass(nil), classes...)
+	sort.SliceStable(classes, func(i, j int) bool {
+		a, b := classes[i].MaxSize, classes[j].MaxSize
+		return b == 0 && a != 0 || a != 0 && a < b
+	})
+	c := &SizeClasses[K, V]{size: size, writes: keyLocks[K]{hash: defaultHash[K](maphash.MakeSeed())}}
+	for _, class := range classes {
+		c.limits = append(c.limits, class.MaxSize)
+		c.capacities = append(c.capacities, class.Capacity)
+		c.classes = append(c.classes, New[K, V](class.Capacity, opts...))
+	}
+	return c
+}
+
+// Classes returns the caches of the classes, from the smallest values.
+func (c *SizeClasses[K, V]) Classes() []Cache[K, V] {
+	return c.classes
+}
+
+// class returns the cache of the class of value.
+func (c *SizeClasses[K, V]) class(key K, value V) Cache[K, V] {
+	n := c.size(key, value)
+	for i, limit := range c.limits[:len(c.limits)-1] {
+		if n <= limit {
+			return c.classes[i]
+		}
+	}
+	return c.classes[len(c.classes)-1]
+}
+
+// holder returns the cache holding key, or nil.
+func (c *SizeClasses[K, V]) holder(key K) Cache[K, V] {
+	for _, class := range c.classes {
+		if class.Contains(key) {
+			return class
+		}
+	}
+	return nil
+}
+
+// reader returns the cache to read key from: the one holding it, or the
+// last one to count the miss.
+func (c *SizeClasses[K, V]) reader(key K) Cache[K, V] {
+	if class := c.holder(key); class != nil {
+		return class
+	}
+	return c.classes[len(c.classes)-1]
+}
+
+// place writes value for key with set on its class, removing key from the
+// other classes. The caller holds the write lock of key.
+func (c *SizeClasses[K, V]) place(key K, value V, set func(class Cache[K, V])) {
+	target := c.class(key, value)
+	set(target)
+	for _, class := range c.classes {
+		if class != target {
+			class.Remove(key)
+		}
+	}
+}
+
+func (c *SizeClasses[K, V]) Set(key K, value V) {
+	defer c.writes.lock(key)()
+	c.place(key, value, func(class Cache[K, V]) { class.Set(key, value) })
+}
+
+func (c *SizeClasses[K, V]) SetAsync(key K, value V) {
+	defer c.writes.lock(key)()
+	c.place(key, value, func(class Cache[K, V]) { class.SetAsync(key, value) })
+}
+
+func (c *SizeClasses[K, V]) Flush(ctx context.Context) error {
+	for _, class := range c.classes {
+		if err := class.Flush(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *SizeClasses[K, V]) Add(key K, value V) (inserted bool) {
+	defer c.writes.lock(key)()
+	if c.holder(key) != nil {
+		return false
+	}
+	return c.class(key, value).Add(key, value)
+}
+
+func (c *SizeClasses[K, V]) Replace(key K, value V) (replaced bool) {
+	defer c.writes.lock(key)()
+	if c.holder(key) == nil {
+		return false
+	}
+	c.place(key, value, func(class Cache[K, V]) { class.Set(key, value) })
+	return true
+}
+
+func (c *SizeClasses[K, V]) Swap(key K, value V) (old V, existed bool) {
+	defer c.writes.lock(key)()
+	if holder := c.holder(key); holder != nil {
+		old, existed = holder.Peek(key)
+	}
+	c.place(key, value, func(class Cache[K, V]) { class.Set(key, value) })
+	return old, existed
+}
+
+func (c *SizeClasses[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	defer c.writes.lock(key)()
+	c.place(key, value, func(class Cache[K, V]) { class.SetWithTTL(key, value, ttl) })
+}
+
+func (c *SizeClasses[K, V]) Get(key K) (value V, ok bool) {
+	return c.reader(key).Get(key)
+}
+
+func (c *SizeClasses[K, V]) TryGet(key K) (value V, ok, locked bool) {
+	return c.reader(key).TryGet(key)
+}
+
+func (c *SizeClasses[K, V]) TrySet(key K, value V) (locked bool) {
+	defer c.writes.lock(key)()
+	c.place(key, value, func(class Cache[K, V]) { locked = class.TrySet(key, value) })
+	return locked
+}
+
+func (c *SizeClasses[K, V]) GetOrLoad(ctx context.Context, key K, load func(ctx context.Context, key K) (V, error)) (V, error) {
+	if value, ok := c.Get(key); ok {
+		return value, nil
+	}
+	return c.loads.do(ctx, key, load, c.Set)
+}
+
+func (c *SizeClasses[K, V]) CompareAndSwap(key K, old, new V) (swapped bool) {
+	defer c.writes.lock(key)()
+	holder := c.holder(key)
+	if holder == nil || !holder.CompareAndSwap(key, old, new) {
+		return false
+	}
+	if target := c.class(key, new); target != holder {
+		target.Set(key, new)
+		holder.Remove(key)
+	}
+	return true
+}
+
+// Update calls fn with the current value for the given key, storing the
+// value fn returns in its class if it asks to. Unlike that of an SLRU, fn
+// runs outside the locks of the classes, but still under the write lock of
+// the key.
+func (c *SizeClasses[K, V]) Update(key K, fn func(old V, exists bool) (new V, store bool)) (value V, ok bool) {
+	defer c.writes.lock(key)()
+	var exists bool
+	if holder := c.holder(key); holder != nil {
+		value, exists = holder.Peek(key)
+	}
+	new, store := fn(value, exists)
+	if !store {
+		return value, exists
+	}
+	c.place(key, new, func(class Cache[K, V]) { class.Set(key, new) })
+	// a new entry may not have been admitted
+	return new, c.class(key, new).Contains(key)
+}
+
+func (c *SizeClasses[K, V]) Contains(key K) (ok bool) {
+	return c.holder(key) != nil
+}
+
+func (c *SizeClasses[K, V]) Peek(key K) (value V, ok bool) {
+	return c.reader(key).Peek(key)
+}
+
+func (c *SizeClasses[K, V]) TTL(key K) (ttl time.Duration, ok bool) {
+	return c.reader(key).TTL(key)
+}
+
+func (c *SizeClasses[K, V]) Remove(key K) (present bool) {
+	defer c.writes.lock(key)()
+	for _, class := range c.classes {
+		if class.Remove(key) {
+			present = true
+		}
+	}
+	return present
+}
+
+func (c *SizeClasses[K, V]) LockKey(key K) (unlock func()) {
+	return c.classes[0].LockKey(key)
+}
+
+// Keys returns the keys of each class in turn, from the smallest values.
+func (c *SizeClasses[K, V]) Keys() []K {
+	var keys []K
+	for _, class := range c.classes {
+		keys = append(keys, class.Keys()...)
+	}
+	return keys
+}
+
+func (c *SizeClasses[K, V]) NewGeneration() (gen uint64) {
+	for _, class := range c.classes {
+		gen = class.NewGeneration()
+	}
+	return gen
+}
+
+func (c *SizeClasses[K, V]) InvalidateBefore(gen uint64) {
+	for _, class := range c.classes {
+		class.InvalidateBefore(gen)
+	}
+}
+
+func (c *SizeClasses[K, V]) Len() (n int) {
+	for _, class := range c.classes {
+		n += class.Len()
+	}
+	return n
+}
+
+func (c *SizeClasses[K, V]) Stats() (stats Stats) {
+	for _, class := range c.classes {
+		st := class.Stats()
+		stats.Merge(&st)
+	}
+	return stats
+}
+
+func (c *SizeClasses[K, V]) Purge() {
+	for _, class := range c.classes {
+		class.Purge()
+	}
+}
+
+func (c *SizeClasses[K, V]) PurgeFunc(fn func(key K, value V) bool) (removed int) {
+	for _, class := range c.classes {
+		removed += class.PurgeFunc(fn)
+	}
+	return removed
+}
+
+func (c *SizeClasses[K, V]) Compact() {
+	for _, class := range c.classes {
+		class.Compact()
+	}
+}
+
+// Resize shares size between the classes in proportion to their current
+// capacities.
+func (c *SizeClasses[K, V]) Resize(size int) (evicted int) {
+	c.resizeLock.Lock()
+	defer c.resizeLock.Unlock()
+
+	total := 0
+	for _, n := range c.capacities {
+		total += n
+	}
+	rest := size
+	for i, class := range c.classes {
+		n := rest
+		if i < len(c.classes)-1 && total > 0 {
+			n = size * c.capacities[i] / total
+		}
+		rest -= n
+		c.capacities[i] = n
+		evicted += class.Resize(n)
+	}
+	return evicted
+}
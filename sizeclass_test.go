@@ -0,0 +1,83 @@
+package slru
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSizeClasses() *SizeClasses[string, string] {
+	return NewSizeClasses[string, string](func(key, value string) int { return len(value) },
+		[]SizeClass{{Capacity: 10}, {MaxSize: 4, Capacity: 1000}})
+}
+
+func TestSizeClasses(t *testing.T) {
+	cache := newTestSizeClasses()
+	small, large := cache.Classes()[0], cache.Classes()[1]
+
+	for i := range 50 {
+		cache.Set(string(rune('a'+i)), "v")
+	}
+	// huge values churn their own class only
+	for i := range 20 {
+		cache.Set(strings.Repeat("K", i+1), strings.Repeat("x", 100))
+	}
+	require.Equal(t, 50, small.Len())
+	require.LessOrEqual(t, large.Len(), 10)
+	v, ok := cache.Get("a")
+	require.True(t, ok)
+	require.Equal(t, "v", v)
+
+	// a value changing class moves with it
+	cache.Set("a", strings.Repeat("x", 100))
+	require.False(t, small.Contains("a"))
+	require.True(t, large.Contains("a"))
+	old, existed := cache.Swap("a", "v")
+	require.True(t, existed)
+	require.Len(t, old, 100)
+	require.True(t, small.Contains("a"))
+	require.False(t, large.Contains("a"))
+
+	require.True(t, cache.CompareAndSwap("a", "v", "longer value"))
+	require.True(t, large.Contains("a"))
+	require.Equal(t, cache.Len(), len(cache.Keys()))
+	require.True(t, cache.Remove("a"))
+	require.False(t, cache.Contains("a"))
+}
+
+func TestSizeClassesUpdate(t *testing.T) {
+	cache := newTestSizeClasses()
+	v, ok := cache.Update("k", func(old string, exists bool) (string, bool) {
+		require.False(t, exists)
+		return "v", true
+	})
+	require.True(t, ok)
+	require.Equal(t, "v", v)
+	v, ok = cache.Update("k", func(old string, exists bool) (string, bool) {
+		return old + "alue", true
+	})
+	require.True(t, ok)
+	require.Equal(t, "value", v)
+	require.True(t, cache.Classes()[1].Contains("k"))
+	require.Equal(t, 1, cache.Len())
+
+	v, err := cache.GetOrLoad(context.Background(), "loaded", func(ctx context.Context, key string) (string, error) {
+		return "l", nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, "l", v)
+	require.True(t, cache.Classes()[0].Contains("loaded"))
+}
+
+func TestSizeClassesResize(t *testing.T) {
+	cache := newTestSizeClasses()
+	for i := range 100 {
+		cache.Set(string(rune('a'+i)), "v")
+	}
+	cache.Resize(101)
+	require.LessOrEqual(t, cache.Classes()[0].Len(), 20)
+	stats := cache.Stats()
+	require.NotZero(t, stats.Evictions)
+}
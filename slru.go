@@ -3,7 +3,12 @@ package slru
 // A prototype of SLRU.
 
 import (
+	"context"
+	"fmt"
+	"log/slog"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/hey-kong/slru/list"
 )
@@ -14,114 +19,1452 @@ const (
 
 // entry holds the key and value of a cache entry.
 type entry[K comparable, V any] struct {
-	key   K
-	value V
+	key    K
+	value  V
+	weight int
+	// bytes is the size of the entry reported by the sizer, if any.
+	bytes int
+	// expireAt is when the entry expires, zero if it never does.
+	expireAt time.Time
+	// segmentTTL is set if expireAt follows the TTL of the segment.
+	segmentTTL bool
+	// gen is the cache generation the entry was written in.
+	gen uint64
+	// version is the version set by SetVersioned, zero for other writes.
+	version uint64
+	// created and accessed are when the entry was inserted and last hit.
+	created  time.Time
+	accessed time.Time
+	hits     uint64
+	// wheelLevel is the level plus one of the timing wheel slot scheduling
+	// the expiry, zero if unscheduled, and wheelSlot the slot.
+	wheelLevel int8
+	wheelSlot  uint8
+	// moves counts the moves of the entry within or between segments, for
+	// KeysPage to tell whether it is still where a walk left it.
+	moves uint32
+	// touched is when the entry was last hit or written for protected
+	// aging, in nanoseconds or in accesses.
+	touched int64
+	// tags are the tags of SetWithTags, indexed in the tags of the cache.
+	tags []string
+	// deps are the keys of DependOn the entry derives from, indexed in the
+	// dependents of the cache.
+	deps []K
+}
+
+// expired reports whether the entry has expired at now.
+func (e *entry[K, V]) expired(now time.Time) bool {
+	return !e.expireAt.IsZero() && !now.Before(e.expireAt)
 }
 
+// SLRU is a segmented LRU cache.
+//
+// lock guards the index and moves between segments. Hits that keep an entry
+// in its segment only hold it shared, and reorder the segment under its own
+// lock, probationLock or protectedLock, so they don't block each other
+// across segments. Locks are taken in the order lock, probationLock,
+// protectedLock; holding lock exclusively needs no segment lock.
 type SLRU[K comparable, V any] struct {
-	lock          sync.RWMutex
-	size          int
-	items         map[K]*list.Element
-	probation     *list.List
-	protected     *list.List
-	probationSize int
-	protectedSize int
+	lock            sync.RWMutex
+	probationLock   sync.Mutex
+	protectedLock   sync.Mutex
+	size            int
+	ratio           float64
+	items           map[K]*list.Element
+	probation       *list.List
+	protected       *list.List
+	probationSize   int
+	protectedSize   int
+	probationWeight int
+	protectedWeight int
+	weigher         func(key K, value V) int
+	ttl             time.Duration
+	equal           func(a, b V) bool
+	cloner          func(value V) V
+	gen             uint64
+	minGen          uint64
+	total           bool
+	logger          *slog.Logger
+	stats           stats
+	latency         bool
+	onEvict         func(key K, value V)
+	trace           *TraceRecorder
+	watched         map[K]*accessRing
+	watchDepth      int
+	watchKeys       []K
+	keyLocks        *keyLocks[K]
+	keyLocksOnce    sync.Once
+	teardown        sync.WaitGroup
+	teardownLock    sync.Mutex
+	// maintenance is held shared by background maintenance runs, and
+	// exclusively while paused by pauses calls to PauseMaintenance.
+	maintenance sync.RWMutex
+	pauseLock   sync.Mutex
+	pauses      int
+	// done is closed by Close to stop the workers, counted by workers.
+	// closeLock guards closed against SetAsync and Flush. writes buffers
+	// the writes of SetAsync, applied once writeWake is signaled. Loads
+	// drop their values if removals counts removals made meanwhile.
+	done      chan struct{}
+	workers   sync.WaitGroup
+	closeLock sync.RWMutex
+	closed    bool
+	onClose   func(cache *SLRU[K, V]) error
+	loads     loadGroup[K, V]
+	removals  uint64
+	loaders   *Loaders[K, V]
+	writes    chan write[K, V]
+	writeWake chan struct{}
+	// janitorInterval is the period of the janitor, if enabled.
+	janitorInterval time.Duration
+	janitorLimit    int
+	// softFloor and softPressure configure WithSoftValues.
+	softFloor    float64
+	softPressure func() bool
+	clock        func() time.Time
+	coarse       *coarseClock
+	// probationTTL and protectedTTL are the TTLs of WithSegmentTTLs.
+	probationTTL time.Duration
+	protectedTTL time.Duration
+	segmentTTLs  bool
+	ttlFunc      func(key K, value V) time.Duration
+	preserveTTL  bool
+	ttlJitter    float64
+	// admitProbability and admitFilter gate the admission of new keys, if
+	// set.
+	admitProbability float64
+	admitFilter      func(key K) bool
+	// seed is the seed of WithSeed, if seeded, and rand the source of the
+	// randomness of the cache it seeds, nil without one.
+	seed   uint64
+	seeded bool
+	rand   *seededRand
+	// shard is the index of the cache among the shardCount shards of a
+	// Sharded, if any.
+	shard      int
+	shardCount int
+	// chaos is the configuration of WithChaos, if any.
+	chaos *Chaos
+	// regions enables the trace regions of WithTraceRegions.
+	regions bool
+	// keyTransform normalizes the keys of operations, if set.
+	keyTransform func(key K) K
+	// evicted holds the evictions of the operation holding the lock, for
+	// the eviction callback.
+	evicted []eviction[K, V]
+	// shards and shardHash are the sharding of New, unused by the shards.
+	shards         int
+	shardHash      func(key K) uint64
+	index          *readIndex[K, V]
+	waitSample     uint32
+	initialSize    int
+	lowWatermark   float64
+	evictBatch     int
+	sizer          func(key K, value V) int
+	probationBytes int
+	protectedBytes int
+	// probationBudget and protectedBudget cap the bytes of the segments,
+	// unlimited if zero.
+	probationBudget int
+	protectedBudget int
+	// free holds the removed elements, with their entries, for reuse when
+	// preallocated entries are, as WithPreallocation.
+	free         []*list.Element
+	preallocated int
+	// wheel schedules expirations for the janitor, if any.
+	wheel *timingWheel[K, V]
+	// agingWindow is how long protected entries may go untouched, in
+	// nanoseconds or, if agingOps, in accesses counted by ops.
+	agingWindow int64
+	agingOps    bool
+	ops         atomic.Int64
+	// bypass buffers the entries inserted during a scan, a run of more
+	// than scanThreshold insertions counted by scanRun, if enabled.
+	bypass        *list.List
+	bypassSize    int
+	bypassWeight  int
+	bypassBytes   int
+	scanThreshold int
+	scanRun       atomic.Int64
+	// bus shares explicit removals with other caches, identified by
+	// origin, with keys encoded by busKeys.
+	bus         InvalidationBus
+	busKeys     Codec[K]
+	origin      string
+	unsubscribe func()
+	// hints exchanges the hintCount hottest keys with peers every
+	// hintInterval, prefetching those missing here with hintLoad, if set.
+	hints            HintExchange
+	hintKeys         Codec[K]
+	hintCount        int
+	hintInterval     time.Duration
+	hintLoad         func(ctx context.Context, key K) (V, error)
+	unsubscribeHints func()
+	mutations        *MutationLog[K, V]
+	// leases are the fills in progress of GetOrLease, numbered by leaseSeq.
+	leases   map[K]*lease
+	leaseSeq uint64
+	leaseTTL time.Duration
+	// tombstones are the latest versions of InvalidateIfOlder, for up to
+	// tombstoneCap keys, the least recent first out of tombstoneKeys.
+	tombstones    map[K]*tombstone
+	tombstoneKeys *list.BoundedList
+	tombstoneCap  int
+	// tags indexes the keys of the entries tagged by SetWithTags.
+	tags map[string]map[K]struct{}
+	// dependents indexes the keys of the entries depending on a key by
+	// DependOn.
+	dependents map[K]map[K]struct{}
+	// autoscaling resizes the cache, if set, estimating the benefit of a
+	// step more from the ghostHits of the evicted keys in ghostKeys.
+	autoscaling *Autoscaling
+	ghosts      map[K]*list.Element
+	ghostKeys   *list.BoundedList
+	ghostHits   uint64
+	// adaptive gives TTLs from the history of changes of the keys in
+	// changeKeys, if set.
+	adaptive   *AdaptiveTTL
+	changes    map[K]*list.Element
+	changeKeys *list.BoundedList
+	// changeWatchers are the channels of Changes, by key.
+	changeWatchers map[K]map[chan Change[V]]struct{}
+	// hardFactor scales the segment limits into the hard limits of
+	// WithHardLimit, drained back every drainInterval, if over 1.
+	hardFactor    float64
+	drainInterval time.Duration
+	// evictRate is the cap of WithEvictionRateLimit, evictTokens the
+	// evictions it allows as of evictRefill.
+	evictRate   float64
+	evictTokens float64
+	evictRefill time.Time
+	// tenantOf names the tenant of a key for WithTenants, quotas are their
+	// quotas and tenants their accounting, by name.
+	tenantOf func(key K) string
+	quotas   map[string]Quota
+	tenants  sync.Map
+	// overflow is the store of WithOverflow, holding up to overflowSize
+	// keys: spilled indexes them, least recently spilled last in
+	// spillOrder, numbering their spills with spillSeq. spills are the
+	// writes to the store pending for the writer, woken by spillWake;
+	// spillLock orders its writes.
+	overflow     Store[K, Spilled[V]]
+	overflowSize int
+	spilled      map[K]*list.Element
+	spillOrder   *list.List
+	spillSeq     uint64
+	spills       []spill[K, V]
+	spillWake    chan struct{}
+	spillLock    sync.Mutex
+}
+
+// Option configures an SLRU.
+type Option[K comparable, V any] func(*SLRU[K, V])
+
+// WithWeigher makes size a capacity in the units returned by weigher instead
+// of a number of entries, e.g. the byte size of each value.
+func WithWeigher[K comparable, V any](weigher func(key K, value V) int) Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		s.weigher = weigher
+	}
+}
+
+// WithProbationRatio sets the share of the size given to the probation
+// segment, DefaultProbationRatio by default.
+func WithProbationRatio[K comparable, V any](ratio float64) Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		s.ratio = ratio
+	}
+}
+
+// WithTotalCapacity enforces size on the total of both segments, letting
+// probation borrow the budget protected doesn't use yet. Victims are still
+// chosen from probation first.
+func WithTotalCapacity[K comparable, V any]() Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		s.total = true
+	}
+}
+
+// WithLogger logs cache lifecycle events to logger: evictions and expired
+// entries discarded on access at debug level, resizes, purges and
+// invalidations at info level.
+func WithLogger[K comparable, V any](logger *slog.Logger) Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		s.logger = logger
+	}
+}
+
+// WithEvictCallback calls fn with each entry evicted to make room. It runs
+// once the evicting operation has released the cache lock, in its
+// goroutine, so fn may call the cache; callbacks of concurrent operations
+// may run concurrently, and a key passed to fn may have been set again
+// meanwhile. Entries cleared by Purge are passed to fn afterwards from a
+// background goroutine, so Purge doesn't stall readers while fn runs over
+// the whole cache.
+func WithEvictCallback[K comparable, V any](fn func(key K, value V)) Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		s.onEvict = fn
+	}
+}
+
+// WithLatencyHistograms records the latency of Get hits and misses, Set
+// and eviction callbacks in Stats.
+func WithLatencyHistograms[K comparable, V any]() Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		s.latency = true
+	}
+}
+
+// WithInitialCapacity sizes the index for n entries up front, so filling
+// the cache doesn't stall Set calls on rehashing a large index. Without
+// it, unweighted caches are sized for their capacity and weighted caches
+// grow from empty. Sharded caches apply n to each shard.
+func WithInitialCapacity[K comparable, V any](n int) Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		s.initialSize = max(n, 0)
+	}
+}
+
+// WithPreallocation allocates n entries up front in a contiguous slab and
+// recycles removed entries into it, so a cache that fits n entries doesn't
+// allocate for inserts in steady state. Entries cleared by Purge are not
+// recycled. The shards of a Sharded split the n entries.
+func WithPreallocation[K comparable, V any](n int) Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		s.preallocated = n
+	}
+}
+
+// preallocate allocates the slab of WithPreallocation, holding n entries.
+func (s *SLRU[K, V]) preallocate(n int) {
+	if n <= 0 {
+		return
+	}
+	slab := make([]struct {
+		ent  entry[K, V]
+		elem list.Element
+	}, n)
+	s.free = make([]*list.Element, n)
+	for i := range slab {
+		slab[i].elem.Value = &slab[i].ent
+		s.free[i] = &slab[i].elem
+	}
+}
+
+// WithLowWatermark makes a segment that overflows evict down to low, a
+// share of its limit such as 0.9, rather than just enough to fit. Evicting
+// in batches amortizes the work when nearly every insert evicts.
+func WithLowWatermark[K comparable, V any](low float64) Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		s.lowWatermark = low
+	}
+}
+
+// WithEvictionBatch makes a segment that overflows evict at least n
+// entries at once, so churn-heavy workloads don't evict on nearly every
+// insert.
+func WithEvictionBatch[K comparable, V any](n int) Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		s.evictBatch = n
+	}
+}
+
+// WithTTL sets the default time-to-live of entries added by Set.
+func WithTTL[K comparable, V any](ttl time.Duration) Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		s.ttl = ttl
+	}
+}
+
+// WithEqual sets the function CompareAndSwap uses to compare values, which
+// is required when V is not comparable.
+func WithEqual[K comparable, V any](equal func(a, b V) bool) Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		s.equal = equal
+	}
+}
+
+// WithSegmentTTLs gives the entries written by Set, Add, Replace, Swap
+// and the like a time-to-live depending on their segment, in place of
+// WithTTL: probation in probation, restarting at protected when they are
+// promoted, so unproven entries can expire sooner than proven ones. An
+// update restarts the TTL of the segment the entry ends up in. A zero
+// TTL never expires. Entries set with an explicit TTL keep it.
+func WithSegmentTTLs[K comparable, V any](probation, protected time.Duration) Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		s.probationTTL = probation
+		s.protectedTTL = protected
+		s.segmentTTLs = true
+	}
+}
+
+// WithTTLFunc gives the entries written without an explicit TTL the
+// time-to-live fn returns for them, in place of WithTTL and
+// WithSegmentTTLs, e.g. from a field of the value or the class of the key.
+// A non-positive TTL never expires. fn runs under the cache lock and must
+// not call the cache.
+func WithTTLFunc[K comparable, V any](fn func(key K, value V) time.Duration) Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		s.ttlFunc = fn
+	}
 }
 
-func New[K comparable, V any](size int) Cache[K, V] {
-	return &SLRU[K, V]{
-		size:          size,
-		items:         make(map[K]*list.Element),
-		probation:     list.New(),
-		protected:     list.New(),
-		probationSize: int(DefaultProbationRatio * float64(size)),
-		protectedSize: size - int(DefaultProbationRatio*float64(size)),
+// WithPreservedTTL keeps the deadline of a live entry when a write without
+// an explicit TTL, such as Set, replaces its value, instead of restarting
+// its TTL. Writes with an explicit TTL, such as SetWithTTL, always set it.
+func WithPreservedTTL[K comparable, V any]() Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		s.preserveTTL = true
 	}
 }
 
+// WithTTLJitter shortens every TTL by a random share of up to fraction of
+// it, so entries written together don't all expire at once and stampede
+// the backend. Deadlines given by SetWithExpireAt are kept as is.
+func WithTTLJitter[K comparable, V any](fraction float64) Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		s.ttlJitter = min(max(fraction, 0), 1)
+	}
+}
+
+// WithClock makes the cache read the time from now instead of time.Now,
+// for expiry, aging and eviction statistics, e.g. to drive it from a fake
+// clock in tests.
+func WithClock[K comparable, V any](now func() time.Time) Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		s.clock = now
+	}
+}
+
+// WithShards makes New spread keys over n independently locked shards, as
+// NewSharded with hash, where a nil hash uses a random maphash. n is
+// rounded up to a power of two; one or less creates an unsharded cache.
+// It has no effect on the shards themselves.
+func WithShards[K comparable, V any](n int, hash func(key K) uint64) Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		s.shards = n
+		s.shardHash = hash
+	}
+}
+
+// WithCloner makes Get and Peek return cloner's copy of a value, so callers
+// can't mutate what other readers see.
+func WithCloner[K comparable, V any](cloner func(value V) V) Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		s.cloner = cloner
+	}
+}
+
+// New creates a cache of the given size, configured by opts: among others
+// the segment split (WithProbationRatio), expiry (WithTTL, WithJanitor),
+// weights (WithWeigher), callbacks (WithEvictCallback), sharding
+// (WithShards), time (WithClock) and statistics (WithLatencyHistograms,
+// WithLockWaitSampling). Options only record settings, so they compose in
+// any order; background work starts once the cache is built.
+func New[K comparable, V any](size int, opts ...Option[K, V]) Cache[K, V] {
+	var probe SLRU[K, V]
+	for _, opt := range opts {
+		opt(&probe)
+	}
+	if probe.shards > 1 {
+		return NewSharded[K, V](size, probe.shards, probe.shardHash, opts...)
+	}
+	return newSLRU[K, V](size, opts...)
+}
+
+func newSLRU[K comparable, V any](size int, opts ...Option[K, V]) *SLRU[K, V] {
+	s := &SLRU[K, V]{
+		ratio:       DefaultProbationRatio,
+		probation:   list.New(),
+		protected:   list.New(),
+		initialSize: -1,
+		done:        make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.seeded {
+		s.rand = newSeededRand(s.seed + uint64(s.shard))
+	}
+	s.preallocate(shardSize(s.preallocated, max(s.shardCount, 1)))
+	s.startWatching()
+	if s.initialSize < 0 {
+		s.initialSize = 0
+		if s.weigher == nil {
+			s.initialSize = max(size, 0)
+		}
+	}
+	s.items = make(map[K]*list.Element, s.initialSize)
+	s.setSize(size)
+	if s.coarse != nil {
+		s.coarse.start()
+	}
+	s.startJanitor()
+	s.startAutoscaling()
+	s.startAdaptiveTTL()
+	s.startDrain()
+	s.startSpilling()
+	if s.writes != nil {
+		s.workers.Add(1)
+		go s.applyWrites()
+	}
+	s.startSoft()
+	if s.bus != nil || s.hints != nil {
+		s.setOrigin()
+	}
+	if s.bus != nil {
+		s.subscribe()
+	}
+	if s.hints != nil {
+		s.startHints()
+	}
+	return s
+}
+
+// setSize sets the total size and splits it between the segments, giving
+// each at least one unit so tiny caches still hold entries. A cache of size
+// 1 has no protected segment and hits refresh the entry within probation.
+// Negative sizes count as 0.
+func (s *SLRU[K, V]) setSize(size int) {
+	size = max(size, 0)
+	s.size = size
+	s.probationSize = int(s.ratio * float64(size))
+	if s.probationSize < 1 && size > 0 {
+		s.probationSize = 1
+	}
+	s.protectedSize = size - s.probationSize
+}
+
 func (s *SLRU[K, V]) Set(key K, value V) {
-	s.lock.Lock()
-	defer s.lock.Unlock()
+	key = s.normalize(key)
+	if s.latency {
+		defer s.stats.set.since(time.Now())
+	}
+	s.acquire()
+	defer s.unlock()
 
-	if e, ok := s.items[key]; ok {
-		if e.List() == s.protected {
-			s.protected.MoveToFront(e)
+	s.set(key, value)
+}
+
+func (s *SLRU[K, V]) Add(key K, value V) (inserted bool) {
+	key = s.normalize(key)
+	s.acquire()
+	defer s.unlock()
+
+	_, exists := s.live(key)
+	s.set(key, value)
+	_, ok := s.items[key]
+	return !exists && ok
+}
+
+func (s *SLRU[K, V]) Replace(key K, value V) (replaced bool) {
+	key = s.normalize(key)
+	s.acquire()
+	defer s.unlock()
+
+	if _, ok := s.live(key); !ok {
+		return false
+	}
+	s.set(key, value)
+	return true
+}
+
+func (s *SLRU[K, V]) Swap(key K, value V) (old V, existed bool) {
+	key = s.normalize(key)
+	s.acquire()
+	defer s.unlock()
+
+	if ent, ok := s.live(key); ok {
+		old, existed = ent.value, true
+	}
+	s.set(key, value)
+	return old, existed
+}
+
+func (s *SLRU[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	key = s.normalize(key)
+	if s.latency {
+		defer s.stats.set.since(time.Now())
+	}
+	s.acquire()
+	defer s.unlock()
+
+	s.setEntry(key, value, s.weigh(key, value), ttl)
+}
+
+// Putter is a cache reporting whether it kept a write, such as an SLRU or
+// a Sharded.
+type Putter[K comparable, V any] interface {
+	Put(key K, value V, ttl time.Duration) (stored bool)
+}
+
+// Put is SetWithTTL, reporting whether the cache kept the entry: it
+// doesn't keep entries rejected by admission or too heavy for probation,
+// nor those the write evicts at once.
+func (s *SLRU[K, V]) Put(key K, value V, ttl time.Duration) (stored bool) {
+	key = s.normalize(key)
+	if s.latency {
+		defer s.stats.set.since(time.Now())
+	}
+	s.acquire()
+	defer s.unlock()
+
+	s.setEntry(key, value, s.weigh(key, value), ttl)
+	_, stored = s.items[key]
+	return stored
+}
+
+// SetWithExpireAt sets the value for the given key, expiring it at at, for
+// deadlines received as timestamps, such as those of tokens. A zero at
+// never expires; one already past removes the key.
+func (s *SLRU[K, V]) SetWithExpireAt(key K, value V, at time.Time) {
+	key = s.normalize(key)
+	if s.latency {
+		defer s.stats.set.since(time.Now())
+	}
+	s.acquire()
+	defer s.unlock()
+
+	now := s.now()
+	if !at.IsZero() && !now.Before(at) {
+		s.remove(key)
+		return
+	}
+	s.setEntryAt(key, value, s.weigh(key, value), now, at, false)
+}
+
+// set adds or updates key and reports whether an entry was evicted.
+func (s *SLRU[K, V]) set(key K, value V) (evicted bool) {
+	return s.setWeighted(key, value, s.weigh(key, value))
+}
+
+// setWeighted is set with an explicit weight for the entry, expiring it as
+// configured for writes without an explicit TTL.
+func (s *SLRU[K, V]) setWeighted(key K, value V, weight int) (evicted bool) {
+	now := s.now()
+	var adaptiveTTL time.Duration
+	if s.adaptive != nil {
+		adaptiveTTL = s.adaptTTL(key, value, now)
+	}
+	e, exists := s.items[key]
+	if exists && s.preserveTTL {
+		if ent := e.Value.(*entry[K, V]); !s.dead(ent, now) {
+			return s.setEntryAt(key, value, weight, now, ent.expireAt, ent.segmentTTL)
 		}
-		if e.List() == s.probation {
-			s.items[e.Value.(*entry[K, V]).key] = s.protected.PushFront(e.Value)
-			s.probation.Remove(e)
-			if s.protected.Len() > s.protectedSize {
-				s.evict(s.protected)
-			}
+	}
+	switch {
+	case s.adaptive != nil:
+		return s.setEntryAt(key, value, weight, now, s.deadline(now, adaptiveTTL), false)
+	case s.ttlFunc != nil:
+		return s.setEntryAt(key, value, weight, now, s.deadline(now, s.ttlFunc(key, value)), false)
+	case s.segmentTTLs:
+		ttl := s.probationTTL
+		if exists && e.List() == s.protected {
+			ttl = s.protectedTTL
 		}
-		e.Value.(*entry[K, V]).value = value
-		return
+		return s.setEntryAt(key, value, weight, now, s.deadline(now, ttl), true)
 	}
+	return s.setEntryAt(key, value, weight, now, s.deadline(now, s.ttl), false)
+}
+
+// setEntry adds or updates key with an explicit weight and ttl, where a
+// non-positive ttl never expires.
+func (s *SLRU[K, V]) setEntry(key K, value V, weight int, ttl time.Duration) (evicted bool) {
+	now := s.now()
+	return s.setEntryAt(key, value, weight, now, s.deadline(now, ttl), false)
+}
 
-	if s.probation.Len() >= s.probationSize {
-		s.evict(s.probation)
+// setEntryAt is setEntry at time now with an absolute expiration, where a
+// zero expireAt never expires. segmentTTL marks expireAt as following the
+// TTL of the segment of the entry, restarting on promotion.
+func (s *SLRU[K, V]) setEntryAt(key K, value V, weight int, now, expireAt time.Time, segmentTTL bool) (evicted bool) {
+	e, exists := s.items[key]
+	var ttl time.Duration
+	if !expireAt.IsZero() {
+		ttl = expireAt.Sub(now)
 	}
-	e := &entry[K, V]{key: key, value: value}
-	s.items[key] = s.probation.PushFront(e)
+	s.record(TraceSet, key, weight, ttl)
+
+	if exists {
+		s.observe(key, AccessSet, e.List())
+		ent := e.Value.(*entry[K, V])
+		bytes := s.measure(key, value)
+		*s.weight(e.List()) += weight - ent.weight
+		*s.bytes(e.List()) += bytes - ent.bytes
+		s.tally(key, 0, weight-ent.weight)
+		ent.value = value
+		ent.weight = weight
+		ent.bytes = bytes
+		ent.expireAt = expireAt
+		ent.segmentTTL = segmentTTL
+		ent.gen = s.gen
+		ent.version = 0
+		s.touch(ent, now)
+		s.reused()
+		s.reschedule(ent)
+		s.publish(ent)
+		s.mutate(MutationSet, ent)
+		s.promote(e)
+		evicted = s.capTenant(key)+s.trim() > 0
+		s.cascade(key)
+		return evicted
+	}
+
+	s.unspill(key)
+	// an entry heavier than probation would only flush it and be evicted
+	bytes := s.measure(key, value)
+	if weight > s.probationLimit() || (s.probationBudget > 0 && bytes > s.probationBudget) {
+		s.observe(key, AccessRejected, nil)
+		return false
+	}
+	if s.throttled(weight) {
+		s.observe(key, AccessRejected, nil)
+		s.stats.throttled.inc()
+		return false
+	}
+	s.unghost(key)
+	if !s.admit(key) {
+		s.observe(key, AccessRejected, nil)
+		s.stats.rejections.inc()
+		return false
+	}
+	s.observe(key, AccessSet, nil)
+	e = s.element()
+	ent := e.Value.(*entry[K, V])
+	*ent = entry[K, V]{
+		key:        key,
+		value:      value,
+		weight:     weight,
+		bytes:      bytes,
+		expireAt:   expireAt,
+		segmentTTL: segmentTTL,
+		gen:        s.gen,
+		created:    now,
+		accessed:   now,
+	}
+	s.touch(ent, now)
+	if s.scanning() {
+		s.push(s.bypass, e)
+		s.reschedule(ent)
+		s.publish(ent)
+		s.mutate(MutationSet, ent)
+		evicted := s.capTenant(key) > 0
+		for s.bypass.Len() > s.bypassSize {
+			s.evict(s.bypass)
+			evicted = true
+		}
+		return evicted
+	}
+	s.push(s.probation, e)
+	s.reschedule(ent)
+	s.publish(ent)
+	s.mutate(MutationSet, ent)
+	return s.capTenant(key)+s.trim() > 0
 }
 
 func (s *SLRU[K, V]) Get(key K) (value V, ok bool) {
-	s.lock.Lock()
-	defer s.lock.Unlock()
-	if e, ok := s.items[key]; ok {
-		if e.List() == s.protected {
-			s.protected.MoveToFront(e)
-		}
-		if e.List() == s.probation {
-			s.items[e.Value.(*entry[K, V]).key] = s.protected.PushFront(e.Value)
-			s.probation.Remove(e)
-			if s.protected.Len() > s.protectedSize {
-				s.evict(s.protected)
+	return s.lookup(s.normalize(key))
+}
+
+// lookup is Get of a normalized key.
+func (s *SLRU[K, V]) lookup(key K) (value V, ok bool) {
+	if s.latency {
+		defer func(start time.Time) {
+			if ok {
+				s.stats.getHit.since(start)
+			} else {
+				s.stats.getMiss.since(start)
+			}
+		}(time.Now())
+	}
+	if s.overflow != nil {
+		defer func() {
+			if !ok {
+				value, ok = s.restore(key)
 			}
+		}()
+	}
+	if value, ok, done := s.getShared(key); done {
+		return value, ok
+	}
+	s.acquire()
+	defer s.unlock()
+
+	return s.get(key)
+}
+
+// get looks up key under the exclusive lock, promoting hits and discarding
+// dead entries.
+func (s *SLRU[K, V]) get(key K) (value V, ok bool) {
+	if e, ok := s.items[key]; ok {
+		ent := e.Value.(*entry[K, V])
+		now := s.now()
+		if s.dead(ent, now) {
+			s.observe(key, AccessExpired, e.List())
+			delete(s.items, key)
+			s.unpublish(key)
+			s.mutate(MutationExpire, ent)
+			s.unlink(e)
+			s.release(e)
+			s.log(slog.LevelDebug, "slru: discard dead entry", "key", key)
+			s.stats.misses.inc()
+			s.lookedUp(key, false)
+			return value, false
 		}
-		return e.Value.(*entry[K, V]).value, true
+		s.observe(key, AccessHit, e.List())
+		s.stats.hits.inc()
+		s.lookedUp(key, true)
+		ent.hits++
+		ent.accessed = now
+		s.touch(ent, now)
+		s.reused()
+		s.promote(e)
+		s.trim()
+		return s.clone(ent.value), true
 	}
 
+	s.observe(key, AccessMiss, nil)
+	s.stats.misses.inc()
+	s.lookedUp(key, false)
 	return
 }
 
-func (s *SLRU[K, V]) Contains(key K) (ok bool) {
-	s.lock.RLock()
+// getShared serves misses and hits that keep the entry in its segment
+// under the shared lock. done is false if the lookup needs the exclusive
+// lock, to promote or discard the entry or to log a watched key.
+func (s *SLRU[K, V]) getShared(key K) (value V, ok, done bool) {
+	s.acquireShared()
 	defer s.lock.RUnlock()
+
+	s.record(TraceGet, key, 0, 0)
+	if _, watched := s.watched[key]; watched {
+		return value, false, false
+	}
+	e, ok := s.items[key]
+	if !ok {
+		s.stats.misses.inc()
+		s.lookedUp(key, false)
+		return value, false, true
+	}
+	ent := e.Value.(*entry[K, V])
+	now := s.now()
+	l := e.List()
+	if s.dead(ent, now) || (l == s.probation && s.protectedSize > 0) || l == s.bypass {
+		return value, false, false
+	}
+
+	m := s.segmentLock(l)
+	m.Lock()
+	l.MoveToFront(e)
+	ent.moves++
+	ent.hits++
+	ent.accessed = now
+	s.touch(ent, now)
+	m.Unlock()
+	s.reused()
+	s.stats.hits.inc()
+	s.lookedUp(key, true)
+	return s.clone(ent.value), true, true
+}
+
+// segmentLock returns the lock of segment l.
+func (s *SLRU[K, V]) segmentLock(l *list.List) *sync.Mutex {
+	if l == s.protected {
+		return &s.protectedLock
+	}
+	return &s.probationLock
+}
+
+// lockSegments locks both segments for a reader holding the shared lock
+// that walks them or reads entry access times, and returns the unlock.
+func (s *SLRU[K, V]) lockSegments() func() {
+	s.probationLock.Lock()
+	s.protectedLock.Lock()
+	return func() {
+		s.protectedLock.Unlock()
+		s.probationLock.Unlock()
+	}
+}
+
+// promote moves a hit element to the front of protected. The caller trims
+// any overflow afterwards.
+func (s *SLRU[K, V]) promote(e *list.Element) {
+	e.Value.(*entry[K, V]).moves++
+	if s.protectedSize < 1 {
+		e.List().MoveToFront(e)
+		return
+	}
+	if e.List() == s.protected {
+		s.protected.MoveToFront(e)
+	} else {
+		s.unlink(e)
+		s.push(s.protected, e)
+		s.stats.promotions.inc()
+		if ent := e.Value.(*entry[K, V]); ent.segmentTTL {
+			ent.expireAt = s.deadline(s.now(), s.protectedTTL)
+			s.reschedule(ent)
+			s.publish(ent)
+		}
+	}
+}
+
+// deadline returns when an entry set at now with ttl expires, zero if ttl
+// is not positive, shortened by the jitter if any.
+func (s *SLRU[K, V]) deadline(now time.Time, ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	if s.ttlJitter > 0 {
+		ttl -= time.Duration(s.ttlJitter * s.randFloat64() * float64(ttl))
+	}
+	return now.Add(ttl)
+}
+
+// trim demotes aged protected entries, then evicts from the segment tails
+// until both fit their limits, returning the number of evicted entries. In total-capacity mode probation may use
+// whatever protected leaves free, and victims come from probation first.
+func (s *SLRU[K, V]) trim() (evicted int) {
+	s.age()
+	protected, _ := s.limits()
+	evicted += s.trimSegment(s.protected, protected, s.protectedBudget)
+	// probation may get what protected just gave up in total-capacity mode
+	_, probation := s.limits()
+	evicted += s.trimSegment(s.probation, probation, s.probationBudget)
+	s.spend(evicted)
+	s.traceEvictions(evicted)
+	return evicted
+}
+
+// trimSegment evicts from the tail of l while it weighs more than limit or
+// its bytes exceed a non-zero budget. It then goes on, while more than the
+// head entry remains, down to the low watermark share of limit and until
+// a batch of evictions is complete.
+func (s *SLRU[K, V]) trimSegment(l *list.List, limit, budget int) (evicted int) {
+	weight, bytes := s.weight(l), s.bytes(l)
+	for (*weight > limit || (budget > 0 && *bytes > budget)) && l.Len() > 0 {
+		s.evict(l)
+		evicted++
+	}
+	if evicted > 0 && s.lowWatermark > 0 {
+		for low := s.lowWatermark * float64(limit); float64(*weight) > low && l.Len() > 1; {
+			s.evict(l)
+			evicted++
+		}
+	}
+	for evicted > 0 && evicted < s.evictBatch && l.Len() > 1 {
+		s.evict(l)
+		evicted++
+	}
+	return evicted
+}
+
+// probationLimit returns the weight probation may currently hold.
+func (s *SLRU[K, V]) probationLimit() int {
+	if s.total {
+		return s.size - s.protectedWeight
+	}
+	return s.probationSize
+}
+
+func (s *SLRU[K, V]) CompareAndSwap(key K, old, new V) (swapped bool) {
+	key = s.normalize(key)
+	s.acquire()
+	defer s.unlock()
+
+	ent, ok := s.live(key)
+	if !ok || !s.equals(ent.value, old) {
+		return false
+	}
+	s.set(key, new)
+	return true
+}
+
+func (s *SLRU[K, V]) Update(key K, fn func(old V, exists bool) (new V, store bool)) (value V, ok bool) {
+	key = s.normalize(key)
+	s.acquire()
+	defer s.unlock()
+
+	ent, exists := s.live(key)
+	if exists {
+		value = ent.value
+	}
+	new, store := fn(value, exists)
+	if !store {
+		return value, exists
+	}
+	s.set(key, new)
+	// a new entry may not have been admitted
 	_, ok = s.items[key]
+	return new, ok
+}
+
+// live returns the entry of key if it is present and not expired.
+func (s *SLRU[K, V]) live(key K) (*entry[K, V], bool) {
+	if e, ok := s.items[key]; ok {
+		if ent := e.Value.(*entry[K, V]); !s.dead(ent, s.now()) {
+			return ent, true
+		}
+	}
+	return nil, false
+}
+
+// equals compares values with the configured equality function, falling
+// back to ==, which panics if V holds values that are not comparable.
+func (s *SLRU[K, V]) equals(a, b V) bool {
+	if s.equal != nil {
+		return s.equal(a, b)
+	}
+	return any(a) == any(b)
+}
+
+func (s *SLRU[K, V]) Contains(key K) (ok bool) {
+	key = s.normalize(key)
+	if s.index != nil {
+		_, ok = s.readLive(key)
+		return ok
+	}
+	s.acquireShared()
+	defer s.lock.RUnlock()
+	_, ok = s.live(key)
 	return
 }
 
 func (s *SLRU[K, V]) Peek(key K) (value V, ok bool) {
-	s.lock.RLock()
+	key = s.normalize(key)
+	if s.index != nil {
+		if snap, ok := s.readLive(key); ok {
+			return s.clone(snap.value), true
+		}
+		return value, false
+	}
+	s.acquireShared()
 	defer s.lock.RUnlock()
 
-	if e, ok := s.items[key]; ok {
-		return e.Value.(*entry[K, V]).value, true
+	if ent, ok := s.live(key); ok {
+		return s.clone(ent.value), true
 	}
 
 	return
 }
 
+// GetStale returns the value for the given key like Peek, but also once it
+// has expired, with how long ago, until it is reclaimed, so callers can
+// fall back to it when the backend is unavailable. Call it instead of Get,
+// which discards the expired entries it finds. Entries invalidated by
+// InvalidateBefore are never returned.
+func (s *SLRU[K, V]) GetStale(key K) (value V, staleFor time.Duration, ok bool) {
+	key = s.normalize(key)
+	s.acquireShared()
+	defer s.lock.RUnlock()
+
+	e, ok := s.items[key]
+	if !ok {
+		return value, 0, false
+	}
+	ent := e.Value.(*entry[K, V])
+	if ent.gen < s.minGen {
+		return value, 0, false
+	}
+	if now := s.now(); ent.expired(now) {
+		staleFor = now.Sub(ent.expireAt)
+	}
+	return s.clone(ent.value), staleFor, true
+}
+
+func (s *SLRU[K, V]) TTL(key K) (ttl time.Duration, ok bool) {
+	key = s.normalize(key)
+	s.acquireShared()
+	defer s.lock.RUnlock()
+
+	if e, ok := s.items[key]; ok {
+		ent := e.Value.(*entry[K, V])
+		now := s.now()
+		if s.dead(ent, now) {
+			return 0, false
+		}
+		if !ent.expireAt.IsZero() {
+			ttl = ent.expireAt.Sub(now)
+		}
+		return ttl, true
+	}
+
+	return 0, false
+}
+
+func (s *SLRU[K, V]) Remove(key K) (present bool) {
+	key = s.normalize(key)
+	if s.bus != nil {
+		defer func() {
+			if present {
+				s.broadcast(Invalidation{Keys: s.encodeKeys(key)})
+			}
+		}()
+	}
+	s.acquire()
+	defer s.unlock()
+
+	s.record(TraceDelete, key, 0, 0)
+	return s.remove(key)
+}
+
+// remove removes key and revokes its lease, reporting whether it was
+// present.
+func (s *SLRU[K, V]) remove(key K) bool {
+	s.removals++
+	s.endLease(key)
+	s.unspill(key)
+	if e, ok := s.items[key]; ok {
+		s.observe(key, AccessRemoved, e.List())
+		delete(s.items, key)
+		s.unpublish(key)
+		s.mutate(MutationRemove, e.Value.(*entry[K, V]))
+		s.unlink(e)
+		s.release(e)
+		s.cascade(key)
+		return true
+	}
+
+	return false
+}
+
+func (s *SLRU[K, V]) Keys() []K {
+	s.acquireShared()
+	defer s.lock.RUnlock()
+	defer s.lockSegments()()
+
+	keys := make([]K, 0, len(s.items))
+	for _, l := range s.segments() {
+		l.EachReverse(func(e *list.Element) bool {
+			keys = append(keys, e.Value.(*entry[K, V]).key)
+			return true
+		})
+	}
+	return keys
+}
+
+func (s *SLRU[K, V]) NewGeneration() (gen uint64) {
+	s.acquire()
+	defer s.unlock()
+
+	s.gen++
+	return s.gen
+}
+
+func (s *SLRU[K, V]) InvalidateBefore(gen uint64) {
+	s.acquire()
+	defer s.unlock()
+
+	s.minGen = max(s.minGen, gen)
+	s.removals++
+	if s.index != nil {
+		s.index.minGen.Store(s.minGen)
+	}
+	s.dropSpilled()
+	s.log(slog.LevelInfo, "slru: invalidate generations", "before", gen)
+}
+
 func (s *SLRU[K, V]) Len() int {
-	s.lock.RLock()
+	s.acquireShared()
 	defer s.lock.RUnlock()
 
-	return s.probation.Len() + s.protected.Len()
+	return len(s.items)
+}
+
+// String summarizes the cache for log lines: its size, the fill of each
+// segment, in weight, and the hit ratio.
+func (s *SLRU[K, V]) String() string {
+	stats := s.Stats()
+	return "SLRU(" + s.fill().String() + fmt.Sprintf(", hit ratio %.1f%%)", 100*stats.HitRatio())
+}
+
+// segmentFill is the weight held and allowed in each segment.
+type segmentFill struct {
+	probation, probationSize int
+	protected, protectedSize int
+}
+
+func (s *SLRU[K, V]) fill() segmentFill {
+	s.acquireShared()
+	defer s.lock.RUnlock()
+	defer s.lockSegments()()
+
+	return segmentFill{s.probationWeight, s.probationSize, s.protectedWeight, s.protectedSize}
+}
+
+func (f segmentFill) add(g segmentFill) segmentFill {
+	return segmentFill{
+		f.probation + g.probation, f.probationSize + g.probationSize,
+		f.protected + g.protected, f.protectedSize + g.protectedSize,
+	}
+}
+
+func (f segmentFill) String() string {
+	return fmt.Sprintf("size %d: probation %d/%d, protected %d/%d",
+		f.probationSize+f.protectedSize, f.probation, f.probationSize, f.protected, f.protectedSize)
 }
 
 func (s *SLRU[K, V]) Purge() {
-	s.lock.Lock()
-	defer s.lock.Unlock()
+	s.purge()
+	if s.bus != nil {
+		s.broadcast(Invalidation{All: true})
+	}
+}
+
+func (s *SLRU[K, V]) purge() {
+	defer s.region("purge")()
+	s.acquire()
+	defer s.unlock()
 
-	s.items = make(map[K]*list.Element)
+	n := len(s.items)
+	segments := s.segments()
+	s.removals++
+	s.items = make(map[K]*list.Element, s.initialSize)
+	if s.index != nil {
+		s.index.m.Store(new(sync.Map))
+	}
 	s.probation = list.New()
 	s.protected = list.New()
+	s.probationWeight = 0
+	s.protectedWeight = 0
+	s.probationBytes = 0
+	s.protectedBytes = 0
+	s.retally()
+	if s.bypass != nil {
+		s.bypass = list.New()
+		s.bypassWeight = 0
+		s.bypassBytes = 0
+		s.scanRun.Store(0)
+	}
+	if s.wheel != nil {
+		s.wheel = newTimingWheel[K, V](s.wheel.tick, s.now())
+	}
+	if s.mutations != nil {
+		s.mutations.append(Mutation[K, V]{Op: MutationPurge})
+	}
+	s.notifyPurge()
+	s.dropSpilled()
+	s.tags = nil
+	s.dependents = nil
+	s.endLeases()
+	s.log(slog.LevelInfo, "slru: purge", "entries", n)
+	if s.onEvict != nil && n > 0 {
+		s.teardown.Add(1)
+		go s.tearDown(segments...)
+	}
+}
+
+// tearDown passes the entries of purged segments to the eviction callback
+// outside the cache lock, one purge at a time.
+func (s *SLRU[K, V]) tearDown(segments ...*list.List) {
+	defer s.teardown.Done()
+	s.teardownLock.Lock()
+	defer s.teardownLock.Unlock()
+
+	if s.chaos != nil && s.chaos.ShuffleCallbacks {
+		s.tearDownShuffled(segments...)
+		return
+	}
+	for _, l := range segments {
+		l.EachReverse(func(e *list.Element) bool {
+			ent := e.Value.(*entry[K, V])
+			s.onEvict(ent.key, ent.value)
+			return true
+		})
+	}
+}
+
+func (s *SLRU[K, V]) Resize(size int) (evicted int) {
+	defer s.region("resize")()
+	s.acquire()
+	defer s.unlock()
+
+	old := s.size
+	s.setSize(size)
+	evicted = s.trim()
+	if size < old/2 {
+		s.compact()
+	}
+	s.log(slog.LevelInfo, "slru: resize", "from", old, "to", size, "evicted", evicted)
+	return evicted
+}
+
+// dead reports whether ent has expired or was invalidated by generation, in
+// which case it is no longer visible and is discarded on access.
+func (s *SLRU[K, V]) dead(ent *entry[K, V], now time.Time) bool {
+	return ent.gen < s.minGen || ent.expired(now)
+}
+
+// now returns the current time used for expiration.
+func (s *SLRU[K, V]) now() time.Time {
+	if s.clock != nil {
+		return s.clock()
+	}
+	return time.Now()
+}
+
+// clone returns the value handed out to readers.
+func (s *SLRU[K, V]) clone(value V) V {
+	if s.cloner == nil {
+		return value
+	}
+	return s.cloner(value)
+}
+
+// weigh returns the weight of an entry, which is 1 without a weigher.
+func (s *SLRU[K, V]) weigh(key K, value V) int {
+	if s.weigher == nil {
+		return 1
+	}
+	return s.weigher(key, value)
+}
+
+// measure returns the bytes of an entry, which are 0 without a sizer.
+func (s *SLRU[K, V]) measure(key K, value V) int {
+	if s.sizer == nil {
+		return 0
+	}
+	return s.sizer(key, value)
+}
+
+// bytes returns the total bytes counter of segment l.
+func (s *SLRU[K, V]) bytes(l *list.List) *int {
+	if l == s.protected {
+		return &s.protectedBytes
+	}
+	if l == s.bypass {
+		return &s.bypassBytes
+	}
+	return &s.probationBytes
+}
+
+// weight returns the total weight counter of segment l.
+func (s *SLRU[K, V]) weight(l *list.List) *int {
+	if l == s.protected {
+		return &s.protectedWeight
+	}
+	if l == s.bypass {
+		return &s.bypassWeight
+	}
+	return &s.probationWeight
+}
+
+// push inserts the element e at the front of segment l and indexes it.
+func (s *SLRU[K, V]) push(l *list.List, e *list.Element) {
+	ent := e.Value.(*entry[K, V])
+	*s.weight(l) += ent.weight
+	*s.bytes(l) += ent.bytes
+	s.tally(ent.key, 1, ent.weight)
+	ent.moves++
+	s.items[ent.key] = l.PushFrontElement(e)
+}
+
+// element returns a detached element holding an entry to fill, reusing a
+// removed one if entries are preallocated.
+func (s *SLRU[K, V]) element() *list.Element {
+	if n := len(s.free); n > 0 {
+		e := s.free[n-1]
+		s.free = s.free[:n-1]
+		return e
+	}
+	return &list.Element{Value: new(entry[K, V])}
+}
+
+// release is done with the removed element e: it unschedules its expiry
+// and keeps e for reuse if entries are preallocated and the free list has
+// room, dropping its key and value.
+func (s *SLRU[K, V]) release(e *list.Element) {
+	ent := e.Value.(*entry[K, V])
+	s.untag(ent)
+	s.undepend(ent)
+	if s.wheel != nil {
+		s.wheel.unschedule(ent)
+	}
+	if len(s.free) < cap(s.free) {
+		*ent = entry[K, V]{}
+		s.free = append(s.free, e)
+	}
+}
+
+// unlink removes e from its segment, leaving the index untouched.
+func (s *SLRU[K, V]) unlink(e *list.Element) *entry[K, V] {
+	ent := e.Value.(*entry[K, V])
+	*s.weight(e.List()) -= ent.weight
+	*s.bytes(e.List()) -= ent.bytes
+	s.tally(ent.key, -1, -ent.weight)
+	e.List().Remove(e)
+	return ent
 }
 
 func (s *SLRU[K, V]) evict(l *list.List) {
-	o := l.Back()
-	key := o.Value.(*entry[K, V]).key
-	delete(s.items, key)
-	l.Remove(o)
+	s.evictElement(l, s.victim(l))
+}
+
+// evictElement evicts e out of segment l.
+func (s *SLRU[K, V]) evictElement(l *list.List, e *list.Element) {
+	ent := s.unlink(e)
+	delete(s.items, ent.key)
+	s.unpublish(ent.key)
+	s.ghost(ent.key)
+	s.mutate(MutationEvict, ent)
+	s.observe(ent.key, AccessEvicted, l)
+	now := s.now()
+	s.stats.evictions.inc()
+	if s.tenantOf != nil {
+		s.tenant(ent.key).evictions.inc()
+	}
+	if l == s.protected {
+		s.stats.protectedEvictions.inc()
+	} else if ent.hits == 0 {
+		s.stats.oneHitWonders.inc()
+	}
+	s.stats.evictionAge.observe(now.Sub(ent.created))
+	s.stats.evictionIdle.observe(now.Sub(ent.accessed))
+	if s.onEvict != nil {
+		s.evicted = append(s.evicted, eviction[K, V]{ent.key, ent.value})
+	}
+	s.spillEntry(ent)
+	if s.logger != nil {
+		s.log(slog.LevelDebug, "slru: evict", "key", ent.key, "segment", s.segment(l))
+	}
+	s.release(e)
+}
+
+// eviction is an evicted entry, held back for the eviction callback until
+// the cache lock is released.
+type eviction[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// notify passes evicted to the eviction callback.
+func (s *SLRU[K, V]) notify(evicted []eviction[K, V]) {
+	for _, ev := range evicted {
+		if s.latency {
+			start := time.Now()
+			s.onEvict(ev.key, ev.value)
+			s.stats.evictCallback.since(start)
+		} else {
+			s.onEvict(ev.key, ev.value)
+		}
+	}
+}
+
+// segment returns the name of segment l.
+func (s *SLRU[K, V]) segment(l *list.List) string {
+	if l == s.protected {
+		return "protected"
+	}
+	if l == s.bypass {
+		return "bypass"
+	}
+	return "probation"
+}
+
+// log logs an event if a logger is configured and enabled at level.
+func (s *SLRU[K, V]) log(level slog.Level, msg string, args ...any) {
+	if s.logger == nil || !s.logger.Enabled(context.Background(), level) {
+		return
+	}
+	s.logger.Log(context.Background(), level, msg, args...)
 }
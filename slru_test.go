@@ -1,7 +1,12 @@
 package slru
 
 import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -43,3 +48,675 @@ func TestLenOnSLRU(t *testing.T) {
 	cache.Set(2, 2)
 	require.Equal(t, 2, cache.Len())
 }
+
+func TestRemoveOnSLRU(t *testing.T) {
+	cache := New[int, int](10)
+	require.False(t, cache.Remove(1))
+
+	cache.Set(1, 1)
+	cache.Set(2, 2)
+	cache.Get(2)
+	require.True(t, cache.Remove(1))
+	require.True(t, cache.Remove(2))
+	require.False(t, cache.Contains(1))
+	require.False(t, cache.Contains(2))
+	require.Equal(t, 0, cache.Len())
+}
+
+func TestKeysOnSLRU(t *testing.T) {
+	cache := New[int, int](20)
+	cache.Set(1, 1)
+	cache.Set(2, 2)
+	cache.Set(3, 3)
+	cache.Get(1)
+
+	// probation from tail to head, followed by protected
+	require.Equal(t, []int{2, 3, 1}, cache.Keys())
+}
+
+func TestResizeOnSLRU(t *testing.T) {
+	cache := New[int, int](20)
+	for i := 0; i < 4; i++ {
+		cache.Set(i, i)
+	}
+	require.Equal(t, 4, cache.Len())
+
+	// probation shrinks from 4 to 2
+	require.Equal(t, 2, cache.Resize(10))
+	require.Equal(t, []int{2, 3}, cache.Keys())
+
+	// sizes of 0 and below evict everything
+	require.Equal(t, 2, cache.Resize(0))
+	require.Zero(t, cache.Len())
+	cache.Resize(10)
+	cache.Set(1, 1)
+	require.Equal(t, 1, cache.Resize(-1))
+	require.Zero(t, cache.Len())
+	cache.Set(1, 1)
+	require.Zero(t, cache.Len())
+}
+
+func TestWeigherOnSLRU(t *testing.T) {
+	cache := New[string, string](50, WithWeigher(func(key string, value string) int {
+		return len(value)
+	}))
+
+	// probation holds a weight of 10
+	cache.Set("a", "aaaa")
+	cache.Set("b", "bbbb")
+	require.Equal(t, 2, cache.Len())
+	cache.Set("c", "cccc")
+	require.False(t, cache.Contains("a"))
+
+	// growing a value on update is accounted for on promotion
+	cache.Set("b", strings.Repeat("b", 38))
+	require.True(t, cache.Contains("b"))
+	cache.Get("c")
+	require.False(t, cache.Contains("b"))
+	require.Equal(t, []string{"c"}, cache.Keys())
+}
+
+func TestPutOnSLRU(t *testing.T) {
+	cache := newSLRU[string, string](50, WithWeigher(func(key string, value string) int {
+		return len(value)
+	}))
+	require.True(t, cache.Put("a", "aaaa", time.Hour))
+	ttl, ok := cache.TTL("a")
+	require.True(t, ok)
+	require.Greater(t, ttl, time.Minute)
+
+	// probation holds a weight of 10
+	require.False(t, cache.Put("b", strings.Repeat("b", 11), 0))
+	require.False(t, cache.Contains("b"))
+	require.True(t, cache.Put("b", "bbbb", 0))
+}
+
+func TestTTLOnSLRU(t *testing.T) {
+	cache := New[int, int](10, WithTTL[int, int](time.Hour))
+	cache.SetWithTTL(1, 1, time.Millisecond)
+	cache.Set(2, 2)
+	require.True(t, cache.Contains(1))
+
+	time.Sleep(2 * time.Millisecond)
+	require.False(t, cache.Contains(1))
+	_, ok := cache.Peek(1)
+	require.False(t, ok)
+	_, ok = cache.Get(1)
+	require.False(t, ok)
+	require.Equal(t, 1, cache.Len())
+
+	v, ok := cache.Get(2)
+	require.True(t, ok)
+	require.Equal(t, 2, v)
+}
+
+func TestTTLQueryOnSLRU(t *testing.T) {
+	cache := New[int, int](10)
+	_, ok := cache.TTL(1)
+	require.False(t, ok)
+
+	cache.Set(1, 1)
+	ttl, ok := cache.TTL(1)
+	require.True(t, ok)
+	require.Zero(t, ttl)
+
+	cache.SetWithTTL(2, 2, time.Minute)
+	ttl, ok = cache.TTL(2)
+	require.True(t, ok)
+	require.InDelta(t, time.Minute, ttl, float64(time.Second))
+}
+
+func TestExpireAtOnSLRU(t *testing.T) {
+	now := time.Unix(1000, 0)
+	cache := newSLRU[int, int](10, WithClock[int, int](func() time.Time { return now }))
+	cache.SetWithExpireAt(1, 1, now.Add(time.Minute))
+	cache.SetWithExpireAt(2, 2, time.Time{})
+	ttl, ok := cache.TTL(1)
+	require.True(t, ok)
+	require.Equal(t, time.Minute, ttl)
+	ttl, ok = cache.TTL(2)
+	require.True(t, ok)
+	require.Zero(t, ttl)
+
+	cache.SetWithExpireAt(2, 2, now)
+	require.False(t, cache.Contains(2))
+	now = now.Add(time.Minute)
+	require.False(t, cache.Contains(1))
+}
+
+func TestSegmentTTLsOnSLRU(t *testing.T) {
+	now := time.Unix(1000, 0)
+	cache := New[int, int](100,
+		WithClock[int, int](func() time.Time { return now }),
+		WithSegmentTTLs[int, int](time.Minute, time.Hour))
+	cache.Set(1, 1)
+	cache.Set(2, 2)
+	cache.SetWithTTL(3, 3, 2*time.Hour)
+	ttl, _ := cache.TTL(1)
+	require.Equal(t, time.Minute, ttl)
+
+	now = now.Add(30 * time.Second)
+	cache.Get(1)
+	cache.Get(3)
+	ttl, _ = cache.TTL(1)
+	require.Equal(t, time.Hour, ttl)
+	ttl, _ = cache.TTL(3)
+	require.Equal(t, 2*time.Hour-30*time.Second, ttl)
+
+	now = now.Add(30 * time.Second)
+	require.False(t, cache.Contains(2))
+	require.True(t, cache.Contains(1))
+	cache.Set(1, 10)
+	ttl, _ = cache.TTL(1)
+	require.Equal(t, time.Hour, ttl)
+}
+
+func TestTTLFuncOnSLRU(t *testing.T) {
+	cache := New[string, int](100, WithTTL[string, int](time.Hour), WithTTLFunc(func(key string, value int) time.Duration {
+		return time.Duration(value) * time.Minute
+	}))
+	cache.Set("a", 5)
+	cache.Set("b", 0)
+	cache.SetWithTTL("c", 5, time.Second)
+	ttl, _ := cache.TTL("a")
+	require.InDelta(t, 5*time.Minute, ttl, float64(time.Second))
+	ttl, _ = cache.TTL("b")
+	require.Zero(t, ttl)
+	ttl, _ = cache.TTL("c")
+	require.LessOrEqual(t, ttl, time.Second)
+}
+
+func TestPreservedTTLOnSLRU(t *testing.T) {
+	now := time.Unix(1000, 0)
+	clock := WithClock[int, int](func() time.Time { return now })
+	reset := New[int, int](100, clock, WithTTL[int, int](time.Minute))
+	preserved := New[int, int](100, clock, WithTTL[int, int](time.Minute), WithPreservedTTL[int, int]())
+	for _, cache := range []Cache[int, int]{reset, preserved} {
+		cache.Set(1, 1)
+		cache.SetWithTTL(2, 2, time.Hour)
+	}
+
+	now = now.Add(30 * time.Second)
+	for _, cache := range []Cache[int, int]{reset, preserved} {
+		cache.Set(1, 10)
+		cache.Set(2, 20)
+	}
+	ttl, _ := reset.TTL(1)
+	require.Equal(t, time.Minute, ttl)
+	ttl, _ = reset.TTL(2)
+	require.Equal(t, time.Minute, ttl)
+	ttl, _ = preserved.TTL(1)
+	require.Equal(t, 30*time.Second, ttl)
+	ttl, _ = preserved.TTL(2)
+	require.Equal(t, time.Hour-30*time.Second, ttl)
+
+	preserved.SetWithTTL(1, 100, time.Hour)
+	ttl, _ = preserved.TTL(1)
+	require.Equal(t, time.Hour, ttl)
+
+	now = now.Add(time.Hour)
+	preserved.Set(1, 1000)
+	ttl, _ = preserved.TTL(1)
+	require.Equal(t, time.Minute, ttl)
+}
+
+func TestTTLJitterOnSLRU(t *testing.T) {
+	now := time.Unix(1000, 0)
+	cache := New[int, int](1000,
+		WithClock[int, int](func() time.Time { return now }),
+		WithTTL[int, int](time.Hour),
+		WithTTLJitter[int, int](0.1))
+	ttls := make(map[time.Duration]bool)
+	for i := range 100 {
+		cache.Set(i, i)
+		ttl, _ := cache.TTL(i)
+		require.LessOrEqual(t, ttl, time.Hour)
+		require.GreaterOrEqual(t, ttl, 54*time.Minute)
+		ttls[ttl] = true
+	}
+	require.Greater(t, len(ttls), 50)
+
+	at := now.Add(time.Minute)
+	cache.(*SLRU[int, int]).SetWithExpireAt(0, 0, at)
+	ttl, _ := cache.TTL(0)
+	require.Equal(t, time.Minute, ttl)
+}
+
+func TestGetStaleOnSLRU(t *testing.T) {
+	now := time.Unix(1000, 0)
+	cache := newSLRU[int, int](100, WithClock[int, int](func() time.Time { return now }))
+	_, _, ok := cache.GetStale(1)
+	require.False(t, ok)
+
+	cache.SetWithTTL(1, 1, time.Minute)
+	v, staleFor, ok := cache.GetStale(1)
+	require.True(t, ok)
+	require.Equal(t, 1, v)
+	require.Zero(t, staleFor)
+
+	now = now.Add(90 * time.Second)
+	v, staleFor, ok = cache.GetStale(1)
+	require.True(t, ok)
+	require.Equal(t, 1, v)
+	require.Equal(t, 30*time.Second, staleFor)
+	_, ok = cache.Get(1)
+	require.False(t, ok)
+	_, _, ok = cache.GetStale(1)
+	require.False(t, ok)
+
+	cache.Set(2, 2)
+	cache.InvalidateBefore(cache.NewGeneration())
+	_, _, ok = cache.GetStale(2)
+	require.False(t, ok)
+}
+
+func TestCompareAndSwapOnSLRU(t *testing.T) {
+	cache := New[int, int](10)
+	require.False(t, cache.CompareAndSwap(1, 0, 1))
+	require.False(t, cache.Contains(1))
+
+	cache.Set(1, 1)
+	require.False(t, cache.CompareAndSwap(1, 2, 3))
+	require.True(t, cache.CompareAndSwap(1, 1, 2))
+	v, _ := cache.Peek(1)
+	require.Equal(t, 2, v)
+
+	slices := New[int, []int](10, WithEqual[int](func(a, b []int) bool {
+		return len(a) == len(b) && (len(a) == 0 || &a[0] == &b[0])
+	}))
+	old := []int{1}
+	slices.Set(1, old)
+	require.True(t, slices.CompareAndSwap(1, old, []int{2}))
+	require.False(t, slices.CompareAndSwap(1, old, []int{3}))
+}
+
+func TestUpdateOnSLRU(t *testing.T) {
+	cache := New[string, int](10)
+	incr := func(old int, exists bool) (int, bool) {
+		return old + 1, true
+	}
+
+	v, ok := cache.Update("n", incr)
+	require.True(t, ok)
+	require.Equal(t, 1, v)
+	v, _ = cache.Update("n", incr)
+	require.Equal(t, 2, v)
+
+	// declining to store leaves the cache untouched
+	v, ok = cache.Update("m", func(old int, exists bool) (int, bool) {
+		require.False(t, exists)
+		return 0, false
+	})
+	require.False(t, ok)
+	require.Zero(t, v)
+	require.False(t, cache.Contains("m"))
+
+	v, ok = cache.Update("n", func(old int, exists bool) (int, bool) {
+		return 0, false
+	})
+	require.True(t, ok)
+	require.Equal(t, 2, v)
+}
+
+func TestAddOnSLRU(t *testing.T) {
+	cache := New[int, int](10)
+	require.True(t, cache.Add(1, 1))
+	require.False(t, cache.Add(1, 2))
+	v, _ := cache.Peek(1)
+	require.Equal(t, 2, v)
+
+	// an expired entry is replaced as if it was absent
+	cache.SetWithTTL(2, 2, time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	require.True(t, cache.Add(2, 3))
+}
+
+func TestReplaceOnSLRU(t *testing.T) {
+	cache := New[int, int](10)
+	require.False(t, cache.Replace(1, 1))
+	require.False(t, cache.Contains(1))
+
+	cache.Set(1, 1)
+	require.True(t, cache.Replace(1, 2))
+	v, _ := cache.Peek(1)
+	require.Equal(t, 2, v)
+
+	cache.Remove(1)
+	require.False(t, cache.Replace(1, 3))
+	require.False(t, cache.Contains(1))
+}
+
+func TestClonerOnSLRU(t *testing.T) {
+	cache := New[int, []int](10, WithCloner[int](func(v []int) []int {
+		return append([]int(nil), v...)
+	}))
+	cache.Set(1, []int{1, 2})
+
+	v, _ := cache.Get(1)
+	v[0] = 100
+	v, _ = cache.Peek(1)
+	require.Equal(t, []int{1, 2}, v)
+}
+
+func TestSwapOnSLRU(t *testing.T) {
+	cache := New[int, int](10)
+	old, existed := cache.Swap(1, 1)
+	require.False(t, existed)
+	require.Zero(t, old)
+
+	old, existed = cache.Swap(1, 2)
+	require.True(t, existed)
+	require.Equal(t, 1, old)
+	v, _ := cache.Peek(1)
+	require.Equal(t, 2, v)
+}
+
+func TestTinyCapacitiesOnSLRU(t *testing.T) {
+	for size := 1; size <= 4; size++ {
+		cache := New[int, int](size)
+
+		cache.Set(1, 1)
+		v, ok := cache.Get(1)
+		require.True(t, ok, "size %d", size)
+		require.Equal(t, 1, v)
+
+		// with a protected segment, a hot entry survives one-off inserts
+		for i := 2; i < 10; i++ {
+			cache.Set(i, i)
+			cache.Get(1)
+		}
+		require.Equal(t, size > 1, cache.Contains(1), "size %d", size)
+		require.True(t, cache.Contains(9), "size %d", size)
+		require.LessOrEqual(t, cache.Len(), size)
+	}
+}
+
+func TestGenerationsOnSLRU(t *testing.T) {
+	cache := New[int, int](20)
+	cache.Set(1, 1)
+	cache.Set(2, 2)
+
+	gen := cache.NewGeneration()
+	cache.Set(3, 3)
+	cache.Set(2, 20)
+	cache.InvalidateBefore(gen)
+
+	require.False(t, cache.Contains(1))
+	_, ok := cache.Get(1)
+	require.False(t, ok)
+	v, ok := cache.Get(2)
+	require.True(t, ok)
+	require.Equal(t, 20, v)
+	require.True(t, cache.Contains(3))
+
+	// invalidating an older generation again is a no-op
+	cache.InvalidateBefore(gen - 1)
+	require.True(t, cache.Contains(3))
+}
+
+func TestTotalCapacityOnSLRU(t *testing.T) {
+	cache := New[int, int](10, WithTotalCapacity[int, int]())
+
+	// probation borrows the empty protected budget
+	for i := 0; i < 10; i++ {
+		cache.Set(i, i)
+	}
+	require.Equal(t, 10, cache.Len())
+
+	// hits move entries to protected without changing the total
+	cache.Get(0)
+	cache.Get(1)
+	require.Equal(t, 10, cache.Len())
+
+	// victims come from probation first
+	cache.Set(10, 10)
+	require.Equal(t, 10, cache.Len())
+	require.False(t, cache.Contains(2))
+	require.True(t, cache.Contains(0))
+	require.True(t, cache.Contains(1))
+}
+
+func TestLoggerOnSLRU(t *testing.T) {
+	var buf strings.Builder
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	cache := New[int, int](10, WithLogger[int, int](logger))
+
+	cache.Set(1, 1)
+	cache.Set(2, 2)
+	cache.Set(3, 3)
+	cache.Resize(20)
+	cache.Purge()
+
+	out := buf.String()
+	require.Contains(t, out, `level=DEBUG msg="slru: evict" key=1 segment=probation`)
+	require.Contains(t, out, `level=INFO msg="slru: resize" from=10 to=20 evicted=0`)
+	require.Contains(t, out, `level=INFO msg="slru: purge"`)
+}
+
+func TestEvictCallbackReentrant(t *testing.T) {
+	var cache Cache[int, int]
+	var evicted []int
+	onEvict := func(key, value int) {
+		// the cache is unlocked: calling it, even its other shards, doesn't
+		// deadlock
+		require.False(t, cache.Contains(key))
+		require.LessOrEqual(t, cache.Len(), 10)
+		evicted = append(evicted, key)
+	}
+	cache = newSLRU[int, int](10, WithEvictCallback(onEvict))
+	for i := range 20 {
+		cache.Set(i, i)
+	}
+	cache.Resize(5)
+	require.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18}, evicted)
+
+	evicted = nil
+	sharded := NewSharded[int, int](10, 2, nil, WithEvictCallback(onEvict))
+	cache = sharded
+	require.NoError(t, sharded.Tx(func(tx *Txn[int, int]) error {
+		for i := range 20 {
+			tx.Set(i, i)
+		}
+		return nil
+	}))
+	require.Len(t, evicted, 20-cache.Len())
+}
+
+func TestPurgeTearsDownInBackground(t *testing.T) {
+	release := make(chan struct{})
+	var purged []int
+	cache := newSLRU[int, int](10, WithEvictCallback(func(key int, value int) {
+		<-release
+		purged = append(purged, key)
+	}))
+	cache.Set(1, 1)
+	cache.Set(2, 2)
+
+	cache.Purge()
+	require.Zero(t, cache.Len())
+	cache.Set(3, 3)
+	require.True(t, cache.Contains(3))
+
+	close(release)
+	cache.teardown.Wait()
+	require.Equal(t, []int{1, 2}, purged)
+}
+
+func TestLockKey(t *testing.T) {
+	cache := New[string, int](10)
+	cache.Set("a", 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock := cache.LockKey("a")
+			defer unlock()
+			v, _ := cache.Get("a")
+			cache.Set("a", v+1)
+		}()
+	}
+	wg.Wait()
+
+	v, _ := cache.Get("a")
+	require.Equal(t, 50, v)
+}
+
+func TestConcurrentHitsAcrossSegments(t *testing.T) {
+	cache := newSLRU[int, int](100)
+	for i := 0; i < 50; i++ {
+		cache.Set(i, i)
+		cache.Get(i)
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				switch g % 4 {
+				case 0:
+					cache.Get(i % 50)
+				case 1:
+					cache.Set(100+i%40, i)
+				case 2:
+					cache.Get(100 + i%40)
+				case 3:
+					cache.Keys()
+					cache.DebugState()
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	require.NoError(t, cache.Verify())
+}
+
+func TestInitialCapacity(t *testing.T) {
+	require.Equal(t, 10, newSLRU[int, int](10).initialSize)
+	require.Zero(t, newSLRU[int, int](10, WithWeigher(func(key, value int) int { return value })).initialSize)
+
+	cache := newSLRU[int, int](10, WithInitialCapacity[int, int](3))
+	require.Equal(t, 3, cache.initialSize)
+	for i := 0; i < 10; i++ {
+		cache.Set(i, i)
+		cache.Get(i)
+	}
+	cache.Purge()
+	cache.Set(1, 1)
+	require.Equal(t, 1, cache.Len())
+}
+
+func TestPreallocation(t *testing.T) {
+	cache := newSLRU[int, int](100, WithPreallocation[int, int](100))
+	for i := 0; i < 1000; i++ {
+		cache.Set(i, i)
+		if i%3 == 0 {
+			cache.Get(i)
+		}
+	}
+	require.NoError(t, cache.Verify())
+
+	i := 1000
+	allocs := testing.AllocsPerRun(1000, func() {
+		cache.Set(i, i)
+		cache.Get(i)
+		i++
+	})
+	require.Zero(t, allocs)
+
+	v, ok := cache.Get(i - 1)
+	require.True(t, ok)
+	require.Equal(t, i-1, v)
+
+	// shards split the entries
+	sharded := NewSharded[int, int](100, 4, nil, WithPreallocation[int, int](100))
+	for _, shard := range sharded.shards {
+		require.Len(t, shard.free, 25)
+	}
+}
+
+func TestLowWatermark(t *testing.T) {
+	cache := New[int, int](100, WithLowWatermark[int, int](0.5))
+	for i := 0; i < 20; i++ {
+		cache.Set(i, i)
+	}
+	require.Equal(t, 20, cache.Len())
+
+	// overflowing probation's 20 entries evicts down to 10
+	cache.Set(20, 20)
+	require.Equal(t, 10, cache.Len())
+	require.Equal(t, uint64(11), cache.Stats().Evictions)
+	require.True(t, cache.Contains(20))
+	require.False(t, cache.Contains(10))
+	require.True(t, cache.Contains(11))
+
+	// the newest entry survives even when it alone is above the watermark
+	tiny := New[int, int](1, WithLowWatermark[int, int](0.5))
+	tiny.Set(1, 1)
+	tiny.Set(2, 2)
+	require.True(t, tiny.Contains(2))
+}
+
+func TestEvictionBatch(t *testing.T) {
+	cache := New[int, int](100, WithEvictionBatch[int, int](5))
+	for i := 0; i < 20; i++ {
+		cache.Set(i, i)
+	}
+	cache.Set(20, 20)
+	require.Equal(t, 16, cache.Len())
+	for i := 21; i < 25; i++ {
+		cache.Set(i, i)
+	}
+	require.Equal(t, uint64(5), cache.Stats().Evictions)
+
+	// the batch never takes the newest entry
+	tiny := New[int, int](2, WithEvictionBatch[int, int](5))
+	tiny.Set(1, 1)
+	tiny.Set(2, 2)
+	require.True(t, tiny.Contains(2))
+}
+
+func TestClockOnSLRU(t *testing.T) {
+	now := time.Unix(0, 0)
+	cache := New[int, int](10, WithClock[int, int](func() time.Time { return now }))
+	cache.SetWithTTL(1, 1, time.Minute)
+	require.True(t, cache.Contains(1))
+	ttl, ok := cache.TTL(1)
+	require.True(t, ok)
+	require.Equal(t, time.Minute, ttl)
+
+	now = now.Add(time.Minute)
+	require.False(t, cache.Contains(1))
+}
+
+func TestShardsOnSLRU(t *testing.T) {
+	_, ok := New[int, int](10).(*SLRU[int, int])
+	require.True(t, ok)
+	_, ok = New[int, int](10, WithShards[int, int](1, nil)).(*SLRU[int, int])
+	require.True(t, ok)
+
+	cache, ok := New[int, int](1024, WithShards[int, int](3, nil), WithProbationRatio[int, int](0.5)).(*Sharded[int, int])
+	require.True(t, ok)
+	require.Len(t, cache.shards, 4)
+	for i := range 64 {
+		cache.Set(i, i)
+	}
+	require.Equal(t, 64, cache.Len())
+}
+
+func TestStringOnSLRU(t *testing.T) {
+	cache := New[int, int](10)
+	for i := range 3 {
+		cache.Set(i, i)
+	}
+	cache.Get(1)
+	cache.Get(9)
+	require.Equal(t, "SLRU(size 10: probation 1/2, protected 1/8, hit ratio 50.0%)", fmt.Sprint(cache))
+
+	sharded := NewSharded[int, int](20, 2, nil)
+	sharded.Set(1, 1)
+	require.Equal(t, "Sharded(2 shards, size 20: probation 1/4, protected 0/16, hit ratio 0.0%)", sharded.String())
+}
@@ -0,0 +1,141 @@
+package slru
+
+import (
+	"time"
+
+	"github.com/hey-kong/slru/list"
+)
+
+// ReadOnlyCache is a read-only view of a cache, such as a Snapshot.
+type ReadOnlyCache[K comparable, V any] interface {
+	// Get gets the value for the given key.
+	Get(key K) (value V, ok bool)
+
+	// Contains checks if a key exists.
+	Contains(key K) (ok bool)
+
+	// TTL returns the remaining time-to-live of the given key, which is zero
+	// if it never expires.
+	TTL(key K) (ttl time.Duration, ok bool)
+
+	// Keys returns the keys, from the next victim to the hottest.
+	Keys() []K
+
+	// Len returns the number of entries.
+	Len() int
+
+	// Range calls fn for each entry, in the order of Keys, until fn returns
+	// false.
+	Range(fn func(key K, value V) bool)
+}
+
+// frozen is a copy of the live entries of a cache at a time.
+type frozen[K comparable, V any] struct {
+	at      time.Time
+	entries []frozenEntry[K, V]
+	index   map[K]int
+	clone   func(value V) V
+}
+
+type frozenEntry[K comparable, V any] struct {
+	key      K
+	value    V
+	expireAt time.Time
+}
+
+// copyEntries appends the live entries of s to snap, from the next victim
+// to the hottest. The caller holds the lock of s, shared.
+func (s *SLRU[K, V]) copyEntries(snap *frozen[K, V]) {
+	defer s.lockSegments()()
+
+	for _, l := range s.segments() {
+		l.EachReverse(func(e *list.Element) bool {
+			if ent := e.Value.(*entry[K, V]); !s.dead(ent, snap.at) {
+				snap.index[ent.key] = len(snap.entries)
+				snap.entries = append(snap.entries, frozenEntry[K, V]{ent.key, ent.value, ent.expireAt})
+			}
+			return true
+		})
+	}
+}
+
+// Snapshot returns a consistent view of the cache as it is now, frozen
+// while the cache goes on serving, to report on or export its contents.
+// Taking it copies the live entries under the shared lock, holding off
+// writers for that copy only; reading it takes no lock. Entries expiring
+// later stay in the view, with the TTL they had when it was taken.
+func (s *SLRU[K, V]) Snapshot() ReadOnlyCache[K, V] {
+	defer s.region("snapshot")()
+	s.acquireShared()
+	defer s.lock.RUnlock()
+
+	snap := &frozen[K, V]{at: s.now(), index: make(map[K]int, len(s.items)), clone: s.clone}
+	snap.entries = make([]frozenEntry[K, V], 0, len(s.items))
+	s.copyEntries(snap)
+	return snap
+}
+
+// Snapshot is SLRU.Snapshot across shards, holding off writers to every
+// shard while it copies them, so the view is consistent across shards.
+func (c *Sharded[K, V]) Snapshot() ReadOnlyCache[K, V] {
+	defer c.region("snapshot")()
+	for _, s := range c.shards {
+		s.acquireShared()
+	}
+	defer func() {
+		for _, s := range c.shards {
+			s.lock.RUnlock()
+		}
+	}()
+
+	first := c.shards[0]
+	snap := &frozen[K, V]{at: first.now(), index: make(map[K]int), clone: first.clone}
+	for _, s := range c.shards {
+		s.copyEntries(snap)
+	}
+	return snap
+}
+
+func (s *frozen[K, V]) Get(key K) (value V, ok bool) {
+	i, ok := s.index[key]
+	if !ok {
+		return value, false
+	}
+	return s.clone(s.entries[i].value), true
+}
+
+func (s *frozen[K, V]) Contains(key K) (ok bool) {
+	_, ok = s.index[key]
+	return ok
+}
+
+func (s *frozen[K, V]) TTL(key K) (ttl time.Duration, ok bool) {
+	i, ok := s.index[key]
+	if !ok {
+		return 0, false
+	}
+	if at := s.entries[i].expireAt; !at.IsZero() {
+		ttl = at.Sub(s.at)
+	}
+	return ttl, true
+}
+
+func (s *frozen[K, V]) Keys() []K {
+	keys := make([]K, len(s.entries))
+	for i, ent := range s.entries {
+		keys[i] = ent.key
+	}
+	return keys
+}
+
+func (s *frozen[K, V]) Len() int {
+	return len(s.entries)
+}
+
+func (s *frozen[K, V]) Range(fn func(key K, value V) bool) {
+	for _, ent := range s.entries {
+		if !fn(ent.key, s.clone(ent.value)) {
+			return
+		}
+	}
+}
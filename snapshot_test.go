@@ -0,0 +1,51 @@
+package slru
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshot(t *testing.T) {
+	cache := newSLRU[int, int](100)
+	cache.Set(1, 1)
+	cache.SetWithTTL(2, 2, time.Hour)
+	cache.Get(1)
+
+	snap := cache.Snapshot()
+	cache.Set(1, 10)
+	cache.Remove(2)
+	cache.Set(3, 3)
+
+	require.Equal(t, 2, snap.Len())
+	require.Equal(t, []int{2, 1}, snap.Keys())
+	v, ok := snap.Get(1)
+	require.True(t, ok)
+	require.Equal(t, 1, v)
+	require.False(t, snap.Contains(3))
+	ttl, ok := snap.TTL(2)
+	require.True(t, ok)
+	require.Greater(t, ttl, time.Minute)
+
+	var keys []int
+	snap.Range(func(key, value int) bool {
+		keys = append(keys, key)
+		return false
+	})
+	require.Equal(t, []int{2}, keys)
+}
+
+func TestSnapshotOnSharded(t *testing.T) {
+	cache := NewSharded[int, int](1000, 4, nil)
+	for i := range 100 {
+		cache.Set(i, i)
+	}
+	snap := cache.Snapshot()
+	cache.Purge()
+	require.Equal(t, 100, snap.Len())
+	require.Zero(t, cache.Len())
+	v, ok := snap.Get(42)
+	require.True(t, ok)
+	require.Equal(t, 42, v)
+}
@@ -0,0 +1,96 @@
+package slru
+
+import (
+	"log/slog"
+	"runtime"
+	"runtime/debug"
+	"runtime/metrics"
+)
+
+// softThreshold is the share of the memory limit above which the heap is
+// considered under pressure by default.
+const softThreshold = 0.9
+
+// WithSoftValues makes the cache give up entries when memory runs short,
+// trading hit ratio for staying under the memory limit. After each garbage
+// collection that finds the heap under pressure, it evicts all of
+// probation and then protected down to floor, a share of its size.
+//
+// pressure reports whether memory is short; if nil, the heap is under
+// pressure when live objects take over 90% of the limit set with
+// debug.SetMemoryLimit or GOMEMLIMIT. The cache then stays reachable for
+// the rest of the program, as the collector keeps signalling it.
+func WithSoftValues[K comparable, V any](floor float64, pressure func() bool) Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		if pressure == nil {
+			pressure = heapPressure
+		}
+		s.softFloor = floor
+		s.softPressure = pressure
+	}
+}
+
+// startSoft sheds entries after collections under pressure, if enabled.
+func (s *SLRU[K, V]) startSoft() {
+	if s.softPressure == nil {
+		return
+	}
+	onGC(func() bool {
+		select {
+		case <-s.done:
+			return false
+		default:
+		}
+		if s.softPressure() {
+			go s.maintain(func() { s.Shed(s.softFloor) })
+		}
+		return true
+	})
+}
+
+// Shed evicts every probation entry and protected entries beyond floor, a
+// share of the protected size, returning the number of evicted entries.
+func (s *SLRU[K, V]) Shed(floor float64) (evicted int) {
+	defer s.region("shed")()
+	s.acquire()
+	defer s.unlock()
+
+	for s.probation.Len() > 0 {
+		s.evict(s.probation)
+		evicted++
+	}
+	for s.protected.Len() > 0 && float64(s.protectedWeight) > floor*float64(s.protectedSize) {
+		s.evict(s.protected)
+		evicted++
+	}
+	s.log(slog.LevelInfo, "slru: shed", "evicted", evicted)
+	return evicted
+}
+
+// gcSignal calls fn after every garbage collection by re-arming a
+// finalizer on a fresh sentinel each time it runs, until fn returns false.
+type gcSignal struct {
+	fn func() bool
+}
+
+func onGC(fn func() bool) {
+	runtime.SetFinalizer(&gcSignal{fn: fn}, rearm)
+}
+
+func rearm(g *gcSignal) {
+	if g.fn() {
+		runtime.SetFinalizer(&gcSignal{fn: g.fn}, rearm)
+	}
+}
+
+// heapPressure reports whether live heap objects exceed softThreshold of
+// the memory limit, and never if no limit is set.
+func heapPressure() bool {
+	limit := debug.SetMemoryLimit(-1)
+	samples := []metrics.Sample{{Name: "/memory/classes/heap/objects:bytes"}}
+	metrics.Read(samples)
+	if samples[0].Value.Kind() != metrics.KindUint64 {
+		return false
+	}
+	return float64(samples[0].Value.Uint64()) > softThreshold*float64(limit)
+}
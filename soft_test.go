@@ -0,0 +1,47 @@
+package slru
+
+import (
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestShed(t *testing.T) {
+	cache := New[int, int](100).(*SLRU[int, int])
+	for i := 0; i < 50; i++ {
+		cache.Set(i, i)
+		if i < 40 {
+			cache.Get(i)
+		}
+	}
+	require.Equal(t, 50, cache.Len())
+
+	// probation goes first, protected keeps a quarter of its 80 entries
+	require.Equal(t, 30, cache.Shed(0.25))
+	require.Equal(t, 20, cache.Len())
+	require.False(t, cache.Contains(45))
+	require.True(t, cache.Contains(39))
+	require.Zero(t, cache.Shed(0.25))
+}
+
+func TestSoftValues(t *testing.T) {
+	var pressure atomic.Bool
+	cache := New[int, int](100, WithSoftValues[int, int](0, pressure.Load))
+	for i := 0; i < 10; i++ {
+		cache.Set(i, i)
+	}
+
+	runtime.GC()
+	time.Sleep(10 * time.Millisecond)
+	require.Equal(t, 10, cache.Len())
+
+	pressure.Store(true)
+	require.Eventually(t, func() bool {
+		runtime.GC()
+		return cache.Len() == 0
+	}, time.Second, 10*time.Millisecond)
+	require.False(t, heapPressure())
+}
@@ -0,0 +1,130 @@
+// Package sqlcache caches database/sql query results in an SLRU, keyed by the
+// normalized SQL text and its arguments.
+package sqlcache
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hey-kong/slru"
+)
+
+// Result is a materialized query result. It is shared by every caller that
+// hits the same cache entry and must not be modified.
+type Result struct {
+	Columns []string
+	Rows    [][]any
+}
+
+// DB is a thin caching layer over a *sql.DB.
+type DB struct {
+	db    *sql.DB
+	cache slru.Cache[string, *Result]
+	ttl   time.Duration
+
+	// OnExec is called after each successful Exec, so writes can invalidate
+	// the queries they affect. If nil, every cached result is invalidated.
+	OnExec func(db *DB, query string, args []any)
+}
+
+// New returns a DB caching up to size query results for ttl each, where a
+// non-positive ttl never expires.
+func New(db *sql.DB, size int, ttl time.Duration) *DB {
+	return &DB{
+		db:    db,
+		cache: slru.New[string, *Result](size),
+		ttl:   ttl,
+	}
+}
+
+// DB returns the underlying *sql.DB.
+func (d *DB) DB() *sql.DB {
+	return d.db
+}
+
+// Query returns the result of query, served from cache when possible.
+func (d *DB) Query(ctx context.Context, query string, args ...any) (*Result, error) {
+	key := Key(query, args...)
+	if r, ok := d.cache.Get(key); ok {
+		return r, nil
+	}
+
+	rows, err := d.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	r := &Result{}
+	if r.Columns, err = rows.Columns(); err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		row := make([]any, len(r.Columns))
+		dest := make([]any, len(row))
+		for i := range row {
+			dest[i] = &row[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		r.Rows = append(r.Rows, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	d.cache.SetWithTTL(key, r, d.ttl)
+	return r, nil
+}
+
+// Exec executes a statement without caching and then runs the OnExec hook.
+func (d *DB) Exec(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	res, err := d.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	if d.OnExec != nil {
+		d.OnExec(d, query, args)
+	} else {
+		d.InvalidateAll()
+	}
+	return res, nil
+}
+
+// Invalidate drops the cached result of query with args.
+func (d *DB) Invalidate(query string, args ...any) {
+	d.cache.Remove(Key(query, args...))
+}
+
+// InvalidateAll drops every cached result.
+func (d *DB) InvalidateAll() {
+	d.cache.Purge()
+}
+
+// Key returns the cache key of query with args. Queries differing only in
+// surrounding whitespace or a trailing semicolon share a key. The query
+// and the type and value of each arg are length-prefixed, so no query or
+// arg can pass for others.
+func Key(query string, args ...any) string {
+	var b strings.Builder
+	field := func(s string) {
+		fmt.Fprintf(&b, "%d:%s", len(s), s)
+	}
+	field(Normalize(query))
+	for _, arg := range args {
+		field(fmt.Sprintf("%T", arg))
+		field(fmt.Sprintf("%v", arg))
+	}
+	return b.String()
+}
+
+// Normalize trims the whitespace around query and a trailing semicolon.
+// Whitespace within query is kept, as it may be part of a string literal.
+func Normalize(query string) string {
+	query = strings.TrimSpace(query)
+	return strings.TrimSpace(strings.TrimSuffix(query, ";"))
+}
@@ -0,0 +1,101 @@
+package sqlcache
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// countingDriver answers every query with a single row holding the number of
+// queries run so far. It is its own Connector, to open databases without
+// registering it.
+type countingDriver struct {
+	queries int
+}
+
+func (d *countingDriver) Open(name string) (driver.Conn, error) { return &conn{d}, nil }
+
+func (d *countingDriver) Connect(context.Context) (driver.Conn, error) { return &conn{d}, nil }
+func (d *countingDriver) Driver() driver.Driver                        { return d }
+
+type conn struct{ d *countingDriver }
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) { return &stmt{c.d}, nil }
+func (c *conn) Close() error                              { return nil }
+func (c *conn) Begin() (driver.Tx, error)                 { return nil, driver.ErrSkip }
+
+type stmt struct{ d *countingDriver }
+
+func (s *stmt) Close() error  { return nil }
+func (s *stmt) NumInput() int { return -1 }
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(1), nil
+}
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.d.queries++
+	return &rows{n: int64(s.d.queries)}, nil
+}
+
+type rows struct {
+	n    int64
+	done bool
+}
+
+func (r *rows) Columns() []string { return []string{"n"} }
+func (r *rows) Close() error      { return nil }
+func (r *rows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = r.n
+	return nil
+}
+
+func TestQueryCache(t *testing.T) {
+	d := &countingDriver{}
+	db := sql.OpenDB(d)
+	defer db.Close()
+
+	ctx := context.Background()
+	cache := New(db, 10, time.Minute)
+
+	r, err := cache.Query(ctx, "SELECT n FROM t WHERE id = ?", 1)
+	require.NoError(t, err)
+	require.Equal(t, []string{"n"}, r.Columns)
+	require.Equal(t, [][]any{{int64(1)}}, r.Rows)
+
+	r, err = cache.Query(ctx, "\n  SELECT n FROM t WHERE id = ?;", 1)
+	require.NoError(t, err)
+	require.Equal(t, [][]any{{int64(1)}}, r.Rows)
+	require.Equal(t, 1, d.queries)
+
+	_, err = cache.Query(ctx, "SELECT n FROM t WHERE id = ?", 2)
+	require.NoError(t, err)
+	require.Equal(t, 2, d.queries)
+
+	cache.Invalidate("SELECT n FROM t WHERE id = ?", 1)
+	r, err = cache.Query(ctx, "SELECT n FROM t WHERE id = ?", 1)
+	require.NoError(t, err)
+	require.Equal(t, [][]any{{int64(3)}}, r.Rows)
+
+	_, err = cache.Exec(ctx, "UPDATE t SET n = 0")
+	require.NoError(t, err)
+	_, err = cache.Query(ctx, "SELECT n FROM t WHERE id = ?", 2)
+	require.NoError(t, err)
+	require.Equal(t, 4, d.queries)
+}
+
+func TestKey(t *testing.T) {
+	require.Equal(t, Key("SELECT 1"), Key("  SELECT 1;\n"))
+	require.NotEqual(t, Key("SELECT * FROM t WHERE name = 'a  b'"), Key("SELECT * FROM t WHERE name = 'a b'"))
+	require.NotEqual(t, Key("q", "x\x00int:1"), Key("q", "x", 1))
+	require.NotEqual(t, Key("q", "1:x"), Key("q", 1, "x"))
+	require.NotEqual(t, Key("q\x006:string1:x"), Key("q", "x"))
+	require.Equal(t, Key("q", 1, "x"), Key("q", 1, "x"))
+}
@@ -0,0 +1,298 @@
+package slru
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/bits"
+	"math/rand/v2"
+	"sync/atomic"
+	"time"
+)
+
+// Histogram counts durations in buckets bounded by powers of two
+// nanoseconds: bucket 0 holds zero durations and bucket i > 0 holds those
+// in [2^(i-1), 2^i) nanoseconds.
+type Histogram struct {
+	Counts [64]uint64
+}
+
+// Bound returns the exclusive upper bound of bucket i.
+func (h *Histogram) Bound(i int) time.Duration {
+	if i >= 63 {
+		return math.MaxInt64
+	}
+	return time.Duration(1) << i
+}
+
+// Count returns the number of observed durations.
+func (h *Histogram) Count() (n uint64) {
+	for _, c := range h.Counts {
+		n += c
+	}
+	return n
+}
+
+// Quantile returns the upper bound of the bucket holding the q-quantile of
+// the observed durations, or zero if there are none.
+func (h *Histogram) Quantile(q float64) time.Duration {
+	n := h.Count()
+	if n == 0 {
+		return 0
+	}
+	rank := uint64(math.Ceil(q * float64(n)))
+	var seen uint64
+	for i, c := range h.Counts {
+		seen += c
+		if seen >= rank && c > 0 {
+			return h.Bound(i)
+		}
+	}
+	return h.Bound(len(h.Counts) - 1)
+}
+
+// Merge adds the counts of o to h.
+func (h *Histogram) Merge(o *Histogram) {
+	for i, c := range o.Counts {
+		h.Counts[i] += c
+	}
+}
+
+// histogramBucket is a non-empty bucket of a Histogram in JSON.
+type histogramBucket struct {
+	Bucket int    `json:"bucket"`
+	Count  uint64 `json:"count"`
+}
+
+// MarshalJSON encodes the non-empty buckets as an array of bucket indexes
+// and counts.
+func (h Histogram) MarshalJSON() ([]byte, error) {
+	buckets := []histogramBucket{}
+	for i, c := range h.Counts {
+		if c > 0 {
+			buckets = append(buckets, histogramBucket{Bucket: i, Count: c})
+		}
+	}
+	return json.Marshal(buckets)
+}
+
+func (h *Histogram) UnmarshalJSON(data []byte) error {
+	var buckets []histogramBucket
+	if err := json.Unmarshal(data, &buckets); err != nil {
+		return err
+	}
+	*h = Histogram{}
+	for _, b := range buckets {
+		if b.Bucket < 0 || b.Bucket >= len(h.Counts) {
+			return fmt.Errorf("slru: histogram bucket %d out of range", b.Bucket)
+		}
+		h.Counts[b.Bucket] += b.Count
+	}
+	return nil
+}
+
+// Stats are cache statistics accumulated since the cache was created.
+type Stats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+
+	// Promotions counts entries moved from probation to protected, and
+	// ProtectedEvictions the evictions out of protected. Probation
+	// evictions of never-hit entries are counted as OneHitWonders.
+	Promotions         uint64
+	ProtectedEvictions uint64
+	OneHitWonders      uint64
+
+	// Demotions counts protected entries aged back to probation.
+	Demotions uint64
+
+	// Expirations counts the expired entries removed by the janitor.
+	Expirations uint64
+
+	// Rejections counts the writes of new keys turned away by admission,
+	// as set with WithAdmissionProbability or WithAdmissionFilter.
+	Rejections uint64
+
+	// Throttled counts the writes of new keys turned away as they would
+	// have evicted past the cap of WithEvictionRateLimit.
+	Throttled uint64
+
+	// EvictionAge and EvictionIdle are the times evicted entries spent in
+	// the cache since they were inserted and since their last hit. Young
+	// evictions suggest the cache is too small.
+	EvictionAge  Histogram
+	EvictionIdle Histogram
+
+	// GetHitLatency, GetMissLatency, SetLatency and EvictCallbackLatency
+	// are operation latencies, recorded with WithLatencyHistograms. They
+	// include the time spent waiting for the cache lock.
+	GetHitLatency        Histogram
+	GetMissLatency       Histogram
+	SetLatency           Histogram
+	EvictCallbackLatency Histogram
+
+	// LockWait is the time spent waiting for the cache lock, sampled with
+	// WithLockWaitSampling.
+	LockWait Histogram
+}
+
+// HitRatio returns the share of lookups that were hits.
+func (s *Stats) HitRatio() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// Merge adds the counters and histograms of o to s, such as those of
+// another instance or shard. The hit ratio of the sum is the ratio over
+// all their lookups, in which each weighs by its traffic.
+func (s *Stats) Merge(o *Stats) {
+	s.Hits += o.Hits
+	s.Misses += o.Misses
+	s.Evictions += o.Evictions
+	s.Promotions += o.Promotions
+	s.ProtectedEvictions += o.ProtectedEvictions
+	s.OneHitWonders += o.OneHitWonders
+	s.Demotions += o.Demotions
+	s.Expirations += o.Expirations
+	s.Rejections += o.Rejections
+	s.Throttled += o.Throttled
+	s.EvictionAge.Merge(&o.EvictionAge)
+	s.EvictionIdle.Merge(&o.EvictionIdle)
+	s.GetHitLatency.Merge(&o.GetHitLatency)
+	s.GetMissLatency.Merge(&o.GetMissLatency)
+	s.SetLatency.Merge(&o.SetLatency)
+	s.EvictCallbackLatency.Merge(&o.EvictCallbackLatency)
+	s.LockWait.Merge(&o.LockWait)
+}
+
+// MergeStats returns the sum of stats.
+func MergeStats(stats ...Stats) (sum Stats) {
+	for i := range stats {
+		sum.Merge(&stats[i])
+	}
+	return sum
+}
+
+// ClusterStats are the Stats of the caches of a fleet, by instance name.
+// They encode to JSON as an object of instances, so collectors can gather
+// them from each instance and dashboards sum them.
+type ClusterStats map[string]Stats
+
+// Total returns the sum of the instance stats.
+func (c ClusterStats) Total() (total Stats) {
+	for _, s := range c {
+		total.Merge(&s)
+	}
+	return total
+}
+
+// HitRatio returns the hit ratio over the lookups of all instances.
+func (c ClusterStats) HitRatio() float64 {
+	total := c.Total()
+	return total.HitRatio()
+}
+
+// Merge adds the stats of the instances of o to those of c, for gathering
+// shards of the same instance or merging regions.
+func (c ClusterStats) Merge(o ClusterStats) {
+	for name, s := range o {
+		sum := c[name]
+		sum.Merge(&s)
+		c[name] = sum
+	}
+}
+
+// counter is a striped atomic counter. Increments spread over cache-line
+// padded stripes so concurrent writers don't contend on one word.
+type counter struct {
+	stripes [8]struct {
+		n atomic.Uint64
+		_ [56]byte
+	}
+}
+
+func (c *counter) inc() {
+	c.stripes[rand.Uint32()%uint32(len(c.stripes))].n.Add(1)
+}
+
+func (c *counter) load() (n uint64) {
+	for i := range c.stripes {
+		n += c.stripes[i].n.Load()
+	}
+	return n
+}
+
+// atomicHistogram is a Histogram that can be observed concurrently.
+type atomicHistogram struct {
+	counts [64]atomic.Uint64
+}
+
+func (h *atomicHistogram) observe(d time.Duration) {
+	h.counts[bits.Len64(uint64(max(d, 0)))].Add(1)
+}
+
+func (h *atomicHistogram) since(start time.Time) {
+	h.observe(time.Since(start))
+}
+
+func (h *atomicHistogram) load() (hist Histogram) {
+	for i := range h.counts {
+		hist.Counts[i] = h.counts[i].Load()
+	}
+	return hist
+}
+
+// stats are the live counters behind Stats. They are updated atomically,
+// so recording them adds no contention to the cache lock, and aggregated
+// when Stats is called.
+type stats struct {
+	hits, misses, evictions   counter
+	promotions                counter
+	protectedEvictions        counter
+	oneHitWonders             counter
+	demotions                 counter
+	expirations               counter
+	rejections                counter
+	throttled                 counter
+	evictionAge, evictionIdle atomicHistogram
+	getHit, getMiss, set      atomicHistogram
+	evictCallback             atomicHistogram
+	lockWait                  atomicHistogram
+}
+
+func (s *stats) load() Stats {
+	return Stats{
+		Hits:                 s.hits.load(),
+		Misses:               s.misses.load(),
+		Evictions:            s.evictions.load(),
+		Promotions:           s.promotions.load(),
+		ProtectedEvictions:   s.protectedEvictions.load(),
+		OneHitWonders:        s.oneHitWonders.load(),
+		Demotions:            s.demotions.load(),
+		Expirations:          s.expirations.load(),
+		Rejections:           s.rejections.load(),
+		Throttled:            s.throttled.load(),
+		EvictionAge:          s.evictionAge.load(),
+		EvictionIdle:         s.evictionIdle.load(),
+		GetHitLatency:        s.getHit.load(),
+		GetMissLatency:       s.getMiss.load(),
+		SetLatency:           s.set.load(),
+		EvictCallbackLatency: s.evictCallback.load(),
+		LockWait:             s.lockWait.load(),
+	}
+}
+
+func (s *SLRU[K, V]) Stats() Stats {
+	return s.stats.load()
+}
+
+func (c *Sharded[K, V]) Stats() (stats Stats) {
+	for _, s := range c.shards {
+		shard := s.Stats()
+		stats.Merge(&shard)
+	}
+	return stats
+}
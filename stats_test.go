@@ -0,0 +1,170 @@
+package slru
+
+import (
+	"encoding/json"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistogram(t *testing.T) {
+	var ah atomicHistogram
+	h := ah.load()
+	require.Zero(t, h.Quantile(0.5))
+
+	ah.observe(0)
+	ah.observe(time.Nanosecond)
+	ah.observe(3 * time.Nanosecond)
+	ah.observe(4 * time.Nanosecond)
+	ah.observe(time.Duration(math.MaxInt64))
+	h = ah.load()
+
+	require.Equal(t, uint64(1), h.Counts[0])
+	require.Equal(t, uint64(1), h.Counts[1])
+	require.Equal(t, uint64(1), h.Counts[2])
+	require.Equal(t, uint64(1), h.Counts[3])
+	require.Equal(t, uint64(1), h.Counts[63])
+	require.Equal(t, uint64(5), h.Count())
+
+	require.Equal(t, 4*time.Nanosecond, h.Quantile(0.6))
+	require.Equal(t, time.Duration(math.MaxInt64), h.Quantile(1))
+}
+
+func TestStatsOnSLRU(t *testing.T) {
+	cache := New[int, int](10)
+	cache.Set(1, 1)
+	cache.Get(1)
+	cache.Get(2)
+	cache.Set(2, 2)
+	cache.Set(3, 3)
+	cache.Set(4, 4)
+
+	stats := cache.Stats()
+	require.Equal(t, uint64(1), stats.Hits)
+	require.Equal(t, uint64(1), stats.Misses)
+	require.Equal(t, 0.5, stats.HitRatio())
+	require.Equal(t, uint64(1), stats.Evictions)
+	require.Equal(t, uint64(1), stats.EvictionAge.Count())
+	require.Less(t, stats.EvictionAge.Quantile(1), time.Second)
+	require.Equal(t, uint64(1), stats.EvictionIdle.Count())
+}
+
+func TestTransitionStats(t *testing.T) {
+	// probation holds 1 entry and protected 2
+	cache := New[int, int](3, WithProbationRatio[int, int](0.3))
+	for i := 1; i <= 3; i++ {
+		cache.Set(i, i)
+		cache.Get(i)
+	}
+	cache.Set(4, 4)
+	cache.Set(5, 5)
+
+	stats := cache.Stats()
+	require.Equal(t, uint64(3), stats.Promotions)
+	require.Equal(t, uint64(1), stats.ProtectedEvictions)
+	require.Equal(t, uint64(1), stats.OneHitWonders)
+	require.Equal(t, uint64(2), stats.Evictions)
+}
+
+func TestCounter(t *testing.T) {
+	var c counter
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				c.inc()
+			}
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, uint64(8000), c.load())
+}
+
+func TestStatsOnSharded(t *testing.T) {
+	cache := NewSharded[int, int](100, 4, nil)
+	for i := 0; i < 10; i++ {
+		cache.Set(i, i)
+		cache.Get(i)
+		cache.Get(-i - 1)
+	}
+
+	stats := cache.Stats()
+	require.Equal(t, uint64(10), stats.Hits)
+	require.Equal(t, uint64(10), stats.Misses)
+}
+
+func TestLatencyHistogramsOnSLRU(t *testing.T) {
+	evicted := 0
+	cache := New[int, int](10, WithLatencyHistograms[int, int](), WithEvictCallback(func(key int, value int) {
+		evicted++
+	}))
+	cache.Set(1, 1)
+	cache.Get(1)
+	cache.Get(2)
+	cache.Set(2, 2)
+	cache.Set(3, 3)
+	cache.Set(4, 4)
+
+	stats := cache.Stats()
+	require.Equal(t, 1, evicted)
+	require.Equal(t, uint64(1), stats.GetHitLatency.Count())
+	require.Equal(t, uint64(1), stats.GetMissLatency.Count())
+	require.Equal(t, uint64(4), stats.SetLatency.Count())
+	require.Equal(t, uint64(1), stats.EvictCallbackLatency.Count())
+
+	// latencies are only recorded when enabled
+	stats = New[int, int](10).Stats()
+	require.Zero(t, stats.SetLatency.Count())
+}
+
+func TestLockWaitSampling(t *testing.T) {
+	cache := New[int, int](10, WithLockWaitSampling[int, int](1))
+	cache.Set(1, 1)
+	cache.Get(1)
+	cache.Contains(1)
+	// the hit in probation takes the shared lock, then the exclusive one to
+	// promote
+	stats := cache.Stats()
+	require.Equal(t, uint64(4), stats.LockWait.Count())
+
+	stats = New[int, int](10).Stats()
+	require.Zero(t, stats.LockWait.Count())
+}
+
+func TestClusterStats(t *testing.T) {
+	a := Stats{Hits: 90, Misses: 10, Evictions: 5}
+	a.EvictionAge.Counts[3] = 2
+	b := Stats{Hits: 1, Misses: 9}
+	b.EvictionAge.Counts[3] = 1
+	b.EvictionAge.Counts[10] = 4
+
+	sum := MergeStats(a, b)
+	require.Equal(t, uint64(91), sum.Hits)
+	require.Equal(t, uint64(5), sum.Evictions)
+	require.Equal(t, uint64(3), sum.EvictionAge.Counts[3])
+	require.Equal(t, uint64(7), sum.EvictionAge.Count())
+
+	cluster := ClusterStats{"a": a, "b": b}
+	// weighted by lookups, not the mean of 0.9 and 0.1
+	require.InDelta(t, 91.0/110, cluster.HitRatio(), 1e-9)
+	require.Equal(t, sum, cluster.Total())
+
+	cluster.Merge(ClusterStats{"b": b, "c": a})
+	require.Equal(t, uint64(2), cluster["b"].Hits)
+	require.Len(t, cluster, 3)
+
+	data, err := json.Marshal(cluster)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"EvictionAge":[{"bucket":3,"count":2}]`)
+	var decoded ClusterStats
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, cluster, decoded)
+
+	var h Histogram
+	require.Error(t, json.Unmarshal([]byte(`[{"bucket":64,"count":1}]`), &h))
+}
@@ -0,0 +1,232 @@
+// Package statsd exports the metrics of a cache to a StatsD server, or to
+// a Datadog agent with DogStatsD tags, for services not scraped by other
+// means:
+//
+//	exp, err := statsd.New("127.0.0.1:8125", cache,
+//		statsd.WithPrefix("api.sessions."),
+//		statsd.WithTags("env:prod", "cache:sessions"))
+//	if err != nil { ... }
+//	defer exp.Close()
+//
+// Every flush interval, it sends the counters of Stats as the increments
+// since the last flush, and the entries, hit ratio and latency quantiles
+// as gauges, batched in as few UDP datagrams as fit.
+package statsd
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hey-kong/slru"
+)
+
+// DefaultPrefix is prepended to the metric names by default.
+const DefaultPrefix = "slru."
+
+// DefaultFlushInterval is the time between flushes by default.
+const DefaultFlushInterval = 10 * time.Second
+
+// maxPacketSize bounds the datagrams sent, to fit the MTU of most
+// networks.
+const maxPacketSize = 1432
+
+// Source is the cache whose metrics are exported, such as an slru.Cache.
+type Source interface {
+	Len() int
+	Stats() slru.Stats
+}
+
+// Exporter sends the metrics of a Source to StatsD periodically.
+type Exporter struct {
+	source   Source
+	conn     net.Conn
+	prefix   string
+	tags     []string
+	interval time.Duration
+
+	lock sync.Mutex
+	last slru.Stats
+	buf  bytes.Buffer
+	err  error
+
+	closeOnce sync.Once
+	done      chan struct{}
+	stopped   chan struct{}
+}
+
+// Option configures an Exporter.
+type Option func(*Exporter)
+
+// WithPrefix prepends prefix to the metric names instead of
+// DefaultPrefix.
+func WithPrefix(prefix string) Option {
+	return func(e *Exporter) {
+		e.prefix = prefix
+	}
+}
+
+// WithTags adds the DogStatsD tags, such as "env:prod", to every metric.
+// Plain StatsD servers don't support tags, so leave them out for those.
+func WithTags(tags ...string) Option {
+	return func(e *Exporter) {
+		e.tags = append(e.tags, tags...)
+	}
+}
+
+// WithFlushInterval sends the metrics every interval instead of
+// DefaultFlushInterval. A non-positive interval disables the periodic
+// flushes, leaving them to Flush.
+func WithFlushInterval(interval time.Duration) Option {
+	return func(e *Exporter) {
+		e.interval = interval
+	}
+}
+
+// New returns an Exporter sending the metrics of source to the StatsD
+// server at addr over UDP, starting its periodic flushes. The counters of
+// the first flush are those since the cache was created.
+func New(addr string, source Source, opts ...Option) (*Exporter, error) {
+	e := &Exporter{
+		source:   source,
+		prefix:   DefaultPrefix,
+		interval: DefaultFlushInterval,
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	e.conn = conn
+	go e.run()
+	return e, nil
+}
+
+func (e *Exporter) run() {
+	defer close(e.stopped)
+	if e.interval <= 0 {
+		<-e.done
+		return
+	}
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-e.done:
+			return
+		case <-ticker.C:
+			e.Flush()
+		}
+	}
+}
+
+// Flush sends the metrics now. Errors of the periodic flushes are kept and
+// returned by the next call to Flush or Close.
+func (e *Exporter) Flush() error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	stats := e.source.Stats()
+	last := e.last
+	e.last = stats
+	e.count("hits", stats.Hits-last.Hits)
+	e.count("misses", stats.Misses-last.Misses)
+	e.count("evictions", stats.Evictions-last.Evictions)
+	e.count("promotions", stats.Promotions-last.Promotions)
+	e.count("protected_evictions", stats.ProtectedEvictions-last.ProtectedEvictions)
+	e.count("one_hit_wonders", stats.OneHitWonders-last.OneHitWonders)
+	e.count("demotions", stats.Demotions-last.Demotions)
+	e.count("expirations", stats.Expirations-last.Expirations)
+	e.count("rejections", stats.Rejections-last.Rejections)
+	e.count("throttled", stats.Throttled-last.Throttled)
+
+	e.gauge("entries", strconv.Itoa(e.source.Len()))
+	if hits, misses := stats.Hits-last.Hits, stats.Misses-last.Misses; hits+misses > 0 {
+		e.gauge("hit_ratio", strconv.FormatFloat(float64(hits)/float64(hits+misses), 'f', 4, 64))
+	}
+	e.quantiles("get_hit_latency", &stats.GetHitLatency)
+	e.quantiles("get_miss_latency", &stats.GetMissLatency)
+	e.quantiles("set_latency", &stats.SetLatency)
+	e.quantiles("evict_callback_latency", &stats.EvictCallbackLatency)
+	e.quantiles("lock_wait", &stats.LockWait)
+	e.send()
+
+	err := e.err
+	e.err = nil
+	return err
+}
+
+// Close stops the periodic flushes, flushes a last time and closes the
+// connection. It returns the first error met since the last Flush.
+func (e *Exporter) Close() (err error) {
+	e.closeOnce.Do(func() {
+		close(e.done)
+		<-e.stopped
+		err = e.Flush()
+		if cerr := e.conn.Close(); err == nil {
+			err = cerr
+		}
+	})
+	return err
+}
+
+// count adds a counter increment, unless zero.
+func (e *Exporter) count(name string, n uint64) {
+	if n > 0 {
+		e.metric(name, strconv.FormatUint(n, 10), "c")
+	}
+}
+
+func (e *Exporter) gauge(name, value string) {
+	e.metric(name, value, "g")
+}
+
+// quantiles adds the median and 99th percentile of h, in milliseconds, if
+// it observed anything.
+func (e *Exporter) quantiles(name string, h *slru.Histogram) {
+	if h.Count() == 0 {
+		return
+	}
+	for _, q := range []struct {
+		suffix string
+		q      float64
+	}{{".p50", 0.5}, {".p99", 0.99}} {
+		ms := float64(h.Quantile(q.q)) / float64(time.Millisecond)
+		e.gauge(name+q.suffix, strconv.FormatFloat(ms, 'f', -1, 64))
+	}
+}
+
+// metric adds a metric line to the datagram being filled, sending it first
+// if the line doesn't fit.
+func (e *Exporter) metric(name, value, kind string) {
+	line := fmt.Sprintf("%s%s:%s|%s", e.prefix, name, value, kind)
+	if len(e.tags) > 0 {
+		line += "|#" + strings.Join(e.tags, ",")
+	}
+	if e.buf.Len() > 0 && e.buf.Len()+1+len(line) > maxPacketSize {
+		e.send()
+	}
+	if e.buf.Len() > 0 {
+		e.buf.WriteByte('\n')
+	}
+	e.buf.WriteString(line)
+}
+
+// send sends the datagram being filled, keeping the first error.
+func (e *Exporter) send() {
+	if e.buf.Len() == 0 {
+		return
+	}
+	if _, err := e.conn.Write(e.buf.Bytes()); err != nil && e.err == nil {
+		e.err = err
+	}
+	e.buf.Reset()
+}
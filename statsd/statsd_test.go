@@ -0,0 +1,75 @@
+package statsd
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hey-kong/slru"
+)
+
+// listen returns the address of a UDP server and a function reading the
+// metric lines of its next datagram.
+func listen(t *testing.T) (addr string, read func() []string) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn.LocalAddr().String(), func() []string {
+		buf := make([]byte, 64<<10)
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		n, _, err := conn.ReadFrom(buf)
+		require.NoError(t, err)
+		return strings.Split(string(buf[:n]), "\n")
+	}
+}
+
+func TestExporter(t *testing.T) {
+	addr, read := listen(t)
+	cache := slru.New[int, int](10)
+	exp, err := New(addr, cache, WithFlushInterval(0))
+	require.NoError(t, err)
+
+	cache.Set(1, 1)
+	cache.Get(1)
+	cache.Get(2)
+	require.NoError(t, exp.Flush())
+	require.Equal(t, []string{
+		"slru.hits:1|c",
+		"slru.misses:1|c",
+		"slru.promotions:1|c",
+		"slru.entries:1|g",
+		"slru.hit_ratio:0.5000|g",
+	}, read())
+
+	// counters are the increments since the last flush
+	cache.Get(1)
+	require.NoError(t, exp.Close())
+	require.Equal(t, []string{"slru.hits:1|c", "slru.entries:1|g", "slru.hit_ratio:1.0000|g"}, read())
+	require.NoError(t, exp.Close())
+}
+
+func TestExporterTags(t *testing.T) {
+	addr, read := listen(t)
+	cache := slru.New[int, int](10)
+	exp, err := New(addr, cache, WithPrefix("app."), WithTags("env:test", "cache:a"), WithFlushInterval(10*time.Millisecond))
+	require.NoError(t, err)
+	defer exp.Close()
+
+	require.Equal(t, []string{"app.entries:0|g|#env:test,cache:a"}, read())
+}
+
+func TestExporterSplitsDatagrams(t *testing.T) {
+	addr, read := listen(t)
+	cache := slru.New[int, int](10)
+	exp, err := New(addr, cache, WithFlushInterval(0), WithTags(strings.Repeat("t", 1000)))
+	require.NoError(t, err)
+	defer exp.Close()
+
+	cache.Get(1)
+	require.NoError(t, exp.Flush())
+	require.Len(t, read(), 1)
+	require.Len(t, read(), 1)
+}
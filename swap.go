@@ -0,0 +1,197 @@
+package slru
+
+import (
+	"cmp"
+	"log/slog"
+	"sync"
+
+	"github.com/hey-kong/slru/list"
+)
+
+// SwapContents exchanges the entries of the cache with those of other, in
+// one step: readers see either all the old entries or all the new ones.
+// Build other offline, e.g. with a full reload from the source of truth,
+// then swap it in behind the same handle, leaving the old entries in
+// other to discard. Each cache keeps its configuration and trims the
+// entries it gets to its size. The swap counts as a write of every entry
+// for generations, and ends the leases of both caches.
+func (s *SLRU[K, V]) SwapContents(other *SLRU[K, V]) {
+	if other == s {
+		return
+	}
+	defer s.region("swap")()
+	other.acquire()
+	s.acquire()
+	defer func() {
+		evicted, otherEvicted := s.unlockEvicted(), other.unlockEvicted()
+		s.notify(evicted)
+		other.notify(otherEvicted)
+		s.flushSpills()
+		other.flushSpills()
+	}()
+
+	sMin, oMin := s.minGen, other.minGen
+	s.exchange(other)
+	s.restamp(oMin)
+	other.restamp(sMin)
+	s.reindex()
+	other.reindex()
+	evicted := s.trim()
+	other.trim()
+	s.log(slog.LevelInfo, "slru: swap contents", "entries", len(s.items), "evicted", evicted)
+}
+
+// SwapContents is SLRU.SwapContents across shards, holding every shard of
+// both caches for the swap. Entries move to the shard of their key if the
+// caches hash keys differently. other must have as many shards as c.
+func (c *Sharded[K, V]) SwapContents(other *Sharded[K, V]) {
+	if other == c {
+		return
+	}
+	if len(other.shards) != len(c.shards) {
+		panic("slru: SwapContents of caches with different numbers of shards")
+	}
+	shards := append(append([]*SLRU[K, V]{}, other.shards...), c.shards...)
+	for _, s := range shards {
+		s.acquire()
+	}
+	defer unlockAll(shards)
+
+	for i, s := range c.shards {
+		o := other.shards[i]
+		sMin, oMin := s.minGen, o.minGen
+		s.exchange(o)
+		s.restamp(oMin)
+		o.restamp(sMin)
+	}
+	for i := range c.shards {
+		c.shards[i].rehome(c.shard)
+		other.shards[i].rehome(other.shard)
+	}
+	for i, s := range c.shards {
+		s.reindex()
+		other.shards[i].reindex()
+		s.trim()
+		other.shards[i].trim()
+	}
+}
+
+// exchange swaps the segments of s and o and their accounting, folding the
+// scan bypass of a cache into its probation if the other has none.
+func (s *SLRU[K, V]) exchange(o *SLRU[K, V]) {
+	if o.bypass == nil {
+		s.foldBypass()
+	}
+	if s.bypass == nil {
+		o.foldBypass()
+	}
+	s.items, o.items = o.items, s.items
+	s.tags, o.tags = o.tags, s.tags
+	s.dependents, o.dependents = o.dependents, s.dependents
+	s.probation, o.probation = o.probation, s.probation
+	s.protected, o.protected = o.protected, s.protected
+	s.probationWeight, o.probationWeight = o.probationWeight, s.probationWeight
+	s.protectedWeight, o.protectedWeight = o.protectedWeight, s.protectedWeight
+	s.probationBytes, o.probationBytes = o.probationBytes, s.probationBytes
+	s.protectedBytes, o.protectedBytes = o.protectedBytes, s.protectedBytes
+	if s.bypass != nil {
+		s.bypass, o.bypass = o.bypass, s.bypass
+		s.bypassWeight, o.bypassWeight = o.bypassWeight, s.bypassWeight
+		s.bypassBytes, o.bypassBytes = o.bypassBytes, s.bypassBytes
+	}
+}
+
+// foldBypass moves the entries of the scan bypass, if any, to the front of
+// probation.
+func (s *SLRU[K, V]) foldBypass() {
+	if s.bypass == nil {
+		return
+	}
+	s.probation.SpliceFront(s.bypass)
+	s.probationWeight += s.bypassWeight
+	s.probationBytes += s.bypassBytes
+	s.bypassWeight, s.bypassBytes = 0, 0
+}
+
+// restamp drops the entries s got that were invalidated before generation
+// minGen of their cache, and stamps the others with the current generation
+// of s.
+func (s *SLRU[K, V]) restamp(minGen uint64) {
+	var dead []*list.Element
+	for _, l := range s.segments() {
+		l.Each(func(e *list.Element) bool {
+			if ent := e.Value.(*entry[K, V]); ent.gen < minGen {
+				dead = append(dead, e)
+			} else {
+				ent.gen = s.gen
+			}
+			return true
+		})
+	}
+	for _, e := range dead {
+		delete(s.items, s.unlink(e).key)
+		s.release(e)
+	}
+}
+
+// rehome moves the entries whose key belongs to another shard, as told by
+// shard, to the same segment of that shard.
+func (s *SLRU[K, V]) rehome(shard func(key K) *SLRU[K, V]) {
+	for _, l := range s.segments() {
+		var moved []*list.Element
+		l.EachReverse(func(e *list.Element) bool {
+			if shard(e.Value.(*entry[K, V]).key) != s {
+				moved = append(moved, e)
+			}
+			return true
+		})
+		for _, e := range moved {
+			ent := s.unlink(e)
+			delete(s.items, ent.key)
+			tags := ent.tags
+			s.untag(ent)
+			s.undepend(ent)
+			d := shard(ent.key)
+			to := d.probation
+			switch l {
+			case s.protected:
+				to = d.protected
+			case s.bypass:
+				to = cmp.Or(d.bypass, d.probation)
+			}
+			d.push(to, &list.Element{Value: ent})
+			d.tag(ent, tags)
+		}
+	}
+}
+
+// reindex rebuilds what tracks the entries of s after they changed
+// wholesale: the expiry schedule, the read index, and the mutation log and
+// watchers of Changes, which get a purge, then a set of every entry.
+// Leases end.
+func (s *SLRU[K, V]) reindex() {
+	if s.wheel != nil {
+		s.wheel = newTimingWheel[K, V](s.wheel.tick, s.now())
+	}
+	if s.index != nil {
+		s.index.m.Store(new(sync.Map))
+	}
+	if s.mutations != nil {
+		s.mutations.append(Mutation[K, V]{Op: MutationPurge})
+	}
+	s.notifyPurge()
+	s.retally()
+	s.dropSpilled()
+	s.removals++
+	for _, l := range s.segments() {
+		l.EachReverse(func(e *list.Element) bool {
+			ent := e.Value.(*entry[K, V])
+			ent.wheelLevel = 0
+			s.reschedule(ent)
+			s.publish(ent)
+			s.mutate(MutationSet, ent)
+			return true
+		})
+	}
+	s.endLeases()
+}
@@ -0,0 +1,58 @@
+package slru
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSwapContents(t *testing.T) {
+	cache := newSLRU[int, int](100, WithJanitor[int, int](time.Millisecond), WithLockFreeReads[int, int]())
+	defer cache.Close()
+	cache.Set(1, 1)
+	cache.InvalidateBefore(cache.NewGeneration())
+	cache.Set(2, 2)
+
+	reload := newSLRU[int, int](100)
+	reload.Set(3, 3)
+	reload.InvalidateBefore(reload.NewGeneration())
+	reload.SetWithTTL(4, 4, time.Millisecond)
+	reload.Set(6, 6)
+	reload.Get(6)
+
+	cache.SwapContents(reload)
+	require.Equal(t, []int{4, 6}, cache.Keys())
+	require.True(t, cache.Contains(6))
+	require.False(t, cache.Contains(2))
+	require.Eventually(t, func() bool { return !cache.Contains(4) }, time.Second, time.Millisecond)
+	require.Equal(t, []int{2}, reload.Keys())
+}
+
+func TestSwapContentsTrims(t *testing.T) {
+	cache := newSLRU[int, int](10)
+	reload := newSLRU[int, int](100)
+	for i := range 50 {
+		reload.Set(i, i)
+	}
+	cache.SwapContents(reload)
+	require.LessOrEqual(t, cache.Len(), 10)
+	require.Zero(t, reload.Len())
+}
+
+func TestSwapContentsOnSharded(t *testing.T) {
+	cache := NewSharded[int, int](1000, 4, nil)
+	cache.Set(-1, -1)
+	reload := NewSharded[int, int](1000, 4, nil)
+	for i := range 100 {
+		reload.Set(i, i)
+	}
+	cache.SwapContents(reload)
+	require.Equal(t, 100, cache.Len())
+	for i := range 100 {
+		v, ok := cache.Get(i)
+		require.True(t, ok)
+		require.Equal(t, i, v)
+	}
+	require.Equal(t, []int{-1}, reload.Keys())
+}
@@ -0,0 +1,103 @@
+package slru
+
+// Tagged is a cache invalidating entries by tag, such as an SLRU or a
+// Sharded.
+type Tagged[K comparable, V any] interface {
+	SetWithTags(key K, value V, tags ...string)
+	InvalidateTag(tag string) (removed int)
+}
+
+// SetWithTags sets the value for the given key, tagged with tags, such as
+// the entity, tenant or table the value derives from, for InvalidateTag to
+// remove it. The tags replace those of an earlier SetWithTags; other
+// writes to the key keep them, so an update doesn't escape invalidation.
+func (s *SLRU[K, V]) SetWithTags(key K, value V, tags ...string) {
+	key = s.normalize(key)
+	s.acquire()
+	defer s.unlock()
+
+	s.set(key, value)
+	// a new entry may not have been admitted
+	if e, ok := s.items[key]; ok {
+		ent := e.Value.(*entry[K, V])
+		s.untag(ent)
+		s.tag(ent, tags)
+	}
+}
+
+// InvalidateTag removes every entry tagged with tag, reporting how many it
+// removed, in time linear in their number. With an invalidation bus, it
+// publishes the tag, for the other caches to remove their own entries
+// tagged with it.
+func (s *SLRU[K, V]) InvalidateTag(tag string) (removed int) {
+	removed = s.removeTag(tag)
+	if s.bus != nil {
+		s.broadcast(Invalidation{Tags: []string{tag}})
+	}
+	return removed
+}
+
+func (s *SLRU[K, V]) removeTag(tag string) (removed int) {
+	s.acquire()
+	defer s.unlock()
+
+	return s.invalidateTag(tag)
+}
+
+func (s *SLRU[K, V]) invalidateTag(tag string) (removed int) {
+	for key := range s.tags[tag] {
+		s.record(TraceDelete, key, 0, 0)
+		if s.remove(key) {
+			removed++
+		}
+	}
+	return removed
+}
+
+// tag indexes ent under tags.
+func (s *SLRU[K, V]) tag(ent *entry[K, V], tags []string) {
+	if len(tags) == 0 {
+		return
+	}
+	if s.tags == nil {
+		s.tags = make(map[string]map[K]struct{})
+	}
+	for _, tag := range tags {
+		keys, ok := s.tags[tag]
+		if !ok {
+			keys = make(map[K]struct{})
+			s.tags[tag] = keys
+		}
+		keys[ent.key] = struct{}{}
+	}
+	ent.tags = tags
+}
+
+// untag removes ent from the index of its tags.
+func (s *SLRU[K, V]) untag(ent *entry[K, V]) {
+	for _, tag := range ent.tags {
+		keys := s.tags[tag]
+		delete(keys, ent.key)
+		if len(keys) == 0 {
+			delete(s.tags, tag)
+		}
+	}
+	ent.tags = nil
+}
+
+// SetWithTags is SLRU.SetWithTags on the shard of key.
+func (c *Sharded[K, V]) SetWithTags(key K, value V, tags ...string) {
+	c.shard(key).SetWithTags(key, value, tags...)
+}
+
+// InvalidateTag is SLRU.InvalidateTag on every shard, publishing the tag
+// once.
+func (c *Sharded[K, V]) InvalidateTag(tag string) (removed int) {
+	for _, s := range c.shards {
+		removed += s.removeTag(tag)
+	}
+	if s := c.shards[0]; s.bus != nil {
+		s.broadcast(Invalidation{Tags: []string{tag}})
+	}
+	return removed
+}
@@ -0,0 +1,51 @@
+package slru
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvalidateTag(t *testing.T) {
+	cache := newSLRU[string, int](100)
+	cache.SetWithTags("user:1", 1, "user:1", "tenant:a")
+	cache.SetWithTags("post:1", 1, "user:1", "tenant:a")
+	cache.SetWithTags("user:2", 2, "user:2", "tenant:b")
+	cache.Set("plain", 0)
+
+	require.Equal(t, 2, cache.InvalidateTag("user:1"))
+	require.False(t, cache.Contains("user:1"))
+	require.False(t, cache.Contains("post:1"))
+	require.True(t, cache.Contains("user:2"))
+	require.Zero(t, cache.InvalidateTag("tenant:a"))
+
+	// updates keep the tags, retagging replaces them
+	cache.Set("user:2", 20)
+	cache.SetWithTags("plain", 0, "tenant:b")
+	require.Equal(t, 2, cache.InvalidateTag("tenant:b"))
+	require.Equal(t, 0, cache.Len())
+	require.NoError(t, cache.Verify())
+	require.Empty(t, cache.tags)
+}
+
+func TestInvalidateTagAfterEviction(t *testing.T) {
+	cache := newSLRU[int, int](10)
+	for i := range 100 {
+		cache.SetWithTags(i, i, "all")
+	}
+	require.Len(t, cache.tags["all"], cache.Len())
+	require.Equal(t, cache.Len(), cache.InvalidateTag("all"))
+	require.Empty(t, cache.tags)
+}
+
+func TestInvalidateTagOnBus(t *testing.T) {
+	bus := NewLocalBus()
+	a := NewSharded[int, int](100, 2, nil, WithInvalidationBus[int, int](bus, JSONCodec[int]{}))
+	b := NewSharded[int, int](100, 2, nil, WithInvalidationBus[int, int](bus, JSONCodec[int]{}))
+	a.SetWithTags(1, 1, "t")
+	b.SetWithTags(1, 1, "t")
+	b.SetWithTags(2, 2, "t")
+
+	require.Equal(t, 1, a.InvalidateTag("t"))
+	require.Zero(t, b.Len())
+}
@@ -0,0 +1,218 @@
+package slru
+
+import (
+	"fmt"
+
+	"github.com/hey-kong/slru/list"
+)
+
+// Quota is the share of the capacity of a tenant of WithTenants, in
+// weight. Entries of a tenant holding up to Min are not evicted to make
+// room for others, while a tenant going over Max evicts its own coldest
+// entries first. A zero Max is unbounded.
+type Quota struct {
+	Min, Max int
+}
+
+// TenantStats are the statistics of a tenant of WithTenants.
+type TenantStats struct {
+	// Len is the number of entries of the tenant and Weight their weight.
+	Len, Weight int
+
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+}
+
+// Tenanted is implemented by caches partitioned with WithTenants.
+type Tenanted interface {
+	// TenantStats returns the statistics of tenant.
+	TenantStats(tenant string) TenantStats
+	// PurgeTenant removes the entries of tenant, returning their number.
+	PurgeTenant(tenant string) (removed int)
+}
+
+// WithTenants partitions the capacity of the cache between tenants, the
+// key of each entry naming its tenant, such as with PrefixClass, so a
+// noisy tenant can't evict the working set of everyone else: evictions
+// skip the entries of tenants holding no more than their Min quota, and
+// writes evict the coldest entries of their tenant while it is over its
+// Max. Tenants missing from quotas are unconstrained. Quotas apply to each
+// shard of a Sharded. Evictions may walk past protected entries, so the
+// sum of the Min quotas should leave room for the other tenants.
+func WithTenants[K comparable, V any](tenant func(key K) string, quotas map[string]Quota) Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		s.tenantOf = tenant
+		s.quotas = make(map[string]Quota, len(quotas))
+		for name, quota := range quotas {
+			s.quotas[name] = quota
+		}
+	}
+}
+
+// tenantState is the accounting of a tenant. Its weight and length change
+// under the exclusive lock, its counters atomically.
+type tenantState struct {
+	quota                   Quota
+	len, weight             int
+	hits, misses, evictions counter
+}
+
+// tenant returns the accounting of the tenant of key.
+func (s *SLRU[K, V]) tenant(key K) *tenantState {
+	name := s.tenantOf(key)
+	if t, ok := s.tenants.Load(name); ok {
+		return t.(*tenantState)
+	}
+	t, _ := s.tenants.LoadOrStore(name, &tenantState{quota: s.quotas[name]})
+	return t.(*tenantState)
+}
+
+// tally adds n entries of weight to the tenant of key.
+func (s *SLRU[K, V]) tally(key K, n, weight int) {
+	if s.tenantOf != nil {
+		t := s.tenant(key)
+		t.len += n
+		t.weight += weight
+	}
+}
+
+// lookedUp counts a hit or miss on key for its tenant.
+func (s *SLRU[K, V]) lookedUp(key K, hit bool) {
+	if s.tenantOf == nil {
+		return
+	}
+	if hit {
+		s.tenant(key).hits.inc()
+	} else {
+		s.tenant(key).misses.inc()
+	}
+}
+
+// victim returns the element to evict out of segment l: its tail, or with
+// tenant quotas the coldest entry whose tenant stays at its Min without
+// it, falling back to the tail.
+func (s *SLRU[K, V]) victim(l *list.List) *list.Element {
+	if len(s.quotas) == 0 {
+		return l.Back()
+	}
+	for e := l.Back(); e != nil; e = e.Prev() {
+		ent := e.Value.(*entry[K, V])
+		if t := s.tenant(ent.key); t.weight-ent.weight >= t.quota.Min {
+			return e
+		}
+	}
+	return l.Back()
+}
+
+// capTenant evicts the coldest entries of the tenant of key while it
+// weighs over its Max, returning the number of evicted entries.
+func (s *SLRU[K, V]) capTenant(key K) (evicted int) {
+	if s.tenantOf == nil {
+		return 0
+	}
+	t := s.tenant(key)
+	if t.quota.Max <= 0 {
+		return 0
+	}
+	name := s.tenantOf(key)
+	for _, l := range s.segments() {
+		for e := l.Back(); e != nil && t.weight > t.quota.Max; {
+			prev := e.Prev()
+			if s.tenantOf(e.Value.(*entry[K, V]).key) == name {
+				s.evictElement(l, e)
+				evicted++
+			}
+			e = prev
+		}
+	}
+	return evicted
+}
+
+// retally recounts the entries of every tenant, after they changed
+// wholesale.
+func (s *SLRU[K, V]) retally() {
+	if s.tenantOf == nil {
+		return
+	}
+	s.tenants.Range(func(_, t any) bool {
+		t.(*tenantState).len, t.(*tenantState).weight = 0, 0
+		return true
+	})
+	for _, l := range s.segments() {
+		l.Each(func(e *list.Element) bool {
+			ent := e.Value.(*entry[K, V])
+			s.tally(ent.key, 1, ent.weight)
+			return true
+		})
+	}
+}
+
+// verifyTenants checks that the accounting of every tenant matches its
+// entries.
+func (s *SLRU[K, V]) verifyTenants() error {
+	if s.tenantOf == nil {
+		return nil
+	}
+	weights := make(map[string]int)
+	for _, e := range s.items {
+		ent := e.Value.(*entry[K, V])
+		weights[s.tenantOf(ent.key)] += ent.weight
+	}
+	var err error
+	s.tenants.Range(func(name, t any) bool {
+		if w := t.(*tenantState).weight; w != weights[name.(string)] {
+			err = fmt.Errorf("slru: tenant %q weighs %d, accounted as %d", name, weights[name.(string)], w)
+			return false
+		}
+		return true
+	})
+	return err
+}
+
+func (s *SLRU[K, V]) TenantStats(tenant string) TenantStats {
+	s.acquireShared()
+	defer s.lock.RUnlock()
+
+	t, ok := s.tenants.Load(tenant)
+	if !ok {
+		return TenantStats{}
+	}
+	return t.(*tenantState).stats()
+}
+
+func (t *tenantState) stats() TenantStats {
+	return TenantStats{
+		Len:       t.len,
+		Weight:    t.weight,
+		Hits:      t.hits.load(),
+		Misses:    t.misses.load(),
+		Evictions: t.evictions.load(),
+	}
+}
+
+func (s *SLRU[K, V]) PurgeTenant(tenant string) (removed int) {
+	if s.tenantOf == nil {
+		return 0
+	}
+	return s.PurgeFunc(func(key K, _ V) bool { return s.tenantOf(key) == tenant })
+}
+
+func (c *Sharded[K, V]) TenantStats(tenant string) (stats TenantStats) {
+	for _, s := range c.shards {
+		shard := s.TenantStats(tenant)
+		stats.Len += shard.Len
+		stats.Weight += shard.Weight
+		stats.Hits += shard.Hits
+		stats.Misses += shard.Misses
+		stats.Evictions += shard.Evictions
+	}
+	return stats
+}
+
+func (c *Sharded[K, V]) PurgeTenant(tenant string) (removed int) {
+	for _, s := range c.shards {
+		removed += s.PurgeTenant(tenant)
+	}
+	return removed
+}
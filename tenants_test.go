@@ -0,0 +1,63 @@
+package slru
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenants(t *testing.T) {
+	cache := newSLRU[string, int](100, WithTenants[string, int](PrefixClass("a:", "b:", "noisy:"), map[string]Quota{
+		"a:":     {Min: 10},
+		"b:":     {Max: 5},
+		"noisy:": {Max: 50},
+	}))
+	for i := range 10 {
+		cache.Set(fmt.Sprint("a:", i), i)
+	}
+	for i := range 10 {
+		cache.Set(fmt.Sprint("b:", i), i)
+	}
+	require.Equal(t, 5, cache.TenantStats("b:").Len)
+	require.True(t, cache.Contains("b:9"))
+	require.False(t, cache.Contains("b:4"))
+
+	// the noisy tenant churns through its own entries and those of the
+	// unconstrained tenant, but not the guaranteed ones of a:
+	for i := range 1000 {
+		cache.Set(fmt.Sprint("noisy:", i), i)
+		cache.Set(fmt.Sprint("other:", i), i)
+	}
+	stats := cache.TenantStats("a:")
+	require.Equal(t, 10, stats.Len)
+	require.Equal(t, 10, stats.Weight)
+	require.Zero(t, stats.Evictions)
+	require.LessOrEqual(t, cache.TenantStats("noisy:").Weight, 50)
+	require.NotZero(t, cache.TenantStats("noisy:").Evictions)
+	require.NotZero(t, cache.TenantStats("").Evictions)
+	require.NoError(t, cache.Verify())
+
+	cache.Get("a:1")
+	cache.Get("a:missing")
+	stats = cache.TenantStats("a:")
+	require.Equal(t, uint64(1), stats.Hits)
+	require.Equal(t, uint64(1), stats.Misses)
+
+	require.Equal(t, 10, cache.PurgeTenant("a:"))
+	require.Zero(t, cache.TenantStats("a:").Len)
+	require.NotZero(t, cache.Len())
+	cache.Purge()
+	require.Zero(t, cache.TenantStats("noisy:").Len)
+	require.NoError(t, cache.Verify())
+}
+
+func TestShardedTenants(t *testing.T) {
+	cache := NewSharded[string, int](100, 4, nil, WithTenants[string, int](PrefixClass("a:"), map[string]Quota{"a:": {Max: 5}}))
+	for i := range 100 {
+		cache.Set(fmt.Sprint("a:", i), i)
+	}
+	require.Equal(t, 20, cache.TenantStats("a:").Len)
+	require.Equal(t, 20, cache.PurgeTenant("a:"))
+	require.Zero(t, cache.Len())
+}
@@ -0,0 +1,40 @@
+package slru
+
+// WithEvictionRateLimit caps evictions to make room at about perSecond, on
+// average over a second of the clock of the cache, for each shard of a
+// Sharded: writes of new keys that would evict past the cap are turned
+// away, counted as Throttled in Stats, so a cache thrashing under a
+// working set it can't hold keeps its entries rather than churning them.
+// A rising Throttled count signals a cache under pressure, on which
+// callers may shed load or widen TTLs. A cap of 0 or less disables it.
+func WithEvictionRateLimit[K comparable, V any](perSecond float64) Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		s.evictRate = perSecond
+	}
+}
+
+// throttled reports whether a new entry of weight would evict past the
+// eviction rate cap.
+func (s *SLRU[K, V]) throttled(weight int) bool {
+	if s.evictRate <= 0 {
+		return false
+	}
+	if _, probation := s.limits(); s.probationWeight+weight <= probation {
+		return false
+	}
+	now := s.now()
+	if s.evictRefill.IsZero() {
+		s.evictTokens = s.evictRate
+	} else {
+		s.evictTokens = min(s.evictRate, s.evictTokens+s.evictRate*now.Sub(s.evictRefill).Seconds())
+	}
+	s.evictRefill = now
+	return s.evictTokens < 1
+}
+
+// spend takes n evictions off the allowance of the rate cap.
+func (s *SLRU[K, V]) spend(n int) {
+	if s.evictRate > 0 {
+		s.evictTokens -= float64(n)
+	}
+}
@@ -0,0 +1,42 @@
+package slru
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvictionRateLimit(t *testing.T) {
+	now := time.Unix(0, 0)
+	cache := newSLRU[int, int](100,
+		WithClock[int, int](func() time.Time { return now }),
+		WithEvictionRateLimit[int, int](10),
+	)
+	for i := range 100 {
+		cache.Set(i, i)
+	}
+	stats := cache.Stats()
+	require.Equal(t, uint64(10), stats.Evictions)
+	require.Equal(t, uint64(70), stats.Throttled)
+	require.Equal(t, 20, cache.Len())
+
+	// updates of cached keys go through
+	cache.Set(29, 0)
+	v, ok := cache.Peek(29)
+	require.True(t, ok)
+	require.Equal(t, 0, v)
+
+	// the allowance refills with time, on top of the room the promotion
+	// of 29 left in probation
+	now = now.Add(500 * time.Millisecond)
+	for i := 100; i < 110; i++ {
+		cache.Set(i, i)
+	}
+	stats = cache.Stats()
+	require.Equal(t, uint64(15), stats.Evictions)
+	require.Equal(t, uint64(74), stats.Throttled)
+	require.True(t, cache.Contains(105))
+	require.False(t, cache.Contains(106))
+	require.NoError(t, cache.Verify())
+}
@@ -0,0 +1,62 @@
+package slru
+
+// Store is a second-level store behind a Tiered cache, such as another Cache
+// or a client of a remote cache.
+type Store[K comparable, V any] interface {
+	Get(key K) (value V, ok bool)
+	Set(key K, value V)
+	Remove(key K) (present bool)
+}
+
+// Tiered checks a local L1 cache first and falls back to an L2 store,
+// back-filling L1 on L2 hits.
+type Tiered[K comparable, V any] struct {
+	l1           Cache[K, V]
+	l2           Store[K, V]
+	writeThrough bool
+}
+
+// NewTiered composes l1 and l2. With writeThrough, Set and Remove apply to
+// both tiers; otherwise l2 is only read and is populated elsewhere.
+func NewTiered[K comparable, V any](l1 Cache[K, V], l2 Store[K, V], writeThrough bool) *Tiered[K, V] {
+	return &Tiered[K, V]{l1: l1, l2: l2, writeThrough: writeThrough}
+}
+
+// Get returns the value from L1, or from L2 after storing it in L1.
+func (t *Tiered[K, V]) Get(key K) (value V, ok bool) {
+	if value, ok = t.l1.Get(key); ok {
+		return value, true
+	}
+	if value, ok = t.l2.Get(key); ok {
+		t.l1.Set(key, value)
+	}
+	return value, ok
+}
+
+// Set sets the value in L1, and in L2 when writing through.
+func (t *Tiered[K, V]) Set(key K, value V) {
+	t.l1.Set(key, value)
+	if t.writeThrough {
+		t.l2.Set(key, value)
+	}
+}
+
+// Remove removes the key from L1, and from L2 when writing through,
+// reporting whether any tier held it.
+func (t *Tiered[K, V]) Remove(key K) (present bool) {
+	present = t.l1.Remove(key)
+	if t.writeThrough && t.l2.Remove(key) {
+		present = true
+	}
+	return present
+}
+
+// L1 returns the local cache.
+func (t *Tiered[K, V]) L1() Cache[K, V] {
+	return t.l1
+}
+
+// L2 returns the second-level store.
+func (t *Tiered[K, V]) L2() Store[K, V] {
+	return t.l2
+}
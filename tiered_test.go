@@ -0,0 +1,39 @@
+package slru
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTiered(t *testing.T) {
+	l1, l2 := New[int, int](10), New[int, int](10)
+	cache := NewTiered[int, int](l1, l2, false)
+
+	l2.Set(1, 10)
+	v, ok := cache.Get(1)
+	require.True(t, ok)
+	require.Equal(t, 10, v)
+	require.True(t, l1.Contains(1))
+
+	cache.Set(2, 20)
+	require.False(t, l2.Contains(2))
+	require.True(t, cache.Remove(1))
+	require.True(t, l2.Contains(1))
+
+	_, ok = cache.Get(3)
+	require.False(t, ok)
+}
+
+func TestTieredWriteThrough(t *testing.T) {
+	l1, l2 := New[int, int](10), New[int, int](10)
+	cache := NewTiered[int, int](l1, l2, true)
+
+	cache.Set(1, 10)
+	require.True(t, l1.Contains(1))
+	require.True(t, l2.Contains(1))
+
+	l1.Remove(1)
+	require.True(t, cache.Remove(1))
+	require.False(t, l2.Contains(1))
+}
@@ -0,0 +1,136 @@
+package slru
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Trace operations, named as in the Twitter cache trace format.
+const (
+	TraceGet    = "get"
+	TraceSet    = "set"
+	TraceDelete = "delete"
+)
+
+// TraceEvent is one cache operation of a trace.
+type TraceEvent struct {
+	Time time.Time
+	Key  string
+	// Size is the weight of a set entry, zero for other operations.
+	Size int
+	Op   string
+	// TTL is the time-to-live of a set entry, zero if it never expires.
+	TTL time.Duration
+}
+
+// TraceRecorder writes the cache operations in the CSV format of the
+// Twitter cache traces: timestamp in seconds, key, key size, value size,
+// client id, operation and TTL in seconds. Keys are formatted with fmt,
+// sizes are entry weights and the client id is always 0.
+type TraceRecorder struct {
+	lock sync.Mutex
+	w    *csv.Writer
+	err  error
+}
+
+// NewTraceRecorder returns a TraceRecorder writing to w.
+func NewTraceRecorder(w io.Writer) *TraceRecorder {
+	return &TraceRecorder{w: csv.NewWriter(w)}
+}
+
+// WithTrace records every Get, write and Remove to recorder.
+func WithTrace[K comparable, V any](recorder *TraceRecorder) Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		s.trace = recorder
+	}
+}
+
+// Record writes event to the trace. Write errors are kept and returned by
+// Flush rather than failing cache operations.
+func (r *TraceRecorder) Record(event TraceEvent) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.err != nil {
+		return
+	}
+	r.err = r.w.Write([]string{
+		strconv.FormatInt(event.Time.Unix(), 10),
+		event.Key,
+		strconv.Itoa(len(event.Key)),
+		strconv.Itoa(event.Size),
+		"0",
+		event.Op,
+		strconv.FormatInt(int64(event.TTL/time.Second), 10),
+	})
+}
+
+// Flush writes any buffered events and returns the first error met.
+func (r *TraceRecorder) Flush() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.w.Flush()
+	if r.err == nil {
+		r.err = r.w.Error()
+	}
+	return r.err
+}
+
+// TraceReader reads a trace in the format written by TraceRecorder.
+type TraceReader struct {
+	r *csv.Reader
+}
+
+// NewTraceReader returns a TraceReader reading from r.
+func NewTraceReader(r io.Reader) *TraceReader {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = 7
+	cr.ReuseRecord = true
+	return &TraceReader{r: cr}
+}
+
+// Read returns the next event of the trace, or io.EOF at its end.
+func (r *TraceReader) Read() (TraceEvent, error) {
+	record, err := r.r.Read()
+	if err != nil {
+		return TraceEvent{}, err
+	}
+	ts, err := strconv.ParseInt(record[0], 10, 64)
+	if err != nil {
+		return TraceEvent{}, fmt.Errorf("slru: bad trace timestamp %q", record[0])
+	}
+	size, err := strconv.Atoi(record[3])
+	if err != nil {
+		return TraceEvent{}, fmt.Errorf("slru: bad trace value size %q", record[3])
+	}
+	ttl, err := strconv.ParseInt(record[6], 10, 64)
+	if err != nil {
+		return TraceEvent{}, fmt.Errorf("slru: bad trace ttl %q", record[6])
+	}
+	return TraceEvent{
+		Time: time.Unix(ts, 0),
+		Key:  record[1],
+		Size: size,
+		Op:   record[5],
+		TTL:  time.Duration(ttl) * time.Second,
+	}, nil
+}
+
+// record traces an operation on key if a recorder is configured.
+func (s *SLRU[K, V]) record(op string, key K, size int, ttl time.Duration) {
+	if s.trace == nil {
+		return
+	}
+	s.trace.Record(TraceEvent{
+		Time: time.Now(),
+		Key:  fmt.Sprint(key),
+		Size: size,
+		Op:   op,
+		TTL:  max(ttl, 0),
+	})
+}
@@ -0,0 +1,49 @@
+package slru
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrace(t *testing.T) {
+	var buf bytes.Buffer
+	recorder := NewTraceRecorder(&buf)
+	cache := New[string, int](10, WithTrace[string, int](recorder))
+
+	cache.Set("a", 1)
+	cache.SetWithTTL("b,c", 2, time.Minute)
+	cache.Get("a")
+	cache.Remove("a")
+	require.NoError(t, recorder.Flush())
+
+	r := NewTraceReader(&buf)
+	var events []TraceEvent
+	for {
+		event, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		require.WithinDuration(t, time.Now(), event.Time, 2*time.Second)
+		event.Time = time.Time{}
+		events = append(events, event)
+	}
+	require.Equal(t, []TraceEvent{
+		{Key: "a", Size: 1, Op: TraceSet},
+		{Key: "b,c", Size: 1, Op: TraceSet, TTL: time.Minute},
+		{Key: "a", Op: TraceGet},
+		{Key: "a", Op: TraceDelete},
+	}, events)
+}
+
+func TestTraceReaderErrors(t *testing.T) {
+	_, err := NewTraceReader(bytes.NewBufferString("x,a,1,1,0,get,0\n")).Read()
+	require.ErrorContains(t, err, "bad trace timestamp")
+
+	_, err = NewTraceReader(bytes.NewBufferString("1,a,1\n")).Read()
+	require.Error(t, err)
+}
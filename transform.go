@@ -0,0 +1,34 @@
+package slru
+
+// WithKeyTransform applies fn to the key of every operation, such as
+// lowercasing, trimming or canonicalizing URLs, so logically identical keys
+// share one entry without each caller normalizing them. Keys are stored,
+// listed and passed to callbacks and loaders as fn returns them. fn must be
+// idempotent and fast: it runs outside the cache lock, at least once per
+// operation. A Sharded applies it before hashing keys to their shard.
+func WithKeyTransform[K comparable, V any](fn func(key K) K) Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		s.keyTransform = fn
+	}
+}
+
+// normalize returns key as transformed by WithKeyTransform.
+func (s *SLRU[K, V]) normalize(key K) K {
+	if s.keyTransform == nil {
+		return key
+	}
+	return s.keyTransform(key)
+}
+
+// normalizeAll returns keys transformed, in a copy rather than in place as
+// they may belong to the caller.
+func (s *SLRU[K, V]) normalizeAll(keys []K) []K {
+	if s.keyTransform == nil {
+		return keys
+	}
+	normalized := make([]K, len(keys))
+	for i, key := range keys {
+		normalized[i] = s.keyTransform(key)
+	}
+	return normalized
+}
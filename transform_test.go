@@ -0,0 +1,68 @@
+package slru
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyTransform(t *testing.T) {
+	lower := WithKeyTransform[string, int](func(key string) string { return strings.ToLower(strings.TrimSpace(key)) })
+	for name, cache := range map[string]Cache[string, int]{
+		"SLRU":    New(100, lower),
+		"Sharded": NewSharded(100, 8, nil, lower),
+	} {
+		t.Run(name, func(t *testing.T) {
+			cache.Set("Foo", 1)
+			v, ok := cache.Get(" foo ")
+			require.True(t, ok)
+			require.Equal(t, 1, v)
+			require.False(t, cache.Add("FOO", 2))
+			require.True(t, cache.CompareAndSwap("fOo", 2, 3))
+			require.Equal(t, []string{"foo"}, cache.Keys())
+
+			v, err := cache.GetOrLoad(context.Background(), "Bar", func(ctx context.Context, key string) (int, error) {
+				require.Equal(t, "bar", key)
+				return 4, nil
+			})
+			require.NoError(t, err)
+			require.Equal(t, 4, v)
+			require.True(t, cache.Contains("BAR"))
+
+			require.NoError(t, cache.(Transactional[string, int]).Tx(func(tx *Txn[string, int]) error {
+				tx.Set("Baz", 5)
+				require.True(t, tx.Contains("baz"))
+				require.True(t, tx.Remove("BAR"))
+				return nil
+			}))
+			require.True(t, cache.Remove("BAZ"))
+			require.Equal(t, 1, cache.Len())
+		})
+	}
+}
+
+func TestKeyTransformRistretto(t *testing.T) {
+	cache, err := NewRistretto[string, int](100, WithKeyTransform[string, int](strings.ToLower))
+	require.NoError(t, err)
+	require.True(t, cache.Set("ABC", 1, 1))
+	v, ok := cache.Get("ABC")
+	require.True(t, ok)
+	require.Equal(t, 1, v)
+	v, ok = cache.Get("abc")
+	require.True(t, ok)
+	require.Equal(t, 1, v)
+	cache.Del("Abc")
+	_, ok = cache.Get("abc")
+	require.False(t, ok)
+}
+
+func TestKeyTransformLRU(t *testing.T) {
+	cache := &LRU[string, int]{s: newSLRU(100, WithKeyTransform[string, int](strings.ToLower))}
+	cache.Add("ABC", 1)
+	v, ok := cache.Get("abc")
+	require.True(t, ok)
+	require.Equal(t, 1, v)
+	require.Equal(t, []string{"abc"}, cache.Keys())
+}
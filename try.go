@@ -0,0 +1,36 @@
+package slru
+
+// TryGet is Get if the cache lock can be acquired without waiting. It
+// reports locked false, without looking up key, if the lock is held.
+func (s *SLRU[K, V]) TryGet(key K) (value V, ok, locked bool) {
+	key = s.normalize(key)
+	if !s.lock.TryLock() {
+		return value, false, false
+	}
+	defer s.unlock()
+
+	s.record(TraceGet, key, 0, 0)
+	value, ok = s.get(key)
+	return value, ok, true
+}
+
+// TrySet is Set if the cache lock can be acquired without waiting. It
+// reports locked false, without setting key, if the lock is held.
+func (s *SLRU[K, V]) TrySet(key K, value V) (locked bool) {
+	key = s.normalize(key)
+	if !s.lock.TryLock() {
+		return false
+	}
+	defer s.unlock()
+
+	s.set(key, value)
+	return true
+}
+
+func (c *Sharded[K, V]) TryGet(key K) (value V, ok, locked bool) {
+	return c.shard(key).TryGet(key)
+}
+
+func (c *Sharded[K, V]) TrySet(key K, value V) (locked bool) {
+	return c.shard(key).TrySet(key, value)
+}
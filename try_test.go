@@ -0,0 +1,23 @@
+package slru
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTryGetAndTrySet(t *testing.T) {
+	cache := newSLRU[string, int](10)
+	require.True(t, cache.TrySet("a", 1))
+	v, ok, locked := cache.TryGet("a")
+	require.True(t, locked)
+	require.True(t, ok)
+	require.Equal(t, 1, v)
+
+	cache.lock.RLock()
+	_, _, locked = cache.TryGet("a")
+	require.False(t, locked)
+	require.False(t, cache.TrySet("b", 2))
+	cache.lock.RUnlock()
+	require.False(t, cache.Contains("b"))
+}
@@ -0,0 +1,142 @@
+package slru
+
+import "time"
+
+// Transactional is a cache applying transactions, such as an SLRU or a
+// Sharded.
+type Transactional[K comparable, V any] interface {
+	Tx(fn func(tx *Txn[K, V]) error) error
+}
+
+// Txn is a transaction of Tx. Its writes are staged, seen by its own reads,
+// and applied together when the function of Tx returns nil. A Txn must not
+// be used once that function returns.
+type Txn[K comparable, V any] struct {
+	shard func(key K) *SLRU[K, V]
+	// normalize is the key transform of the cache.
+	normalize func(key K) K
+	writes    map[K]txWrite[V]
+	// order lists the written keys in the order first written.
+	order []K
+}
+
+// txWrite is a staged write. ttl applies if timed, as with SetWithTTL.
+type txWrite[V any] struct {
+	value  V
+	ttl    time.Duration
+	timed  bool
+	remove bool
+}
+
+// Get returns the value of key as written by the transaction, or else as
+// cached, promoting it.
+func (tx *Txn[K, V]) Get(key K) (value V, ok bool) {
+	key = tx.normalize(key)
+	if w, staged := tx.writes[key]; staged {
+		return w.value, !w.remove
+	}
+	return tx.shard(key).get(key)
+}
+
+// Contains reports whether key is present as written by the transaction,
+// or else as cached, without promoting it.
+func (tx *Txn[K, V]) Contains(key K) bool {
+	key = tx.normalize(key)
+	if w, staged := tx.writes[key]; staged {
+		return !w.remove
+	}
+	_, ok := tx.shard(key).live(key)
+	return ok
+}
+
+// Set stages setting the value for key.
+func (tx *Txn[K, V]) Set(key K, value V) {
+	tx.stage(key, txWrite[V]{value: value})
+}
+
+// SetWithTTL stages setting the value for key, expiring after ttl.
+func (tx *Txn[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	tx.stage(key, txWrite[V]{value: value, ttl: ttl, timed: true})
+}
+
+// Remove stages removing key, reporting whether it was present.
+func (tx *Txn[K, V]) Remove(key K) (present bool) {
+	present = tx.Contains(key)
+	tx.stage(key, txWrite[V]{remove: true})
+	return present
+}
+
+func (tx *Txn[K, V]) stage(key K, w txWrite[V]) {
+	key = tx.normalize(key)
+	if tx.writes == nil {
+		tx.writes = make(map[K]txWrite[V])
+	}
+	if _, staged := tx.writes[key]; !staged {
+		tx.order = append(tx.order, key)
+	}
+	tx.writes[key] = w
+}
+
+// commit applies the staged writes, returning the keys removed.
+func (tx *Txn[K, V]) commit() (removed []K) {
+	for _, key := range tx.order {
+		w, s := tx.writes[key], tx.shard(key)
+		switch {
+		case w.remove:
+			s.record(TraceDelete, key, 0, 0)
+			if s.remove(key) {
+				removed = append(removed, key)
+			}
+		case w.timed:
+			s.setEntry(key, w.value, s.weigh(key, w.value), w.ttl)
+		default:
+			s.set(key, w.value)
+		}
+	}
+	return removed
+}
+
+// Tx calls fn with a transaction and applies its writes atomically with
+// respect to other operations, for related entries that must stay mutually
+// consistent: no other operation runs between its reads and writes, and
+// its writes are all discarded if fn returns an error, which Tx returns.
+// The cache is locked while fn runs, so fn must be quick and must not call
+// methods of the cache.
+func (s *SLRU[K, V]) Tx(fn func(tx *Txn[K, V]) error) error {
+	removed, err := func() ([]K, error) {
+		s.acquire()
+		defer s.unlock()
+
+		tx := &Txn[K, V]{shard: func(K) *SLRU[K, V] { return s }, normalize: s.normalize}
+		if err := fn(tx); err != nil {
+			return nil, err
+		}
+		return tx.commit(), nil
+	}()
+	if s.bus != nil && len(removed) > 0 {
+		s.broadcast(Invalidation{Keys: s.encodeKeys(removed...)})
+	}
+	return err
+}
+
+// Tx is SLRU.Tx across shards, locking every shard while fn runs.
+func (c *Sharded[K, V]) Tx(fn func(tx *Txn[K, V]) error) error {
+	removed, err := func() ([]K, error) {
+		for _, s := range c.shards {
+			s.acquire()
+		}
+		defer unlockAll(c.shards)
+
+		tx := &Txn[K, V]{shard: c.shard, normalize: c.shards[0].normalize}
+		if err := fn(tx); err != nil {
+			return nil, err
+		}
+		return tx.commit(), nil
+	}()
+	for _, key := range removed {
+		if s := c.shard(key); s.bus != nil {
+			s.broadcast(Invalidation{Keys: s.encodeKeys(key)})
+		}
+	}
+	return err
+}
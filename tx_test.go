@@ -0,0 +1,81 @@
+package slru
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTx(t *testing.T) {
+	cache := newSLRU[string, int](100)
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+
+	err := cache.Tx(func(tx *Txn[string, int]) error {
+		a, _ := tx.Get("a")
+		tx.Set("a", a+10)
+		v, ok := tx.Get("a")
+		require.True(t, ok)
+		require.Equal(t, 11, v)
+		require.True(t, tx.Remove("b"))
+		require.False(t, tx.Contains("b"))
+		tx.SetWithTTL("c", 3, time.Hour)
+		return nil
+	})
+	require.NoError(t, err)
+	v, _ := cache.Get("a")
+	require.Equal(t, 11, v)
+	require.False(t, cache.Contains("b"))
+	ttl, ok := cache.TTL("c")
+	require.True(t, ok)
+	require.Greater(t, ttl, time.Minute)
+}
+
+func TestTxRollback(t *testing.T) {
+	errAbort := errors.New("abort")
+	cache := newSLRU[string, int](100)
+	cache.Set("a", 1)
+	err := cache.Tx(func(tx *Txn[string, int]) error {
+		tx.Set("a", 2)
+		tx.Set("b", 2)
+		return errAbort
+	})
+	require.ErrorIs(t, err, errAbort)
+	v, _ := cache.Get("a")
+	require.Equal(t, 1, v)
+	require.False(t, cache.Contains("b"))
+}
+
+func TestTxOnSharded(t *testing.T) {
+	cache := NewSharded[int, int](1000, 4, nil)
+	cache.Set(0, 100)
+
+	// transfers between keys keep their sum, whatever the shards of the keys
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 100 {
+				from, to := (i+j)%10, (i+j+1)%10
+				require.NoError(t, cache.Tx(func(tx *Txn[int, int]) error {
+					a, _ := tx.Get(from)
+					b, _ := tx.Get(to)
+					tx.Set(from, a-1)
+					tx.Set(to, b+1)
+					return nil
+				}))
+			}
+		}()
+	}
+	wg.Wait()
+	var sum int
+	for i := range 10 {
+		v, _ := cache.Get(i)
+		sum += v
+	}
+	require.Equal(t, 100, sum)
+}
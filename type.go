@@ -1,22 +1,152 @@
 package slru
 
-// Cache is the interface for a cache.
+import (
+	"context"
+	"time"
+)
+
+// Cache is the interface for a cache, implemented by SLRU, Sharded, Policy
+// and SizeClasses. Depend on it rather than on the concrete types to swap
+// them.
+//
+// The method set is frozen: methods are neither added, removed nor changed
+// within a major version, so implementations outside this package keep
+// compiling. New capabilities come as separate interfaces, such as
+// LeaseStore, which callers check for with a type assertion.
 type Cache[K comparable, V any] interface {
 	// Set sets the value for the given key on cache.
 	Set(key K, value V)
 
+	// SetAsync sets the value for the given key on cache without waiting for
+	// the cache lock if writes are buffered. The write is visible once applied.
+	SetAsync(key K, value V)
+
+	// Flush waits until the writes buffered by SetAsync are applied, or until
+	// ctx is done.
+	Flush(ctx context.Context) error
+
+	// Add sets the value for the given key, reporting whether it was newly
+	// inserted rather than replacing an existing value.
+	Add(key K, value V) (inserted bool)
+
+	// Replace sets the value for the given key only if it is already in
+	// cache, reporting whether it was.
+	Replace(key K, value V) (replaced bool)
+
+	// Swap sets the value for the given key and returns the previous value,
+	// if there was one.
+	Swap(key K, value V) (old V, existed bool)
+
+	// SetWithTTL sets the value for the given key, expiring it after ttl.
+	SetWithTTL(key K, value V, ttl time.Duration)
+
 	// Get gets the value for the given key from cache.
 	Get(key K) (value V, ok bool)
 
+	// TryGet gets the value for the given key unless that would wait for
+	// the cache lock, reporting whether the lock was acquired.
+	TryGet(key K) (value V, ok, locked bool)
+
+	// TrySet sets the value for the given key unless that would wait for
+	// the cache lock, reporting whether the lock was acquired.
+	TrySet(key K, value V) (locked bool)
+
+	// GetOrLoad gets the value for the given key, loading and caching it
+	// with load on a miss. Concurrent misses share one load, and callers stop
+	// waiting when their ctx is done.
+	GetOrLoad(ctx context.Context, key K, load func(ctx context.Context, key K) (V, error)) (V, error)
+
+	// CompareAndSwap sets the value for the given key to new only if its
+	// current value equals old, reporting whether it did.
+	CompareAndSwap(key K, old, new V) (swapped bool)
+
+	// Update calls fn with the current value for the given key, storing the
+	// value fn returns if it asks to, and returns the resulting value. fn runs
+	// under the cache lock and must not call the cache.
+	Update(key K, fn func(old V, exists bool) (new V, store bool)) (value V, ok bool)
+
 	// Contains check if a key exists in cache without updating the recent-ness
 	Contains(key K) (ok bool)
 
 	// Peek returns key's value without updating the recent-ness.
 	Peek(key K) (value V, ok bool)
 
+	// TTL returns the remaining time-to-live of the given key, which is zero
+	// if it never expires.
+	TTL(key K) (ttl time.Duration, ok bool)
+
+	// Remove removes the given key from cache, reporting whether it was present.
+	Remove(key K) (present bool)
+
+	// LockKey locks the given key for the caller until it calls unlock,
+	// without locking the cache.
+	LockKey(key K) (unlock func())
+
+	// Keys returns the keys in cache, from the probation tail to the protected head.
+	Keys() []K
+
+	// NewGeneration starts a new generation that subsequent writes are
+	// stamped with, and returns it.
+	NewGeneration() (gen uint64)
+
+	// InvalidateBefore invalidates every entry written before generation
+	// gen in O(1); invalidated entries are discarded lazily on access.
+	InvalidateBefore(gen uint64)
+
 	// Len returns the number of entries in the cache.
 	Len() int
 
+	// Stats returns the statistics of the cache.
+	Stats() Stats
+
 	// Purge clears all cache entries
 	Purge()
+
+	// PurgeFunc removes the entries for which fn returns true, returning how
+	// many it removed. fn runs under the cache lock and must not call the cache.
+	PurgeFunc(fn func(key K, value V) bool) (removed int)
+
+	// Compact releases memory retained beyond what the current entries need.
+	Compact()
+
+	// Resize changes the cache size, returning the number of evicted entries.
+	Resize(size int) (evicted int)
 }
+
+var (
+	_ Cache[int, int] = (*SLRU[int, int])(nil)
+	_ Cache[int, int] = (*Sharded[int, int])(nil)
+	_ Cache[int, int] = (*Policy[int, int])(nil)
+	_ Cache[int, int] = (*SizeClasses[int, int])(nil)
+
+	_ Store[int, int] = (*Tiered[int, int])(nil)
+	_ Store[int, int] = (*FileStore[int, int])(nil)
+
+	_ LeaseStore[int, int] = (*SLRU[int, int])(nil)
+	_ LeaseStore[int, int] = (*Sharded[int, int])(nil)
+	_ LeaseStore[int, int] = (*Tiered[int, int])(nil)
+
+	_ Putter[int, int] = (*SLRU[int, int])(nil)
+	_ Putter[int, int] = (*Sharded[int, int])(nil)
+
+	_ Transactional[int, int] = (*SLRU[int, int])(nil)
+	_ Transactional[int, int] = (*Sharded[int, int])(nil)
+
+	_ Tagged[int, int] = (*SLRU[int, int])(nil)
+	_ Tagged[int, int] = (*Sharded[int, int])(nil)
+
+	_ Dependent[int, int] = (*SLRU[int, int])(nil)
+	_ Dependent[int, int] = (*Sharded[int, int])(nil)
+
+	_ ChangeNotifier[int, int] = (*SLRU[int, int])(nil)
+	_ ChangeNotifier[int, int] = (*Sharded[int, int])(nil)
+
+	_ KeyPager[int] = (*SLRU[int, int])(nil)
+	_ KeyPager[int] = (*Sharded[int, int])(nil)
+
+	_ Loading[int, int] = (*SLRU[int, int])(nil)
+	_ Loading[int, int] = (*Sharded[int, int])(nil)
+
+	_ Tenanted = (*SLRU[int, int])(nil)
+	_ Tenanted = (*Sharded[int, int])(nil)
+)
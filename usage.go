@@ -0,0 +1,78 @@
+package slru
+
+// SegmentUsage is what a segment holds against its limits.
+type SegmentUsage struct {
+	Len    int
+	Weight int
+	// Limit is the weight the segment may hold.
+	Limit int
+	// Bytes are the entry sizes reported by WithSizer, and Budget their
+	// limit set with WithSegmentBudgets, unlimited if zero.
+	Bytes  int
+	Budget int
+}
+
+// Usage is the usage of both segments.
+type Usage struct {
+	Probation SegmentUsage
+	Protected SegmentUsage
+}
+
+// WithSizer accounts the size in bytes of each entry, as returned by sizer,
+// per segment in Usage, independently of the weights bounding the cache.
+func WithSizer[K comparable, V any](sizer func(key K, value V) int) Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		s.sizer = sizer
+	}
+}
+
+// WithSegmentBudgets caps the bytes, as returned by the WithSizer function,
+// of each segment on top of their weight limits, so a few huge entries
+// can't take over protected unnoticed. A zero budget is unlimited.
+func WithSegmentBudgets[K comparable, V any](probation, protected int) Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		s.probationBudget = probation
+		s.protectedBudget = protected
+	}
+}
+
+// Usage returns the current usage of the segments.
+func (s *SLRU[K, V]) Usage() Usage {
+	s.acquireShared()
+	defer s.lock.RUnlock()
+
+	return Usage{
+		Probation: SegmentUsage{
+			Len:    s.probation.Len(),
+			Weight: s.probationWeight,
+			Limit:  s.probationLimit(),
+			Bytes:  s.probationBytes,
+			Budget: s.probationBudget,
+		},
+		Protected: SegmentUsage{
+			Len:    s.protected.Len(),
+			Weight: s.protectedWeight,
+			Limit:  s.protectedSize,
+			Bytes:  s.protectedBytes,
+			Budget: s.protectedBudget,
+		},
+	}
+}
+
+func (u *SegmentUsage) merge(o *SegmentUsage) {
+	u.Len += o.Len
+	u.Weight += o.Weight
+	u.Limit += o.Limit
+	u.Bytes += o.Bytes
+	u.Budget += o.Budget
+}
+
+// Usage returns the usage of the segments summed over the shards.
+func (c *Sharded[K, V]) Usage() (usage Usage) {
+	for _, s := range c.shards {
+		shard := s.Usage()
+		usage.Probation.merge(&shard.Probation)
+		usage.Protected.merge(&shard.Protected)
+	}
+	return usage
+}
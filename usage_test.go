@@ -0,0 +1,54 @@
+package slru
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsage(t *testing.T) {
+	sizer := func(key string, value []byte) int { return len(value) }
+	cache := newSLRU[string, []byte](10, WithSizer(sizer))
+	cache.Set("a", make([]byte, 100))
+	cache.Get("a")
+	cache.Set("b", make([]byte, 10))
+	cache.Set("c", make([]byte, 20))
+
+	require.Equal(t, Usage{
+		Probation: SegmentUsage{Len: 2, Weight: 2, Limit: 2, Bytes: 30},
+		Protected: SegmentUsage{Len: 1, Weight: 1, Limit: 8, Bytes: 100},
+	}, cache.Usage())
+	require.NoError(t, cache.Verify())
+
+	// updates are accounted too
+	cache.Set("a", make([]byte, 50))
+	require.Equal(t, 50, cache.Usage().Protected.Bytes)
+
+	sharded := NewSharded[string, []byte](20, 2, nil, WithSizer(sizer))
+	sharded.Set("a", make([]byte, 5))
+	usage := sharded.Usage()
+	require.Equal(t, 5, usage.Probation.Bytes)
+	require.Equal(t, 4, usage.Probation.Limit)
+}
+
+func TestSegmentBudgets(t *testing.T) {
+	sizer := func(key string, value []byte) int { return len(value) }
+	cache := newSLRU[string, []byte](100, WithSizer(sizer), WithSegmentBudgets[string, []byte](50, 100))
+
+	// too big for probation's budget
+	cache.Set("huge", make([]byte, 60))
+	require.False(t, cache.Contains("huge"))
+
+	for _, key := range []string{"a", "b", "c"} {
+		cache.Set(key, make([]byte, 40))
+		cache.Get(key)
+	}
+	// protected keeps 100 bytes, so only the last two hits
+	require.Equal(t, []string{"b", "c"}, cache.Keys())
+	require.Equal(t, 80, cache.Usage().Protected.Bytes)
+
+	cache.Set("d", make([]byte, 30))
+	cache.Set("e", make([]byte, 30))
+	require.False(t, cache.Contains("d"))
+	require.NoError(t, cache.Verify())
+}
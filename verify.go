@@ -0,0 +1,142 @@
+package slru
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Verify checks the internal invariants of the cache: the segment lists are
+// well formed, every segment element is indexed, the index holds nothing
+// else, the tag and dependency indexes only hold tagged and dependent
+// entries, and the segment weights match their entries and fit their limits.
+func (s *SLRU[K, V]) Verify() error {
+	s.acquireShared()
+	defer s.lock.RUnlock()
+	defer s.lockSegments()()
+
+	return s.verify()
+}
+
+func (s *SLRU[K, V]) verify() error {
+	n := 0
+	for _, l := range s.segments() {
+		if err := l.Check(); err != nil {
+			return fmt.Errorf("slru: %s: %w", s.segment(l), err)
+		}
+		weight, bytes := 0, 0
+		for e := l.Front(); e != nil; e = e.Next() {
+			ent := e.Value.(*entry[K, V])
+			if s.items[ent.key] != e {
+				return fmt.Errorf("slru: key %v in %s is not indexed", ent.key, s.segment(l))
+			}
+			weight += ent.weight
+			bytes += ent.bytes
+			n++
+		}
+		if weight != *s.weight(l) {
+			return fmt.Errorf("slru: %s weighs %d, accounted as %d", s.segment(l), weight, *s.weight(l))
+		}
+		if bytes != *s.bytes(l) {
+			return fmt.Errorf("slru: %s holds %d bytes, accounted as %d", s.segment(l), bytes, *s.bytes(l))
+		}
+	}
+	if n != len(s.items) {
+		return fmt.Errorf("slru: index holds %d keys, segments %d", len(s.items), n)
+	}
+	if s.index != nil {
+		shadowed := 0
+		s.index.m.Load().Range(func(key, _ any) bool {
+			if _, ok := s.items[key.(K)]; !ok {
+				shadowed = -1
+				return false
+			}
+			shadowed++
+			return true
+		})
+		if shadowed != len(s.items) {
+			return fmt.Errorf("slru: read index is out of sync with the index")
+		}
+	}
+	for tag, keys := range s.tags {
+		for key := range keys {
+			e, ok := s.items[key]
+			if !ok || !slices.Contains(e.Value.(*entry[K, V]).tags, tag) {
+				return fmt.Errorf("slru: tag %q indexes key %v, not tagged with it", tag, key)
+			}
+		}
+	}
+	for dep, keys := range s.dependents {
+		for key := range keys {
+			e, ok := s.items[key]
+			if !ok || !slices.Contains(e.Value.(*entry[K, V]).deps, dep) {
+				return fmt.Errorf("slru: key %v is indexed as depending on %v, not declared", key, dep)
+			}
+		}
+	}
+	if err := s.verifyTenants(); err != nil {
+		return err
+	}
+	protected, probation := s.limits()
+	if s.protectedWeight > protected {
+		return fmt.Errorf("slru: protected weighs %d over its size %d", s.protectedWeight, protected)
+	}
+	if s.bypass != nil && s.bypass.Len() > s.bypassSize {
+		return fmt.Errorf("slru: bypass holds %d entries over its size %d", s.bypass.Len(), s.bypassSize)
+	}
+	if s.probationWeight > probation {
+		return fmt.Errorf("slru: probation weighs %d over its limit %d", s.probationWeight, probation)
+	}
+	return nil
+}
+
+// Verify checks the internal invariants of every shard.
+func (c *Sharded[K, V]) Verify() error {
+	for i, s := range c.shards {
+		if err := s.Verify(); err != nil {
+			return fmt.Errorf("shard %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// unlock releases the write lock, then passes the entries evicted
+// meanwhile to the eviction callback.
+func (s *SLRU[K, V]) unlock() {
+	if evicted := s.unlockEvicted(); len(evicted) > 0 {
+		s.notify(evicted)
+	}
+	s.flushSpills()
+}
+
+// unlockAll releases the write locks of shards, then passes the entries
+// they evicted meanwhile to their eviction callbacks, so callbacks calling
+// any of them don't deadlock.
+func unlockAll[K comparable, V any](shards []*SLRU[K, V]) {
+	evicted := make([][]eviction[K, V], len(shards))
+	for i, s := range shards {
+		evicted[i] = s.unlockEvicted()
+	}
+	for i, s := range shards {
+		s.notify(evicted[i])
+		s.flushSpills()
+	}
+}
+
+// unlockEvicted releases the write lock, after the disturbances of
+// WithChaos, if any, verifying the invariants first in builds with the
+// slrudebug tag. It returns the evictions held back for the callback, for
+// callers releasing several caches before notifying any.
+func (s *SLRU[K, V]) unlockEvicted() []eviction[K, V] {
+	if s.chaos != nil {
+		s.unsettle()
+	}
+	if verifyMutations {
+		if err := s.verify(); err != nil {
+			panic(err)
+		}
+	}
+	evicted := s.evicted
+	s.evicted = nil
+	s.lock.Unlock()
+	return evicted
+}
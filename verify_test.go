@@ -0,0 +1,35 @@
+package slru
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyOnSLRU(t *testing.T) {
+	cache := newSLRU[int, int](20)
+	for i := 0; i < 30; i++ {
+		cache.Set(i, i)
+		cache.Get(i / 2)
+	}
+	require.NoError(t, cache.Verify())
+
+	// corrupt the index
+	cache.items[100] = cache.probation.Front()
+	require.ErrorContains(t, cache.Verify(), "index holds")
+	delete(cache.items, 100)
+
+	cache.probationWeight++
+	require.ErrorContains(t, cache.Verify(), "probation weighs")
+}
+
+func TestVerifyOnSharded(t *testing.T) {
+	cache := NewSharded[int, int](100, 4, nil)
+	for i := 0; i < 100; i++ {
+		cache.Set(i, i)
+	}
+	require.NoError(t, cache.Verify())
+
+	cache.shards[2].protectedWeight = -1
+	require.ErrorContains(t, cache.Verify(), "shard 2: slru: protected weighs")
+}
@@ -0,0 +1,113 @@
+package slru
+
+import "github.com/hey-kong/slru/list"
+
+// DefaultTombstones is the number of keys whose invalidated versions are
+// remembered unless set with WithTombstones.
+const DefaultTombstones = 1024
+
+// tombstone is the version a key was invalidated at.
+type tombstone struct {
+	version uint64
+	e       *list.Element
+}
+
+// WithTombstones remembers the versions of InvalidateIfOlder for the last
+// n invalidated keys, guarding them against stale fills arriving late.
+func WithTombstones[K comparable, V any](n int) Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		s.tombstoneCap = n
+	}
+}
+
+// SetVersioned sets the value for the given key at version, reporting
+// whether it did: it doesn't if the entry has a newer version, or if the
+// key was invalidated at a newer version by InvalidateIfOlder. Versions
+// come from the source of the data, such as the positions of a change
+// feed, so fills and invalidations delivered out of order can't
+// resurrect stale values. Writes other than SetVersioned reset the
+// version to zero.
+func (s *SLRU[K, V]) SetVersioned(key K, value V, version uint64) (stored bool) {
+	key = s.normalize(key)
+	s.acquire()
+	defer s.unlock()
+
+	if t, ok := s.tombstones[key]; ok && t.version > version {
+		return false
+	}
+	if ent, ok := s.live(key); ok && ent.version > version {
+		return false
+	}
+	s.set(key, value)
+	if e, ok := s.items[key]; ok {
+		e.Value.(*entry[K, V]).version = version
+	}
+	return true
+}
+
+// InvalidateIfOlder removes the entry for the given key if its version is
+// older than version, reporting whether it did, and rejects later
+// SetVersioned calls with older versions.
+func (s *SLRU[K, V]) InvalidateIfOlder(key K, version uint64) (removed bool) {
+	key = s.normalize(key)
+	s.acquire()
+	defer s.unlock()
+
+	s.bury(key, version)
+	s.removals++
+	if e, ok := s.items[key]; ok && e.Value.(*entry[K, V]).version < version {
+		return s.remove(key)
+	}
+	return false
+}
+
+// Version returns the version of the entry for the given key, which is
+// zero unless it was set by SetVersioned.
+func (s *SLRU[K, V]) Version(key K) (version uint64, ok bool) {
+	key = s.normalize(key)
+	s.acquireShared()
+	defer s.lock.RUnlock()
+
+	ent, ok := s.live(key)
+	if !ok {
+		return 0, false
+	}
+	return ent.version, true
+}
+
+// bury records that key was invalidated at version.
+func (s *SLRU[K, V]) bury(key K, version uint64) {
+	if t, ok := s.tombstones[key]; ok {
+		t.version = max(t.version, version)
+		s.tombstoneKeys.MoveToFront(t.e)
+		return
+	}
+	if s.tombstones == nil {
+		n := s.tombstoneCap
+		if n == 0 {
+			n = DefaultTombstones
+		}
+		s.tombstones = make(map[K]*tombstone)
+		s.tombstoneKeys = list.NewBounded(n, func(v any) {
+			delete(s.tombstones, v.(K))
+		})
+	}
+	if e := s.tombstoneKeys.PushFront(key); e != nil {
+		s.tombstones[key] = &tombstone{version: version, e: e}
+	}
+}
+
+// SetVersioned is SLRU.SetVersioned on the shard of key.
+func (c *Sharded[K, V]) SetVersioned(key K, value V, version uint64) (stored bool) {
+	return c.shard(key).SetVersioned(key, value, version)
+}
+
+// InvalidateIfOlder is SLRU.InvalidateIfOlder on the shard of key.
+func (c *Sharded[K, V]) InvalidateIfOlder(key K, version uint64) (removed bool) {
+	return c.shard(key).InvalidateIfOlder(key, version)
+}
+
+// Version is SLRU.Version on the shard of key.
+func (c *Sharded[K, V]) Version(key K) (version uint64, ok bool) {
+	return c.shard(key).Version(key)
+}
@@ -0,0 +1,52 @@
+package slru
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersioned(t *testing.T) {
+	cache := newSLRU[int, string](100)
+	require.True(t, cache.SetVersioned(1, "v2", 2))
+	require.False(t, cache.SetVersioned(1, "v1", 1))
+	require.True(t, cache.SetVersioned(1, "v2'", 2))
+	version, ok := cache.Version(1)
+	require.True(t, ok)
+	require.Equal(t, uint64(2), version)
+
+	// an invalidation arriving before the fill it follows
+	require.False(t, cache.InvalidateIfOlder(1, 2))
+	require.True(t, cache.InvalidateIfOlder(1, 3))
+	require.False(t, cache.Contains(1))
+	require.False(t, cache.SetVersioned(1, "v2", 2))
+	require.True(t, cache.SetVersioned(1, "v3", 3))
+	value, _ := cache.Get(1)
+	require.Equal(t, "v3", value)
+
+	require.False(t, cache.InvalidateIfOlder(2, 5))
+	require.False(t, cache.SetVersioned(2, "v4", 4))
+
+	// unversioned writes reset the version
+	cache.Set(1, "plain")
+	version, _ = cache.Version(1)
+	require.Zero(t, version)
+	require.True(t, cache.SetVersioned(1, "v3", 3))
+	_, ok = cache.Version(3)
+	require.False(t, ok)
+	require.NoError(t, cache.Verify())
+}
+
+func TestTombstones(t *testing.T) {
+	cache := newSLRU[int, string](100, WithTombstones[int, string](2))
+	cache.InvalidateIfOlder(1, 10)
+	cache.InvalidateIfOlder(2, 10)
+	cache.InvalidateIfOlder(1, 11)
+	cache.InvalidateIfOlder(3, 10)
+
+	// 2 was the least recently invalidated
+	require.True(t, cache.SetVersioned(2, "v", 1))
+	require.False(t, cache.SetVersioned(1, "v", 10))
+	require.False(t, cache.SetVersioned(3, "v", 9))
+	require.Len(t, cache.tombstones, 2)
+}
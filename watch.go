@@ -0,0 +1,148 @@
+package slru
+
+import (
+	"time"
+
+	"github.com/hey-kong/slru/list"
+)
+
+// DefaultWatchDepth is the number of accesses kept per watched key.
+const DefaultWatchDepth = 16
+
+// Access outcomes recorded for watched keys.
+const (
+	AccessHit      = "hit"
+	AccessMiss     = "miss"
+	AccessExpired  = "expired"
+	AccessSet      = "set"
+	AccessRejected = "rejected"
+	AccessEvicted  = "evicted"
+	AccessRemoved  = "removed"
+)
+
+// Access is an operation on a watched key and its outcome.
+type Access struct {
+	Time    time.Time
+	Outcome string
+	// Segment is where the entry was before the operation, empty if it
+	// wasn't cached.
+	Segment string
+}
+
+// accessRing keeps the latest accesses of a watched key.
+type accessRing struct {
+	accesses []Access
+	next     int
+	full     bool
+}
+
+func (r *accessRing) add(a Access) {
+	r.accesses[r.next] = a
+	r.next = (r.next + 1) % len(r.accesses)
+	r.full = r.full || r.next == 0
+}
+
+// list returns the accesses from oldest to newest.
+func (r *accessRing) list() []Access {
+	if !r.full {
+		return append([]Access(nil), r.accesses[:r.next]...)
+	}
+	return append(append([]Access(nil), r.accesses[r.next:]...), r.accesses[:r.next]...)
+}
+
+// WithWatchedKeys keeps the last depth accesses of each of keys, queryable
+// with AccessLog. A non-positive depth uses DefaultWatchDepth.
+func WithWatchedKeys[K comparable, V any](depth int, keys ...K) Option[K, V] {
+	return func(s *SLRU[K, V]) {
+		if depth > 0 {
+			s.watchDepth = depth
+		}
+		s.watchKeys = append(s.watchKeys, keys...)
+	}
+}
+
+// startWatching watches the keys of WithWatchedKeys, normalized once
+// every option has run.
+func (s *SLRU[K, V]) startWatching() {
+	for _, key := range s.watchKeys {
+		s.watchKey(s.normalize(key))
+	}
+	s.watchKeys = nil
+}
+
+// Watch starts keeping the recent accesses of key.
+func (s *SLRU[K, V]) Watch(key K) {
+	key = s.normalize(key)
+	s.acquire()
+	defer s.unlock()
+
+	s.watchKey(key)
+}
+
+// Unwatch stops keeping the accesses of key and drops its log.
+func (s *SLRU[K, V]) Unwatch(key K) {
+	key = s.normalize(key)
+	s.acquire()
+	defer s.unlock()
+
+	delete(s.watched, key)
+}
+
+// AccessLog returns the recent accesses of a watched key from oldest to
+// newest, or nil if key isn't watched.
+func (s *SLRU[K, V]) AccessLog(key K) []Access {
+	key = s.normalize(key)
+	s.acquireShared()
+	defer s.lock.RUnlock()
+
+	if r, ok := s.watched[key]; ok {
+		return r.list()
+	}
+	return nil
+}
+
+func (s *SLRU[K, V]) watchKey(key K) {
+	if s.watched == nil {
+		s.watched = make(map[K]*accessRing)
+	}
+	if _, ok := s.watched[key]; !ok {
+		depth := s.watchDepth
+		if depth < 1 {
+			depth = DefaultWatchDepth
+		}
+		s.watched[key] = &accessRing{accesses: make([]Access, depth)}
+	}
+}
+
+// observe records an access to key if it is watched, where l is the segment
+// the entry was in or nil.
+func (s *SLRU[K, V]) observe(key K, outcome string, l *list.List) {
+	if len(s.watched) == 0 {
+		return
+	}
+	r, ok := s.watched[key]
+	if !ok {
+		return
+	}
+	a := Access{Time: time.Now(), Outcome: outcome}
+	if l != nil {
+		a.Segment = s.segment(l)
+	}
+	r.add(a)
+}
+
+// Watch starts keeping the recent accesses of key.
+func (c *Sharded[K, V]) Watch(key K) {
+	c.shard(key).Watch(key)
+}
+
+// Unwatch stops keeping the accesses of key and drops its log.
+func (c *Sharded[K, V]) Unwatch(key K) {
+	c.shard(key).Unwatch(key)
+}
+
+// AccessLog returns the recent accesses of a watched key from oldest to
+// newest, or nil if key isn't watched.
+func (c *Sharded[K, V]) AccessLog(key K) []Access {
+	return c.shard(key).AccessLog(key)
+}
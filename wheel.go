@@ -0,0 +1,102 @@
+package slru
+
+import "time"
+
+const (
+	wheelBits   = 6
+	wheelSlots  = 1 << wheelBits
+	wheelLevels = 4
+)
+
+// timingWheel schedules entry expirations in a hierarchical timing wheel.
+// Level 0 has a slot per tick, and each higher level slots span the whole
+// level below, so advancing the wheel only looks at the entries due and at
+// the slots cascading down, never at every entry with a TTL.
+type timingWheel[K comparable, V any] struct {
+	tick  time.Duration
+	start time.Time
+	// now is the last tick reached, whose level 0 slot is still being
+	// processed if draining.
+	now      int64
+	draining bool
+	slots    [wheelLevels][wheelSlots]map[*entry[K, V]]struct{}
+}
+
+func newTimingWheel[K comparable, V any](tick time.Duration, start time.Time) *timingWheel[K, V] {
+	return &timingWheel[K, V]{tick: tick, start: start}
+}
+
+// tickOf returns the first tick at or after t.
+func (w *timingWheel[K, V]) tickOf(t time.Time) int64 {
+	d := t.Sub(w.start)
+	return int64((d + w.tick - 1) / w.tick)
+}
+
+// schedule places ent in the slot of its expiry, no earlier than the next
+// tick.
+func (w *timingWheel[K, V]) schedule(ent *entry[K, V]) {
+	w.place(ent, max(w.tickOf(ent.expireAt), w.now+1))
+}
+
+func (w *timingWheel[K, V]) place(ent *entry[K, V], t int64) {
+	level := 0
+	for delta := t - w.now; level < wheelLevels-1 && delta >= 1<<(wheelBits*(level+1)); level++ {
+	}
+	slot := (t >> (wheelBits * level)) & (wheelSlots - 1)
+	if w.slots[level][slot] == nil {
+		w.slots[level][slot] = make(map[*entry[K, V]]struct{})
+	}
+	w.slots[level][slot][ent] = struct{}{}
+	ent.wheelLevel, ent.wheelSlot = int8(level+1), uint8(slot)
+}
+
+// unschedule removes ent from the wheel if it is scheduled.
+func (w *timingWheel[K, V]) unschedule(ent *entry[K, V]) {
+	if ent.wheelLevel == 0 {
+		return
+	}
+	delete(w.slots[ent.wheelLevel-1][ent.wheelSlot], ent)
+	ent.wheelLevel = 0
+}
+
+// advance processes the ticks that passed by now, calling due with each
+// entry whose expiry tick has passed, and returns how many. Entries are
+// unscheduled before due is called. With a positive limit, it stops after
+// limit entries, and the next call resumes where it stopped.
+func (w *timingWheel[K, V]) advance(now time.Time, limit int, due func(ent *entry[K, V])) (done int) {
+	to := int64(now.Sub(w.start) / w.tick)
+	for {
+		if w.draining {
+			slot := w.now & (wheelSlots - 1)
+			for ent := range w.slots[0][slot] {
+				if limit > 0 && done == limit {
+					return done
+				}
+				delete(w.slots[0][slot], ent)
+				ent.wheelLevel = 0
+				due(ent)
+				done++
+			}
+			w.slots[0][slot] = nil
+			w.draining = false
+		}
+		if w.now >= to {
+			return done
+		}
+		w.now++
+		// cascade the higher levels whose slot starts at this tick, from
+		// the top down
+		for level := wheelLevels - 1; level > 0; level-- {
+			if w.now&(1<<(wheelBits*level)-1) != 0 {
+				continue
+			}
+			slot := (w.now >> (wheelBits * level)) & (wheelSlots - 1)
+			entries := w.slots[level][slot]
+			w.slots[level][slot] = nil
+			for ent := range entries {
+				w.place(ent, max(w.tickOf(ent.expireAt), w.now))
+			}
+		}
+		w.draining = true
+	}
+}
@@ -0,0 +1,90 @@
+package slru
+
+import (
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimingWheel(t *testing.T) {
+	start := time.Unix(0, 0)
+	w := newTimingWheel[int, int](time.Second, start)
+
+	r := rand.New(rand.NewPCG(1, 2))
+	want := map[*entry[int, int]]int64{}
+	for i := 0; i < 1000; i++ {
+		tick := 1 + r.Int64N(300000)
+		if i%10 == 0 {
+			tick = 1 + r.Int64N(100)
+		}
+		ent := &entry[int, int]{key: i, expireAt: start.Add(time.Duration(tick)*time.Second - time.Millisecond)}
+		w.schedule(ent)
+		want[ent] = tick
+	}
+	// unscheduled entries never come due
+	gone := &entry[int, int]{expireAt: start.Add(5 * time.Second)}
+	w.schedule(gone)
+	w.unschedule(gone)
+
+	for now := int64(1); now <= 300000; now += 1 + r.Int64N(500) {
+		w.advance(start.Add(time.Duration(now)*time.Second), 0, func(ent *entry[int, int]) {
+			tick, ok := want[ent]
+			require.True(t, ok)
+			require.LessOrEqual(t, tick, now)
+			require.False(t, ent.expireAt.After(start.Add(time.Duration(now)*time.Second)))
+			delete(want, ent)
+		})
+		for ent, tick := range want {
+			require.Greater(t, tick, now, "entry %d is overdue", ent.key)
+		}
+	}
+	w.advance(start.Add(300001*time.Second), 0, func(ent *entry[int, int]) {
+		delete(want, ent)
+	})
+	require.Empty(t, want)
+}
+
+func TestTimingWheelLimit(t *testing.T) {
+	start := time.Unix(0, 0)
+	w := newTimingWheel[int, int](time.Second, start)
+	for i := range 10 {
+		w.schedule(&entry[int, int]{key: i, expireAt: start.Add(time.Duration(1+i%2) * time.Second)})
+	}
+	late := &entry[int, int]{key: 10, expireAt: start.Add(10 * time.Second)}
+	w.schedule(late)
+
+	var due []int
+	collect := func(ent *entry[int, int]) { due = append(due, ent.key) }
+	now := start.Add(5 * time.Second)
+	require.Equal(t, 4, w.advance(now, 4, collect))
+	require.Equal(t, 4, w.advance(now, 4, collect))
+	require.Equal(t, 2, w.advance(now, 4, collect))
+	require.Zero(t, w.advance(now, 4, collect))
+	require.ElementsMatch(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, due)
+	require.Equal(t, 1, w.advance(start.Add(10*time.Second), 4, collect))
+}
+
+func TestJanitor(t *testing.T) {
+	cache := New[int, int](100, WithJanitor[int, int](5*time.Millisecond))
+	cache.SetWithTTL(1, 1, 10*time.Millisecond)
+	cache.SetWithTTL(2, 2, time.Hour)
+	cache.Set(3, 3)
+	cache.SetWithTTL(4, 4, 10*time.Millisecond)
+	cache.Remove(4)
+
+	require.Eventually(t, func() bool { return cache.Len() == 2 }, time.Second, 5*time.Millisecond)
+	require.True(t, cache.Contains(2))
+	require.True(t, cache.Contains(3))
+	require.Equal(t, uint64(1), cache.Stats().Expirations)
+}
+
+func TestJanitorLimit(t *testing.T) {
+	cache := newSLRU[int, int](100, WithJanitor[int, int](time.Millisecond), WithJanitorLimit[int, int](2))
+	for i := range 10 {
+		cache.SetWithTTL(i, i, time.Millisecond)
+	}
+	require.Eventually(t, func() bool { return cache.Stats().Expirations == 10 }, time.Second, time.Millisecond)
+	require.Zero(t, cache.Len())
+}